@@ -8,10 +8,22 @@ import (
 	adminCommon "github.com/splitio/split-synchronizer/v5/splitio/admin/common"
 	"github.com/splitio/split-synchronizer/v5/splitio/admin/controllers"
 	"github.com/splitio/split-synchronizer/v5/splitio/common"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/changefeed"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/evaluator"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/history"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/impressiontap"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/queues"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/snapshot"
 	cstorage "github.com/splitio/split-synchronizer/v5/splitio/common/storage"
+	ssync "github.com/splitio/split-synchronizer/v5/splitio/common/sync"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/usage"
 	"github.com/splitio/split-synchronizer/v5/splitio/producer/evcalc"
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/fairness"
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/task"
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/worker"
 	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/application"
 	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/services"
+	proxyStorage "github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
 
 	"github.com/gin-gonic/gin"
 )
@@ -36,6 +48,17 @@ type Options struct {
 	HcAppMonitor      application.MonitorIterface
 	HcServicesMonitor services.MonitorIterface
 	Snapshotter       cstorage.Snapshotter
+	SnapshotLoader    snapshot.Loader
+	Overrides         proxyStorage.SplitOverrides
+	Resyncer          ssync.Resyncer
+	History           history.Store
+	ChangeFeed        *changefeed.Feed
+	Usage             *usage.Tracker
+	ImpressionTap     *impressiontap.Tap
+	Pipelines         []*task.PipelinedSyncTask
+	QueueCaps         *worker.QueueCapWorker
+	Queues            *queues.Manager
+	Fairness          []*fairness.Tracker
 	FullConfig        interface{}
 }
 
@@ -60,6 +83,8 @@ func NewServer(options *Options) (*http.Server, error) {
 		options.EventsEvCalc,
 		options.Runtime,
 		options.HcAppMonitor,
+		options.Pipelines,
+		options.QueueCaps,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error instantiating dashboard controller: %w", err)
@@ -85,11 +110,66 @@ func NewServer(options *Options) (*http.Server, error) {
 	}
 	observabilityController.Register(admin)
 
+	apiController := controllers.NewAPIController(options.Logger, options.Storages)
+	api := admin.Group("/api/v1")
+	apiController.Register(api)
+
+	debuggerController := controllers.NewDebuggerController(
+		options.Logger,
+		evaluator.NewEvaluator(options.Storages.SplitStorage, options.Storages.SegmentStorage, options.Logger),
+	)
+	debuggerController.Register(admin)
+
+	if options.History != nil {
+		historyController := controllers.NewHistoryController(options.Logger, options.History)
+		historyController.Register(api)
+	}
+
+	if options.Usage != nil {
+		usageController := controllers.NewUsageController(options.Logger, options.Usage, options.Storages.SplitStorage)
+		usageController.Register(api)
+	}
+
+	if len(options.Pipelines) > 0 {
+		pipelinesController := controllers.NewPipelinesController(options.Logger, options.Pipelines)
+		pipelinesController.Register(api)
+	}
+
+	if len(options.Fairness) > 0 {
+		producersController := controllers.NewProducersController(options.Logger, options.Fairness)
+		producersController.Register(api)
+	}
+
+	if options.Queues != nil {
+		queuesController := controllers.NewQueuesController(options.Logger, options.Queues)
+		queuesController.Register(api)
+	}
+
+	if options.ImpressionTap != nil {
+		impressionTapController := controllers.NewImpressionTapController(options.Logger, options.ImpressionTap)
+		impressionTapController.Register(admin)
+	}
+
+	if options.ChangeFeed != nil {
+		eventsController := controllers.NewEventsController(options.Logger, options.ChangeFeed)
+		eventsController.Register(admin)
+	}
+
 	if options.Snapshotter != nil {
-		snapshotController := controllers.NewSnapshotController(options.Logger, options.Snapshotter)
+		snapshotController := controllers.NewSnapshotController(options.Logger, options.Snapshotter, options.SnapshotLoader)
 		snapshotController.Register(admin)
 	}
 
+	if options.Overrides != nil {
+		overridesController := controllers.NewOverridesController(options.Logger, options.Overrides)
+		overridesController.Register(admin)
+	}
+
+	if options.Resyncer != nil {
+		resyncController := controllers.NewResyncController(options.Logger, options.Resyncer)
+		resyncController.Register(admin)
+	}
+
 	return &http.Server{
 		Addr:    fmt.Sprintf("%s:%d", options.Host, options.Port),
 		Handler: router,
@@ -0,0 +1,331 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/storage"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	adminCommon "github.com/splitio/split-synchronizer/v5/splitio/admin/common"
+)
+
+const (
+	defaultAPIPageSize = 100
+	maxAPIPageSize     = 1000
+)
+
+// APIController exposes a versioned, read-only json API over the splits & segments stored locally
+type APIController struct {
+	logger           logging.LoggerInterface
+	storages         adminCommon.Storages
+	segmentKeysCache *segmentKeysCache
+}
+
+// NewAPIController constructs a new admin API controller
+func NewAPIController(logger logging.LoggerInterface, storages adminCommon.Storages) *APIController {
+	return &APIController{
+		logger:           logger,
+		storages:         storages,
+		segmentKeysCache: newSegmentKeysCache(storages.SegmentStorage, maxCachedSegmentKeys),
+	}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *APIController) Register(router gin.IRouter) {
+	router.GET("/splits", c.splits)
+	router.GET("/splits/:name", c.split)
+	router.GET("/segments", c.segments)
+	router.GET("/segments/:name/keys", c.segmentKeys)
+	router.GET("/openapi.json", c.spec)
+}
+
+// SplitView is the representation of a split returned by the API
+type SplitView struct {
+	Name             string        `json:"name"`
+	TrafficType      string        `json:"trafficType"`
+	Killed           bool          `json:"killed"`
+	Status           string        `json:"status"`
+	DefaultTreatment string        `json:"defaultTreatment"`
+	ChangeNumber     int64         `json:"changeNumber"`
+	Treatments       []string      `json:"treatments"`
+	Segments         []string      `json:"segments"`
+	Definition       dtos.SplitDTO `json:"definition"`
+}
+
+// SegmentView is the representation of a segment returned by the API
+type SegmentView struct {
+	Name         string   `json:"name"`
+	ChangeNumber int64    `json:"changeNumber"`
+	KeyCount     int      `json:"keyCount"`
+	UsedBy       []string `json:"usedBy"`
+}
+
+// SegmentKeyView is the representation of a segment key returned by the API.
+// Removed keys are only reported in proxy mode
+type SegmentKeyView struct {
+	Key          string `json:"key"`
+	Removed      bool   `json:"removed,omitempty"`
+	ChangeNumber int64  `json:"changeNumber,omitempty"`
+}
+
+// Page wraps a list of items and the cursor to be used to fetch the next page (if any)
+type Page struct {
+	Items      interface{} `json:"items"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+}
+
+func (c *APIController) splits(ctx *gin.Context) {
+	// curl 'http://localhost:3010/admin/api/v1/splits?prefix=new_&trafficType=user&killed=false&segment=employees&limit=10'
+	paging, err := parsePaging(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var killed *bool
+	if raw := ctx.Query("killed"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "killed must be a boolean"})
+			return
+		}
+		killed = &parsed
+	}
+
+	prefix, trafficType, segment := ctx.Query("prefix"), ctx.Query("trafficType"), ctx.Query("segment")
+	all := c.storages.SplitStorage.All()
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+
+	views := make([]SplitView, 0, paging.limit)
+	page := Page{}
+	for idx := range all {
+		split := &all[idx]
+		if split.Name <= paging.after ||
+			!strings.HasPrefix(split.Name, prefix) ||
+			(trafficType != "" && split.TrafficTypeName != trafficType) ||
+			(killed != nil && split.Killed != *killed) {
+			continue
+		}
+
+		view := toSplitView(split)
+		if segment != "" && !contains(view.Segments, segment) {
+			continue
+		}
+
+		if len(views) == paging.limit {
+			page.NextCursor = encodeCursor(views[len(views)-1].Name)
+			break
+		}
+		views = append(views, view)
+	}
+
+	page.Items = views
+	ctx.JSON(http.StatusOK, page)
+}
+
+func (c *APIController) split(ctx *gin.Context) {
+	// curl http://localhost:3010/admin/api/v1/splits/some_split
+	split := c.storages.SplitStorage.Split(ctx.Param("name"))
+	if split == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "split not found"})
+		return
+	}
+	ctx.JSON(http.StatusOK, toSplitView(split))
+}
+
+func (c *APIController) segments(ctx *gin.Context) {
+	// curl 'http://localhost:3010/admin/api/v1/segments?prefix=beta_&limit=10'
+	paging, err := parsePaging(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	usedBy := make(map[string][]string)
+	for _, split := range c.storages.SplitStorage.All() {
+		for _, segment := range referencedSegments(&split) {
+			usedBy[segment] = append(usedBy[segment], split.Name)
+		}
+	}
+
+	names := make([]string, 0, len(usedBy))
+	for name := range usedBy {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	keyCount := segmentKeyCounter(c.storages.SegmentStorage)
+	prefix := ctx.Query("prefix")
+	views := make([]SegmentView, 0, paging.limit)
+	page := Page{}
+	for _, name := range names {
+		if name <= paging.after || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		if len(views) == paging.limit {
+			page.NextCursor = encodeCursor(views[len(views)-1].Name)
+			break
+		}
+
+		view := SegmentView{Name: name, UsedBy: usedBy[name], KeyCount: keyCount(name)}
+		view.ChangeNumber, _ = c.storages.SegmentStorage.ChangeNumber(name)
+		sort.Strings(view.UsedBy)
+		views = append(views, view)
+	}
+
+	page.Items = views
+	ctx.JSON(http.StatusOK, page)
+}
+
+func (c *APIController) segmentKeys(ctx *gin.Context) {
+	// curl 'http://localhost:3010/admin/api/v1/segments/some_segment/keys?prefix=user_&limit=100'
+	paging, err := parsePaging(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := ctx.Param("name")
+	keys := c.segmentKeysCache.keys(name)
+	if len(keys) == 0 && c.storages.SegmentStorage.Keys(name) == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "segment not found"})
+		return
+	}
+
+	// cached keys are sorted, so the page starts right after the cursor & prefix matches are contiguous
+	prefix := ctx.Query("prefix")
+	start := sort.Search(len(keys), func(i int) bool { return keys[i].Name > paging.after && keys[i].Name >= prefix })
+	views := make([]SegmentKeyView, 0, paging.limit)
+	page := Page{}
+	for _, key := range keys[start:] {
+		if !strings.HasPrefix(key.Name, prefix) {
+			break
+		}
+
+		if len(views) == paging.limit {
+			page.NextCursor = encodeCursor(views[len(views)-1].Key)
+			break
+		}
+		views = append(views, SegmentKeyView{Key: key.Name, Removed: key.Removed, ChangeNumber: key.ChangeNumber})
+	}
+
+	page.Items = views
+	ctx.JSON(http.StatusOK, page)
+}
+
+func (c *APIController) spec(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "application/json", []byte(openAPISpec))
+}
+
+type paging struct {
+	limit int
+	after string
+}
+
+// parsePaging reads the limit & the cursor from the querystring. Cursors are opaque to clients, and are built
+// by encoding the last item returned, since all collections are sorted by name
+func parsePaging(ctx *gin.Context) (*paging, error) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultAPIPageSize)))
+	if err != nil || limit <= 0 || limit > maxAPIPageSize {
+		return nil, fmt.Errorf("limit must be an integer between 1 and %d", maxAPIPageSize)
+	}
+
+	after, err := base64.RawURLEncoding.DecodeString(ctx.Query("cursor"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &paging{limit: limit, after: string(after)}, nil
+}
+
+func encodeCursor(last string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(last))
+}
+
+func toSplitView(split *dtos.SplitDTO) SplitView {
+	treatments := make(map[string]struct{})
+	for _, condition := range split.Conditions {
+		for _, partition := range condition.Partitions {
+			treatments[partition.Treatment] = struct{}{}
+		}
+	}
+
+	view := SplitView{
+		Name:             split.Name,
+		TrafficType:      split.TrafficTypeName,
+		Killed:           split.Killed,
+		Status:           split.Status,
+		DefaultTreatment: split.DefaultTreatment,
+		ChangeNumber:     split.ChangeNumber,
+		Treatments:       make([]string, 0, len(treatments)),
+		Segments:         referencedSegments(split),
+		Definition:       *split,
+	}
+	for treatment := range treatments {
+		view.Treatments = append(view.Treatments, treatment)
+	}
+	sort.Strings(view.Treatments)
+	return view
+}
+
+func referencedSegments(split *dtos.SplitDTO) []string {
+	segments := make([]string, 0)
+	for _, condition := range split.Conditions {
+		for _, matcher := range condition.MatcherGroup.Matchers {
+			if matcher.UserDefinedSegment != nil && !contains(segments, matcher.UserDefinedSegment.SegmentName) {
+				segments = append(segments, matcher.UserDefinedSegment.SegmentName)
+			}
+		}
+	}
+	sort.Strings(segments)
+	return segments
+}
+
+// segmentSizer is implemented by storages able to count the keys in a segment without fetching them (SCARD in redis)
+type segmentSizer interface {
+	Size(name string) (int, error)
+}
+
+// segmentCounter is implemented by storages that keep track of the number of active keys in each segment (proxy)
+type segmentCounter interface {
+	NamesAndCount() map[string]int
+}
+
+// segmentKeyCounter returns a function that counts the keys of a segment, avoiding reading whole segments
+// whenever the storage supports it
+func segmentKeyCounter(segmentStorage storage.SegmentStorage) func(name string) int {
+	if sizer, ok := segmentStorage.(segmentSizer); ok {
+		return func(name string) int {
+			size, _ := sizer.Size(name)
+			return size
+		}
+	}
+
+	if counter, ok := segmentStorage.(segmentCounter); ok {
+		counts := counter.NamesAndCount()
+		return func(name string) int { return counts[name] }
+	}
+
+	return func(name string) int {
+		if keys := segmentStorage.Keys(name); keys != nil {
+			return keys.Size()
+		}
+		return 0
+	}
+}
+
+func contains(items []string, item string) bool {
+	for _, current := range items {
+		if current == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/storage/inmemory/mutexmap"
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	adminCommon "github.com/splitio/split-synchronizer/v5/splitio/admin/common"
+)
+
+func segmentCondition(segment string, treatment string) dtos.ConditionDTO {
+	return dtos.ConditionDTO{
+		MatcherGroup: dtos.MatcherGroupDTO{Matchers: []dtos.MatcherDTO{{
+			MatcherType:        "IN_SEGMENT",
+			UserDefinedSegment: &dtos.UserDefinedSegmentMatcherDataDTO{SegmentName: segment},
+		}}},
+		Partitions: []dtos.PartitionDTO{{Treatment: treatment, Size: 100}},
+	}
+}
+
+type sizedSegmentStorage struct {
+	*mutexmap.MMSegmentStorage
+	sized  []string
+	listed int
+}
+
+func (s *sizedSegmentStorage) Size(name string) (int, error) {
+	s.sized = append(s.sized, name)
+	return 42, nil
+}
+
+func (s *sizedSegmentStorage) Keys(name string) *set.ThreadUnsafeSet {
+	s.listed++
+	return s.MMSegmentStorage.Keys(name)
+}
+
+func TestAPISegmentKeyCount(t *testing.T) {
+	splits := mutexmap.NewMMSplitStorage()
+	splits.Update([]dtos.SplitDTO{{Name: "split_a", Conditions: []dtos.ConditionDTO{segmentCondition("employees", "on")}}}, nil, 1)
+	segments := &sizedSegmentStorage{MMSegmentStorage: mutexmap.NewMMSegmentStorage()}
+	segments.Update("employees", set.NewSet("key1", "key2"), set.NewSet(), 10)
+
+	ctrl := NewAPIController(logging.NewLogger(nil), adminCommon.Storages{SplitStorage: splits, SegmentStorage: segments})
+	_, router := gin.CreateTestContext(httptest.NewRecorder())
+	ctrl.Register(router.Group("/api/v1"))
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/segments", nil)
+	router.ServeHTTP(resp, req)
+
+	var page struct {
+		Items []SegmentView `json:"items"`
+	}
+	json.Unmarshal(resp.Body.Bytes(), &page)
+	if len(page.Items) != 1 || page.Items[0].KeyCount != 42 {
+		t.Error("the key count should come from the storage cardinality. Got: ", page.Items)
+	}
+
+	if len(segments.sized) != 1 || segments.sized[0] != "employees" || segments.listed != 0 {
+		t.Error("segments should be counted without reading their keys. Got: ", segments.sized, segments.listed)
+	}
+}
+
+func TestAPIEndpoints(t *testing.T) {
+	splits := mutexmap.NewMMSplitStorage()
+	splits.Update([]dtos.SplitDTO{
+		{Name: "split_a", TrafficTypeName: "user", ChangeNumber: 1, Conditions: []dtos.ConditionDTO{segmentCondition("employees", "on")}},
+		{Name: "split_b", TrafficTypeName: "user", ChangeNumber: 2, Killed: true},
+		{Name: "split_c", TrafficTypeName: "account", ChangeNumber: 3, Conditions: []dtos.ConditionDTO{segmentCondition("employees", "on"), segmentCondition("beta", "off")}},
+		{Name: "other", TrafficTypeName: "user", ChangeNumber: 4},
+	}, nil, 4)
+
+	segments := mutexmap.NewMMSegmentStorage()
+	segments.Update("employees", set.NewSet("key1", "key2", "key3", "other1"), set.NewSet(), 10)
+	segments.Update("beta", set.NewSet("key1"), set.NewSet(), 11)
+
+	ctrl := NewAPIController(logging.NewLogger(nil), adminCommon.Storages{SplitStorage: splits, SegmentStorage: segments})
+	_, router := gin.CreateTestContext(httptest.NewRecorder())
+	ctrl.Register(router.Group("/api/v1"))
+
+	get := func(path string, into interface{}) int {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(resp, req)
+		if into != nil {
+			json.Unmarshal(resp.Body.Bytes(), into)
+		}
+		return resp.Code
+	}
+
+	type splitsPage struct {
+		Items      []SplitView `json:"items"`
+		NextCursor string      `json:"nextCursor"`
+	}
+
+	var page splitsPage
+	if code := get("/api/v1/splits?prefix=split_&limit=2", &page); code != 200 || len(page.Items) != 2 || page.Items[0].Name != "split_a" || page.NextCursor == "" {
+		t.Error("wrong first page: ", code, page)
+	}
+
+	cursor := page.NextCursor
+	page = splitsPage{}
+	if get("/api/v1/splits?prefix=split_&limit=2&cursor="+cursor, &page); len(page.Items) != 1 || page.Items[0].Name != "split_c" || page.NextCursor != "" {
+		t.Error("wrong second page: ", page)
+	}
+
+	page = splitsPage{}
+	if get("/api/v1/splits?segment=employees&trafficType=user", &page); len(page.Items) != 1 || page.Items[0].Name != "split_a" {
+		t.Error("wrong segment & traffic type filtering: ", page)
+	}
+
+	page = splitsPage{}
+	if get("/api/v1/splits?killed=true", &page); len(page.Items) != 1 || page.Items[0].Name != "split_b" {
+		t.Error("wrong killed filtering: ", page)
+	}
+
+	if code := get("/api/v1/splits?killed=maybe", nil); code != http.StatusBadRequest {
+		t.Error("invalid filters should be rejected. Got: ", code)
+	}
+
+	var split SplitView
+	if code := get("/api/v1/splits/split_c", &split); code != 200 || len(split.Segments) != 2 || split.Segments[0] != "beta" || split.Definition.ChangeNumber != 3 {
+		t.Error("wrong split: ", code, split)
+	}
+
+	if code := get("/api/v1/splits/nonexistent", nil); code != http.StatusNotFound {
+		t.Error("unknown splits should return 404. Got: ", code)
+	}
+
+	var segmentsPage struct {
+		Items []SegmentView `json:"items"`
+	}
+	if get("/api/v1/segments", &segmentsPage); len(segmentsPage.Items) != 2 || segmentsPage.Items[1].Name != "employees" ||
+		segmentsPage.Items[1].KeyCount != 4 || len(segmentsPage.Items[1].UsedBy) != 2 || segmentsPage.Items[1].ChangeNumber != 10 {
+		t.Error("wrong segments: ", segmentsPage)
+	}
+
+	var keysPage struct {
+		Items      []SegmentKeyView `json:"items"`
+		NextCursor string           `json:"nextCursor"`
+	}
+	if get("/api/v1/segments/employees/keys?prefix=key&limit=2", &keysPage); len(keysPage.Items) != 2 || keysPage.Items[0].Key != "key1" || keysPage.NextCursor == "" {
+		t.Error("wrong keys page: ", keysPage)
+	}
+
+	cursor = keysPage.NextCursor
+	keysPage.Items, keysPage.NextCursor = nil, ""
+	if get("/api/v1/segments/employees/keys?prefix=key&limit=2&cursor="+cursor, &keysPage); len(keysPage.Items) != 1 || keysPage.Items[0].Key != "key3" || keysPage.NextCursor != "" {
+		t.Error("wrong second keys page: ", keysPage)
+	}
+
+	if code := get("/api/v1/segments/nonexistent/keys", nil); code != http.StatusNotFound {
+		t.Error("unknown segments should return 404. Got: ", code)
+	}
+
+	var spec map[string]interface{}
+	if code := get("/api/v1/openapi.json", &spec); code != 200 || spec["openapi"] != "3.0.3" {
+		t.Error("the spec should be served as valid json. Got: ", code)
+	}
+}
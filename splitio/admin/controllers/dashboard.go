@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"html/template"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/splitio/go-toolkit/v5/logging"
@@ -13,8 +14,11 @@ import (
 	adminCommon "github.com/splitio/split-synchronizer/v5/splitio/admin/common"
 	"github.com/splitio/split-synchronizer/v5/splitio/admin/views/dashboard"
 	"github.com/splitio/split-synchronizer/v5/splitio/common"
+	cstorage "github.com/splitio/split-synchronizer/v5/splitio/common/storage"
 	"github.com/splitio/split-synchronizer/v5/splitio/log"
 	"github.com/splitio/split-synchronizer/v5/splitio/producer/evcalc"
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/task"
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/worker"
 	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/application"
 )
 
@@ -29,8 +33,18 @@ type DashboardController struct {
 	eventsEvCalc      evcalc.Monitor
 	runtime           common.Runtime
 	appMonitor        application.MonitorIterface
+	segmentLookup     cstorage.SegmentLookup
+	segmentKeysCache  *segmentKeysCache
+	pipelines         []*task.PipelinedSyncTask
+	queueCaps         *worker.QueueCapWorker
 }
 
+const (
+	defaultSegmentKeysPageSize = 100
+	maxSegmentKeysPageSize     = 1000
+	maxCachedSegmentKeys       = 10
+)
+
 // NewDashboardController instantiates a new dashboard controller
 func NewDashboardController(
 	name string,
@@ -41,6 +55,8 @@ func NewDashboardController(
 	eventsEvCalc evcalc.Monitor,
 	runtime common.Runtime,
 	appMonitor application.MonitorIterface,
+	pipelines []*task.PipelinedSyncTask,
+	queueCaps *worker.QueueCapWorker,
 ) (*DashboardController, error) {
 
 	toReturn := &DashboardController{
@@ -52,6 +68,10 @@ func NewDashboardController(
 		eventsEvCalc:      eventsEvCalc,
 		impressionsEvCalc: impressionEvCalc,
 		appMonitor:        appMonitor,
+		pipelines:         pipelines,
+		queueCaps:         queueCaps,
+		segmentLookup:     cstorage.NewSegmentLookup(storages.SplitStorage, storages.SegmentStorage, logger),
+		segmentKeysCache:  newSegmentKeysCache(storages.SegmentStorage, maxCachedSegmentKeys),
 	}
 
 	var err error
@@ -66,6 +86,7 @@ func NewDashboardController(
 func (c *DashboardController) Register(router gin.IRouter) {
 	router.GET("/dashboard", c.dashboard)
 	router.GET("/dashboard/segmentKeys/:segment", c.segmentKeys)
+	router.GET("/dashboard/keySegments", c.keySegments)
 	router.GET("/dashboard/stats", c.stats)
 }
 
@@ -89,18 +110,66 @@ func (c *DashboardController) stats(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, c.gatherStats())
 }
 
-// segmentKeys returns a keys for a given segment
+// segmentKeys returns a page of keys for a given segment, optionally filtered by a substring.
+// Requests without any paging or filtering parameters get every key as a plain array, as they used to
 func (c *DashboardController) segmentKeys(ctx *gin.Context) {
+	// curl 'http://localhost:3010/admin/dashboard/segmentKeys/some_segment?filter=abc&offset=100&limit=100'
 	segmentName := ctx.Param("segment")
 	if segmentName == "" {
 		ctx.AbortWithStatus(400)
 		return
 	}
-	ctx.JSON(200, bundleSegmentKeysInfo(segmentName, c.storages.SegmentStorage))
+
+	if !hasAnyQueryParam(ctx, "filter", "offset", "limit") {
+		ctx.JSON(200, bundleSegmentKeys(segmentName, c.storages.SegmentStorage, ""))
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultSegmentKeysPageSize)))
+	if err != nil || limit <= 0 || limit > maxSegmentKeysPageSize {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be an integer between 1 and %d", maxSegmentKeysPageSize)})
+		return
+	}
+
+	ctx.JSON(200, bundleSegmentKeysInfo(segmentName, c.segmentKeysCache, ctx.Query("filter"), offset, limit))
+}
+
+// keySegments returns the segments a key belongs to
+func (c *DashboardController) keySegments(ctx *gin.Context) {
+	// curl 'http://localhost:3010/admin/dashboard/keySegments?key=some_key'
+	key := ctx.Query("key")
+	if key == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "a key is required"})
+		return
+	}
+
+	segments, err := c.segmentLookup.SegmentsForKey(key)
+	if err != nil {
+		c.logger.Error("error looking up segments for key: ", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"key": key, "segments": segments})
 }
 
 // \} -- end of endpoint functions
 
+func hasAnyQueryParam(ctx *gin.Context, names ...string) bool {
+	query := ctx.Request.URL.Query()
+	for _, name := range names {
+		if _, ok := query[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *DashboardController) renderDashboard() ([]byte, error) {
 	var layoutBuffer bytes.Buffer
 	err := c.layout.Execute(&layoutBuffer, dashboard.RootObject{
@@ -139,6 +208,16 @@ func (c *DashboardController) gatherStats() *dashboard.GlobalStats {
 		eventsLambda = c.eventsEvCalc.Lambda()
 	}
 
+	pipelines := make([]task.PipelineMetrics, 0, len(c.pipelines))
+	for _, pipeline := range c.pipelines {
+		pipelines = append(pipelines, pipeline.Metrics())
+	}
+
+	var queueCaps []worker.QueueCapStatus
+	if c.queueCaps != nil {
+		queueCaps = c.queueCaps.Status()
+	}
+
 	return &dashboard.GlobalStats{
 		Splits:                 bundleSplitInfo(c.storages.SplitStorage),
 		Segments:               bundleSegmentInfo(c.storages.SplitStorage, c.storages.SegmentStorage),
@@ -157,5 +236,7 @@ func (c *DashboardController) gatherStats() *dashboard.GlobalStats {
 		LoggedErrors:           errorCount,
 		LoggedMessages:         errorMessages,
 		Uptime:                 int64(c.runtime.Uptime().Seconds()),
+		Pipelines:              pipelines,
+		QueueCaps:              queueCaps,
 	}
 }
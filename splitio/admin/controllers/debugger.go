@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/evaluator"
+)
+
+// DebuggerController bundles endpoints used to troubleshoot evaluations using the locally stored data
+type DebuggerController struct {
+	logger    logging.LoggerInterface
+	evaluator *evaluator.Evaluator
+}
+
+// NewDebuggerController constructs a new evaluation debugger controller
+func NewDebuggerController(logger logging.LoggerInterface, evaluator *evaluator.Evaluator) *DebuggerController {
+	return &DebuggerController{logger: logger, evaluator: evaluator}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *DebuggerController) Register(router gin.IRouter) {
+	router.POST("/debugger/evaluate", c.evaluate)
+}
+
+type debugEvaluationRequest struct {
+	Key          string                 `json:"key"`
+	BucketingKey string                 `json:"bucketingKey"`
+	Split        string                 `json:"split"`
+	Attributes   map[string]interface{} `json:"attributes"`
+}
+
+func (c *DebuggerController) evaluate(ctx *gin.Context) {
+	// curl -X POST -d '{"key":"user1","split":"some_split","attributes":{"age":30}}' http://localhost:3010/admin/debugger/evaluate
+	var req debugEvaluationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "error parsing request body: " + err.Error()})
+		return
+	}
+
+	if req.Key == "" || req.Split == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "key & split are required"})
+		return
+	}
+
+	// no impressions are generated here, the explanation is only returned to the caller
+	key := evaluator.Key{MatchingKey: req.Key, BucketingKey: req.BucketingKey}
+	ctx.JSON(http.StatusOK, c.evaluator.Explain(key, req.Split, req.Attributes))
+}
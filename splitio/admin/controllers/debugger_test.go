@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/storage/inmemory/mutexmap"
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/evaluator"
+)
+
+func TestDebuggerEvaluate(t *testing.T) {
+	logger := logging.NewLogger(nil)
+	splits := mutexmap.NewMMSplitStorage()
+	segments := mutexmap.NewMMSegmentStorage()
+	segments.Update("employees", set.NewSet("employee1"), set.NewSet(), 1)
+	splits.Update([]dtos.SplitDTO{{
+		Name:              "split1",
+		Status:            "ACTIVE",
+		ChangeNumber:      123,
+		DefaultTreatment:  "off",
+		TrafficAllocation: 100,
+		Conditions: []dtos.ConditionDTO{{
+			ConditionType: "WHITELIST",
+			Label:         "in segment employees",
+			MatcherGroup: dtos.MatcherGroupDTO{Combiner: "AND", Matchers: []dtos.MatcherDTO{{
+				MatcherType:        "IN_SEGMENT",
+				UserDefinedSegment: &dtos.UserDefinedSegmentMatcherDataDTO{SegmentName: "employees"},
+			}}},
+			Partitions: []dtos.PartitionDTO{{Treatment: "on", Size: 100}},
+		}},
+	}}, nil, 123)
+
+	ctrl := NewDebuggerController(logger, evaluator.NewEvaluator(splits, segments, logger))
+	_, router := gin.CreateTestContext(httptest.NewRecorder())
+	ctrl.Register(router)
+
+	do := func(body string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/debugger/evaluate", bytes.NewBufferString(body))
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := do(`{"key":"employee1","split":"split1"}`)
+	var explanation evaluator.Explanation
+	json.Unmarshal(resp.Body.Bytes(), &explanation)
+	if resp.Code != http.StatusOK || explanation.Treatment != "on" || explanation.Label != "in segment employees" {
+		t.Error("wrong explanation: ", resp.Code, resp.Body.String())
+	}
+
+	if explanation.MatchedCondition == nil || len(explanation.SegmentChecks) != 1 || !explanation.SegmentChecks[0].Member || explanation.Bucket == nil {
+		t.Error("matched condition, segment checks & bucket should be included. Got: ", resp.Body.String())
+	}
+
+	if resp := do(`{"split":"split1"}`); resp.Code != http.StatusBadRequest {
+		t.Error("a key should be required. Got: ", resp.Code)
+	}
+}
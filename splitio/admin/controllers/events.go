@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/changefeed"
+)
+
+const (
+	eventsHeartbeatPeriod = 15 * time.Second
+	eventsRetryMs         = 3000
+
+	// sent when the requested last event id is no longer in the buffer & some messages were lost
+	eventsMessageReset = "RESET"
+)
+
+// EventsController streams the change feed as server-sent events
+type EventsController struct {
+	logger logging.LoggerInterface
+	feed   *changefeed.Feed
+}
+
+// NewEventsController constructs a new change feed controller
+func NewEventsController(logger logging.LoggerInterface, feed *changefeed.Feed) *EventsController {
+	return &EventsController{logger: logger, feed: feed}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *EventsController) Register(router gin.IRouter) {
+	router.GET("/events/stream", c.stream)
+}
+
+func (c *EventsController) stream(ctx *gin.Context) {
+	// curl -N -H 'Last-Event-ID: 42' http://localhost:3010/admin/events/stream
+	lastID := int64(-1)
+	raw := ctx.GetHeader("Last-Event-ID")
+	if raw == "" {
+		raw = ctx.Query("lastEventId")
+	}
+	if raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed < 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "last event id must be a non-negative integer"})
+			return
+		}
+		lastID = parsed
+	}
+
+	subscription, backlog, complete := c.feed.Subscribe(lastID)
+	defer c.feed.Unsubscribe(subscription)
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	fmt.Fprintf(ctx.Writer, "retry: %d\n\n", eventsRetryMs)
+	if !complete {
+		fmt.Fprintf(ctx.Writer, "event: %s\ndata: {\"reason\":\"requested events are no longer available\"}\n\n", eventsMessageReset)
+	}
+	for _, message := range backlog {
+		if err := writeEvent(ctx.Writer, message); err != nil {
+			c.logger.Error("error writing change feed message: ", err)
+			return
+		}
+	}
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeatPeriod)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			io.WriteString(ctx.Writer, ": heartbeat\n\n")
+		case message, ok := <-subscription.Messages():
+			if !ok { // the subscriber fell behind. the client is expected to reconnect with the last id received
+				return
+			}
+			if err := writeEvent(ctx.Writer, message); err != nil {
+				c.logger.Error("error writing change feed message: ", err)
+				return
+			}
+		}
+		ctx.Writer.Flush()
+	}
+}
+
+func writeEvent(w io.Writer, message changefeed.Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("error serializing message: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", message.ID, message.Type, data)
+	return err
+}
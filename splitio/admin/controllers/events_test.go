@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/changefeed"
+)
+
+func TestEventsStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	feed := changefeed.New(2)
+	for idx := 0; idx < 3; idx++ {
+		feed.Publish(changefeed.MessageSyncError, changefeed.SyncError{Resource: "splits", Error: "something"})
+	}
+
+	router := gin.New()
+	NewEventsController(logging.NewLogger(nil), feed).Register(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/events/stream", nil)
+	req.Header.Set("Last-Event-ID", "0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal("error connecting to stream: ", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Error("wrong response: ", resp.StatusCode, resp.Header)
+	}
+
+	lines := make(chan string, 100)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	// the reset notice is followed by the 2 messages still buffered
+	expected := []string{"retry: 3000", "event: RESET", "id: 2", "id: 3", "id: 4"}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		feed.Publish(changefeed.MessageHealthChanged, changefeed.HealthChange{Component: "application"})
+	}()
+
+	var received []string
+	timeout := time.After(2 * time.Second)
+	for len(received) < len(expected) {
+		select {
+		case line := <-lines:
+			if strings.HasPrefix(line, "id: ") || strings.HasPrefix(line, "retry: ") || line == "event: RESET" {
+				received = append(received, line)
+			}
+			if strings.HasPrefix(line, "data: ") && strings.Contains(line, `"type":"HEALTH_CHANGED"`) && !strings.Contains(line, `"component":"application"`) {
+				t.Error("wrong health message: ", line)
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for messages. Got: ", received)
+		}
+	}
+
+	for idx := range expected {
+		if received[idx] != expected[idx] {
+			t.Errorf("expected line %d to be '%s'. Got: '%s'", idx, expected[idx], received[idx])
+		}
+	}
+
+	resp, _ = http.Get(server.URL + "/events/stream?lastEventId=abc")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Error("invalid event ids should be rejected. Got: ", resp.StatusCode)
+	}
+}
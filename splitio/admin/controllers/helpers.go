@@ -1,6 +1,9 @@
 package controllers
 
 import (
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/splitio/go-split-commons/v4/storage"
@@ -76,29 +79,122 @@ func bundleSegmentInfo(splitStorage storage.SplitStorage, segmentStorage storage
 	return summaries
 }
 
-func bundleSegmentKeysInfo(name string, segmentStorage storage.SegmentStorageConsumer) []dashboard.SegmentKeySummary {
-
+// bundleSegmentKeys returns every key of a segment matching the filter (if any), sorted by name
+func bundleSegmentKeys(name string, segmentStorage storage.SegmentStorageConsumer, filter string) []dashboard.SegmentKeySummary {
 	keys := segmentStorage.Keys(name)
+	if keys == nil {
+		return []dashboard.SegmentKeySummary{}
+	}
+
 	segmentKeys := make([]dashboard.SegmentKeySummary, 0, keys.Size())
+	for _, key := range keys.List() {
+		var summary dashboard.SegmentKeySummary
+		switch k := key.(type) {
+		case persistent.SegmentKey:
+			summary = dashboard.SegmentKeySummary{Name: k.Name, Removed: k.Removed, ChangeNumber: k.ChangeNumber}
+		case string:
+			summary = dashboard.SegmentKeySummary{Name: k}
+		default:
+			continue
+		}
 
-	if keys != nil {
-		for _, key := range keys.List() {
-			switch k := key.(type) {
-			case persistent.SegmentKey:
-				segmentKeys = append(segmentKeys, dashboard.SegmentKeySummary{
-					Name:         k.Name,
-					Removed:      k.Removed,
-					ChangeNumber: k.ChangeNumber,
-				})
-			case string:
-				segmentKeys = append(segmentKeys, dashboard.SegmentKeySummary{Name: k})
-			}
+		if filter == "" || strings.Contains(summary.Name, filter) {
+			segmentKeys = append(segmentKeys, summary)
 		}
 	}
 
+	// keys are sorted so that pages are stable across requests
+	sort.Slice(segmentKeys, func(i, j int) bool { return segmentKeys[i].Name < segmentKeys[j].Name })
 	return segmentKeys
 }
 
+// segmentKeysCache keeps the sorted keys of the most recently paged segments, so that paging through a segment only
+// reads & sorts it again once its change number moves
+type segmentKeysCache struct {
+	segmentStorage storage.SegmentStorageConsumer
+	maxSegments    int
+	entries        map[string]cachedSegmentKeys
+	mutex          sync.Mutex
+}
+
+type cachedSegmentKeys struct {
+	changeNumber int64
+	keys         []dashboard.SegmentKeySummary
+}
+
+func newSegmentKeysCache(segmentStorage storage.SegmentStorageConsumer, maxSegments int) *segmentKeysCache {
+	return &segmentKeysCache{
+		segmentStorage: segmentStorage,
+		maxSegments:    maxSegments,
+		entries:        make(map[string]cachedSegmentKeys, maxSegments),
+	}
+}
+
+// keys returns every key of a segment sorted by name. The returned slice is shared and must not be modified
+func (c *segmentKeysCache) keys(name string) []dashboard.SegmentKeySummary {
+	cn, _ := c.segmentStorage.ChangeNumber(name)
+
+	c.mutex.Lock()
+	cached, ok := c.entries[name]
+	c.mutex.Unlock()
+	if ok && cached.changeNumber == cn {
+		return cached.keys
+	}
+
+	// the storage is read without holding the lock, concurrent misses for the same segment just race to store it
+	keys := bundleSegmentKeys(name, c.segmentStorage, "")
+	if len(keys) == 0 {
+		return keys
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, ok := c.entries[name]; !ok && len(c.entries) >= c.maxSegments {
+		for evicted := range c.entries {
+			delete(c.entries, evicted)
+			break
+		}
+	}
+	c.entries[name] = cachedSegmentKeys{changeNumber: cn, keys: keys}
+	return keys
+}
+
+// bundleSegmentKeysInfo returns a page of the keys of a segment matching the filter (if any). Sorted keys are cached
+// per segment change number, so only the first page of each segment version reads it from storage
+func bundleSegmentKeysInfo(
+	name string,
+	cache *segmentKeysCache,
+	filter string,
+	offset int,
+	limit int,
+) dashboard.SegmentKeysPage {
+	segmentKeys := cache.keys(name)
+	page := dashboard.SegmentKeysPage{Offset: offset, Limit: limit, Keys: []dashboard.SegmentKeySummary{}}
+	if filter == "" {
+		page.Total = len(segmentKeys)
+		if offset < len(segmentKeys) {
+			end := offset + limit
+			if end > len(segmentKeys) {
+				end = len(segmentKeys)
+			}
+			page.Keys = segmentKeys[offset:end]
+		}
+		return page
+	}
+
+	// the total is needed, so every cached key is matched, but only the requested page is copied
+	for _, key := range segmentKeys {
+		if !strings.Contains(key.Name, filter) {
+			continue
+		}
+		if page.Total >= offset && len(page.Keys) < limit {
+			page.Keys = append(page.Keys, key)
+		}
+		page.Total++
+	}
+	return page
+}
+
 func successfulRequests(t storage.TelemetryPeeker) int64 {
 	var count int64
 	for _, resource := range []int{telemetry.SplitSync, telemetry.SegmentSync, telemetry.ImpressionSync, telemetry.ImpressionCountSync,
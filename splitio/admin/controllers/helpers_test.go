@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/splitio/go-split-commons/v4/storage/inmemory/mutexmap"
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+)
+
+func TestSegmentKeysPagination(t *testing.T) {
+	segments := &sizedSegmentStorage{MMSegmentStorage: mutexmap.NewMMSegmentStorage()}
+	segments.Update("s1", set.NewSet("key3", "key1", "key2", "other"), set.NewSet(), 1)
+	cache := newSegmentKeysCache(segments, 1)
+
+	page := bundleSegmentKeysInfo("s1", cache, "", 0, 2)
+	if page.Total != 4 || len(page.Keys) != 2 || page.Keys[0].Name != "key1" || page.Keys[1].Name != "key2" {
+		t.Error("wrong first page: ", page)
+	}
+
+	page = bundleSegmentKeysInfo("s1", cache, "key", 2, 2)
+	if page.Total != 3 || len(page.Keys) != 1 || page.Keys[0].Name != "key3" {
+		t.Error("wrong filtered page: ", page)
+	}
+
+	if page = bundleSegmentKeysInfo("s1", cache, "", 10, 2); len(page.Keys) != 0 || page.Total != 4 {
+		t.Error("offsets past the end should return an empty page: ", page)
+	}
+
+	if page = bundleSegmentKeysInfo("nonexistent", cache, "", 0, 2); len(page.Keys) != 0 || page.Total != 0 {
+		t.Error("unknown segments should return an empty page: ", page)
+	}
+
+	if segments.listed != 2 {
+		t.Error("s1 should be read once & then served from the cache, unknown segments are not cached. Got: ", segments.listed)
+	}
+
+	segments.Update("s1", set.NewSet("key0"), set.NewSet("other"), 2)
+	if page = bundleSegmentKeysInfo("s1", cache, "", 0, 10); page.Total != 4 || page.Keys[0].Name != "key0" || segments.listed != 3 {
+		t.Error("the cached keys should be refreshed once the change number moves: ", page, segments.listed)
+	}
+
+	if keys := bundleSegmentKeys("s1", segments, ""); len(keys) != 4 || keys[0].Name != "key0" || keys[3].Name != "key3" {
+		t.Error("every key should be listed when not paging: ", keys)
+	}
+
+	if keys := bundleSegmentKeys("nonexistent", segments, ""); keys == nil || len(keys) != 0 {
+		t.Error("unknown segments should be listed as an empty array: ", keys)
+	}
+}
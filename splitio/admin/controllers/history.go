@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/history"
+)
+
+// HistoryController exposes the changes applied to splits
+type HistoryController struct {
+	logger logging.LoggerInterface
+	store  history.Store
+}
+
+// NewHistoryController constructs a new split history controller
+func NewHistoryController(logger logging.LoggerInterface, store history.Store) *HistoryController {
+	return &HistoryController{logger: logger, store: store}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *HistoryController) Register(router gin.IRouter) {
+	router.GET("/history", c.timeline)
+	router.GET("/history/changes/:id", c.change)
+	router.GET("/history/splits/:name", c.splitHistory)
+}
+
+// ChangeWithDiff bundles a change and the differences between the previous & new definitions
+type ChangeWithDiff struct {
+	history.Change
+	Diff []history.FieldChange `json:"diff"`
+}
+
+func (c *HistoryController) timeline(ctx *gin.Context) {
+	// curl 'http://localhost:3010/admin/api/v1/history?split=some_split&since=1650000000000&limit=50&definitions=true'
+	filter, ok := parseHistoryFilter(ctx)
+	if !ok {
+		return
+	}
+	filter.SplitName = ctx.Query("split")
+
+	changes := c.store.Changes(*filter)
+	if withDefinitions, _ := strconv.ParseBool(ctx.Query("definitions")); !withDefinitions {
+		for idx := range changes {
+			changes[idx].Previous = nil
+			changes[idx].Current = nil
+		}
+	}
+	ctx.JSON(http.StatusOK, gin.H{"items": changes})
+}
+
+func (c *HistoryController) change(ctx *gin.Context) {
+	// curl http://localhost:3010/admin/api/v1/history/changes/123
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "id must be an integer"})
+		return
+	}
+
+	change := c.store.Change(id)
+	if change == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "change not found"})
+		return
+	}
+
+	withDiff, err := c.withDiff(change)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, withDiff)
+}
+
+func (c *HistoryController) splitHistory(ctx *gin.Context) {
+	// curl 'http://localhost:3010/admin/api/v1/history/splits/some_split?at=1650000000000'
+	name := ctx.Param("name")
+	if raw := ctx.Query("at"); raw != "" {
+		at, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "at must be a timestamp in milliseconds"})
+			return
+		}
+
+		change := c.store.At(name, at)
+		if change == nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "no changes recorded for the split before the requested time"})
+			return
+		}
+		ctx.JSON(http.StatusOK, change)
+		return
+	}
+
+	filter, ok := parseHistoryFilter(ctx)
+	if !ok {
+		return
+	}
+	filter.SplitName = name
+
+	changes := c.store.Changes(*filter)
+	items := make([]ChangeWithDiff, 0, len(changes))
+	for idx := range changes {
+		withDiff, err := c.withDiff(&changes[idx])
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		items = append(items, *withDiff)
+	}
+	ctx.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (c *HistoryController) withDiff(change *history.Change) (*ChangeWithDiff, error) {
+	diff, err := history.Diff(change.Previous, change.Current)
+	if err != nil {
+		c.logger.Error("error computing split diff: ", err)
+		return nil, err
+	}
+	return &ChangeWithDiff{Change: *change, Diff: diff}, nil
+}
+
+// parseHistoryFilter reads the time range & limit from the querystring. On failure, the response is written here
+func parseHistoryFilter(ctx *gin.Context) (*history.Filter, bool) {
+	filter := &history.Filter{}
+	var err error
+	for param, target := range map[string]*int64{"since": &filter.Since, "until": &filter.Until} {
+		if raw := ctx.Query(param); raw != "" {
+			if *target, err = strconv.ParseInt(raw, 10, 64); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": param + " must be a timestamp in milliseconds"})
+				return nil, false
+			}
+		}
+	}
+
+	if filter.Limit, err = strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultAPIPageSize))); err != nil || filter.Limit <= 0 || filter.Limit > maxAPIPageSize {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be an integer between 1 and " + strconv.Itoa(maxAPIPageSize)})
+		return nil, false
+	}
+	return filter, true
+}
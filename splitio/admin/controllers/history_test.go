@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/history"
+)
+
+func TestHistoryEndpoints(t *testing.T) {
+	store := history.NewInMemoryStore(10)
+	store.Record(
+		history.Change{SplitName: "split1", Type: history.TypeCreated, ChangeNumber: 1, PreviousChangeNumber: -1, ReceivedAt: 1000,
+			Current: &dtos.SplitDTO{Name: "split1", ChangeNumber: 1, DefaultTreatment: "off"}},
+		history.Change{SplitName: "split1", Type: history.TypeUpdated, ChangeNumber: 2, PreviousChangeNumber: 1, ReceivedAt: 2000,
+			Previous: &dtos.SplitDTO{Name: "split1", ChangeNumber: 1, DefaultTreatment: "off"},
+			Current:  &dtos.SplitDTO{Name: "split1", ChangeNumber: 2, DefaultTreatment: "on"}},
+		history.Change{SplitName: "split2", Type: history.TypeCreated, ChangeNumber: 3, PreviousChangeNumber: -1, ReceivedAt: 3000,
+			Current: &dtos.SplitDTO{Name: "split2", ChangeNumber: 3}},
+	)
+
+	ctrl := NewHistoryController(logging.NewLogger(nil), store)
+	_, router := gin.CreateTestContext(httptest.NewRecorder())
+	ctrl.Register(router)
+
+	get := func(path string, into interface{}) int {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(resp, req)
+		if into != nil {
+			json.Unmarshal(resp.Body.Bytes(), into)
+		}
+		return resp.Code
+	}
+
+	var timeline struct {
+		Items []history.Change `json:"items"`
+	}
+	if code := get("/history?since=1500", &timeline); code != 200 || len(timeline.Items) != 2 || timeline.Items[0].SplitName != "split2" || timeline.Items[0].Current != nil {
+		t.Error("wrong timeline: ", code, timeline)
+	}
+
+	timeline.Items = nil
+	if get("/history?split=split1&definitions=true", &timeline); len(timeline.Items) != 2 || timeline.Items[0].Current == nil {
+		t.Error("definitions should be included when requested: ", timeline)
+	}
+
+	var change ChangeWithDiff
+	if code := get("/history/changes/2", &change); code != 200 || len(change.Diff) != 2 || change.Diff[1].Path != "defaultTreatment" {
+		t.Error("wrong change diff: ", code, change)
+	}
+
+	if code := get("/history/changes/10", nil); code != http.StatusNotFound {
+		t.Error("unknown changes should return 404. Got: ", code)
+	}
+
+	var splitHistory struct {
+		Items []ChangeWithDiff `json:"items"`
+	}
+	if get("/history/splits/split1", &splitHistory); len(splitHistory.Items) != 2 || len(splitHistory.Items[0].Diff) != 2 {
+		t.Error("wrong split history: ", splitHistory)
+	}
+
+	var at history.Change
+	if code := get("/history/splits/split1?at=1999", &at); code != 200 || at.ChangeNumber != 1 || at.Current.DefaultTreatment != "off" {
+		t.Error("wrong definition at 1999: ", code, at)
+	}
+
+	if code := get("/history/splits/split1?at=500", nil); code != http.StatusNotFound {
+		t.Error("no definition should be found before the first change. Got: ", code)
+	}
+
+	if code := get("/history?limit=0", nil); code != http.StatusBadRequest {
+		t.Error("invalid limits should be rejected. Got: ", code)
+	}
+}
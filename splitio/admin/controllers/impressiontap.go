@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/impressiontap"
+)
+
+// ImpressionTapController streams impressions matching a key, flag and/or machine name as server-sent events
+type ImpressionTapController struct {
+	logger logging.LoggerInterface
+	tap    *impressiontap.Tap
+}
+
+// NewImpressionTapController constructs a new impression tap controller
+func NewImpressionTapController(logger logging.LoggerInterface, tap *impressiontap.Tap) *ImpressionTapController {
+	return &ImpressionTapController{logger: logger, tap: tap}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *ImpressionTapController) Register(router gin.IRouter) {
+	router.GET("/impressions/tap", c.stream)
+}
+
+func (c *ImpressionTapController) stream(ctx *gin.Context) {
+	// curl -N 'http://localhost:3010/admin/impressions/tap?key=some_user&flag=some_split&machineName=ip-1-2-3-4&maxItems=50&ttlSeconds=120'
+	filter := impressiontap.Filter{Key: ctx.Query("key"), Flag: ctx.Query("flag"), MachineName: ctx.Query("machineName")}
+	limits := map[string]int{"maxItems": 0, "ttlSeconds": 0}
+	for param := range limits {
+		if raw := ctx.Query(param); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed <= 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": param + " must be a positive integer"})
+				return
+			}
+			limits[param] = parsed
+		}
+	}
+
+	subscription, err := c.tap.Subscribe(filter, limits["maxItems"], time.Duration(limits["ttlSeconds"])*time.Second)
+	switch err {
+	case nil:
+	case impressiontap.ErrTooManySubscriptions:
+		ctx.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer c.tap.Unsubscribe(subscription)
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	io.WriteString(ctx.Writer, ": tap started\n\n")
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeatPeriod)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			io.WriteString(ctx.Writer, ": heartbeat\n\n")
+		case impression, ok := <-subscription.Items():
+			if !ok {
+				fmt.Fprintf(ctx.Writer, "event: end\ndata: {\"reason\":%q}\n\n", subscription.Reason())
+				ctx.Writer.Flush()
+				return
+			}
+
+			data, err := json.Marshal(impression)
+			if err != nil {
+				c.logger.Error("error serializing tapped impression: ", err)
+				continue
+			}
+			fmt.Fprintf(ctx.Writer, "event: impression\ndata: %s\n\n", data)
+		}
+		ctx.Writer.Flush()
+	}
+}
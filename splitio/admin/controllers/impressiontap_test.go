@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/impressiontap"
+)
+
+func TestImpressionTapStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tap := impressiontap.New(1)
+
+	router := gin.New()
+	NewImpressionTapController(logging.NewLogger(nil), tap).Register(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, _ := http.Get(server.URL + "/impressions/tap")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Error("a tap without filters should be rejected. Got: ", resp.StatusCode)
+	}
+
+	resp, _ = http.Get(server.URL + "/impressions/tap?key=user1&maxItems=abc")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Error("an invalid maxItems should be rejected. Got: ", resp.StatusCode)
+	}
+
+	resp, err := http.Get(server.URL + "/impressions/tap?key=user1&maxItems=1")
+	if err != nil {
+		t.Fatal("error connecting to tap: ", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Error("wrong response: ", resp.StatusCode, resp.Header)
+	}
+
+	if second, _ := http.Get(server.URL + "/impressions/tap?key=user2"); second.StatusCode != http.StatusTooManyRequests {
+		t.Error("a second tap should exceed the limit. Got: ", second.StatusCode)
+	}
+
+	lines := make(chan string, 100)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	metadata := &dtos.Metadata{SDKVersion: "go-1.2.3", MachineName: "m1"}
+	tap.Offer(metadata, "split1", &dtos.ImpressionDTO{KeyName: "user2", Treatment: "on"})
+	tap.Offer(metadata, "split1", &dtos.ImpressionDTO{KeyName: "user1", Treatment: "off"})
+
+	var received []string
+	timeout := time.After(2 * time.Second)
+	for done := false; !done; {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				done = true
+				break
+			}
+			if strings.HasPrefix(line, "event: ") || strings.HasPrefix(line, "data: ") {
+				received = append(received, line)
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for the tap to end. Got: ", received)
+		}
+	}
+
+	if len(received) != 4 || received[0] != "event: impression" || received[2] != "event: end" {
+		t.Fatal("wrong events received: ", received)
+	}
+	if !strings.Contains(received[1], `"keyName":"user1"`) || !strings.Contains(received[1], `"treatment":"off"`) {
+		t.Error("wrong impression: ", received[1])
+	}
+	if received[3] != `data: {"reason":"`+impressiontap.ReasonCapReached+`"}` {
+		t.Error("wrong end reason: ", received[3])
+	}
+}
@@ -0,0 +1,420 @@
+package controllers
+
+// openAPISpec documents the endpoints exposed by the APIController
+const openAPISpec = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "Split Synchronizer & Proxy admin API",
+    "version": "1.0.0",
+    "description": "Read-only access to the splits & segments stored locally. Collections are sorted by name and paginated using opaque cursors."
+  },
+  "servers": [{"url": "/admin/api/v1"}],
+  "components": {
+    "securitySchemes": {
+      "basicAuth": {"type": "http", "scheme": "basic"}
+    },
+    "parameters": {
+      "limit": {
+        "name": "limit", "in": "query", "required": false,
+        "description": "Maximum number of items to return",
+        "schema": {"type": "integer", "minimum": 1, "maximum": 1000, "default": 100}
+      },
+      "cursor": {
+        "name": "cursor", "in": "query", "required": false,
+        "description": "Value of nextCursor from the previous page",
+        "schema": {"type": "string"}
+      },
+      "prefix": {
+        "name": "prefix", "in": "query", "required": false,
+        "description": "Only return items whose name starts with this value",
+        "schema": {"type": "string"}
+      }
+    },
+    "schemas": {
+      "Error": {
+        "type": "object",
+        "properties": {"error": {"type": "string"}}
+      },
+      "Split": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "trafficType": {"type": "string"},
+          "killed": {"type": "boolean"},
+          "status": {"type": "string"},
+          "defaultTreatment": {"type": "string"},
+          "changeNumber": {"type": "integer", "format": "int64"},
+          "treatments": {"type": "array", "items": {"type": "string"}},
+          "segments": {"type": "array", "items": {"type": "string"}},
+          "definition": {"type": "object", "description": "Split definition as received from Split servers"}
+        }
+      },
+      "Segment": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "changeNumber": {"type": "integer", "format": "int64"},
+          "keyCount": {"type": "integer"},
+          "usedBy": {"type": "array", "items": {"type": "string"}}
+        }
+      },
+      "SegmentKey": {
+        "type": "object",
+        "properties": {
+          "key": {"type": "string"},
+          "removed": {"type": "boolean", "description": "Only reported in proxy mode"},
+          "changeNumber": {"type": "integer", "format": "int64", "description": "Only reported in proxy mode"}
+        }
+      },
+      "Change": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "integer", "format": "int64"},
+          "splitName": {"type": "string"},
+          "type": {"type": "string", "enum": ["created", "updated", "killed", "archived"]},
+          "source": {"type": "string", "enum": ["polling", "streaming", "localKill"]},
+          "changeNumber": {"type": "integer", "format": "int64"},
+          "previousChangeNumber": {"type": "integer", "format": "int64"},
+          "receivedAt": {"type": "integer", "format": "int64", "description": "Milliseconds since epoch"},
+          "previous": {"type": "object", "description": "Definition before the change"},
+          "current": {"type": "object", "description": "Definition after the change"}
+        }
+      },
+      "ChangeWithDiff": {
+        "allOf": [
+          {"$ref": "#/components/schemas/Change"},
+          {
+            "type": "object",
+            "properties": {
+              "diff": {
+                "type": "array",
+                "items": {
+                  "type": "object",
+                  "properties": {"path": {"type": "string"}, "before": {}, "after": {}}
+                }
+              }
+            }
+          }
+        ]
+      },
+      "SplitsPage": {
+        "type": "object",
+        "properties": {
+          "items": {"type": "array", "items": {"$ref": "#/components/schemas/Split"}},
+          "nextCursor": {"type": "string"}
+        }
+      },
+      "SegmentsPage": {
+        "type": "object",
+        "properties": {
+          "items": {"type": "array", "items": {"$ref": "#/components/schemas/Segment"}},
+          "nextCursor": {"type": "string"}
+        }
+      },
+      "SegmentKeysPage": {
+        "type": "object",
+        "properties": {
+          "items": {"type": "array", "items": {"$ref": "#/components/schemas/SegmentKey"}},
+          "nextCursor": {"type": "string"}
+        }
+      },
+      "FlagUsage": {
+        "type": "object",
+        "properties": {
+          "flag": {"type": "string"},
+          "total": {"type": "integer", "format": "int64"},
+          "treatments": {"type": "object", "additionalProperties": {"type": "integer", "format": "int64"}},
+          "sdks": {"type": "object", "additionalProperties": {"type": "integer", "format": "int64"}},
+          "lastSeen": {"type": "integer", "format": "int64", "description": "Milliseconds since epoch"}
+        }
+      },
+      "PipelineSettings": {
+        "type": "object",
+        "properties": {
+          "fetchSize": {"type": "integer", "format": "int64"},
+          "processConcurrency": {"type": "integer"},
+          "processBatchSize": {"type": "integer"},
+          "postConcurrency": {"type": "integer"}
+        }
+      },
+      "ProducersStatus": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "policy": {"type": "string", "enum": ["none", "quota", "sample"]},
+          "backlogThreshold": {"type": "integer", "format": "int64"},
+          "backlog": {"type": "integer", "format": "int64"},
+          "throttling": {"type": "boolean"},
+          "windowMs": {"type": "integer", "format": "int64"},
+          "sources": {"type": "integer"},
+          "deferred": {"type": "integer", "format": "int64", "description": "Items over the quota, re-queued to be processed in a later window"},
+          "dropped": {"type": "integer", "format": "int64", "description": "Items sampled out"},
+          "producers": {
+            "type": "array",
+            "items": {
+              "type": "object",
+              "properties": {
+                "sdkVersion": {"type": "string"},
+                "machineName": {"type": "string"},
+                "machineIP": {"type": "string"},
+                "windowItems": {"type": "integer", "format": "int64"},
+                "previousWindowItems": {"type": "integer", "format": "int64"},
+                "share": {"type": "number", "description": "Fraction of the items received during the current & previous windows"},
+                "total": {"type": "integer", "format": "int64"},
+                "deferred": {"type": "integer", "format": "int64"},
+                "dropped": {"type": "integer", "format": "int64"},
+                "lastSeen": {"type": "integer", "format": "int64", "description": "Milliseconds since epoch"}
+              }
+            }
+          }
+        }
+      },
+      "QueueInfo": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "size": {"type": "integer", "format": "int64", "description": "Items in redis, or staged bulks in proxy mode"},
+          "flushable": {"type": "boolean"}
+        }
+      },
+      "PipelineStatus": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "settings": {"$ref": "#/components/schemas/PipelineSettings"},
+          "autoTuning": {
+            "type": "object",
+            "description": "Only present when auto-tuning is enabled",
+            "properties": {
+              "min": {"$ref": "#/components/schemas/PipelineSettings"},
+              "max": {"$ref": "#/components/schemas/PipelineSettings"},
+              "lambda": {"type": "number"},
+              "posts": {"type": "integer", "format": "int64"},
+              "errorRate": {"type": "number"},
+              "throttledRate": {"type": "number"},
+              "avgPostLatencyMs": {"type": "number"},
+              "lastDecision": {"type": "string"},
+              "lastEvaluation": {"type": "integer", "format": "int64", "description": "Milliseconds since epoch"},
+              "lastAdjustment": {"type": "integer", "format": "int64", "description": "Milliseconds since epoch"}
+            }
+          }
+        }
+      },
+      "StaleReport": {
+        "type": "object",
+        "properties": {
+          "windowMs": {"type": "integer", "format": "int64"},
+          "trackingSince": {"type": "integer", "format": "int64", "description": "Milliseconds since epoch"},
+          "complete": {"type": "boolean", "description": "False if impressions have been tracked for less time than the window"},
+          "flags": {
+            "type": "array",
+            "items": {
+              "type": "object",
+              "properties": {
+                "name": {"type": "string"},
+                "lastSeen": {"type": "integer", "format": "int64", "nullable": true}
+              }
+            }
+          }
+        }
+      }
+    }
+  },
+  "security": [{"basicAuth": []}],
+  "paths": {
+    "/splits": {
+      "get": {
+        "summary": "List splits",
+        "parameters": [
+          {"$ref": "#/components/parameters/limit"},
+          {"$ref": "#/components/parameters/cursor"},
+          {"$ref": "#/components/parameters/prefix"},
+          {"name": "trafficType", "in": "query", "required": false, "schema": {"type": "string"}},
+          {"name": "killed", "in": "query", "required": false, "schema": {"type": "boolean"}},
+          {"name": "segment", "in": "query", "required": false, "description": "Only return splits referencing this segment", "schema": {"type": "string"}}
+        ],
+        "responses": {
+          "200": {"description": "A page of splits", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/SplitsPage"}}}},
+          "400": {"description": "Invalid parameters", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/splits/{name}": {
+      "get": {
+        "summary": "Get a split",
+        "parameters": [{"name": "name", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {
+          "200": {"description": "The split", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Split"}}}},
+          "404": {"description": "Split not found", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/segments": {
+      "get": {
+        "summary": "List segments referenced by splits",
+        "parameters": [
+          {"$ref": "#/components/parameters/limit"},
+          {"$ref": "#/components/parameters/cursor"},
+          {"$ref": "#/components/parameters/prefix"}
+        ],
+        "responses": {
+          "200": {"description": "A page of segments", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/SegmentsPage"}}}},
+          "400": {"description": "Invalid parameters", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/segments/{name}/keys": {
+      "get": {
+        "summary": "List the keys of a segment",
+        "parameters": [
+          {"name": "name", "in": "path", "required": true, "schema": {"type": "string"}},
+          {"$ref": "#/components/parameters/limit"},
+          {"$ref": "#/components/parameters/cursor"},
+          {"$ref": "#/components/parameters/prefix"}
+        ],
+        "responses": {
+          "200": {"description": "A page of keys", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/SegmentKeysPage"}}}},
+          "400": {"description": "Invalid parameters", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}},
+          "404": {"description": "Segment not found", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/history": {
+      "get": {
+        "summary": "Timeline of split changes, newest first",
+        "parameters": [
+          {"name": "split", "in": "query", "required": false, "schema": {"type": "string"}},
+          {"name": "since", "in": "query", "required": false, "schema": {"type": "integer", "format": "int64"}},
+          {"name": "until", "in": "query", "required": false, "schema": {"type": "integer", "format": "int64"}},
+          {"name": "definitions", "in": "query", "required": false, "description": "Include full definitions", "schema": {"type": "boolean"}},
+          {"$ref": "#/components/parameters/limit"}
+        ],
+        "responses": {
+          "200": {"description": "Changes", "content": {"application/json": {"schema": {"type": "object", "properties": {"items": {"type": "array", "items": {"$ref": "#/components/schemas/Change"}}}}}}},
+          "400": {"description": "Invalid parameters", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/history/changes/{id}": {
+      "get": {
+        "summary": "Get a change along with the diff between definitions",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer", "format": "int64"}}],
+        "responses": {
+          "200": {"description": "The change", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ChangeWithDiff"}}}},
+          "404": {"description": "Change not found", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/history/splits/{name}": {
+      "get": {
+        "summary": "Changes applied to a split with diffs, or the change in effect at a point in time",
+        "parameters": [
+          {"name": "name", "in": "path", "required": true, "schema": {"type": "string"}},
+          {"name": "at", "in": "query", "required": false, "description": "Timestamp in millis. Returns the latest change before it", "schema": {"type": "integer", "format": "int64"}},
+          {"$ref": "#/components/parameters/limit"}
+        ],
+        "responses": {
+          "200": {"description": "Changes with diffs, or a single change if 'at' is supplied"},
+          "404": {"description": "No change recorded before 'at'", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/usage/flags": {
+      "get": {
+        "summary": "Impressions per flag, treatment & sdk within the rolling window, sorted by volume",
+        "parameters": [
+          {"name": "prefix", "in": "query", "required": false, "schema": {"type": "string"}}
+        ],
+        "responses": {
+          "200": {"description": "Flag usage", "content": {"application/json": {"schema": {"type": "object", "properties": {"items": {"type": "array", "items": {"$ref": "#/components/schemas/FlagUsage"}}}}}}}
+        }
+      }
+    },
+    "/usage/stale": {
+      "get": {
+        "summary": "Flags defined in storage without impressions during the stale window",
+        "responses": {
+          "200": {"description": "Stale flags", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/StaleReport"}}}}
+        }
+      }
+    },
+    "/pipelines": {
+      "get": {
+        "summary": "Current settings of the impressions, events & unique keys pipelines (synchronizer mode only)",
+        "responses": {
+          "200": {"description": "Pipelines", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/PipelineStatus"}}}}}
+        }
+      }
+    },
+    "/producers": {
+      "get": {
+        "summary": "Sdk instances generating the most impressions & events, along with the fairness policy state (synchronizer mode only)",
+        "parameters": [
+          {"name": "queue", "in": "query", "required": false, "description": "impressions or events", "schema": {"type": "string"}},
+          {"name": "limit", "in": "query", "required": false, "schema": {"type": "integer"}}
+        ],
+        "responses": {
+          "200": {"description": "Top producers per queue", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/ProducersStatus"}}}}},
+          "400": {"description": "Invalid parameters", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}},
+          "404": {"description": "Unknown queue", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/queues": {
+      "get": {
+        "summary": "Impressions, events & unique keys queues along with their sizes",
+        "responses": {
+          "200": {"description": "Queues", "content": {"application/json": {"schema": {"type": "object", "properties": {"items": {"type": "array", "items": {"$ref": "#/components/schemas/QueueInfo"}}, "maxDrop": {"type": "integer", "format": "int64"}}}}}}
+        }
+      }
+    },
+    "/queues/{name}/peek": {
+      "get": {
+        "summary": "Decoded items at the head of a queue, without removing them",
+        "parameters": [
+          {"name": "name", "in": "path", "required": true, "schema": {"type": "string"}},
+          {"name": "count", "in": "query", "required": false, "description": "Capped by the configured peek limit", "schema": {"type": "integer"}},
+          {"name": "redact", "in": "query", "required": false, "description": "Replace user keys with a placeholder", "schema": {"type": "boolean"}}
+        ],
+        "responses": {
+          "200": {"description": "Items", "content": {"application/json": {"schema": {"type": "object", "properties": {"items": {"type": "array", "items": {"type": "object"}}}}}}},
+          "400": {"description": "Invalid parameters", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}},
+          "404": {"description": "Queue not found", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/queues/{name}/flush": {
+      "post": {
+        "summary": "Post the items in a queue right away instead of waiting for the next period",
+        "parameters": [{"name": "name", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {
+          "202": {"description": "Flush requested"},
+          "400": {"description": "The queue cannot be flushed", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}},
+          "404": {"description": "Queue not found", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/queues/{name}/drop": {
+      "post": {
+        "summary": "Discard the oldest items of a queue",
+        "parameters": [
+          {"name": "name", "in": "path", "required": true, "schema": {"type": "string"}},
+          {"name": "confirm", "in": "query", "required": true, "description": "Must match the queue name", "schema": {"type": "string"}},
+          {"name": "count", "in": "query", "required": false, "description": "Defaults to the whole queue. Rejected if above the configured max drop size", "schema": {"type": "integer", "format": "int64"}}
+        ],
+        "responses": {
+          "200": {"description": "Number of dropped items", "content": {"application/json": {"schema": {"type": "object", "properties": {"queue": {"type": "string"}, "dropped": {"type": "integer", "format": "int64"}}}}}},
+          "400": {"description": "Missing confirmation or too many items", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}},
+          "404": {"description": "Queue not found", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    },
+    "/openapi.json": {
+      "get": {
+        "summary": "This specification",
+        "responses": {"200": {"description": "OpenAPI document"}}
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+)
+
+// OverridesController bundles endpoints used to manage local split overrides
+type OverridesController struct {
+	logger    logging.LoggerInterface
+	overrides storage.SplitOverrides
+}
+
+// NewOverridesController constructs a new overrides controller
+func NewOverridesController(logger logging.LoggerInterface, overrides storage.SplitOverrides) *OverridesController {
+	return &OverridesController{logger: logger, overrides: overrides}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *OverridesController) Register(router gin.IRouter) {
+	router.GET("/overrides", c.list)
+	router.PUT("/overrides/:name", c.set)
+	router.DELETE("/overrides/:name", c.remove)
+}
+
+type overrideRequest struct {
+	Type             string         `json:"type"`
+	DefaultTreatment string         `json:"defaultTreatment"`
+	Definition       *dtos.SplitDTO `json:"definition"`
+	TTLSeconds       int64          `json:"ttlSeconds"`
+}
+
+func (c *OverridesController) list(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.overrides.All())
+}
+
+func (c *OverridesController) set(ctx *gin.Context) {
+	// curl -X PUT -d '{"type":"kill","defaultTreatment":"off","ttlSeconds":3600}' http://localhost:3010/admin/overrides/some_split
+	var req overrideRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "error parsing request body: " + err.Error()})
+		return
+	}
+
+	if req.TTLSeconds < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ttlSeconds cannot be negative"})
+		return
+	}
+
+	override := storage.SplitOverride{
+		SplitName:        ctx.Param("name"),
+		Type:             req.Type,
+		DefaultTreatment: req.DefaultTreatment,
+		Definition:       req.Definition,
+	}
+	if req.TTLSeconds > 0 {
+		override.ExpiresAt = time.Now().Add(time.Duration(req.TTLSeconds)*time.Second).UnixNano() / int64(time.Millisecond)
+	}
+
+	result, err := c.overrides.Set(override)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrInvalidOverride):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, storage.ErrSplitNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.logger.Error("error setting override: ", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (c *OverridesController) remove(ctx *gin.Context) {
+	if err := c.overrides.Remove(ctx.Param("name")); err != nil {
+		if errors.Is(err, storage.ErrOverrideNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.logger.Error("error removing override: ", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+)
+
+type overridesMock struct {
+	set     []storage.SplitOverride
+	removed []string
+}
+
+func (m *overridesMock) Set(override storage.SplitOverride) (*storage.SplitOverride, error) {
+	if override.SplitName == "unknown" {
+		return nil, fmt.Errorf("%w: unknown", storage.ErrSplitNotFound)
+	}
+	m.set = append(m.set, override)
+	return &override, nil
+}
+
+func (m *overridesMock) Remove(name string) error {
+	if name == "unknown" {
+		return storage.ErrOverrideNotFound
+	}
+	m.removed = append(m.removed, name)
+	return nil
+}
+
+func (m *overridesMock) All() []storage.SplitOverride { return m.set }
+
+func TestOverridesEndpoints(t *testing.T) {
+	mock := &overridesMock{}
+	ctrl := NewOverridesController(logging.NewLogger(nil), mock)
+	_, router := gin.CreateTestContext(httptest.NewRecorder())
+	ctrl.Register(router)
+
+	do := func(method string, path string, body string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := do(http.MethodPut, "/overrides/split1", `{"type":"kill","defaultTreatment":"off","ttlSeconds":60}`)
+	if resp.Code != http.StatusOK {
+		t.Error("wrong status code: ", resp.Code)
+	}
+
+	if len(mock.set) != 1 || mock.set[0].SplitName != "split1" || mock.set[0].Type != "kill" || mock.set[0].ExpiresAt == 0 {
+		t.Error("wrong override forwarded: ", mock.set)
+	}
+
+	if resp := do(http.MethodPut, "/overrides/unknown", `{"type":"kill"}`); resp.Code != http.StatusNotFound {
+		t.Error("wrong status code: ", resp.Code)
+	}
+
+	if resp := do(http.MethodPut, "/overrides/split1", `{"type":`); resp.Code != http.StatusBadRequest {
+		t.Error("wrong status code: ", resp.Code)
+	}
+
+	resp = do(http.MethodGet, "/overrides", "")
+	var listed []storage.SplitOverride
+	if err := json.Unmarshal(resp.Body.Bytes(), &listed); err != nil || len(listed) != 1 {
+		t.Error("wrong overrides listed: ", resp.Body.String(), err)
+	}
+
+	if resp := do(http.MethodDelete, "/overrides/split1", ""); resp.Code != http.StatusNoContent || len(mock.removed) != 1 {
+		t.Error("wrong status code: ", resp.Code)
+	}
+
+	if resp := do(http.MethodDelete, "/overrides/unknown", ""); resp.Code != http.StatusNotFound {
+		t.Error("wrong status code: ", resp.Code)
+	}
+}
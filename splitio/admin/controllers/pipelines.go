@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/task"
+)
+
+// PipelinesController exposes the current settings of the impressions, events & unique keys pipelines,
+// along with the latest auto-tuning decisions
+type PipelinesController struct {
+	logger    logging.LoggerInterface
+	pipelines []*task.PipelinedSyncTask
+}
+
+// NewPipelinesController constructs a new pipelines controller
+func NewPipelinesController(logger logging.LoggerInterface, pipelines []*task.PipelinedSyncTask) *PipelinesController {
+	return &PipelinesController{logger: logger, pipelines: pipelines}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *PipelinesController) Register(router gin.IRouter) {
+	router.GET("/pipelines", c.status)
+}
+
+func (c *PipelinesController) status(ctx *gin.Context) {
+	// curl 'http://localhost:3010/admin/api/v1/pipelines'
+	statuses := make([]task.PipelineStatus, 0, len(c.pipelines))
+	for _, pipeline := range c.pipelines {
+		statuses = append(statuses, pipeline.Status())
+	}
+	ctx.JSON(http.StatusOK, statuses)
+}
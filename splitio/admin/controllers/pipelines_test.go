@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/task"
+)
+
+type nopWorker struct{}
+
+func (nopWorker) Fetch() ([]string, error)                                { return nil, nil }
+func (nopWorker) Process(rawData [][]byte, sink chan<- interface{}) error { return nil }
+func (nopWorker) BuildRequest(data interface{}) (*http.Request, error)    { return nil, nil }
+
+func TestPipelinesEndpoint(t *testing.T) {
+	logger := logging.NewLogger(nil)
+	static, _ := task.NewPipelinedTask(&task.Config{Name: "events", Logger: logger, Worker: nopWorker{}, ProcessConcurrency: 2, PostConcurrency: 5})
+	tuned, _ := task.NewPipelinedTask(&task.Config{
+		Name:       "impressions",
+		Logger:     logger,
+		Worker:     nopWorker{},
+		AutoTuning: &task.AutoTuningConfig{MinPostConcurrency: 1, MaxPostConcurrency: 10},
+	})
+
+	ctrl := NewPipelinesController(logger, []*task.PipelinedSyncTask{tuned, static})
+	_, router := gin.CreateTestContext(httptest.NewRecorder())
+	ctrl.Register(router)
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/pipelines", nil)
+	router.ServeHTTP(resp, req)
+
+	var statuses []task.PipelineStatus
+	json.Unmarshal(resp.Body.Bytes(), &statuses)
+	if resp.Code != http.StatusOK || len(statuses) != 2 {
+		t.Fatal("wrong response: ", resp.Code, resp.Body.String())
+	}
+
+	if statuses[0].Name != "impressions" || statuses[0].AutoTuning == nil || statuses[0].Settings.PostConcurrency != 10 || statuses[0].AutoTuning.Max.PostConcurrency != 10 {
+		t.Error("wrong tuned pipeline status: ", statuses[0], statuses[0].AutoTuning)
+	}
+
+	if statuses[1].Name != "events" || statuses[1].AutoTuning != nil || statuses[1].Settings.ProcessConcurrency != 2 || statuses[1].Settings.PostConcurrency != 5 {
+		t.Error("wrong static pipeline status: ", statuses[1])
+	}
+}
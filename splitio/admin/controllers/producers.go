@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/fairness"
+)
+
+const defaultTopProducers = 10
+
+// ProducersController exposes the volume generated by each sdk instance sharing the impressions & events queues,
+// so that noisy services can be identified
+type ProducersController struct {
+	logger   logging.LoggerInterface
+	trackers []*fairness.Tracker
+}
+
+// NewProducersController constructs a new producers controller
+func NewProducersController(logger logging.LoggerInterface, trackers []*fairness.Tracker) *ProducersController {
+	return &ProducersController{logger: logger, trackers: trackers}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *ProducersController) Register(router gin.IRouter) {
+	router.GET("/producers", c.top)
+}
+
+func (c *ProducersController) top(ctx *gin.Context) {
+	// curl 'http://localhost:3010/admin/api/v1/producers?queue=impressions&limit=10'
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultTopProducers)))
+	if err != nil || limit <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+		return
+	}
+
+	queue := ctx.Query("queue")
+	statuses := make([]fairness.Status, 0, len(c.trackers))
+	for _, tracker := range c.trackers {
+		if queue == "" || queue == tracker.Name() {
+			statuses = append(statuses, tracker.Status(limit))
+		}
+	}
+
+	if len(statuses) == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "unknown queue " + queue})
+		return
+	}
+	ctx.JSON(http.StatusOK, statuses)
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/fairness"
+)
+
+func TestProducersEndpoint(t *testing.T) {
+	impressions, _ := fairness.NewTracker(fairness.Config{Name: "impressions"})
+	events, _ := fairness.NewTracker(fairness.Config{Name: "events"})
+	for idx := 0; idx < 3; idx++ {
+		impressions.Admit(&dtos.Metadata{SDKVersion: "go-6.1.0", MachineName: "m1"}, []byte{byte(idx)})
+	}
+	impressions.Admit(&dtos.Metadata{SDKVersion: "go-6.1.0", MachineName: "m2"}, []byte{0})
+
+	ctrl := NewProducersController(logging.NewLogger(nil), []*fairness.Tracker{impressions, events})
+	_, router := gin.CreateTestContext(httptest.NewRecorder())
+	ctrl.Register(router)
+
+	get := func(path string, target interface{}) int {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(resp, req)
+		json.Unmarshal(resp.Body.Bytes(), target)
+		return resp.Code
+	}
+
+	var statuses []fairness.Status
+	if code := get("/producers", &statuses); code != 200 || len(statuses) != 2 {
+		t.Error("both queues should be reported. Got: ", code, statuses)
+	}
+
+	statuses = nil
+	if code := get("/producers?queue=impressions&limit=1", &statuses); code != 200 || len(statuses) != 1 {
+		t.Fatal("only impressions should be reported. Got: ", code, statuses)
+	}
+	if len(statuses[0].Producers) != 1 || statuses[0].Producers[0].MachineName != "m1" || statuses[0].Sources != 2 {
+		t.Error("the top producer should be m1. Got: ", statuses[0])
+	}
+
+	var errResp map[string]string
+	if code := get("/producers?queue=nonexistent", &errResp); code != 404 {
+		t.Error("unknown queues should return a 404. Got: ", code)
+	}
+
+	if code := get("/producers?limit=abc", &errResp); code != 400 {
+		t.Error("invalid limits should be rejected. Got: ", code)
+	}
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/queues"
+)
+
+// QueuesController exposes admin actions over the impressions, events & unique keys queues:
+// listing them, peeking into them, requesting an immediate flush & dropping their oldest items
+type QueuesController struct {
+	logger  logging.LoggerInterface
+	manager *queues.Manager
+}
+
+// NewQueuesController constructs a new queues controller
+func NewQueuesController(logger logging.LoggerInterface, manager *queues.Manager) *QueuesController {
+	return &QueuesController{logger: logger, manager: manager}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *QueuesController) Register(router gin.IRouter) {
+	router.GET("/queues", c.list)
+	router.GET("/queues/:name/peek", c.peek)
+	router.POST("/queues/:name/flush", c.flush)
+	router.POST("/queues/:name/drop", c.drop)
+}
+
+func (c *QueuesController) list(ctx *gin.Context) {
+	// curl 'http://localhost:3010/admin/api/v1/queues'
+	ctx.JSON(http.StatusOK, gin.H{"items": c.manager.Queues(), "maxDrop": c.manager.MaxDrop()})
+}
+
+func (c *QueuesController) peek(ctx *gin.Context) {
+	// curl 'http://localhost:3010/admin/api/v1/queues/impressions/peek?count=10&redact=true'
+	count, err := strconv.Atoi(ctx.DefaultQuery("count", strconv.Itoa(queues.DefaultPeekSize)))
+	if err != nil || count <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "count must be a positive integer"})
+		return
+	}
+
+	redact, err := strconv.ParseBool(ctx.DefaultQuery("redact", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "redact must be a boolean"})
+		return
+	}
+
+	items, err := c.manager.Peek(ctx.Param("name"), count, redact)
+	if err != nil {
+		ctx.JSON(queueErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (c *QueuesController) flush(ctx *gin.Context) {
+	// curl -XPOST 'http://localhost:3010/admin/api/v1/queues/events/flush'
+	name := ctx.Param("name")
+	if err := c.manager.Flush(name); err != nil {
+		ctx.JSON(queueErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.logger.Info(fmt.Sprintf("immediate flush of %s requested from the admin api", name))
+	ctx.JSON(http.StatusAccepted, gin.H{"queue": name})
+}
+
+func (c *QueuesController) drop(ctx *gin.Context) {
+	// curl -XPOST 'http://localhost:3010/admin/api/v1/queues/events/drop?confirm=events&count=1000'
+	name := ctx.Param("name")
+	if ctx.Query("confirm") != name {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "the confirm parameter must match the queue name"})
+		return
+	}
+
+	var count int64
+	if raw := ctx.Query("count"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "count must be a positive integer"})
+			return
+		}
+		count = parsed
+	}
+
+	dropped, err := c.manager.Drop(name, count)
+	if err != nil {
+		ctx.JSON(queueErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.logger.Warning(fmt.Sprintf("%d items dropped from %s from the admin api", dropped, name))
+	ctx.JSON(http.StatusOK, gin.H{"queue": name, "dropped": dropped})
+}
+
+func queueErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, queues.ErrQueueNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, queues.ErrNotFlushable), errors.Is(err, queues.ErrDropTooLarge):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/queues"
+)
+
+type rawListMock struct {
+	items []string
+}
+
+func (l *rawListMock) Count() int64 { return int64(len(l.items)) }
+
+func (l *rawListMock) Peek(n int64) ([]string, error) {
+	if n > int64(len(l.items)) {
+		n = int64(len(l.items))
+	}
+	return l.items[:n], nil
+}
+
+func (l *rawListMock) Drop(n int64) error {
+	l.items = l.items[n:]
+	return nil
+}
+
+func TestQueuesEndpoints(t *testing.T) {
+	events := &rawListMock{items: []string{`{"e":{"key":"user1"}}`, `{"e":{"key":"user2"}}`, `{"e":{"key":"user3"}}`}}
+	failed := &rawListMock{items: []string{`{"e":{"key":"user4"}}`}}
+	flushes := 0
+	manager := queues.NewManager(2, 0,
+		queues.NewRedisQueue("events", events, func() error { flushes++; return nil }),
+		queues.NewRedisQueue("events.failed", failed, nil),
+	)
+
+	ctrl := NewQueuesController(logging.NewLogger(nil), manager)
+	_, router := gin.CreateTestContext(httptest.NewRecorder())
+	ctrl.Register(router)
+
+	call := func(method string, path string, target interface{}) int {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		router.ServeHTTP(resp, req)
+		if target != nil {
+			json.Unmarshal(resp.Body.Bytes(), target)
+		}
+		return resp.Code
+	}
+
+	var list struct {
+		Items   []queues.Info `json:"items"`
+		MaxDrop int64         `json:"maxDrop"`
+	}
+	if code := call(http.MethodGet, "/queues", &list); code != 200 || len(list.Items) != 2 || list.MaxDrop != 2 {
+		t.Error("wrong queues response: ", code, list)
+	}
+	if list.Items[0] != (queues.Info{Name: "events", Size: 3, Flushable: true}) {
+		t.Error("wrong queue info: ", list.Items[0])
+	}
+
+	var peeked struct {
+		Items []map[string]map[string]string `json:"items"`
+	}
+	if code := call(http.MethodGet, "/queues/events/peek?count=2&redact=true", &peeked); code != 200 || len(peeked.Items) != 2 {
+		t.Fatal("wrong peek response: ", code, peeked)
+	}
+	if peeked.Items[0]["e"]["key"] != "<redacted>" {
+		t.Error("keys should be redacted. Got: ", peeked.Items[0])
+	}
+
+	if code := call(http.MethodGet, "/queues/events/peek?count=abc", nil); code != 400 {
+		t.Error("invalid counts should be rejected. Got: ", code)
+	}
+
+	if code := call(http.MethodGet, "/queues/nonexistent/peek", nil); code != 404 {
+		t.Error("unknown queues should return a 404. Got: ", code)
+	}
+
+	if code := call(http.MethodPost, "/queues/events/flush", nil); code != 202 || flushes != 1 {
+		t.Error("the flush should have been requested. Got: ", code, flushes)
+	}
+
+	if code := call(http.MethodPost, "/queues/events.failed/flush", nil); code != 400 {
+		t.Error("non-flushable queues should be rejected. Got: ", code)
+	}
+
+	if code := call(http.MethodPost, "/queues/events/drop?count=1", nil); code != 400 || events.Count() != 3 {
+		t.Error("drops without confirmation should be rejected. Got: ", code, events.Count())
+	}
+
+	if code := call(http.MethodPost, "/queues/events/drop?confirm=events", nil); code != 400 || events.Count() != 3 {
+		t.Error("drops above the limit should be rejected. Got: ", code, events.Count())
+	}
+
+	var dropped struct {
+		Dropped int64 `json:"dropped"`
+	}
+	if code := call(http.MethodPost, "/queues/events/drop?confirm=events&count=2", &dropped); code != 200 || dropped.Dropped != 2 || events.Count() != 1 {
+		t.Error("the 2 oldest items should have been dropped. Got: ", code, dropped, events.Count())
+	}
+}
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	ssync "github.com/splitio/split-synchronizer/v5/splitio/common/sync"
+)
+
+// ResyncController bundles endpoints used to force synchronizations
+type ResyncController struct {
+	logger   logging.LoggerInterface
+	resyncer ssync.Resyncer
+}
+
+// NewResyncController constructs a new resync controller
+func NewResyncController(logger logging.LoggerInterface, resyncer ssync.Resyncer) *ResyncController {
+	return &ResyncController{logger: logger, resyncer: resyncer}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *ResyncController) Register(router gin.IRouter) {
+	router.POST("/sync/splits", c.syncSplits)
+	router.POST("/sync/segments/:name", c.syncSegment)
+	router.POST("/sync/rebuild", c.rebuild)
+}
+
+func (c *ResyncController) syncSplits(ctx *gin.Context) {
+	// curl -X POST http://localhost:3010/admin/sync/splits
+	result, err := c.resyncer.SyncSplits()
+	c.respond(ctx, "splits", result, err)
+}
+
+func (c *ResyncController) syncSegment(ctx *gin.Context) {
+	// curl -X POST http://localhost:3010/admin/sync/segments/some_segment
+	result, err := c.resyncer.SyncSegment(ctx.Param("name"))
+	c.respond(ctx, "segment", result, err)
+}
+
+func (c *ResyncController) rebuild(ctx *gin.Context) {
+	// curl -X POST http://localhost:3010/admin/sync/rebuild
+	result, err := c.resyncer.Rebuild()
+	c.respond(ctx, "full rebuild", result, err)
+}
+
+func (c *ResyncController) respond(ctx *gin.Context, what string, result interface{}, err error) {
+	if err != nil {
+		c.logger.Error("error in forced "+what+" sync: ", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "result": result})
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	ssync "github.com/splitio/split-synchronizer/v5/splitio/common/sync"
+)
+
+type resyncerMock struct {
+	segments []string
+	fail     bool
+}
+
+func (m *resyncerMock) SyncSplits() (*ssync.SplitsResyncResult, error) {
+	return &ssync.SplitsResyncResult{PreviousChangeNumber: 1, ChangeNumber: 2, UpdatedSplits: []string{"split1"}}, nil
+}
+
+func (m *resyncerMock) SyncSegment(name string) (*ssync.SegmentResyncResult, error) {
+	m.segments = append(m.segments, name)
+	return &ssync.SegmentResyncResult{Name: name, PreviousChangeNumber: 3, ChangeNumber: 4, UpdatedKeys: 2}, nil
+}
+
+func (m *resyncerMock) Rebuild() (*ssync.RebuildResult, error) {
+	result := &ssync.RebuildResult{Splits: &ssync.SplitsResyncResult{PreviousChangeNumber: 2, ChangeNumber: 2}}
+	if m.fail {
+		return result, errors.New("some error")
+	}
+	return result, nil
+}
+
+func TestResyncEndpoints(t *testing.T) {
+	mock := &resyncerMock{}
+	ctrl := NewResyncController(logging.NewLogger(nil), mock)
+	_, router := gin.CreateTestContext(httptest.NewRecorder())
+	ctrl.Register(router)
+
+	post := func(path string, target interface{}) int {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, path, nil)
+		router.ServeHTTP(resp, req)
+		json.Unmarshal(resp.Body.Bytes(), target)
+		return resp.Code
+	}
+
+	var splits ssync.SplitsResyncResult
+	if code := post("/sync/splits", &splits); code != 200 || splits.ChangeNumber != 2 || len(splits.UpdatedSplits) != 1 {
+		t.Error("wrong splits result: ", code, splits)
+	}
+
+	var segment ssync.SegmentResyncResult
+	if code := post("/sync/segments/segment1", &segment); code != 200 || segment.Name != "segment1" || segment.ChangeNumber != 4 {
+		t.Error("wrong segment result: ", code, segment)
+	}
+
+	if len(mock.segments) != 1 || mock.segments[0] != "segment1" {
+		t.Error("the segment name should be forwarded. Got: ", mock.segments)
+	}
+
+	var rebuild ssync.RebuildResult
+	if code := post("/sync/rebuild", &rebuild); code != 200 || rebuild.Splits == nil || rebuild.Splits.ChangeNumber != 2 {
+		t.Error("wrong rebuild result: ", code, rebuild)
+	}
+
+	mock.fail = true
+	var failed struct {
+		Error  string              `json:"error"`
+		Result ssync.RebuildResult `json:"result"`
+	}
+	if code := post("/sync/rebuild", &failed); code != 500 || failed.Error != "some error" || failed.Result.Splits == nil {
+		t.Error("errors should be reported along with the partial result. Got: ", code, failed)
+	}
+}
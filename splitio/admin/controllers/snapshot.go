@@ -1,7 +1,9 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"strconv"
 	"time"
@@ -16,16 +18,20 @@ import (
 type SnapshotController struct {
 	logger logging.LoggerInterface
 	db     storage.Snapshotter
+	loader snapshot.Loader
 }
 
-// NewSnapshotController constructs a new snapshot controller
-func NewSnapshotController(logger logging.LoggerInterface, db storage.Snapshotter) *SnapshotController {
-	return &SnapshotController{logger: logger, db: db}
+// NewSnapshotController constructs a new snapshot controller. If `loader` is nil, snapshots cannot be uploaded
+func NewSnapshotController(logger logging.LoggerInterface, db storage.Snapshotter, loader snapshot.Loader) *SnapshotController {
+	return &SnapshotController{logger: logger, db: db, loader: loader}
 }
 
 // Register mounts the endpoints int he provided router
 func (c *SnapshotController) Register(router gin.IRouter) {
 	router.GET("/snapshot", c.downloadSnapshot)
+	if c.loader != nil {
+		router.POST("/snapshot", c.loadSnapshot)
+	}
 }
 
 func (c *SnapshotController) downloadSnapshot(ctx *gin.Context) {
@@ -57,3 +63,40 @@ func (c *SnapshotController) downloadSnapshot(ctx *gin.Context) {
 	ctx.Writer.Header().Set("Content-Length", strconv.Itoa(len(encodedSnap)))
 	ctx.Writer.Write(encodedSnap)
 }
+
+func (c *SnapshotController) loadSnapshot(ctx *gin.Context) {
+	// curl -X POST --data-binary @split.proxy.0001.snapshot http://localhost:3010/admin/snapshot?force=true
+	force := false
+	if raw := ctx.Query("force"); raw != "" {
+		var err error
+		if force, err = strconv.ParseBool(raw); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid value for 'force' parameter"})
+			return
+		}
+	}
+
+	body, err := ioutil.ReadAll(ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "error reading request body"})
+		return
+	}
+
+	snap, err := snapshot.Decode(body)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("error decoding snapshot: %s", err)})
+		return
+	}
+
+	result, err := c.loader.Load(snap, force)
+	if err != nil {
+		if errors.Is(err, snapshot.ErrOlderSnapshot) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.logger.Error("error loading snapshot: ", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -35,7 +35,7 @@ func TestDownloadProxySnapshot(t *testing.T) {
 		return
 	}
 
-	ctrl := NewSnapshotController(logging.NewLogger(nil), dbInstance)
+	ctrl := NewSnapshotController(logging.NewLogger(nil), dbInstance, nil)
 
 	resp := httptest.NewRecorder()
 	ctx, router := gin.CreateTestContext(resp)
@@ -76,3 +76,51 @@ func TestDownloadProxySnapshot(t *testing.T) {
 		t.Error("loaded snapshot is different to downloaded")
 	}
 }
+
+type snapshotLoaderMock struct {
+	loadCall func(snap *snapshot.Snapshot, force bool) (*snapshot.LoadResult, error)
+}
+
+func (m *snapshotLoaderMock) Load(snap *snapshot.Snapshot, force bool) (*snapshot.LoadResult, error) {
+	return m.loadCall(snap, force)
+}
+
+func TestUploadProxySnapshot(t *testing.T) {
+	raw, err := ioutil.ReadFile("../../../test/snapshot/proxy.snapshot")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	loader := &snapshotLoaderMock{loadCall: func(snap *snapshot.Snapshot, force bool) (*snapshot.LoadResult, error) {
+		if !force {
+			return nil, snapshot.ErrOlderSnapshot
+		}
+		return &snapshot.LoadResult{ChangeNumber: 123}, nil
+	}}
+
+	ctrl := NewSnapshotController(logging.NewLogger(nil), nil, loader)
+	resp := httptest.NewRecorder()
+	ctx, router := gin.CreateTestContext(resp)
+	ctrl.Register(router)
+
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/snapshot", bytes.NewReader(raw))
+	router.ServeHTTP(resp, ctx.Request)
+	if resp.Code != http.StatusConflict {
+		t.Error("an older snapshot should return 409. Got: ", resp.Code)
+	}
+
+	resp = httptest.NewRecorder()
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/snapshot?force=true", bytes.NewReader(raw))
+	router.ServeHTTP(resp, ctx.Request)
+	if resp.Code != http.StatusOK {
+		t.Error("a forced load should return 200. Got: ", resp.Code)
+	}
+
+	resp = httptest.NewRecorder()
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/snapshot", bytes.NewReader([]byte("garbage")))
+	router.ServeHTTP(resp, ctx.Request)
+	if resp.Code != http.StatusBadRequest {
+		t.Error("an invalid snapshot should return 400. Got: ", resp.Code)
+	}
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v4/storage"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/usage"
+)
+
+// UsageController exposes impression counters per flag & stale flags
+type UsageController struct {
+	logger  logging.LoggerInterface
+	tracker *usage.Tracker
+	splits  storage.SplitStorageConsumer
+}
+
+// NewUsageController constructs a new flag usage controller
+func NewUsageController(logger logging.LoggerInterface, tracker *usage.Tracker, splits storage.SplitStorageConsumer) *UsageController {
+	return &UsageController{logger: logger, tracker: tracker, splits: splits}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *UsageController) Register(router gin.IRouter) {
+	router.GET("/usage/flags", c.flags)
+	router.GET("/usage/stale", c.stale)
+}
+
+func (c *UsageController) flags(ctx *gin.Context) {
+	// curl 'http://localhost:3010/admin/api/v1/usage/flags?prefix=new_'
+	prefix := ctx.Query("prefix")
+	all := c.tracker.Usage()
+	items := make([]usage.FlagUsage, 0, len(all))
+	for _, flagUsage := range all {
+		if strings.HasPrefix(flagUsage.Flag, prefix) {
+			items = append(items, flagUsage)
+		}
+	}
+	ctx.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (c *UsageController) stale(ctx *gin.Context) {
+	// curl http://localhost:3010/admin/api/v1/usage/stale
+	ctx.JSON(http.StatusOK, c.tracker.Stale(c.splits.SplitNames()))
+}
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/storage/inmemory/mutexmap"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/usage"
+)
+
+func TestUsageEndpoints(t *testing.T) {
+	splits := mutexmap.NewMMSplitStorage()
+	splits.Update([]dtos.SplitDTO{{Name: "new_flag"}, {Name: "old_flag"}, {Name: "dead_flag"}}, nil, 1)
+
+	tracker := usage.NewTracker(time.Hour, time.Hour)
+	batch := make(usage.Batch)
+	batch.Add("go-6.1.0", "new_flag", "on", 3)
+	batch.Add("java-4.0.0", "old_flag", "off", 1)
+	tracker.Record(batch)
+
+	ctrl := NewUsageController(logging.NewLogger(nil), tracker, splits)
+	_, router := gin.CreateTestContext(httptest.NewRecorder())
+	ctrl.Register(router)
+
+	get := func(path string, into interface{}) int {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(resp, req)
+		json.Unmarshal(resp.Body.Bytes(), into)
+		return resp.Code
+	}
+
+	var flags struct {
+		Items []usage.FlagUsage `json:"items"`
+	}
+	if code := get("/usage/flags", &flags); code != http.StatusOK || len(flags.Items) != 2 || flags.Items[0].Flag != "new_flag" || flags.Items[0].Total != 3 {
+		t.Error("wrong usage: ", code, flags)
+	}
+	if get("/usage/flags?prefix=old", &flags); len(flags.Items) != 1 || flags.Items[0].SDKs["java-4.0.0"] != 1 {
+		t.Error("wrong filtered usage: ", flags)
+	}
+
+	var report usage.StaleReport
+	if code := get("/usage/stale", &report); code != http.StatusOK || len(report.Flags) != 1 || report.Flags[0].Name != "dead_flag" || report.Complete {
+		t.Error("wrong stale report: ", code, report)
+	}
+}
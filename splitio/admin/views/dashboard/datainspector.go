@@ -94,6 +94,21 @@ const dataInspector = `
   
         <!-- SEGMENTS DATA -->
         <div role="tabpanel" class="tab-pane" id="segments-data">
+          <div class="row">
+            <div class="col-md-12">
+              <div class="gray1Box metricBox">
+                <form class="form-inline" onsubmit="javascript:lookupKeySegments();return false;">
+                  <div class="form-group">
+                    <input type="text" id="keySegmentsInput" class="form-control input-sm" placeholder="Find segments containing key">
+                  </div>
+                  <button type="submit" class="btn btn-default btn-sm">
+                    <span class="glyphicon glyphicon-search" aria-hidden="true"></span>
+                  </button>
+                  <span id="keySegmentsResult"></span>
+                </form>
+              </div>
+            </div>
+          </div>
           <div class="row">
             <div class="col-md-12">
               <div class="bg-primary metricBox">
@@ -0,0 +1,85 @@
+package dashboard
+
+const debugger = `
+{{define "Debugger"}}
+  <div role="tabpanel" class="tab-pane" id="evaluation-debugger">
+    <div class="row">
+      <div class="col-md-12">
+        <div class="gray1Box metricBox">
+          <h4>Evaluate</h4>
+          <form id="debugger_form" class="form-inline" onsubmit="javascript:debugEvaluation();return false;">
+            <div class="form-group">
+              <input type="text" id="debuggerKey" class="form-control" placeholder="Key" required>
+            </div>
+            <div class="form-group">
+              <input type="text" id="debuggerBucketingKey" class="form-control" placeholder="Bucketing key (optional)">
+            </div>
+            <div class="form-group">
+              <input type="text" id="debuggerSplit" class="form-control" placeholder="Split name" required>
+            </div>
+            <button type="submit" class="btn btn-primary">Evaluate</button>
+            <div class="form-group" style="width:100%;margin-top:10px">
+              <textarea id="debuggerAttributes" class="form-control" style="width:100%" rows="4"
+                placeholder='Attributes as JSON (optional), ie: {"age": 30, "plan": "gold"}'></textarea>
+            </div>
+          </form>
+          <p id="debugger_error" class="text-danger"></p>
+        </div>
+      </div>
+    </div>
+
+    <div class="row">
+      <div class="col-md-12">
+        <div class="bg-primary metricBox">
+          <h4>Result</h4>
+          <table id="debugger_result" class="table table-condensed">
+            <tbody>
+            </tbody>
+          </table>
+        </div>
+      </div>
+    </div>
+
+    <div class="row">
+      <div class="col-md-6">
+        <div class="bg-primary metricBox">
+          <h4>Conditions</h4>
+          <table id="debugger_conditions" class="table table-condensed">
+            <thead>
+              <tr>
+                <th>#</th>
+                <th>Type</th>
+                <th>Label</th>
+                <th>Matched</th>
+              </tr>
+            </thead>
+            <tbody>
+            </tbody>
+          </table>
+        </div>
+      </div>
+      <div class="col-md-6">
+        <div class="bg-primary metricBox">
+          <h4>Segments & dependencies</h4>
+          <table id="debugger_checks" class="table table-condensed">
+            <thead>
+              <tr>
+                <th>Check</th>
+                <th>Detail</th>
+                <th>Result</th>
+              </tr>
+            </thead>
+            <tbody>
+            </tbody>
+          </table>
+        </div>
+      </div>
+    </div>
+
+    <div class="alert alert-info" role="alert">
+      <h3 class="alert-heading">Evaluation debugger</h3>
+      <p>Evaluations are performed using the data currently stored by this instance. No impressions are generated.</p>
+    </div>
+  </div>
+{{end}}
+`
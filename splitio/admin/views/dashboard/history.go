@@ -0,0 +1,60 @@
+package dashboard
+
+const history = `
+{{define "History"}}
+  <div role="tabpanel" class="tab-pane" id="split-history">
+    <div class="row">
+      <div class="col-md-12">
+        <div class="gray1Box metricBox">
+          <form class="form-inline" onsubmit="javascript:refreshHistory();return false;">
+            <div class="form-group">
+              <input type="text" id="historySplitName" class="form-control input-sm" placeholder="Filter by split name">
+            </div>
+            <button type="submit" class="btn btn-default btn-sm">
+              <span class="glyphicon glyphicon-filter" aria-hidden="true"></span>
+            </button>
+          </form>
+        </div>
+      </div>
+    </div>
+
+    <div class="row">
+      <div class="col-md-7">
+        <div class="bg-primary metricBox">
+          <h4>Timeline</h4>
+          <table id="history_rows" class="table table-condensed table-hover">
+            <thead>
+              <tr>
+                <th>Received</th>
+                <th>Split</th>
+                <th>Type</th>
+                <th>Source</th>
+                <th>Change Number</th>
+                <th>&nbsp;</th>
+              </tr>
+            </thead>
+            <tbody>
+            </tbody>
+          </table>
+        </div>
+      </div>
+      <div class="col-md-5">
+        <div class="bg-primary metricBox">
+          <h4 id="history_diff_title">Diff</h4>
+          <table id="history_diff" class="table table-condensed">
+            <thead>
+              <tr>
+                <th>Field</th>
+                <th>Before</th>
+                <th>After</th>
+              </tr>
+            </thead>
+            <tbody>
+            </tbody>
+          </table>
+        </div>
+      </div>
+    </div>
+  </div>
+{{end}}
+`
@@ -33,41 +33,78 @@ const mainScript = `
   
       $('.segmentKeysDetailedList-tbody').html("");
       $('#segmentKeysDetailedList-tbody-'+segment).html('<tr><td colspan="3"><p>Loading keys...</p></td></tr>');
-      $.get("/admin/dashboard/segmentKeys/"+segment, function(data) {
-	let html = '';
-	html = data.reduce(function(block, item) {
-	    const rows = [
-		'<tr class="segmentKeyItem">',
-		'<td><span class="' + (item.removed ? '"redbox" "' : '') + 'segmentKeyItemName">' + item.name + '</span></td>',
-	    ];
-	    {{if .ProxyMode}}
-		rows.push('<td>' + item.removed + '</td>')
-		rows.push('<td>' + item.cn + '</td>')
-	    {{end}}
-	    rows.push('</tr>');
-	    return block + rows.join('');
-	}, '');
-
-        $('#segmentKeysDetailedList-tbody-'+segment).html(html);
+      loadSegmentKeys(segment, 0);
+    }
+
+    const segmentKeysPageSize = 100;
+
+    function formatSegmentKey(item) {
+      const rows = [
+        '<tr class="segmentKeyItem">',
+        '<td><span class="' + (item.removed ? '"redbox" "' : '') + 'segmentKeyItemName">' + item.name + '</span></td>',
+      ];
+      {{if .ProxyMode}}
+        rows.push('<td>' + item.removed + '</td>')
+        rows.push('<td>' + item.cn + '</td>')
+      {{end}}
+      rows.push('</tr>');
+      return rows.join('');
+    }
+
+    function loadSegmentKeys(segment, offset) {
+      const query = $.param({
+        offset: offset,
+        limit: segmentKeysPageSize,
+        filter: ($("#filterSegmentKeyInput-"+segment).val() || '').trim(),
+      });
+
+      $.get("/admin/dashboard/segmentKeys/"+segment+"?"+query, function(data) {
+        const tbody = $('#segmentKeysDetailedList-tbody-'+segment);
+        if (offset == 0) {
+          tbody.html('');
+        }
+        tbody.find('tr.segmentKeysMore').remove();
+        tbody.append(data.keys.map(formatSegmentKey).join(''));
+
+        const loaded = data.offset + data.keys.length;
+        if (loaded < data.total) {
+          tbody.append(
+            '<tr class="segmentKeysMore"><td colspan="3">' +
+            '<a href="#" onclick="javascript:loadSegmentKeys(\'' + segment + '\', ' + loaded + ');return false;">' +
+            'Load more (' + (data.total - loaded) + ' remaining)</a>' +
+            '</td></tr>');
+        }
       })
     }
   
     function filterSegmentKeys(segmentName){
-      $("tr.segmentKeyItem").removeClass("filterDisplayNone");
-      var filter = $("#filterSegmentKeyInput-"+segmentName).val();
-  
-      $("tr.segmentKeyItem").each(function() {
-        $this = $(this);
-        var segmentName = $this.find("span.segmentKeyItemName").html();
-        if (segmentName.indexOf(filter.trim()) == -1) {
-          $this.addClass("filterDisplayNone");
-        }
-      });
+      loadSegmentKeys(segmentName, 0);
     }
   
-    function resetFilterSegmentKeys(){
-      $("tr.segmentKeyItem").removeClass("filterDisplayNone");
+    function resetFilterSegmentKeys(segmentName){
       $(".filterSegmentKeyInput").val("");
+      loadSegmentKeys(segmentName, 0);
+    }
+
+    function lookupKeySegments() {
+      const key = $('#keySegmentsInput').val().trim();
+      if (key == '') {
+        return;
+      }
+
+      $('#keySegmentsResult').text('Looking up segments...');
+      $.ajax({
+        dataType: "json",
+        url: "/admin/dashboard/keySegments?" + $.param({key: key}),
+        success: function(data) {
+          $('#keySegmentsResult').text(data.segments.length > 0
+            ? 'Key "' + data.key + '" belongs to: ' + data.segments.join(', ')
+            : 'Key "' + data.key + '" does not belong to any segment');
+        },
+        error: function(xhr) {
+          $('#keySegmentsResult').text((xhr.responseJSON && xhr.responseJSON.error) || 'unexpected error');
+        },
+      });
     }
   
     function resetFilterSplits(){
@@ -274,7 +311,7 @@ const mainScript = `
           	  '<button class="btn btn-default btn-sm" type="button" onclick="javascript:filterSegmentKeys(\'' + segment.name + '\');">' +
 	  	  '  <span class="glyphicon glyphicon-filter" aria-hidden="true"></span>' +
 	  	  '</button>' +
-                    '<button class="btn btn-default btn-sm" type="button" onclick="javascript:resetFilterSegmentKeys();">' +
+                    '<button class="btn btn-default btn-sm" type="button" onclick="javascript:resetFilterSegmentKeys(\'' + segment.name + '\');">' +
 	  	    '<span class="glyphicon glyphicon-remove" aria-hidden="true"></span>' +
 	  	  '</button>' +
           	'</span>' +
@@ -409,9 +446,458 @@ const mainScript = `
     renderBackendStatsChart(stats.backendLatencies);
     {{if .ProxyMode}}
         renderSDKChart(stats.latencies);
+    {{else}}
+        renderPipelineMetrics(stats.pipelines);
+        renderQueueCapWarnings(stats.queueCaps);
     {{end}}
   };
 
+  {{if .ProxyMode}}
+  function formatOverride(override) {
+    return (
+      '<tr>' +
+      '  <td>' + override.splitName + '</td>' +
+      '  <td>' + override.type + '</td>' +
+      '  <td>' + (override.defaultTreatment || '') + '</td>' +
+      '  <td>' + override.baseChangeNumber + '</td>' +
+      '  <td>' + override.changeNumber + '</td>' +
+      '  <td>' + (override.expiresAt ? new Date(override.expiresAt).toUTCString() : 'never') + '</td>' +
+      '  <td><a href="#" onclick="javascript:removeOverride(\'' + override.splitName + '\');return false;" class="btn-xs">' +
+      '    <span class="glyphicon glyphicon-trash" aria-hidden="true"></span>' +
+      '  </a></td>' +
+      '</tr>\n');
+  };
+
+  function updateOverrides(overrides) {
+    $('#override_rows tbody').empty();
+    $('#override_rows tbody').append(overrides.map(formatOverride).join('\n'));
+  };
+
+  function refreshOverrides() {
+    $.getJSON("/admin/overrides", updateOverrides);
+  };
+
+  function overrideFailed(xhr) {
+    $('#override_error').text((xhr.responseJSON && xhr.responseJSON.error) || 'unexpected error');
+  };
+
+  function setOverride() {
+    const body = {
+      type: $('#overrideType').val(),
+      defaultTreatment: $('#overrideDefaultTreatment').val(),
+      ttlSeconds: parseInt($('#overrideTTL').val() || '0', 10),
+    };
+
+    if (body.type == 'pin') {
+      try {
+        body.definition = JSON.parse($('#overrideDefinition').val());
+      } catch (e) {
+        $('#override_error').text('invalid split definition: ' + e.message);
+        return;
+      }
+    }
+
+    $.ajax({
+      type: "PUT",
+      url: "/admin/overrides/" + encodeURIComponent($('#overrideSplitName').val()),
+      contentType: "application/json",
+      data: JSON.stringify(body),
+      success: function() {
+        $('#override_error').text('');
+        $('#override_form')[0].reset();
+        refreshOverrides();
+      },
+      error: overrideFailed,
+    });
+  };
+
+  function removeOverride(splitName) {
+    if (!confirm("The override for " + splitName + " will be removed, are you sure?")) {
+      return;
+    }
+
+    $.ajax({
+      type: "DELETE",
+      url: "/admin/overrides/" + encodeURIComponent(splitName),
+      success: refreshOverrides,
+      error: overrideFailed,
+    });
+  };
+  {{end}}
+
+  function formatHistoryChange(change) {
+    return (
+      '<tr>' +
+      '  <td>' + new Date(change.receivedAt).toUTCString() + '</td>' +
+      '  <td>' + change.splitName + '</td>' +
+      '  <td>' + change.type + '</td>' +
+      '  <td>' + change.source + '</td>' +
+      '  <td>' + change.previousChangeNumber + ' &rarr; ' + change.changeNumber + '</td>' +
+      '  <td><a href="#" onclick="javascript:showHistoryDiff(' + change.id + ');return false;" class="btn-xs">' +
+      '    <span class="glyphicon glyphicon-eye-open" aria-hidden="true"></span>' +
+      '  </a></td>' +
+      '</tr>\n');
+  };
+
+  function refreshHistory() {
+    const query = $.param({split: $('#historySplitName').val().trim(), limit: 100});
+    $.getJSON("/admin/api/v1/history?" + query, function(data) {
+      $('#history_rows tbody').empty();
+      $('#history_rows tbody').append(data.items.map(formatHistoryChange).join('\n'));
+    });
+  };
+
+  function showHistoryDiff(id) {
+    $.getJSON("/admin/api/v1/history/changes/" + id, function(change) {
+      $('#history_diff_title').text('Diff for ' + change.splitName + ' (' + change.previousChangeNumber + ' \u2192 ' + change.changeNumber + ')');
+      $('#history_diff tbody').empty();
+      $('#history_diff tbody').append(change.diff.map(function(field) {
+        return '<tr><td>' + (field.path || '(definition)') + '</td>' +
+          '<td><code>' + JSON.stringify(field.before) + '</code></td>' +
+          '<td><code>' + JSON.stringify(field.after) + '</code></td></tr>';
+      }).join('\n'));
+    });
+  };
+
+  function formatCounts(counts) {
+    return Object.keys(counts).sort().map(function(name) {
+      return name + ': ' + counts[name];
+    }).join('<br/>');
+  };
+
+  function formatLastSeen(lastSeen) {
+    return lastSeen ? new Date(lastSeen).toUTCString() : 'never';
+  };
+
+  function refreshUsage() {
+    $.getJSON("/admin/api/v1/usage/flags", function(data) {
+      $('#usage_rows tbody').empty();
+      $('#usage_rows tbody').append(data.items.map(function(usage) {
+        return '<tr><td>' + usage.flag + '</td><td>' + usage.total + '</td>' +
+          '<td>' + formatCounts(usage.treatments) + '</td><td>' + formatCounts(usage.sdks) + '</td>' +
+          '<td>' + formatLastSeen(usage.lastSeen) + '</td></tr>';
+      }).join('\n'));
+    });
+
+    $.getJSON("/admin/api/v1/usage/stale", function(report) {
+      $('#usage_stale_notice').text(report.complete ? '' :
+        'Impressions tracked since ' + new Date(report.trackingSince).toUTCString() + ', flags might not be stale yet.');
+      $('#usage_stale tbody').empty();
+      $('#usage_stale tbody').append(report.flags.map(function(flag) {
+        return '<tr><td>' + flag.name + '</td><td>' + formatLastSeen(flag.lastSeen) + '</td></tr>';
+      }).join('\n'));
+    });
+  };
+
+  function formatTunedSetting(pipeline, setting) {
+    var value = pipeline.settings[setting];
+    if (!pipeline.autoTuning) {
+      return value;
+    }
+    return value + ' <small>(' + pipeline.autoTuning.min[setting] + ' - ' + pipeline.autoTuning.max[setting] + ')</small>';
+  };
+
+  function formatTuningDecision(tuning) {
+    if (!tuning) {
+      return 'disabled';
+    }
+    var adjusted = tuning.lastAdjustment ? 'last adjusted ' + new Date(tuning.lastAdjustment).toUTCString() : 'never adjusted';
+    return tuning.lastDecision + '<br/><small>' + adjusted + '. Posts: ' + tuning.posts +
+      ', errors: ' + (tuning.errorRate * 100).toFixed(1) + '%, throttled: ' + (tuning.throttledRate * 100).toFixed(1) +
+      '%, avg latency: ' + tuning.avgPostLatencyMs.toFixed(0) + 'ms</small>';
+  };
+
+  function refreshPipelines() {
+    $.getJSON("/admin/api/v1/pipelines", function(pipelines) {
+      $('#pipelines_rows tbody').empty();
+      $('#pipelines_rows tbody').append(pipelines.map(function(pipeline) {
+        return '<tr><td>' + pipeline.name + '</td>' +
+          '<td>' + formatTunedSetting(pipeline, 'fetchSize') + '</td>' +
+          '<td>' + formatTunedSetting(pipeline, 'processConcurrency') + '</td>' +
+          '<td>' + formatTunedSetting(pipeline, 'processBatchSize') + '</td>' +
+          '<td>' + formatTunedSetting(pipeline, 'postConcurrency') + '</td>' +
+          '<td>' + formatTuningDecision(pipeline.autoTuning) + '</td></tr>';
+      }).join('\n'));
+    });
+  };
+
+  var queuesMaxDrop = 0;
+
+  function formatQueueActions(queue) {
+    var actions = '<button class="btn btn-default btn-xs" onclick="javascript:peekQueue(\'' + queue.name + '\');">Peek</button> ';
+    if (queue.flushable) {
+      actions += '<button class="btn btn-primary btn-xs" onclick="javascript:flushQueue(\'' + queue.name + '\');">Flush now</button> ';
+    }
+    return actions + '<button class="btn btn-danger btn-xs" onclick="javascript:dropQueue(\'' + queue.name + '\', ' + queue.size + ');">Drop</button>';
+  };
+
+  function refreshQueues() {
+    $.getJSON("/admin/api/v1/queues", function(data) {
+      queuesMaxDrop = data.maxDrop;
+      $('#queues_rows tbody').empty();
+      $('#queues_rows tbody').append(data.items.map(function(queue) {
+        return '<tr><td>' + queue.name + '</td><td>' + queue.size + '</td><td>' + formatQueueActions(queue) + '</td></tr>';
+      }).join('\n'));
+    });
+  };
+
+  function showQueueActionResult(level, message) {
+    $('#queue_action_result').html('<div class="alert alert-' + level + '" role="alert">' + $('<span>').text(message).html() + '</div>');
+  };
+
+  function queueActionFailed(xhr) {
+    var message = (xhr.responseJSON && xhr.responseJSON.error) ? xhr.responseJSON.error : xhr.statusText;
+    showQueueActionResult('danger', message);
+  };
+
+  function peekQueue(name) {
+    var query = $.param({count: 10, redact: $('#queue_peek_redact').is(':checked')});
+    $.ajax({
+      type: "GET",
+      url: "/admin/api/v1/queues/" + encodeURIComponent(name) + "/peek?" + query,
+      success: function(data) {
+        showQueueActionResult('info', 'First ' + data.items.length + ' items in ' + name);
+        $('#queue_peek_items').text(JSON.stringify(data.items, null, 2)).show();
+      },
+      error: queueActionFailed,
+    });
+  };
+
+  function flushQueue(name) {
+    $.ajax({
+      type: "POST",
+      url: "/admin/api/v1/queues/" + encodeURIComponent(name) + "/flush",
+      success: function() {
+        showQueueActionResult('success', 'Flush of ' + name + ' requested');
+        refreshQueues();
+      },
+      error: queueActionFailed,
+    });
+  };
+
+  function dropQueue(name, size) {
+    if (size == 0) {
+      showQueueActionResult('info', name + ' is empty');
+      return;
+    }
+
+    if (size > queuesMaxDrop) {
+      showQueueActionResult('warning', name + ' has ' + size + ' items, above the max of ' + queuesMaxDrop + ' that can be dropped at once. Use the admin API to drop it in chunks.');
+      return;
+    }
+
+    if (!confirm("All " + size + " items in " + name + " will be discarded and never posted, are you sure?")) {
+      return;
+    }
+
+    $.ajax({
+      type: "POST",
+      url: "/admin/api/v1/queues/" + encodeURIComponent(name) + "/drop?" + $.param({confirm: name, count: size}),
+      success: function(data) {
+        showQueueActionResult('success', data.dropped + ' items dropped from ' + name);
+        $('#queue_peek_items').hide();
+        refreshQueues();
+      },
+      error: queueActionFailed,
+    });
+  };
+
+  const pipelineCharts = {};
+  const stageLatencyLabels = ["<1", "1-1.5", "1.5-2.25", "2.25-3.38", "3.38-5.06", "5.06-7.59", "7.59-11.39", "11.39-17.09", "17.09-25.63", "25.63-38.44", "38.44-57.67", "57.67-86.5", "86.5-129.75", "129.75-194.62", "194.62-291.93", "291.93-437.89", "437.89-656.84", "656.84-985.26", "985.26-1477.89", "1477.89-2216.84", "2216.84-3325.26", "3325.26-4987.89", ">4987.89"];
+
+  function renderPipelineChart(id, config) {
+    if (pipelineCharts[id]) {
+      pipelineCharts[id].data = config.data;
+      pipelineCharts[id].update();
+      return;
+    }
+    pipelineCharts[id] = new Chart(document.getElementById(id).getContext('2d'), config);
+  };
+
+  function formatOccupancy(buffer) {
+    return buffer.used + ' / ' + buffer.capacity;
+  };
+
+  function formatPostedBytes(pipeline) {
+    if (pipeline.rawBytes == 0) {
+      return '-';
+    }
+    const ratio = (100 * pipeline.sentBytes / pipeline.rawBytes).toFixed(1);
+    return formatBytes(pipeline.rawBytes) + ' / ' + formatBytes(pipeline.sentBytes) + ' (' + ratio + '%)';
+  };
+
+  function formatBytes(bytes) {
+    const units = ['B', 'KB', 'MB', 'GB', 'TB'];
+    let idx = 0;
+    while (bytes >= 1024 && idx < units.length - 1) {
+      bytes /= 1024;
+      idx++;
+    }
+    return (idx == 0 ? bytes : bytes.toFixed(2)) + ' ' + units[idx];
+  };
+
+  function statusCodeColor(code, alpha) {
+    if (code.startsWith('2')) {
+      return 'rgba(75, 192, 192, ' + alpha + ')';
+    }
+    return code === '429' ? 'rgba(255, 205, 86, ' + alpha + ')' : 'rgba(255, 99, 132, ' + alpha + ')';
+  };
+
+  function renderPipelineMetrics(pipelines) {
+    if (!pipelines) {
+      return;
+    }
+
+    $('#pipeline_metrics_rows tbody').empty();
+    $('#pipeline_metrics_rows tbody').append(pipelines.map(function(pipeline) {
+      return '<tr><td>' + pipeline.name + '</td><td>' + pipeline.fetched + '</td><td>' + pipeline.processed + '</td>' +
+        '<td>' + pipeline.posted + '</td><td>' + pipeline.requeued + '</td><td>' + pipeline.dropped + '</td>' +
+        '<td>' + pipeline.retries + '</td><td>' + formatPostedBytes(pipeline) + '</td><td>' + formatOccupancy(pipeline.inputBuffer) + '</td>' +
+        '<td>' + formatOccupancy(pipeline.preSubmitBuffer) + '</td></tr>';
+    }).join('\n'));
+
+    pipelines.forEach(function(pipeline) {
+      const latenciesId = 'pipeline_latencies_' + pipeline.name;
+      const statusId = 'pipeline_status_' + pipeline.name;
+      if (!document.getElementById(latenciesId)) {
+        $('#pipeline_metrics_charts').append(
+          '<div class="row">' +
+          '  <div class="col-md-8"><div class="gray1Box metricBox">' +
+          '    <h4>' + pipeline.name + ' stage latencies <small>(milliseconds)</small></h4><canvas id="' + latenciesId + '"></canvas>' +
+          '  </div></div>' +
+          '  <div class="col-md-4"><div class="gray1Box metricBox">' +
+          '    <h4>' + pipeline.name + ' post status codes</h4><canvas id="' + statusId + '"></canvas>' +
+          '  </div></div>' +
+          '</div>');
+      }
+
+      renderPipelineChart(latenciesId, {
+        type: 'horizontalBar',
+        data: {
+          labels: stageLatencyLabels,
+          datasets: [
+            {label: 'fetch', data: pipeline.latencies.fetch, backgroundColor: 'rgba(255, 159, 64, 0.2)', borderColor: 'rgba(255, 159, 64, 1)', borderWidth: 1},
+            {label: 'process', data: pipeline.latencies.process, backgroundColor: 'rgba(54, 162, 235, 0.2)', borderColor: 'rgba(54, 162, 235, 1)', borderWidth: 1},
+            {label: 'post', data: pipeline.latencies.post, backgroundColor: 'rgba(75, 192, 192, 0.2)', borderColor: 'rgba(75, 192, 192, 1)', borderWidth: 1}
+          ]
+        },
+        options: {scales: {yAxes: [{ticks: {beginAtZero: true}}]}}
+      });
+
+      const codes = Object.keys(pipeline.statusCodes).sort();
+      renderPipelineChart(statusId, {
+        type: 'pie',
+        data: {
+          labels: codes,
+          datasets: [{
+            data: codes.map(function(code) { return pipeline.statusCodes[code]; }),
+            backgroundColor: codes.map(function(code) { return statusCodeColor(code, 0.2); }),
+            borderColor: codes.map(function(code) { return statusCodeColor(code, 1); }),
+            borderWidth: 1
+          }]
+        }
+      });
+    });
+  };
+
+  function formatOverflowOutcome(queueCap) {
+    switch (queueCap.policy) {
+      case 'sample':
+        return queueCap.trimmed + ' items discarded and ' + queueCap.sampled + ' re-queued (' + (queueCap.sampleRate * 100) + '% sample)';
+      case 'spill':
+        return queueCap.spilled + ' items spilled to disk';
+      default:
+        return queueCap.trimmed + ' oldest items discarded';
+    }
+  };
+
+  function renderQueueCapWarnings(queueCaps) {
+    const container = $('#queue_cap_warnings');
+    container.empty();
+    if (!queueCaps) {
+      return;
+    }
+
+    queueCaps.forEach(function(queueCap) {
+      if (queueCap.capHits == 0) {
+        return;
+      }
+
+      const severity = queueCap.lastError ? 'alert-danger' : 'alert-warning';
+      container.append(
+        '<div class="alert ' + severity + '" role="alert">' +
+        '  <strong>' + queueCap.name + ' queue reached its cap of ' + queueCap.maxLength + ' items ' + queueCap.capHits + ' time(s).</strong> ' +
+        '  Last time: ' + new Date(queueCap.lastHit).toUTCString() + '. Policy: ' + queueCap.policy + ', ' + formatOverflowOutcome(queueCap) + ' so far.' +
+        (queueCap.lastError ? '<br/>Last error: ' + queueCap.lastError : '') +
+        '</div>');
+    });
+  };
+
+  function debuggerRow(name, value) {
+    return '<tr><th>' + name + '</th><td>' + value + '</td></tr>';
+  };
+
+  function updateDebugger(explanation) {
+    $('#debugger_error').text('');
+    $('#debugger_result tbody').empty();
+    $('#debugger_result tbody').append([
+      debuggerRow('Treatment', explanation.treatment),
+      debuggerRow('Label', explanation.label),
+      debuggerRow('Config', explanation.config || '-'),
+      debuggerRow('Change number', explanation.changeNumber),
+      debuggerRow('Killed', explanation.killed),
+      debuggerRow('Traffic allocation', explanation.trafficAllocation + '%' +
+        (explanation.trafficAllocationBucket != null ? ' (bucket: ' + explanation.trafficAllocationBucket + ')' : '')),
+      debuggerRow('Bucket', explanation.bucket != null ? explanation.bucket : '-'),
+      debuggerRow('Matched condition', explanation.matchedCondition
+        ? '#' + explanation.matchedCondition.index + ' ' + explanation.matchedCondition.label
+        : '-'),
+    ].join('\n'));
+
+    $('#debugger_conditions tbody').empty();
+    $('#debugger_conditions tbody').append(explanation.conditions.map(function(condition) {
+      return '<tr><td>' + condition.index + '</td><td>' + condition.conditionType + '</td><td>' + condition.label + '</td><td>' + condition.matched + '</td></tr>';
+    }).join('\n'));
+
+    const segments = explanation.segmentChecks.map(function(check) {
+      return '<tr><td>Segment ' + check.segment + '</td><td>' + check.key + '</td><td>' + (check.error || (check.member ? 'member' : 'not a member')) + '</td></tr>';
+    });
+    const dependencies = explanation.dependencies.map(function(check) {
+      return '<tr><td>Split ' + check.split + '</td><td>' + check.treatment + ' (' + check.label + ')</td><td>' + (check.matched ? 'matched' : 'not matched') + '</td></tr>';
+    });
+    $('#debugger_checks tbody').empty();
+    $('#debugger_checks tbody').append(segments.concat(dependencies).join('\n'));
+  };
+
+  function debugEvaluation() {
+    const body = {
+      key: $('#debuggerKey').val(),
+      bucketingKey: $('#debuggerBucketingKey').val(),
+      split: $('#debuggerSplit').val(),
+    };
+
+    const attributes = $('#debuggerAttributes').val();
+    if (attributes) {
+      try {
+        body.attributes = JSON.parse(attributes);
+      } catch (e) {
+        $('#debugger_error').text('invalid attributes: ' + e.message);
+        return;
+      }
+    }
+
+    $.ajax({
+      type: "POST",
+      url: "/admin/debugger/evaluate",
+      contentType: "application/json",
+      data: JSON.stringify(body),
+      success: updateDebugger,
+      error: function(xhr) {
+        $('#debugger_error').text((xhr.responseJSON && xhr.responseJSON.error) || 'unexpected error');
+      },
+    });
+  };
+
   function refreshStats() {
     $.getJSON("/admin/dashboard/stats", processStats);
   };
@@ -448,11 +934,27 @@ const mainScript = `
   
     processStats(initialData.stats);
     updateHealthCards(initialData.health);
+    refreshHistory();
+    refreshUsage();
+    refreshQueues();
+    {{if .ProxyMode}}
+      refreshOverrides();
+    {{else}}
+      refreshPipelines();
+    {{end}}
 
   
     setInterval(function() {
       refreshStats();
       refreshHealth();
+      refreshHistory();
+      refreshUsage();
+      refreshQueues();
+      {{if .ProxyMode}}
+        refreshOverrides();
+      {{else}}
+        refreshPipelines();
+      {{end}}
     }, {{.RefreshTime}});
   });
 
@@ -6,6 +6,8 @@ import (
 	"html/template"
 	"strings"
 
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/task"
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/worker"
 	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/application"
 	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/services"
 )
@@ -65,8 +67,12 @@ const main = `
       {{template "Cards" .}}
       {{template "UpstreamStats" .}}
       {{if .ProxyMode}}{{template "SdkStats" .}}{{end}}
-      {{if not .ProxyMode}}{{template "QueueManager" .}}{{end}}
+      {{if .ProxyMode}}{{template "Overrides" .}}{{end}}
+      {{template "QueueManager" .}}
       {{template "DataInspector" .}}
+      {{template "History" .}}
+      {{template "Debugger" .}}
+      {{template "FlagUsage" .}}
     </div>
   </div>
    {{template "MainScript" .}}
@@ -87,23 +93,25 @@ type RootObject struct {
 
 // GlobalStats runtime stats used to render the dashboard
 type GlobalStats struct {
-	BackendTotalRequests   int64            `json:"backendTotalRequests"`
-	RequestsOk             int64            `json:"requestsOk"`
-	RequestsErrored        int64            `json:"requestsErrored"`
-	BackendRequestsOk      int64            `json:"backendRequestsOk"`
-	BackendRequestsErrored int64            `json:"backendRequestsErrored"`
-	SdksTotalRequests      int64            `json:"sdksTotalRequests"`
-	LoggedErrors           int64            `json:"loggedErrors"`
-	LoggedMessages         []string         `json:"loggedMessages"`
-	Splits                 []SplitSummary   `json:"splits"`
-	Segments               []SegmentSummary `json:"segments"`
-	Latencies              []ChartJSData    `json:"latencies"`
-	BackendLatencies       []ChartJSData    `json:"backendLatencies"`
-	ImpressionsQueueSize   int64            `json:"impressionsQueueSize"`
-	ImpressionsLambda      float64          `json:"impressionsLambda"`
-	EventsQueueSize        int64            `json:"eventsQueueSize"`
-	EventsLambda           float64          `json:"eventsLambda"`
-	Uptime                 int64            `json:"uptime"`
+	BackendTotalRequests   int64                   `json:"backendTotalRequests"`
+	RequestsOk             int64                   `json:"requestsOk"`
+	RequestsErrored        int64                   `json:"requestsErrored"`
+	BackendRequestsOk      int64                   `json:"backendRequestsOk"`
+	BackendRequestsErrored int64                   `json:"backendRequestsErrored"`
+	SdksTotalRequests      int64                   `json:"sdksTotalRequests"`
+	LoggedErrors           int64                   `json:"loggedErrors"`
+	LoggedMessages         []string                `json:"loggedMessages"`
+	Splits                 []SplitSummary          `json:"splits"`
+	Segments               []SegmentSummary        `json:"segments"`
+	Latencies              []ChartJSData           `json:"latencies"`
+	BackendLatencies       []ChartJSData           `json:"backendLatencies"`
+	ImpressionsQueueSize   int64                   `json:"impressionsQueueSize"`
+	ImpressionsLambda      float64                 `json:"impressionsLambda"`
+	EventsQueueSize        int64                   `json:"eventsQueueSize"`
+	EventsLambda           float64                 `json:"eventsLambda"`
+	Uptime                 int64                   `json:"uptime"`
+	Pipelines              []task.PipelineMetrics  `json:"pipelines"`
+	QueueCaps              []worker.QueueCapStatus `json:"queueCaps"`
 }
 
 // SplitSummary encapsulates a minimalistic view of split properties to be presented in the dashboard
@@ -133,6 +141,14 @@ type SegmentKeySummary struct {
 	ChangeNumber int64  `json:"cn"`
 }
 
+// SegmentKeysPage is a page of (optionally filtered) keys belonging to a segment
+type SegmentKeysPage struct {
+	Keys   []SegmentKeySummary `json:"keys"`
+	Total  int                 `json:"total"`
+	Offset int                 `json:"offset"`
+	Limit  int                 `json:"limit"`
+}
+
 // RGBA bundles input to CSS's rgba function
 type RGBA struct {
 	Red   int32
@@ -175,6 +191,10 @@ func AssembleDashboardTemplate() (*template.Template, error) {
 		upstreamStats,
 		queueManager,
 		dataInspector,
+		overrides,
+		debugger,
+		history,
+		flagUsage,
 		menu,
 		mainScript,
 		// Main layout
@@ -14,14 +14,17 @@ const menu = `
 	  <span class="glyphicon glyphicon-stats" aria-hidden="true"></span>&nbsp;SDK stats
 	</a>
       </li>
-    {{end}}
-    {{if not .ProxyMode}}
       <li role="presentation">
-        <a href="#queue-manager" aria-controls="queue-manager" role="tab" data-toggle="tab">
-	  <span class="glyphicon glyphicon-info-sign" aria-hidden="true"></span>&nbsp;Queue Manager
+        <a href="#split-overrides" aria-controls="split-overrides" role="tab" data-toggle="tab">
+	  <span class="glyphicon glyphicon-pencil" aria-hidden="true"></span>&nbsp;Overrides
 	</a>
       </li>
     {{end}}
+    <li role="presentation">
+      <a href="#queue-manager" aria-controls="queue-manager" role="tab" data-toggle="tab">
+        <span class="glyphicon glyphicon-info-sign" aria-hidden="true"></span>&nbsp;Queue Manager
+      </a>
+    </li>
     <li role="presentation">
       <a href="#backend-stats" aria-controls="backend-stats" role="tab" data-toggle="tab">
         <span class="glyphicon glyphicon-stats" aria-hidden="true"></span>&nbsp;Split stats
@@ -32,6 +35,21 @@ const menu = `
         <span class="glyphicon glyphicon-search" aria-hidden="true"></span>&nbsp;Data inspector
       </a>
     </li>
+    <li role="presentation">
+      <a href="#split-history" aria-controls="split-history" role="tab" data-toggle="tab">
+        <span class="glyphicon glyphicon-time" aria-hidden="true"></span>&nbsp;History
+      </a>
+    </li>
+    <li role="presentation">
+      <a href="#evaluation-debugger" aria-controls="evaluation-debugger" role="tab" data-toggle="tab">
+        <span class="glyphicon glyphicon-wrench" aria-hidden="true"></span>&nbsp;Debugger
+      </a>
+    </li>
+    <li role="presentation">
+      <a href="#flag-usage" aria-controls="flag-usage" role="tab" data-toggle="tab">
+        <span class="glyphicon glyphicon-fire" aria-hidden="true"></span>&nbsp;Flag usage
+      </a>
+    </li>
   </ul>
 {{end}}
 `
@@ -0,0 +1,66 @@
+package dashboard
+
+const overrides = `
+{{define "Overrides"}}
+  <div role="tabpanel" class="tab-pane" id="split-overrides">
+    <div class="row">
+      <div class="col-md-12">
+        <div class="gray1Box metricBox">
+          <h4>New override</h4>
+          <form id="override_form" class="form-inline" onsubmit="javascript:setOverride();return false;">
+            <div class="form-group">
+              <input type="text" id="overrideSplitName" class="form-control" placeholder="Split name" required>
+            </div>
+            <div class="form-group">
+              <select id="overrideType" class="form-control">
+                <option value="kill">Kill</option>
+                <option value="defaultTreatment">Change default treatment</option>
+                <option value="pin">Pin definition</option>
+              </select>
+            </div>
+            <div class="form-group">
+              <input type="text" id="overrideDefaultTreatment" class="form-control" placeholder="Default treatment">
+            </div>
+            <div class="form-group">
+              <input type="number" id="overrideTTL" class="form-control" min="0" placeholder="TTL in seconds (optional)">
+            </div>
+            <button type="submit" class="btn btn-primary">Apply</button>
+            <div class="form-group" style="width:100%;margin-top:10px">
+              <textarea id="overrideDefinition" class="form-control" style="width:100%" rows="6"
+                placeholder="Split definition as JSON (only for pinned overrides)"></textarea>
+            </div>
+          </form>
+          <p id="override_error" class="text-danger"></p>
+        </div>
+      </div>
+    </div>
+
+    <div class="row">
+      <div class="col-md-12">
+        <div class="bg-primary metricBox">
+          <table id="override_rows" class="table table-condensed table-hover">
+            <thead>
+              <tr>
+                <th>Split</th>
+                <th>Type</th>
+                <th>Default Treatment</th>
+                <th>Base Change Number</th>
+                <th>Change Number</th>
+                <th>Expires</th>
+                <th>&nbsp;</th>
+              </tr>
+            </thead>
+            <tbody>
+            </tbody>
+          </table>
+        </div>
+      </div>
+    </div>
+
+    <div class="alert alert-info" role="alert">
+      <h3 class="alert-heading">Local overrides</h3>
+      <p>Overrides modify the splits served by this proxy without changing them in Split. They are kept until they expire, are removed, or a newer version of the split is received from Split servers.</p>
+    </div>
+  </div>
+{{end}}
+`
@@ -3,7 +3,34 @@ package dashboard
 const queueManager = `
 {{define "QueueManager"}}
   <div role="tabpanel" class="tab-pane" id="queue-manager">
-  
+
+    <div class="row">
+      <div class="col-md-12">
+        <div class="gray1Box metricBox">
+          <h4>Queues</h4>
+          <table id="queues_rows" class="table table-condensed">
+            <thead>
+              <tr>
+                <th>Queue</th>
+                <th>{{if .ProxyMode}}Staged bulks{{else}}Items{{end}}</th>
+                <th>Actions</th>
+              </tr>
+            </thead>
+            <tbody>
+            </tbody>
+          </table>
+          <div class="checkbox">
+            <label><input type="checkbox" id="queue_peek_redact" checked> Redact keys when peeking</label>
+          </div>
+          <div id="queue_action_result"></div>
+          <pre id="queue_peek_items" style="display: none; max-height: 400px; overflow: auto;"></pre>
+        </div>
+      </div>
+    </div>
+    {{if not .ProxyMode}}
+
+    <div id="queue_cap_warnings"></div>
+
     <div class="row">
       <div class="col-md-6">
         <div class="gray1Box metricBox">
@@ -33,6 +60,55 @@ const queueManager = `
         </div>
       </div>
     </div>
+
+    <div class="row">
+      <div class="col-md-12">
+        <div class="gray1Box metricBox">
+          <h4>Pipelines</h4>
+          <table id="pipelines_rows" class="table table-condensed">
+            <thead>
+              <tr>
+                <th>Pipeline</th>
+                <th>Fetch size</th>
+                <th>Processing threads</th>
+                <th>Batch size</th>
+                <th>Post threads</th>
+                <th>Auto-tuning</th>
+              </tr>
+            </thead>
+            <tbody>
+            </tbody>
+          </table>
+        </div>
+      </div>
+    </div>
+
+    <div class="row">
+      <div class="col-md-12">
+        <div class="gray1Box metricBox">
+          <h4>Pipeline stages</h4>
+          <table id="pipeline_metrics_rows" class="table table-condensed">
+            <thead>
+              <tr>
+                <th>Pipeline</th>
+                <th>Fetched</th>
+                <th>Processed</th>
+                <th>Posted</th>
+                <th>Requeued</th>
+                <th>Dropped</th>
+                <th>Retries</th>
+                <th>Bytes (raw / sent)</th>
+                <th>Input buffer</th>
+                <th>Pre-submit buffer</th>
+              </tr>
+            </thead>
+            <tbody>
+            </tbody>
+          </table>
+        </div>
+      </div>
+    </div>
+    <div id="pipeline_metrics_charts"></div>
     </br>
     </br>
     </br>
@@ -50,6 +126,7 @@ const queueManager = `
       </ul>
       <p>For further information you can visit <a href="https://help.split.io/hc/en-us/articles/360018343391-Split-Synchronizer-Runbook" class="alert-link">Split Synchronizer Runbook</a>.</p>
     </div>
+    {{end}}
   </div>
 {{end}}
 `
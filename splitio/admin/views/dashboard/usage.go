@@ -0,0 +1,44 @@
+package dashboard
+
+const flagUsage = `
+{{define "FlagUsage"}}
+  <div role="tabpanel" class="tab-pane" id="flag-usage">
+    <div class="row">
+      <div class="col-md-8">
+        <div class="bg-primary metricBox">
+          <h4>Impressions per flag</h4>
+          <table id="usage_rows" class="table table-condensed table-hover">
+            <thead>
+              <tr>
+                <th>Flag</th>
+                <th>Impressions</th>
+                <th>Treatments</th>
+                <th>SDKs</th>
+                <th>Last seen</th>
+              </tr>
+            </thead>
+            <tbody>
+            </tbody>
+          </table>
+        </div>
+      </div>
+      <div class="col-md-4">
+        <div class="bg-primary metricBox">
+          <h4>Stale flags</h4>
+          <p id="usage_stale_notice"></p>
+          <table id="usage_stale" class="table table-condensed">
+            <thead>
+              <tr>
+                <th>Flag</th>
+                <th>Last seen</th>
+              </tr>
+            </thead>
+            <tbody>
+            </tbody>
+          </table>
+        </div>
+      </div>
+    </div>
+  </div>
+{{end}}
+`
@@ -0,0 +1,124 @@
+package changefeed
+
+import (
+	"sync"
+	"time"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/webhooks"
+)
+
+// Message types, on top of the webhook event types (split updates & kills, segment updates)
+const (
+	MessageHealthChanged = "HEALTH_CHANGED"
+	MessageSyncError     = "SYNC_ERROR"
+)
+
+const subscriberQueueSize = 100
+
+// Message is an entry of the change feed. IDs are sequential & can be used to resume a subscription
+type Message struct {
+	ID        int64       `json:"id"`
+	Type      string      `json:"type"`
+	Timestamp int64       `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// Subscription receives the messages published after it was created. Subscribers that fall behind have
+// their channel closed, and are expected to resubscribe with the last ID received
+type Subscription struct {
+	messages chan Message
+	closed   bool
+}
+
+// Messages returns the channel where new messages are received
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Feed keeps the latest messages in a ring buffer & fans new ones out to subscribers
+type Feed struct {
+	buffer      []Message
+	count       int
+	lastID      int64
+	subscribers map[*Subscription]struct{}
+	mutex       sync.Mutex
+}
+
+// New constructs a feed that keeps up to `size` messages for resuming subscriptions
+func New(size int) *Feed {
+	if size < 1 {
+		size = 1
+	}
+	return &Feed{buffer: make([]Message, size), subscribers: make(map[*Subscription]struct{})}
+}
+
+// Publish appends a message to the feed & forwards it to every subscriber
+func (f *Feed) Publish(messageType string, data interface{}) Message {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.lastID++
+	message := Message{ID: f.lastID, Type: messageType, Timestamp: time.Now().UnixNano() / int64(time.Millisecond), Data: data}
+	f.buffer[int((f.lastID-1)%int64(len(f.buffer)))] = message
+	if f.count < len(f.buffer) {
+		f.count++
+	}
+
+	for subscription := range f.subscribers {
+		select {
+		case subscription.messages <- message:
+		default:
+			f.unsubscribe(subscription)
+		}
+	}
+	return message
+}
+
+// Notify publishes a webhook event, so that the feed can be plugged wherever webhook notifiers are used
+func (f *Feed) Notify(event webhooks.Event) error {
+	f.Publish(event.Type, event)
+	return nil
+}
+
+// Subscribe registers a new subscription. If lastID is non-negative, the buffered messages published after it are
+// returned as well. The returned flag is false when some of those messages were already evicted from the buffer
+func (f *Feed) Subscribe(lastID int64) (*Subscription, []Message, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	subscription := &Subscription{messages: make(chan Message, subscriberQueueSize)}
+	f.subscribers[subscription] = struct{}{}
+	if lastID < 0 || lastID >= f.lastID {
+		return subscription, nil, true
+	}
+
+	oldest := f.lastID - int64(f.count) + 1
+	complete := lastID >= oldest-1
+	if lastID < oldest {
+		lastID = oldest - 1
+	}
+
+	backlog := make([]Message, 0, f.lastID-lastID)
+	for id := lastID + 1; id <= f.lastID; id++ {
+		backlog = append(backlog, f.buffer[int((id-1)%int64(len(f.buffer)))])
+	}
+	return subscription, backlog, complete
+}
+
+// Unsubscribe stops forwarding messages to the subscription & closes its channel
+func (f *Feed) Unsubscribe(subscription *Subscription) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.unsubscribe(subscription)
+}
+
+func (f *Feed) unsubscribe(subscription *Subscription) {
+	if subscription.closed {
+		return
+	}
+	delete(f.subscribers, subscription)
+	subscription.closed = true
+	close(subscription.messages)
+}
+
+var _ webhooks.Notifier = (*Feed)(nil)
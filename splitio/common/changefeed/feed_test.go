@@ -0,0 +1,130 @@
+package changefeed
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/splitio/go-split-commons/v4/synchronizer/worker/split"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/webhooks"
+	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/application"
+	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/services"
+)
+
+func TestFeedResume(t *testing.T) {
+	feed := New(3)
+	for idx := 0; idx < 5; idx++ {
+		feed.Publish(MessageSyncError, idx)
+	}
+
+	sub, backlog, complete := feed.Subscribe(-1)
+	if len(backlog) != 0 || !complete {
+		t.Error("new subscriptions without a last id should not get a backlog. Got: ", backlog)
+	}
+	feed.Unsubscribe(sub)
+
+	sub, backlog, complete = feed.Subscribe(3)
+	if len(backlog) != 2 || backlog[0].ID != 4 || backlog[1].ID != 5 || backlog[1].Data != 4 || !complete {
+		t.Error("messages after the last id should be replayed. Got: ", backlog, complete)
+	}
+	feed.Unsubscribe(sub)
+
+	sub, backlog, complete = feed.Subscribe(1)
+	if len(backlog) != 3 || backlog[0].ID != 3 || complete {
+		t.Error("evicted messages should be reported as lost. Got: ", backlog, complete)
+	}
+
+	feed.Notify(webhooks.Event{Type: webhooks.EventSplitKilled, Names: []string{"split1"}})
+	if message := <-sub.Messages(); message.ID != 6 || message.Type != webhooks.EventSplitKilled {
+		t.Error("new messages should be forwarded to subscribers. Got: ", message)
+	}
+
+	feed.Unsubscribe(sub)
+	feed.Unsubscribe(sub)
+	if _, ok := <-sub.Messages(); ok {
+		t.Error("the channel should be closed after unsubscribing")
+	}
+}
+
+func TestFeedSlowSubscriber(t *testing.T) {
+	feed := New(10)
+	sub, _, _ := feed.Subscribe(-1)
+	for idx := 0; idx <= subscriberQueueSize; idx++ {
+		feed.Publish(MessageSyncError, idx)
+	}
+
+	received := 0
+	for range sub.Messages() {
+		received++
+	}
+	if received != subscriberQueueSize {
+		t.Error("a lagging subscriber should be dropped once its queue is full. Received: ", received)
+	}
+}
+
+type appMonitorMock struct {
+	application.MonitorIterface
+	status application.HealthDto
+}
+
+func (m *appMonitorMock) GetHealthStatus() application.HealthDto { return m.status }
+
+type servicesMonitorMock struct {
+	services.MonitorIterface
+	status services.HealthDto
+}
+
+func (m *servicesMonitorMock) GetHealthStatus() services.HealthDto { return m.status }
+
+func TestHealthWatcher(t *testing.T) {
+	feed := New(10)
+	app := &appMonitorMock{status: application.HealthDto{Healthy: true, Items: []application.ItemDto{{Name: "Splits", Healthy: true}}}}
+	svcs := &servicesMonitorMock{status: services.HealthDto{Items: []services.ItemDto{{Service: "https://sdk.split.io", Healthy: true}}}}
+	watcher := &healthWatcher{feed: feed, app: app, services: svcs}
+	sub, _, _ := feed.Subscribe(-1)
+
+	watcher.check()
+	watcher.check()
+	if len(sub.Messages()) != 0 {
+		t.Error("no transitions should be published while the health doesn't change")
+	}
+
+	app.status = application.HealthDto{Healthy: false, Items: []application.ItemDto{{Name: "Splits", Healthy: false}}}
+	watcher.check()
+	if len(sub.Messages()) != 2 {
+		t.Error("the application & splits transitions should be published. Got: ", len(sub.Messages()))
+	}
+	for idx := 0; idx < 2; idx++ {
+		message := <-sub.Messages()
+		if change, ok := message.Data.(HealthChange); !ok || message.Type != MessageHealthChanged || change.Healthy {
+			t.Error("wrong health transition: ", message)
+		}
+	}
+
+	svcs.status = services.HealthDto{Items: []services.ItemDto{{Service: "https://sdk.split.io", Healthy: false}}}
+	watcher.check()
+	if message := <-sub.Messages(); message.Data.(HealthChange).Component != "https://sdk.split.io" {
+		t.Error("service transitions should be published. Got: ", message)
+	}
+}
+
+type splitUpdaterMock struct {
+	split.Updater
+}
+
+func (m *splitUpdaterMock) SynchronizeSplits(till *int64) (*split.UpdateResult, error) {
+	return nil, errors.New("something")
+}
+
+func TestSyncErrors(t *testing.T) {
+	feed := New(10)
+	sub, _, _ := feed.Subscribe(-1)
+	if _, err := NewSplitUpdater(&splitUpdaterMock{}, feed).SynchronizeSplits(nil); err == nil {
+		t.Error("the error should be propagated")
+	}
+
+	message := <-sub.Messages()
+	if syncErr, ok := message.Data.(SyncError); !ok || message.Type != MessageSyncError || syncErr.Resource != ResourceSplits || syncErr.Error != "something" {
+		t.Error("the sync error should be published. Got: ", message)
+	}
+}
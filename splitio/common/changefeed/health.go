@@ -0,0 +1,69 @@
+package changefeed
+
+import (
+	"github.com/splitio/go-toolkit/v5/asynctask"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/application"
+	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/services"
+)
+
+// component name used for the overall application health
+const componentApplication = "application"
+
+// HealthChange is the payload of health transition messages
+type HealthChange struct {
+	Component string `json:"component"`
+	Healthy   bool   `json:"healthy"`
+}
+
+// healthWatcher compares the health reported by the monitors against the previous check
+type healthWatcher struct {
+	feed     *Feed
+	app      application.MonitorIterface
+	services services.MonitorIterface
+	previous map[string]bool
+}
+
+func (w *healthWatcher) check() {
+	current := make(map[string]bool)
+	if w.app != nil {
+		status := w.app.GetHealthStatus()
+		current[componentApplication] = status.Healthy
+		for _, item := range status.Items {
+			current[item.Name] = item.Healthy
+		}
+	}
+
+	if w.services != nil {
+		for _, item := range w.services.GetHealthStatus().Items {
+			current[item.Service] = item.Healthy
+		}
+	}
+
+	// the first check only sets the baseline
+	if w.previous != nil {
+		for component, healthy := range current {
+			if previous, ok := w.previous[component]; ok && previous != healthy {
+				w.feed.Publish(MessageHealthChanged, HealthChange{Component: component, Healthy: healthy})
+			}
+		}
+	}
+	w.previous = current
+}
+
+// NewHealthWatcherTask builds a task that periodically checks the health monitors & publishes transitions
+func NewHealthWatcherTask(
+	feed *Feed,
+	app application.MonitorIterface,
+	services services.MonitorIterface,
+	logger logging.LoggerInterface,
+	period int,
+) *asynctask.AsyncTask {
+	watcher := &healthWatcher{feed: feed, app: app, services: services}
+	doWork := func(l logging.LoggerInterface) error {
+		watcher.check()
+		return nil
+	}
+	return asynctask.NewAsyncTask("change-feed-health-watcher", doWork, period, nil, nil, logger)
+}
@@ -0,0 +1,71 @@
+package changefeed
+
+import (
+	"github.com/splitio/go-split-commons/v4/synchronizer/worker/segment"
+	"github.com/splitio/go-split-commons/v4/synchronizer/worker/split"
+)
+
+// resources reported in sync errors
+const (
+	ResourceSplits   = "splits"
+	ResourceSegments = "segments"
+)
+
+// SyncError is the payload of sync error messages
+type SyncError struct {
+	Resource string `json:"resource"`
+	Name     string `json:"name,omitempty"`
+	Error    string `json:"error"`
+}
+
+// SplitUpdater wraps a split updater & publishes failed syncs
+type SplitUpdater struct {
+	split.Updater
+	feed *Feed
+}
+
+// NewSplitUpdater constructs a new split updater wrapper
+func NewSplitUpdater(wrapped split.Updater, feed *Feed) *SplitUpdater {
+	return &SplitUpdater{Updater: wrapped, feed: feed}
+}
+
+// SynchronizeSplits forwards the call to the wrapped updater & publishes the error if any
+func (u *SplitUpdater) SynchronizeSplits(till *int64) (*split.UpdateResult, error) {
+	result, err := u.Updater.SynchronizeSplits(till)
+	if err != nil {
+		u.feed.Publish(MessageSyncError, SyncError{Resource: ResourceSplits, Error: err.Error()})
+	}
+	return result, err
+}
+
+// SegmentUpdater wraps a segment updater & publishes failed syncs
+type SegmentUpdater struct {
+	segment.Updater
+	feed *Feed
+}
+
+// NewSegmentUpdater constructs a new segment updater wrapper
+func NewSegmentUpdater(wrapped segment.Updater, feed *Feed) *SegmentUpdater {
+	return &SegmentUpdater{Updater: wrapped, feed: feed}
+}
+
+// SynchronizeSegment forwards the call to the wrapped updater & publishes the error if any
+func (u *SegmentUpdater) SynchronizeSegment(name string, till *int64) (*segment.UpdateResult, error) {
+	result, err := u.Updater.SynchronizeSegment(name, till)
+	if err != nil {
+		u.feed.Publish(MessageSyncError, SyncError{Resource: ResourceSegments, Name: name, Error: err.Error()})
+	}
+	return result, err
+}
+
+// SynchronizeSegments forwards the call to the wrapped updater & publishes the error if any
+func (u *SegmentUpdater) SynchronizeSegments() (map[string]segment.UpdateResult, error) {
+	results, err := u.Updater.SynchronizeSegments()
+	if err != nil {
+		u.feed.Publish(MessageSyncError, SyncError{Resource: ResourceSegments, Error: err.Error()})
+	}
+	return results, err
+}
+
+var _ split.Updater = (*SplitUpdater)(nil)
+var _ segment.Updater = (*SegmentUpdater)(nil)
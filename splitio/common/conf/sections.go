@@ -10,17 +10,25 @@ type Logging struct {
 
 // Admin configuration options
 type Admin struct {
-	Host     string `json:"host" s-cli:"admin-host" s-def:"0.0.0.0" s-desc:"Host where the admin server will listen"`
-	Port     int64  `json:"port" s-cli:"admin-port" s-def:"3010" s-desc:"Admin port where incoming connections will be accepted"`
-	Username string `json:"username" s-cli:"admin-username" s-def:"" s-desc:"HTTP basic auth username for admin endpoints"`
-	Password string `json:"password" s-cli:"admin-password" s-def:"" s-desc:"HTTP basic auth password for admin endpoints"`
-	SecureHC bool   `json:"secureChecks" s-cli:"admin-secure-hc" s-def:"false" s-desc:"Secure Healthcheck endpoints as well."`
+	Host                   string `json:"host" s-cli:"admin-host" s-def:"0.0.0.0" s-desc:"Host where the admin server will listen"`
+	Port                   int64  `json:"port" s-cli:"admin-port" s-def:"3010" s-desc:"Admin port where incoming connections will be accepted"`
+	Username               string `json:"username" s-cli:"admin-username" s-def:"" s-desc:"HTTP basic auth username for admin endpoints"`
+	Password               string `json:"password" s-cli:"admin-password" s-def:"" s-desc:"HTTP basic auth password for admin endpoints"`
+	SecureHC               bool   `json:"secureChecks" s-cli:"admin-secure-hc" s-def:"false" s-desc:"Secure Healthcheck endpoints as well."`
+	FlagHistorySize        int64  `json:"flagHistorySize" s-cli:"admin-flag-history-size" s-def:"1000" s-desc:"Max number of split changes kept in the history exposed by the admin API"`
+	EventsBuffer           int64  `json:"eventsBuffer" s-cli:"admin-events-buffer" s-def:"500" s-desc:"Max number of change feed events kept for resuming admin event streams"`
+	FlagUsageWindowMinutes int64  `json:"flagUsageWindowMinutes" s-cli:"admin-flag-usage-window-minutes" s-def:"60" s-desc:"Time window covered by the per-flag impression counters exposed by the admin API"`
+	StaleFlagWindowHours   int64  `json:"staleFlagWindowHours" s-cli:"admin-stale-flag-window-hours" s-def:"168" s-desc:"Flags with no impressions during this window are reported as stale"`
+	QueueMaxDrop           int64  `json:"queueMaxDrop" s-cli:"admin-queue-max-drop" s-def:"100000" s-desc:"Max number of items that can be dropped from a queue in a single admin action"`
+	QueuePeekLimit         int64  `json:"queuePeekLimit" s-cli:"admin-queue-peek-limit" s-def:"100" s-desc:"Max number of items returned when peeking into a queue from the admin API"`
 }
 
 // Integrations configuration options
 type Integrations struct {
 	ImpressionListener ImpressionListener `json:"impressionListener" s-nested:"true"`
 	Slack              Slack              `json:"slack" s-nested:"true"`
+	Webhook            Webhook            `json:"webhook" s-nested:"true"`
+	FileExport         FileExport         `json:"fileExport" s-nested:"true"`
 }
 
 // ImpressionListener configuration options
@@ -34,3 +42,28 @@ type Slack struct {
 	Webhook string `json:"webhook" s-cli:"slack-webhook" s-def:"" s-desc:"slack webhook to post log messages"`
 	Channel string `json:"channel" s-cli:"slack-channel" s-def:"" s-desc:"slack channel to post log messages"`
 }
+
+// Webhook configuration options
+type Webhook struct {
+	Endpoint     string   `json:"endpoint" s-cli:"webhook-endpoint" s-def:"" s-desc:"HTTP endpoint notified when splits are updated/killed or segments updated"`
+	Secret       string   `json:"secret" s-cli:"webhook-secret" s-def:"" s-desc:"secret used to sign webhook payloads with HMAC-SHA256 (unsigned if empty)"`
+	MaxRetries   int64    `json:"maxRetries" s-cli:"webhook-max-retries" s-def:"3" s-desc:"max number of retries for failed webhook posts"`
+	QueueSize    int64    `json:"queueSize" s-cli:"webhook-queue-size" s-def:"100" s-desc:"max number of webhook events to queue"`
+	Flags        []string `json:"flags" s-cli:"webhook-flags" s-def:"" s-desc:"only notify changes to these flags (comma-separated, all if empty)"`
+	TrafficTypes []string `json:"trafficTypes" s-cli:"webhook-traffic-types" s-def:"" s-desc:"only notify changes to flags of these traffic types (comma-separated, all if empty)"`
+}
+
+// FileExport configuration options
+type FileExport struct {
+	Directory        string `json:"directory" s-cli:"file-export-directory" s-def:"" s-desc:"Directory where impressions & events are exported as NDJSON files partitioned by hour & sdk (disabled if empty)"`
+	Compress         bool   `json:"compress" s-cli:"file-export-compress" s-def:"true" s-desc:"Gzip exported files"`
+	MaxFileSizeBytes int64  `json:"maxFileSizeBytes" s-cli:"file-export-max-file-size-bytes" s-def:"104857600" s-desc:"Size after which an exported file is rotated"`
+	MaxFileAgeMs     int64  `json:"maxFileAgeMs" s-cli:"file-export-max-file-age-ms" s-def:"900000" s-desc:"Time after which an exported file is rotated"`
+}
+
+// Snapshot configuration options
+type Snapshot struct {
+	Directory string `json:"directory" s-cli:"snapshot-directory" s-def:"" s-desc:"Directory where periodic snapshots are written (disabled if empty)"`
+	RateMs    int64  `json:"rateMs" s-cli:"snapshot-rate-ms" s-def:"3600000" s-desc:"How often to write a snapshot"`
+	MaxToKeep int64  `json:"maxToKeep" s-cli:"snapshot-max-to-keep" s-def:"5" s-desc:"How many snapshots to keep before removing the oldest one"`
+}
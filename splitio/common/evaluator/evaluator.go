@@ -0,0 +1,215 @@
+// Package evaluator implements split evaluation on top of the synchronizer & proxy storages,
+// mimicking the behaviour of split SDKs
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+// Control is the treatment returned when a split cannot be evaluated
+const Control = "control"
+
+// Labels attached to evaluation results (and impressions)
+const (
+	LabelKilled             = "killed"
+	LabelDefaultRule        = "default rule"
+	LabelNotInSplit         = "not in split"
+	LabelDefinitionNotFound = "definition not found"
+	LabelException          = "exception"
+	LabelUnsupportedMatcher = "targeting rule type unsupported by sdk"
+)
+
+const conditionTypeRollout = "ROLLOUT"
+
+// SplitSource is used to fetch split definitions
+type SplitSource interface {
+	Split(name string) *dtos.SplitDTO
+}
+
+// SegmentSource is used to check segment membership
+type SegmentSource interface {
+	SegmentContainsKey(segmentName string, key string) (bool, error)
+}
+
+// Key identifies the entity for which a split is evaluated
+type Key struct {
+	MatchingKey  string `json:"matchingKey"`
+	BucketingKey string `json:"bucketingKey,omitempty"`
+}
+
+func (k *Key) bucketingKey() string {
+	if k.BucketingKey != "" {
+		return k.BucketingKey
+	}
+	return k.MatchingKey
+}
+
+// Result is the outcome of evaluating a split for a key
+type Result struct {
+	Treatment    string  `json:"treatment"`
+	Label        string  `json:"label"`
+	ChangeNumber int64   `json:"changeNumber"`
+	Config       *string `json:"config"`
+}
+
+// Evaluator computes treatments for keys using the split & segment data available locally
+type Evaluator struct {
+	splits   SplitSource
+	segments SegmentSource
+	logger   logging.LoggerInterface
+}
+
+// NewEvaluator constructs a new evaluator
+func NewEvaluator(splits SplitSource, segments SegmentSource, logger logging.LoggerInterface) *Evaluator {
+	return &Evaluator{splits: splits, segments: segments, logger: logger}
+}
+
+// Evaluate returns the treatment for a key & split
+func (e *Evaluator) Evaluate(key Key, split string, attributes map[string]interface{}) *Result {
+	return e.evaluate(&evaluation{key: key, attributes: attributes}, split)
+}
+
+// EvaluateMany returns the treatments for a key & a list of splits
+func (e *Evaluator) EvaluateMany(key Key, splits []string, attributes map[string]interface{}) map[string]*Result {
+	results := make(map[string]*Result, len(splits))
+	for _, split := range splits {
+		results[split] = e.Evaluate(key, split, attributes)
+	}
+	return results
+}
+
+// Explain evaluates a split for a key (just like Evaluate does) and keeps track of the steps taken to compute the treatment
+func (e *Evaluator) Explain(key Key, split string, attributes map[string]interface{}) *Explanation {
+	explanation := &Explanation{
+		Split:         split,
+		Key:           key,
+		Conditions:    []ConditionTrace{},
+		SegmentChecks: []SegmentCheck{},
+		Dependencies:  []DependencyCheck{},
+	}
+	explanation.Result = *e.evaluate(&evaluation{key: key, attributes: attributes, trace: explanation}, split)
+	return explanation
+}
+
+// evaluation holds the state of an ongoing evaluation
+type evaluation struct {
+	key        Key
+	attributes map[string]interface{}
+	depth      int
+	trace      *Explanation
+}
+
+// tracingTopLevel returns true if the evaluation is being explained and it's not a nested one (dependency matchers)
+func (c *evaluation) tracingTopLevel() bool {
+	return c.trace != nil && c.depth == 0
+}
+
+func (e *Evaluator) evaluate(ctx *evaluation, splitName string) *Result {
+	split := e.splits.Split(splitName)
+	if split == nil {
+		return &Result{Treatment: Control, Label: LabelDefinitionNotFound, ChangeNumber: -1}
+	}
+
+	if ctx.tracingTopLevel() {
+		ctx.trace.Killed = split.Killed
+		ctx.trace.TrafficAllocation = split.TrafficAllocation
+	}
+
+	if split.Killed {
+		return e.withConfig(split, split.DefaultTreatment, LabelKilled)
+	}
+
+	treatment, label, err := e.doEvaluation(ctx, split)
+	if err != nil {
+		e.logger.Error(fmt.Sprintf("error evaluating split '%s': %s", splitName, err))
+		if errors.Is(err, errUnsupportedMatcher) {
+			return &Result{Treatment: Control, Label: LabelUnsupportedMatcher, ChangeNumber: split.ChangeNumber}
+		}
+		return &Result{Treatment: Control, Label: LabelException, ChangeNumber: split.ChangeNumber}
+	}
+
+	return e.withConfig(split, treatment, label)
+}
+
+func (e *Evaluator) doEvaluation(ctx *evaluation, split *dtos.SplitDTO) (string, string, error) {
+	inRollout := false
+	for idx := range split.Conditions {
+		condition := &split.Conditions[idx]
+		if !inRollout && condition.ConditionType == conditionTypeRollout {
+			if split.TrafficAllocation < 100 {
+				trafficBucket := bucket(split.Algo, ctx.key.bucketingKey(), split.TrafficAllocationSeed)
+				if ctx.tracingTopLevel() {
+					ctx.trace.TrafficAllocationBucket = &trafficBucket
+				}
+				if trafficBucket > split.TrafficAllocation {
+					return split.DefaultTreatment, LabelNotInSplit, nil
+				}
+			}
+			inRollout = true
+		}
+
+		matched, err := e.conditionMatches(ctx, condition)
+		if err != nil {
+			return "", "", err
+		}
+
+		if ctx.tracingTopLevel() {
+			ctx.trace.Conditions = append(ctx.trace.Conditions, ConditionTrace{
+				Index:         idx,
+				Label:         condition.Label,
+				ConditionType: condition.ConditionType,
+				Matched:       matched,
+			})
+		}
+
+		if matched {
+			conditionBucket := bucket(split.Algo, ctx.key.bucketingKey(), split.Seed)
+			if ctx.tracingTopLevel() {
+				ctx.trace.Bucket = &conditionBucket
+				matchedCondition := ctx.trace.Conditions[len(ctx.trace.Conditions)-1]
+				ctx.trace.MatchedCondition = &matchedCondition
+			}
+			return treatmentFor(condition.Partitions, conditionBucket), condition.Label, nil
+		}
+	}
+
+	return split.DefaultTreatment, LabelDefaultRule, nil
+}
+
+// conditionMatches returns true if all the matchers in the condition match (AND is the only supported combiner)
+func (e *Evaluator) conditionMatches(ctx *evaluation, condition *dtos.ConditionDTO) (bool, error) {
+	for idx := range condition.MatcherGroup.Matchers {
+		matched, err := e.matches(ctx, &condition.MatcherGroup.Matchers[idx])
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (e *Evaluator) withConfig(split *dtos.SplitDTO, treatment string, label string) *Result {
+	result := &Result{Treatment: treatment, Label: label, ChangeNumber: split.ChangeNumber}
+	if config, ok := split.Configurations[treatment]; ok {
+		result.Config = &config
+	}
+	return result
+}
+
+func treatmentFor(partitions []dtos.PartitionDTO, bucket int) string {
+	if len(partitions) == 1 && partitions[0].Size == 100 {
+		return partitions[0].Treatment
+	}
+
+	accumulated := 0
+	for _, partition := range partitions {
+		accumulated += partition.Size
+		if bucket <= accumulated {
+			return partition.Treatment
+		}
+	}
+	return Control
+}
@@ -0,0 +1,256 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/storage/inmemory/mutexmap"
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+func strPtr(s string) *string { return &s }
+
+func onCondition(label string, matchers ...dtos.MatcherDTO) dtos.ConditionDTO {
+	return dtos.ConditionDTO{
+		ConditionType: "WHITELIST",
+		Label:         label,
+		MatcherGroup:  dtos.MatcherGroupDTO{Combiner: "AND", Matchers: matchers},
+		Partitions:    []dtos.PartitionDTO{{Treatment: "on", Size: 100}},
+	}
+}
+
+func TestMurmurBuckets(t *testing.T) {
+	samples := []struct {
+		seed   int64
+		key    string
+		bucket int
+	}{
+		{467569525, "aUfEsdPN1twuEjff9Sl", 52},
+		{467569525, "svjffePClyAC8TVKj", 43},
+		{467569525, "7nC66TUuPTt", 94},
+		{1382200474, "50ueEpD85Grkqx", 59},
+		{-450081880, "Y4w437dgNrVOG", 18},
+		{-450081880, "l4Q1Ga6a03LZO", 67},
+	}
+
+	for _, sample := range samples {
+		if b := bucket(algoMurmur, sample.key, sample.seed); b != sample.bucket {
+			t.Errorf("wrong bucket for key '%s'. Expected %d, got %d", sample.key, sample.bucket, b)
+		}
+	}
+}
+
+func TestLegacyBuckets(t *testing.T) {
+	samples := []struct {
+		seed   int64
+		key    string
+		bucket int
+	}{
+		{973529038, "oHDuTSey2rdeIP2P", 72},
+		{-1271841407, "9RZICEV7mvMA3UWizzMJoxEFIc", 21},
+		{-1319915559, "dw68EoGTS1WHM40LmVymzKBv", 4},
+		{708051602, "\uaa21\u1a68\u7185\u74eb\u62ef\u8c74\u9b3d\u3e6a\u34aa\u8c06\u2bb0\u2406\ufaec\uf6aaW\uaff2\u2a32\ufcb9", 52},
+		{-2100444868, "\u0ee9\u44c5\ua47a\u53ab\u0e94\u5463\u63c1\u41ed\ueed5\ua463\uf87e\uc8f6\u7d89\u78f6\ufe45\u4dd3\uef5c\u78c9", 7},
+		{583278361, "\u7146\uc198\u72d4\u8e0a\u4d40\ub924\u0150\u022b\u9d7a\u711b\U0001c74d\u1cc4\uc670\u326b\u0085", 40},
+		{390595662, "\U00014994\U000d8004\u36eb\U00015b9c\u6666\uaf6e\u56f3\u0303\u186b\ue527\u0a66\u65a5\U0001c137\uff1c", 86},
+	}
+
+	for _, sample := range samples {
+		if b := bucket(algoLegacy, sample.key, sample.seed); b != sample.bucket {
+			t.Errorf("wrong bucket for key '%s'. Expected %d, got %d", sample.key, sample.bucket, b)
+		}
+	}
+}
+
+func TestEvaluator(t *testing.T) {
+	splits := mutexmap.NewMMSplitStorage()
+	segments := mutexmap.NewMMSegmentStorage()
+	segments.Update("employees", set.NewSet("employee1"), set.NewSet(), 1)
+
+	splits.Update([]dtos.SplitDTO{
+		{
+			Name:              "targeted",
+			Status:            "ACTIVE",
+			ChangeNumber:      10,
+			DefaultTreatment:  "off",
+			TrafficAllocation: 100,
+			Algo:              algoMurmur,
+			Conditions: []dtos.ConditionDTO{
+				onCondition("whitelisted", dtos.MatcherDTO{MatcherType: "WHITELIST", Whitelist: &dtos.WhitelistMatcherDataDTO{Whitelist: []string{"key1"}}}),
+				onCondition("in segment employees", dtos.MatcherDTO{MatcherType: "IN_SEGMENT",
+					UserDefinedSegment: &dtos.UserDefinedSegmentMatcherDataDTO{SegmentName: "employees"}}),
+				onCondition("age >= 18 and plan in set", dtos.MatcherDTO{
+					MatcherType:  "GREATER_THAN_OR_EQUAL_TO",
+					KeySelector:  &dtos.KeySelectorDTO{Attribute: strPtr("age")},
+					UnaryNumeric: &dtos.UnaryNumericMatcherDataDTO{DataType: "NUMBER", Value: 18},
+				}, dtos.MatcherDTO{
+					MatcherType: "CONTAINS_ANY_OF_SET",
+					KeySelector: &dtos.KeySelectorDTO{Attribute: strPtr("plans")},
+					Whitelist:   &dtos.WhitelistMatcherDataDTO{Whitelist: []string{"gold", "platinum"}},
+				}),
+				onCondition("not registered", dtos.MatcherDTO{
+					MatcherType: "EQUAL_TO_BOOLEAN",
+					Negate:      true,
+					KeySelector: &dtos.KeySelectorDTO{Attribute: strPtr("registered")},
+					Boolean:     func() *bool { b := true; return &b }(),
+				}),
+			},
+			Configurations: map[string]string{"on": `{"color":"blue"}`},
+		},
+		{
+			Name:             "killed",
+			Status:           "ACTIVE",
+			ChangeNumber:     20,
+			Killed:           true,
+			DefaultTreatment: "off",
+		},
+		{
+			Name:              "no_traffic",
+			Status:            "ACTIVE",
+			DefaultTreatment:  "off",
+			TrafficAllocation: 0,
+			Conditions: []dtos.ConditionDTO{{
+				ConditionType: "ROLLOUT",
+				MatcherGroup:  dtos.MatcherGroupDTO{Combiner: "AND", Matchers: []dtos.MatcherDTO{{MatcherType: "ALL_KEYS"}}},
+				Partitions:    []dtos.PartitionDTO{{Treatment: "on", Size: 100}},
+			}},
+		},
+		{
+			Name:              "dependent",
+			Status:            "ACTIVE",
+			DefaultTreatment:  "off",
+			TrafficAllocation: 100,
+			Conditions: []dtos.ConditionDTO{onCondition("depends on targeted", dtos.MatcherDTO{
+				MatcherType: "IN_SPLIT_TREATMENT",
+				Dependency:  &dtos.DependencyMatcherDataDTO{Split: "targeted", Treatments: []string{"on"}},
+			})},
+		},
+		{
+			Name:              "unsupported",
+			Status:            "ACTIVE",
+			DefaultTreatment:  "off",
+			TrafficAllocation: 100,
+			Conditions:        []dtos.ConditionDTO{onCondition("unknown", dtos.MatcherDTO{MatcherType: "SOME_NEW_MATCHER"})},
+		},
+	}, nil, 20)
+
+	evaluator := NewEvaluator(splits, segments, logging.NewLogger(nil))
+	registered := map[string]interface{}{"registered": true}
+
+	expectations := []struct {
+		key        Key
+		split      string
+		attributes map[string]interface{}
+		treatment  string
+		label      string
+	}{
+		{Key{MatchingKey: "key1"}, "targeted", registered, "on", "whitelisted"},
+		{Key{MatchingKey: "employee1", BucketingKey: "b1"}, "targeted", registered, "on", "in segment employees"},
+		{Key{MatchingKey: "key2"}, "targeted", map[string]interface{}{"age": float64(21), "plans": []interface{}{"gold"}, "registered": true}, "on", "age >= 18 and plan in set"},
+		{Key{MatchingKey: "key2"}, "targeted", map[string]interface{}{"age": 17, "plans": []string{"gold"}, "registered": "TRUE"}, "off", LabelDefaultRule},
+		{Key{MatchingKey: "key2"}, "targeted", nil, "on", "not registered"},
+		{Key{MatchingKey: "key1"}, "killed", nil, "off", LabelKilled},
+		{Key{MatchingKey: "key1"}, "no_traffic", nil, "off", LabelNotInSplit},
+		{Key{MatchingKey: "key1"}, "dependent", registered, "on", "depends on targeted"},
+		{Key{MatchingKey: "key2"}, "dependent", registered, "off", LabelDefaultRule},
+		{Key{MatchingKey: "key1"}, "unsupported", nil, Control, LabelUnsupportedMatcher},
+		{Key{MatchingKey: "key1"}, "nonexistent", nil, Control, LabelDefinitionNotFound},
+	}
+
+	for _, exp := range expectations {
+		result := evaluator.Evaluate(exp.key, exp.split, exp.attributes)
+		if result.Treatment != exp.treatment || result.Label != exp.label {
+			t.Errorf("wrong result for key '%s' in split '%s': %+v", exp.key.MatchingKey, exp.split, result)
+		}
+	}
+
+	results := evaluator.EvaluateMany(Key{MatchingKey: "key1"}, []string{"targeted", "killed"}, registered)
+	if len(results) != 2 || results["targeted"].Config == nil || *results["targeted"].Config != `{"color":"blue"}` {
+		t.Error("config should be attached to the treatment. Got: ", results)
+	}
+
+	if results["killed"].Config != nil || results["killed"].ChangeNumber != 20 {
+		t.Error("wrong result for killed split: ", results["killed"])
+	}
+}
+
+func TestDatetimeMatchers(t *testing.T) {
+	const day = int64(24 * 60 * 60 * 1000)
+	reference := 100 * day
+	if !compareNumeric(matcherEqualTo, dataTypeDatetime, reference+5000, reference) {
+		t.Error("datetimes within the same day should be considered equal")
+	}
+
+	if compareNumeric(matcherGreaterThanOrEq, dataTypeDatetime, reference-30000, reference+1000) {
+		t.Error("datetimes should be compared with minute precision")
+	}
+
+	if !compareNumeric(matcherLessThanOrEq, dataTypeDatetime, reference+59000, reference) {
+		t.Error("seconds should be truncated")
+	}
+}
+
+func TestExplain(t *testing.T) {
+	splits := mutexmap.NewMMSplitStorage()
+	segments := mutexmap.NewMMSegmentStorage()
+	segments.Update("employees", set.NewSet("employee1"), set.NewSet(), 1)
+	splits.Update([]dtos.SplitDTO{
+		{
+			Name:                  "rollout",
+			Status:                "ACTIVE",
+			ChangeNumber:          10,
+			DefaultTreatment:      "off",
+			TrafficAllocation:     99,
+			TrafficAllocationSeed: 467569525,
+			Seed:                  467569525,
+			Algo:                  algoMurmur,
+			Conditions: []dtos.ConditionDTO{
+				onCondition("in segment employees", dtos.MatcherDTO{MatcherType: "IN_SEGMENT",
+					UserDefinedSegment: &dtos.UserDefinedSegmentMatcherDataDTO{SegmentName: "employees"}}),
+				{
+					ConditionType: "ROLLOUT",
+					Label:         "default rule",
+					MatcherGroup: dtos.MatcherGroupDTO{Combiner: "AND", Matchers: []dtos.MatcherDTO{{
+						MatcherType: "IN_SPLIT_TREATMENT",
+						Dependency:  &dtos.DependencyMatcherDataDTO{Split: "base", Treatments: []string{"on"}},
+					}}},
+					Partitions: []dtos.PartitionDTO{{Treatment: "on", Size: 50}, {Treatment: "off", Size: 50}},
+				},
+			},
+		},
+		{
+			Name:              "base",
+			Status:            "ACTIVE",
+			DefaultTreatment:  "off",
+			TrafficAllocation: 100,
+			Conditions:        []dtos.ConditionDTO{onCondition("all keys", dtos.MatcherDTO{MatcherType: "ALL_KEYS"})},
+		},
+	}, nil, 10)
+
+	evaluator := NewEvaluator(splits, segments, logging.NewLogger(nil))
+	explanation := evaluator.Explain(Key{MatchingKey: "7nC66TUuPTt"}, "rollout", nil)
+	if explanation.Treatment != "off" || explanation.Label != "default rule" || explanation.TrafficAllocation != 99 {
+		t.Error("wrong result: ", explanation.Result, explanation.TrafficAllocation)
+	}
+
+	if explanation.TrafficAllocationBucket == nil || *explanation.TrafficAllocationBucket != 94 || explanation.Bucket == nil || *explanation.Bucket != 94 {
+		t.Error("buckets should be recorded. Got: ", explanation.TrafficAllocationBucket, explanation.Bucket)
+	}
+
+	if len(explanation.Conditions) != 2 || explanation.Conditions[0].Matched || explanation.MatchedCondition == nil || explanation.MatchedCondition.Index != 1 {
+		t.Error("wrong condition trace: ", explanation.Conditions, explanation.MatchedCondition)
+	}
+
+	if len(explanation.SegmentChecks) != 1 || explanation.SegmentChecks[0].Segment != "employees" || explanation.SegmentChecks[0].Member {
+		t.Error("wrong segment checks: ", explanation.SegmentChecks)
+	}
+
+	if len(explanation.Dependencies) != 1 || explanation.Dependencies[0].Split != "base" || !explanation.Dependencies[0].Matched {
+		t.Error("wrong dependency checks: ", explanation.Dependencies)
+	}
+
+	if result := evaluator.Evaluate(Key{MatchingKey: "7nC66TUuPTt"}, "rollout", nil); *result != explanation.Result {
+		t.Error("explanations should not alter the result. Got: ", result)
+	}
+}
@@ -0,0 +1,41 @@
+package evaluator
+
+// Explanation contains the result of an evaluation along with the information used to compute it
+type Explanation struct {
+	Result
+	Split                   string            `json:"split"`
+	Key                     Key               `json:"key"`
+	Killed                  bool              `json:"killed"`
+	TrafficAllocation       int               `json:"trafficAllocation"`
+	TrafficAllocationBucket *int              `json:"trafficAllocationBucket,omitempty"`
+	Bucket                  *int              `json:"bucket,omitempty"`
+	MatchedCondition        *ConditionTrace   `json:"matchedCondition,omitempty"`
+	Conditions              []ConditionTrace  `json:"conditions"`
+	SegmentChecks           []SegmentCheck    `json:"segmentChecks"`
+	Dependencies            []DependencyCheck `json:"dependencies"`
+}
+
+// ConditionTrace describes a condition evaluated for the requested split
+type ConditionTrace struct {
+	Index         int    `json:"index"`
+	Label         string `json:"label"`
+	ConditionType string `json:"conditionType"`
+	Matched       bool   `json:"matched"`
+}
+
+// SegmentCheck describes a segment membership lookup performed during the evaluation
+type SegmentCheck struct {
+	Segment string `json:"segment"`
+	Key     string `json:"key"`
+	Member  bool   `json:"member"`
+	Error   string `json:"error,omitempty"`
+}
+
+// DependencyCheck describes the evaluation of a split the requested one depends on
+type DependencyCheck struct {
+	Split     string   `json:"split"`
+	Treatment string   `json:"treatment"`
+	Label     string   `json:"label"`
+	Expected  []string `json:"expected"`
+	Matched   bool     `json:"matched"`
+}
@@ -0,0 +1,32 @@
+package evaluator
+
+import (
+	"unicode/utf16"
+
+	"github.com/splitio/go-toolkit/v5/hasher"
+)
+
+// Hashing algorithms used to bucket keys, as referenced by the `algo` property of splits
+const (
+	algoLegacy = 1
+	algoMurmur = 2
+)
+
+// bucket returns a number in the [1, 100] range for a key & seed, using the algorithm specified by the split
+func bucket(algo int, key string, seed int64) int {
+	if algo == algoMurmur {
+		return int(hasher.Sum32WithSeed([]byte(key), uint32(seed))%100) + 1
+	}
+
+	// legacy hashing mimics java's String.hashCode(): utf-16 code units & signed 32 bits overflow
+	var h int32
+	for _, c := range utf16.Encode([]rune(key)) {
+		h = 31*h + int32(c)
+	}
+	h ^= int32(seed)
+	b := h % 100
+	if b < 0 {
+		b = -b
+	}
+	return int(b) + 1
+}
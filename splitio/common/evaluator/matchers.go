@@ -0,0 +1,292 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+)
+
+// Matcher types
+const (
+	matcherAllKeys          = "ALL_KEYS"
+	matcherInSegment        = "IN_SEGMENT"
+	matcherWhitelist        = "WHITELIST"
+	matcherEqualTo          = "EQUAL_TO"
+	matcherGreaterThanOrEq  = "GREATER_THAN_OR_EQUAL_TO"
+	matcherLessThanOrEq     = "LESS_THAN_OR_EQUAL_TO"
+	matcherBetween          = "BETWEEN"
+	matcherEqualToSet       = "EQUAL_TO_SET"
+	matcherPartOfSet        = "PART_OF_SET"
+	matcherContainsAllOfSet = "CONTAINS_ALL_OF_SET"
+	matcherContainsAnyOfSet = "CONTAINS_ANY_OF_SET"
+	matcherStartsWith       = "STARTS_WITH"
+	matcherEndsWith         = "ENDS_WITH"
+	matcherContainsString   = "CONTAINS_STRING"
+	matcherMatchesString    = "MATCHES_STRING"
+	matcherEqualToBoolean   = "EQUAL_TO_BOOLEAN"
+	matcherInSplitTreatment = "IN_SPLIT_TREATMENT"
+)
+
+const (
+	dataTypeDatetime = "DATETIME"
+
+	// upper bound for nested IN_SPLIT_TREATMENT evaluations, to prevent cycles from hanging the evaluator
+	maxDependencyEvaluations = 10
+)
+
+var errUnsupportedMatcher = errors.New("unsupported matcher")
+
+func (e *Evaluator) matches(ctx *evaluation, matcher *dtos.MatcherDTO) (bool, error) {
+	value, ok := matchingValue(ctx, matcher)
+	if !ok {
+		// the attribute required by the matcher has not been supplied
+		return matcher.Negate, nil
+	}
+
+	result, err := e.match(ctx, matcher, value)
+	if err != nil {
+		return false, err
+	}
+	return result != matcher.Negate, nil
+}
+
+func (e *Evaluator) match(ctx *evaluation, matcher *dtos.MatcherDTO, value interface{}) (bool, error) {
+	switch matcher.MatcherType {
+	case matcherAllKeys:
+		return true, nil
+	case matcherInSegment:
+		key, ok := value.(string)
+		if !ok || matcher.UserDefinedSegment == nil {
+			return false, nil
+		}
+		contained, err := e.segments.SegmentContainsKey(matcher.UserDefinedSegment.SegmentName, key)
+		if ctx.trace != nil {
+			check := SegmentCheck{Segment: matcher.UserDefinedSegment.SegmentName, Key: key, Member: contained}
+			if err != nil {
+				check.Error = err.Error()
+			}
+			ctx.trace.SegmentChecks = append(ctx.trace.SegmentChecks, check)
+		}
+		if err != nil {
+			return false, fmt.Errorf("error checking membership for segment '%s': %w", matcher.UserDefinedSegment.SegmentName, err)
+		}
+		return contained, nil
+	case matcherWhitelist:
+		str, ok := value.(string)
+		return ok && matcher.Whitelist != nil && contains(matcher.Whitelist.Whitelist, str), nil
+	case matcherEqualTo, matcherGreaterThanOrEq, matcherLessThanOrEq:
+		if matcher.UnaryNumeric == nil {
+			return false, nil
+		}
+		return compareNumeric(matcher.MatcherType, matcher.UnaryNumeric.DataType, value, matcher.UnaryNumeric.Value), nil
+	case matcherBetween:
+		if matcher.Between == nil {
+			return false, nil
+		}
+		return compareNumeric(matcherGreaterThanOrEq, matcher.Between.DataType, value, matcher.Between.Start) &&
+			compareNumeric(matcherLessThanOrEq, matcher.Between.DataType, value, matcher.Between.End), nil
+	case matcherEqualToSet, matcherPartOfSet, matcherContainsAllOfSet, matcherContainsAnyOfSet:
+		items, ok := toStringSet(value)
+		if !ok || matcher.Whitelist == nil {
+			return false, nil
+		}
+		return compareSets(matcher.MatcherType, items, matcher.Whitelist.Whitelist), nil
+	case matcherStartsWith, matcherEndsWith, matcherContainsString:
+		str, ok := value.(string)
+		if !ok || matcher.Whitelist == nil {
+			return false, nil
+		}
+		return matchString(matcher.MatcherType, str, matcher.Whitelist.Whitelist), nil
+	case matcherMatchesString:
+		str, ok := value.(string)
+		if !ok || matcher.String == nil {
+			return false, nil
+		}
+		re, err := regexp.Compile(*matcher.String)
+		if err != nil {
+			return false, nil
+		}
+		return re.MatchString(str), nil
+	case matcherEqualToBoolean:
+		asBool, ok := toBool(value)
+		return ok && matcher.Boolean != nil && asBool == *matcher.Boolean, nil
+	case matcherInSplitTreatment:
+		if matcher.Dependency == nil {
+			return false, nil
+		}
+		if ctx.depth >= maxDependencyEvaluations {
+			return false, fmt.Errorf("too many nested dependencies evaluating '%s'", matcher.Dependency.Split)
+		}
+		result := e.evaluate(&evaluation{key: ctx.key, attributes: ctx.attributes, depth: ctx.depth + 1, trace: ctx.trace}, matcher.Dependency.Split)
+		matched := contains(matcher.Dependency.Treatments, result.Treatment)
+		if ctx.trace != nil {
+			ctx.trace.Dependencies = append(ctx.trace.Dependencies, DependencyCheck{
+				Split:     matcher.Dependency.Split,
+				Treatment: result.Treatment,
+				Label:     result.Label,
+				Expected:  matcher.Dependency.Treatments,
+				Matched:   matched,
+			})
+		}
+		return matched, nil
+	}
+	return false, fmt.Errorf("%w: %s", errUnsupportedMatcher, matcher.MatcherType)
+}
+
+// matchingValue returns the key, or the attribute referenced by the matcher if any
+func matchingValue(ctx *evaluation, matcher *dtos.MatcherDTO) (interface{}, bool) {
+	if matcher.MatcherType == matcherAllKeys || matcher.MatcherType == matcherInSplitTreatment {
+		return nil, true
+	}
+
+	if matcher.KeySelector == nil || matcher.KeySelector.Attribute == nil {
+		return ctx.key.MatchingKey, true
+	}
+
+	value, ok := ctx.attributes[*matcher.KeySelector.Attribute]
+	return value, ok && value != nil
+}
+
+func compareNumeric(matcherType string, dataType string, value interface{}, reference int64) bool {
+	asInt, ok := toInt64(value)
+	if !ok {
+		return false
+	}
+
+	if dataType == dataTypeDatetime {
+		truncateTo := int64(time.Minute / time.Millisecond)
+		if matcherType == matcherEqualTo {
+			truncateTo = int64(24 * time.Hour / time.Millisecond)
+		}
+		asInt = asInt - asInt%truncateTo
+		reference = reference - reference%truncateTo
+	}
+
+	switch matcherType {
+	case matcherEqualTo:
+		return asInt == reference
+	case matcherGreaterThanOrEq:
+		return asInt >= reference
+	case matcherLessThanOrEq:
+		return asInt <= reference
+	}
+	return false
+}
+
+func compareSets(matcherType string, items map[string]struct{}, reference []string) bool {
+	switch matcherType {
+	case matcherEqualToSet:
+		if len(items) != len(toSet(reference)) {
+			return false
+		}
+		return containsAll(items, reference)
+	case matcherPartOfSet:
+		if len(items) == 0 {
+			return false
+		}
+		referenceSet := toSet(reference)
+		for item := range items {
+			if _, ok := referenceSet[item]; !ok {
+				return false
+			}
+		}
+		return true
+	case matcherContainsAllOfSet:
+		return len(reference) > 0 && containsAll(items, reference)
+	case matcherContainsAnyOfSet:
+		for _, item := range reference {
+			if _, ok := items[item]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchString(matcherType string, value string, reference []string) bool {
+	for _, item := range reference {
+		switch {
+		case matcherType == matcherStartsWith && strings.HasPrefix(value, item),
+			matcherType == matcherEndsWith && strings.HasSuffix(value, item),
+			matcherType == matcherContainsString && strings.Contains(value, item):
+			return true
+		}
+	}
+	return false
+}
+
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		return int64(v), true
+	case float32:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+func toBool(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		asBool, err := strconv.ParseBool(strings.ToLower(v))
+		return asBool, err == nil
+	}
+	return false, false
+}
+
+func toStringSet(value interface{}) (map[string]struct{}, bool) {
+	switch v := value.(type) {
+	case []string:
+		return toSet(v), true
+	case []interface{}:
+		items := make(map[string]struct{}, len(v))
+		for _, item := range v {
+			str, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			items[str] = struct{}{}
+		}
+		return items, true
+	}
+	return nil, false
+}
+
+func toSet(items []string) map[string]struct{} {
+	toReturn := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		toReturn[item] = struct{}{}
+	}
+	return toReturn
+}
+
+func containsAll(items map[string]struct{}, reference []string) bool {
+	for _, item := range reference {
+		if _, ok := items[item]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(items []string, item string) bool {
+	for _, current := range items {
+		if current == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,475 @@
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-toolkit/v5/asynctask"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/conf"
+)
+
+// Kinds of exported data, used as the top-level directory of each partition
+const (
+	KindImpressions = "impressions"
+	KindEvents      = "events"
+)
+
+const (
+	// ManifestName is the file (relative to the export directory) where an entry is appended for every completed file
+	ManifestName = "manifest.ndjson"
+
+	// DefaultRotationPeriod is how often (in seconds) the rotation task looks for files to hand over
+	DefaultRotationPeriod = 30
+
+	defaultMaxFileSize = 100 * 1024 * 1024
+	defaultMaxFileAge  = 15 * time.Minute
+	hourLayout         = "2006-01-02T15"
+	unknownSDK         = "unknown"
+	fileSuffix         = ".ndjson"
+	gzipSuffix         = ".gz"
+	partialSuffix      = ".part"
+)
+
+// ErrClosed is returned when writing to a sink that has been closed
+var ErrClosed = errors.New("export sink closed")
+
+// Impression is the structure of each line written to an impressions file
+type Impression struct {
+	SDKVersion   string `json:"sdkVersion"`
+	MachineName  string `json:"machineName,omitempty"`
+	MachineIP    string `json:"machineIP,omitempty"`
+	Feature      string `json:"feature"`
+	KeyName      string `json:"keyName"`
+	BucketingKey string `json:"bucketingKey,omitempty"`
+	Treatment    string `json:"treatment"`
+	Label        string `json:"label"`
+	ChangeNumber int64  `json:"changeNumber"`
+	Time         int64  `json:"time"`
+	PreviousTime int64  `json:"previousTime,omitempty"`
+}
+
+// Event is the structure of each line written to an events file
+type Event struct {
+	SDKVersion      string                 `json:"sdkVersion"`
+	MachineName     string                 `json:"machineName,omitempty"`
+	MachineIP       string                 `json:"machineIP,omitempty"`
+	Key             string                 `json:"key"`
+	TrafficTypeName string                 `json:"trafficTypeName"`
+	EventTypeID     string                 `json:"eventTypeId"`
+	Value           interface{}            `json:"value"`
+	Timestamp       int64                  `json:"timestamp"`
+	Properties      map[string]interface{} `json:"properties,omitempty"`
+}
+
+// ManifestEntry describes a completed file, ready to be shipped
+type ManifestEntry struct {
+	Path       string `json:"path"` // relative to the export directory
+	Kind       string `json:"kind"`
+	Hour       string `json:"hour"`
+	SDK        string `json:"sdk"`
+	Records    int64  `json:"records"`
+	Bytes      int64  `json:"bytes"`
+	SHA256     string `json:"sha256"`
+	Compressed bool   `json:"compressed"`
+	OpenedAt   int64  `json:"openedAt"`
+	ClosedAt   int64  `json:"closedAt"`
+}
+
+// Config bundles the options used to build an export sink
+type Config struct {
+	Logger      logging.LoggerInterface
+	Directory   string
+	Compress    bool
+	MaxFileSize int64
+	MaxFileAge  time.Duration
+}
+
+func (c *Config) normalize() {
+	if c.MaxFileSize <= 0 {
+		c.MaxFileSize = defaultMaxFileSize
+	}
+
+	if c.MaxFileAge <= 0 {
+		c.MaxFileAge = defaultMaxFileAge
+	}
+}
+
+type partition struct {
+	kind string
+	hour string
+	sdk  string
+}
+
+func (p *partition) directory() string {
+	return filepath.Join(p.kind, "hour="+p.hour, "sdk="+p.sdk)
+}
+
+// byteCounter counts the bytes written to the underlying file
+type byteCounter int64
+
+func (b *byteCounter) Write(p []byte) (int, error) {
+	*b += byteCounter(len(p))
+	return len(p), nil
+}
+
+type partFile struct {
+	file     *os.File
+	path     string // relative final path. The file is written with a `.part` suffix until it's closed
+	gzip     *gzip.Writer
+	out      io.Writer
+	hash     hash.Hash
+	size     byteCounter
+	records  int64
+	openedAt time.Time
+}
+
+// Sink writes impressions & events as newline-delimited json (optionally gzipped) into a local directory,
+// partitioned by kind, UTC hour & sdk version: <dir>/<kind>/hour=<yyyy-mm-ddThh>/sdk=<sdk-version>/<file>.
+// Files are rotated when they exceed the max size or age, or when the hour is over. The file being written has a
+// `.part` suffix which is removed upon rotation, after which an entry is appended to the manifest, so that a
+// separate shipper only picks up complete files.
+type Sink struct {
+	logger      logging.LoggerInterface
+	directory   string
+	compress    bool
+	maxFileSize int64
+	maxFileAge  time.Duration
+	files       map[partition]*partFile
+	manifest    *os.File
+	closed      bool
+	now         func() time.Time
+	mutex       sync.Mutex
+}
+
+// NewSink constructs a new export sink
+func NewSink(cfg *Config) (*Sink, error) {
+	cfg.normalize()
+	if cfg.Directory == "" {
+		return nil, errors.New("an export directory is required")
+	}
+
+	if err := os.MkdirAll(cfg.Directory, 0755); err != nil {
+		return nil, fmt.Errorf("error creating export directory '%s': %w", cfg.Directory, err)
+	}
+
+	sink := &Sink{
+		logger:      cfg.Logger,
+		directory:   cfg.Directory,
+		compress:    cfg.Compress,
+		maxFileSize: cfg.MaxFileSize,
+		maxFileAge:  cfg.MaxFileAge,
+		files:       make(map[partition]*partFile),
+		now:         time.Now,
+	}
+
+	if partials := sink.partialFiles(); len(partials) > 0 {
+		sink.logger.Warning(fmt.Sprintf("found %d incomplete export files left by a previous run (ie: %s). They won't be listed in the manifest",
+			len(partials), partials[0]))
+	}
+	return sink, nil
+}
+
+// Setup builds an export sink from the config options. A nil sink is returned if exporting is disabled
+func Setup(cfg *conf.FileExport, logger logging.LoggerInterface) (*Sink, error) {
+	if cfg.Directory == "" {
+		return nil, nil
+	}
+
+	return NewSink(&Config{
+		Logger:      logger,
+		Directory:   cfg.Directory,
+		Compress:    cfg.Compress,
+		MaxFileSize: cfg.MaxFileSizeBytes,
+		MaxFileAge:  time.Duration(cfg.MaxFileAgeMs) * time.Millisecond,
+	})
+}
+
+// WriteImpressions appends impressions generated by an sdk
+func (s *Sink) WriteImpressions(metadata *dtos.Metadata, impressions []dtos.ImpressionsDTO) error {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	var records int64
+	for _, forFeature := range impressions {
+		for _, impression := range forFeature.KeyImpressions {
+			err := encoder.Encode(Impression{
+				SDKVersion:   metadata.SDKVersion,
+				MachineName:  metadata.MachineName,
+				MachineIP:    metadata.MachineIP,
+				Feature:      forFeature.TestName,
+				KeyName:      impression.KeyName,
+				BucketingKey: impression.BucketingKey,
+				Treatment:    impression.Treatment,
+				Label:        impression.Label,
+				ChangeNumber: impression.ChangeNumber,
+				Time:         impression.Time,
+				PreviousTime: impression.Pt,
+			})
+			if err != nil {
+				return fmt.Errorf("error serializing impression: %w", err)
+			}
+			records++
+		}
+	}
+	return s.write(KindImpressions, metadata, buffer.Bytes(), records)
+}
+
+// WriteEvents appends events generated by an sdk
+func (s *Sink) WriteEvents(metadata *dtos.Metadata, events []dtos.EventDTO) error {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	for _, event := range events {
+		err := encoder.Encode(Event{
+			SDKVersion:      metadata.SDKVersion,
+			MachineName:     metadata.MachineName,
+			MachineIP:       metadata.MachineIP,
+			Key:             event.Key,
+			TrafficTypeName: event.TrafficTypeName,
+			EventTypeID:     event.EventTypeID,
+			Value:           event.Value,
+			Timestamp:       event.Timestamp,
+			Properties:      event.Properties,
+		})
+		if err != nil {
+			return fmt.Errorf("error serializing event: %w", err)
+		}
+	}
+	return s.write(KindEvents, metadata, buffer.Bytes(), int64(len(events)))
+}
+
+// RotateIfExpired closes the files that have been open for longer than the configured max age, or whose hour is over.
+// It's meant to be called periodically so that files are handed over even if no more data arrives.
+func (s *Sink) RotateIfExpired() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	currentHour := now.UTC().Format(hourLayout)
+	var errs []string
+	for p, f := range s.files {
+		if p.hour == currentHour && now.Sub(f.openedAt) < s.maxFileAge {
+			continue
+		}
+		if err := s.closeFile(p, f); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("error rotating export files: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Close closes every open file & the manifest. Subsequent writes fail with ErrClosed
+func (s *Sink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	var errs []string
+	for p, f := range s.files {
+		if err := s.closeFile(p, f); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if s.manifest != nil {
+		if err := s.manifest.Close(); err != nil {
+			errs = append(errs, fmt.Sprintf("error closing manifest: %s", err))
+		}
+		s.manifest = nil
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("error closing export files: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (s *Sink) write(kind string, metadata *dtos.Metadata, lines []byte, records int64) error {
+	if records == 0 {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+
+	now := s.now()
+	p := partition{kind: kind, hour: now.UTC().Format(hourLayout), sdk: sdkName(metadata.SDKVersion)}
+	f := s.files[p]
+	if f != nil && (int64(f.size) >= s.maxFileSize || now.Sub(f.openedAt) >= s.maxFileAge) {
+		if err := s.closeFile(p, f); err != nil {
+			s.logger.Error(err.Error())
+		}
+		f = nil
+	}
+
+	if f == nil {
+		var err error
+		if f, err = s.open(p, now); err != nil {
+			return err
+		}
+	}
+
+	if _, err := f.out.Write(lines); err != nil {
+		return fmt.Errorf("error writing to export file '%s': %w", f.path, err)
+	}
+	f.records += records
+	return nil
+}
+
+func (s *Sink) open(p partition, now time.Time) (*partFile, error) {
+	dir := filepath.Join(s.directory, p.directory())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating export directory '%s': %w", dir, err)
+	}
+
+	name := fmt.Sprintf("%s.%d%s", p.kind, now.UnixNano(), fileSuffix)
+	if s.compress {
+		name += gzipSuffix
+	}
+
+	path := filepath.Join(p.directory(), name)
+	file, err := os.OpenFile(filepath.Join(s.directory, path+partialSuffix), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error creating export file '%s': %w", path, err)
+	}
+
+	f := &partFile{file: file, path: path, hash: sha256.New(), openedAt: now}
+	f.out = io.MultiWriter(file, f.hash, &f.size)
+	if s.compress {
+		f.gzip = gzip.NewWriter(f.out)
+		f.out = f.gzip
+	}
+
+	s.files[p] = f
+	return f, nil
+}
+
+// closeFile flushes & closes a file, removes its `.part` suffix and lists it in the manifest
+func (s *Sink) closeFile(p partition, f *partFile) error {
+	delete(s.files, p)
+	if f.gzip != nil {
+		if err := f.gzip.Close(); err != nil {
+			s.logger.Error(fmt.Sprintf("error flushing export file '%s': %s", f.path, err))
+		}
+	}
+
+	if err := f.file.Sync(); err != nil {
+		s.logger.Error(fmt.Sprintf("error syncing export file '%s': %s", f.path, err))
+	}
+
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("error closing export file '%s': %w", f.path, err)
+	}
+
+	final := filepath.Join(s.directory, f.path)
+	if err := os.Rename(final+partialSuffix, final); err != nil {
+		return fmt.Errorf("error renaming export file '%s': %w", f.path, err)
+	}
+
+	return s.appendToManifest(&ManifestEntry{
+		Path:       filepath.ToSlash(f.path),
+		Kind:       p.kind,
+		Hour:       p.hour,
+		SDK:        p.sdk,
+		Records:    f.records,
+		Bytes:      int64(f.size),
+		SHA256:     hex.EncodeToString(f.hash.Sum(nil)),
+		Compressed: f.gzip != nil,
+		OpenedAt:   f.openedAt.UnixNano() / int64(time.Millisecond),
+		ClosedAt:   s.now().UnixNano() / int64(time.Millisecond),
+	})
+}
+
+func (s *Sink) appendToManifest(entry *ManifestEntry) error {
+	if s.manifest == nil {
+		path := filepath.Join(s.directory, ManifestName)
+		manifest, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("error opening export manifest '%s': %w", path, err)
+		}
+		s.manifest = manifest
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error serializing manifest entry: %w", err)
+	}
+
+	if _, err := s.manifest.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error writing manifest entry for '%s': %w", entry.Path, err)
+	}
+
+	if err := s.manifest.Sync(); err != nil {
+		s.logger.Error("error syncing export manifest: ", err)
+	}
+
+	s.logger.Debug("export file ready: ", entry.Path)
+	return nil
+}
+
+func (s *Sink) partialFiles() []string {
+	var partials []string
+	filepath.Walk(s.directory, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && strings.HasSuffix(path, partialSuffix) {
+			partials = append(partials, path)
+		}
+		return nil
+	})
+	return partials
+}
+
+// sdkName makes an sdk version safe to use as a directory name
+func sdkName(version string) string {
+	if version == "" {
+		return unknownSDK
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, version)
+}
+
+// NewRotationTask builds a task that periodically hands over the files that have exceeded their max age or whose
+// hour is over. The sink is closed when the task is stopped, so it should be stopped after the tasks writing to it
+func NewRotationTask(sink *Sink, logger logging.LoggerInterface, period int) *asynctask.AsyncTask {
+	doWork := func(l logging.LoggerInterface) error {
+		if err := sink.RotateIfExpired(); err != nil {
+			l.Error(err.Error())
+		}
+		return nil
+	}
+	onStop := func(l logging.LoggerInterface) {
+		if err := sink.Close(); err != nil {
+			l.Error(err.Error())
+		}
+	}
+	return asynctask.NewAsyncTask("export-file-rotation", doWork, period, nil, onStop, logger)
+}
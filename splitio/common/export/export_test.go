@@ -0,0 +1,198 @@
+package export
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+var (
+	goSDK   = dtos.Metadata{SDKVersion: "go-6.1.0", MachineName: "m1", MachineIP: "1.2.3.4"}
+	javaSDK = dtos.Metadata{SDKVersion: "java 4.4.0/rc", MachineName: "m2", MachineIP: "5.6.7.8"}
+)
+
+func readManifest(t *testing.T, dir string) []ManifestEntry {
+	t.Helper()
+	file, err := os.Open(filepath.Join(dir, ManifestName))
+	if err != nil {
+		t.Fatal("the manifest should exist. Got: ", err)
+	}
+	defer file.Close()
+
+	var entries []ManifestEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry ManifestEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatal("invalid manifest entry: ", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func readLines(t *testing.T, dir string, entry ManifestEntry) []map[string]interface{} {
+	t.Helper()
+	raw, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(entry.Path)))
+	if err != nil {
+		t.Fatal("the exported file should exist. Got: ", err)
+	}
+
+	if sum := sha256.Sum256(raw); hex.EncodeToString(sum[:]) != entry.SHA256 || int64(len(raw)) != entry.Bytes {
+		t.Error("the manifest checksum & size should match the file. Got: ", entry)
+	}
+
+	reader := strings.NewReader(string(raw))
+	var scanner *bufio.Scanner
+	if entry.Compressed {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			t.Fatal("the exported file should be gzipped. Got: ", err)
+		}
+		scanner = bufio.NewScanner(gz)
+	} else {
+		scanner = bufio.NewScanner(reader)
+	}
+
+	var lines []map[string]interface{}
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatal("invalid exported line: ", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestSinkPartitions(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewSink(&Config{Logger: logging.NewLogger(nil), Directory: dir, Compress: true})
+	if err != nil {
+		t.Fatal("the sink should be created. Got: ", err)
+	}
+	now := time.Date(2026, 10, 19, 6, 30, 0, 0, time.UTC)
+	sink.now = func() time.Time { return now }
+
+	err = sink.WriteImpressions(&goSDK, []dtos.ImpressionsDTO{
+		{TestName: "f1", KeyImpressions: []dtos.ImpressionDTO{{KeyName: "key1", Treatment: "on", Time: 1, ChangeNumber: 2, Label: "l1", Pt: 3}}},
+		{TestName: "f2", KeyImpressions: []dtos.ImpressionDTO{{KeyName: "key2", Treatment: "off"}, {KeyName: "key3", Treatment: "off"}}},
+	})
+	if err != nil {
+		t.Error("impressions should be written. Got: ", err)
+	}
+
+	value := 1.5
+	if err := sink.WriteEvents(&javaSDK, []dtos.EventDTO{{Key: "key1", TrafficTypeName: "user", EventTypeID: "click", Value: &value}}); err != nil {
+		t.Error("events should be written. Got: ", err)
+	}
+
+	if err := sink.WriteEvents(&goSDK, nil); err != nil || len(sink.files) != 2 {
+		t.Error("empty bulks should not open files. Got: ", err, len(sink.files))
+	}
+
+	partial, _ := filepath.Glob(filepath.Join(dir, "impressions", "hour=2026-10-19T06", "sdk=go-6.1.0", "impressions.*.ndjson.gz.part"))
+	if len(partial) != 1 {
+		t.Error("the file being written should have a .part suffix. Got: ", partial)
+	}
+
+	if err := sink.RotateIfExpired(); err != nil || len(sink.files) != 2 {
+		t.Error("files should not be rotated before they expire. Got: ", err, len(sink.files))
+	}
+
+	now = now.Add(time.Hour)
+	if err := sink.RotateIfExpired(); err != nil || len(sink.files) != 0 {
+		t.Error("files should be rotated once their hour is over. Got: ", err, len(sink.files))
+	}
+
+	entries := readManifest(t, dir)
+	if len(entries) != 2 {
+		t.Fatal("both files should be listed in the manifest. Got: ", entries)
+	}
+
+	for _, entry := range entries {
+		lines := readLines(t, dir, entry)
+		switch entry.Kind {
+		case KindImpressions:
+			if entry.SDK != "go-6.1.0" || entry.Hour != "2026-10-19T06" || entry.Records != 3 || len(lines) != 3 || !entry.Compressed {
+				t.Error("wrong impressions entry: ", entry)
+			}
+			if lines[0]["feature"] != "f1" || lines[0]["keyName"] != "key1" || lines[0]["machineName"] != "m1" || lines[0]["previousTime"] != 3.0 {
+				t.Error("wrong impression line: ", lines[0])
+			}
+		case KindEvents:
+			if entry.SDK != "java_4.4.0_rc" || entry.Records != 1 || len(lines) != 1 {
+				t.Error("wrong events entry: ", entry)
+			}
+			if lines[0]["eventTypeId"] != "click" || lines[0]["value"] != 1.5 || lines[0]["sdkVersion"] != "java 4.4.0/rc" {
+				t.Error("wrong event line: ", lines[0])
+			}
+		default:
+			t.Error("unexpected kind: ", entry.Kind)
+		}
+	}
+
+	partial, _ = filepath.Glob(filepath.Join(dir, "*", "*", "*", "*.part"))
+	if len(partial) != 0 {
+		t.Error("no partial files should be left after rotating. Got: ", partial)
+	}
+}
+
+func TestSinkRotation(t *testing.T) {
+	dir := t.TempDir()
+	sink, _ := NewSink(&Config{Logger: logging.NewLogger(nil), Directory: dir, MaxFileSize: 100, MaxFileAge: time.Minute})
+	now := time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC)
+	sink.now = func() time.Time { now = now.Add(time.Millisecond); return now }
+
+	events := []dtos.EventDTO{{Key: "key1", TrafficTypeName: "user", EventTypeID: "click"}}
+	for idx := 0; idx < 3; idx++ {
+		sink.WriteEvents(&goSDK, events)
+	}
+	if entries := readManifest(t, dir); len(entries) != 2 || entries[0].Compressed {
+		t.Error("files should be rotated once they exceed the max size. Got: ", entries)
+	}
+
+	now = now.Add(time.Minute)
+	sink.WriteEvents(&goSDK, events)
+	if entries := readManifest(t, dir); len(entries) != 3 || entries[2].Records != 1 {
+		t.Error("files should be rotated once they exceed the max age. Got: ", entries)
+	}
+
+	if err := sink.Close(); err != nil {
+		t.Error("the sink should be closed. Got: ", err)
+	}
+
+	entries := readManifest(t, dir)
+	if len(entries) != 4 || entries[3].Records != 1 {
+		t.Error("closing the sink should hand over every open file. Got: ", entries)
+	}
+	for _, entry := range entries {
+		readLines(t, dir, entry)
+	}
+
+	if err := sink.WriteEvents(&goSDK, events); !errors.Is(err, ErrClosed) {
+		t.Error("writing to a closed sink should fail. Got: ", err)
+	}
+}
+
+func TestSdkName(t *testing.T) {
+	if name := sdkName(""); name != unknownSDK {
+		t.Error("empty versions should be reported as unknown. Got: ", name)
+	}
+
+	if name := sdkName("../../etc"); name != ".._.._etc" {
+		t.Error("path separators should be replaced. Got: ", name)
+	}
+}
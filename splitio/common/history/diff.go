@@ -0,0 +1,110 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+)
+
+// FieldChange describes a difference between two split definitions
+type FieldChange struct {
+	Path   string      `json:"path"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Diff returns the fields that differ between two split definitions, using json paths (ie: conditions[0].label)
+func Diff(before *dtos.SplitDTO, after *dtos.SplitDTO) ([]FieldChange, error) {
+	asGenericBefore, err := toGeneric(before)
+	if err != nil {
+		return nil, fmt.Errorf("error processing previous definition: %w", err)
+	}
+
+	asGenericAfter, err := toGeneric(after)
+	if err != nil {
+		return nil, fmt.Errorf("error processing current definition: %w", err)
+	}
+
+	changes := make([]FieldChange, 0)
+	diffValues("", asGenericBefore, asGenericAfter, &changes)
+	return changes, nil
+}
+
+func toGeneric(split *dtos.SplitDTO) (interface{}, error) {
+	if split == nil {
+		return nil, nil
+	}
+
+	serialized, err := json.Marshal(split)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	err = json.Unmarshal(serialized, &generic)
+	return generic, err
+}
+
+func diffValues(path string, before interface{}, after interface{}, changes *[]FieldChange) {
+	switch b := before.(type) {
+	case map[string]interface{}:
+		if a, ok := after.(map[string]interface{}); ok {
+			diffObjects(path, b, a, changes)
+			return
+		}
+	case []interface{}:
+		if a, ok := after.([]interface{}); ok {
+			diffArrays(path, b, a, changes)
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, FieldChange{Path: path, Before: before, After: after})
+	}
+}
+
+func diffObjects(path string, before map[string]interface{}, after map[string]interface{}, changes *[]FieldChange) {
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for key := range before {
+		keys[key] = struct{}{}
+	}
+	for key := range after {
+		keys[key] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	for _, key := range sorted {
+		child := key
+		if path != "" {
+			child = path + "." + key
+		}
+		diffValues(child, before[key], after[key], changes)
+	}
+}
+
+func diffArrays(path string, before []interface{}, after []interface{}, changes *[]FieldChange) {
+	longest := len(before)
+	if len(after) > longest {
+		longest = len(after)
+	}
+
+	for idx := 0; idx < longest; idx++ {
+		var b, a interface{}
+		if idx < len(before) {
+			b = before[idx]
+		}
+		if idx < len(after) {
+			a = after[idx]
+		}
+		diffValues(fmt.Sprintf("%s[%d]", path, idx), b, a, changes)
+	}
+}
@@ -0,0 +1,115 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/storage/inmemory/mutexmap"
+	"github.com/splitio/go-split-commons/v4/synchronizer/worker/split"
+)
+
+func TestInMemoryStore(t *testing.T) {
+	store := NewInMemoryStore(3)
+	for idx := int64(1); idx <= 4; idx++ {
+		name := "split1"
+		if idx%2 == 0 {
+			name = "split2"
+		}
+		store.Record(Change{SplitName: name, ChangeNumber: idx, ReceivedAt: idx * 1000})
+	}
+
+	all := store.Changes(Filter{})
+	if len(all) != 3 || all[0].ID != 4 || all[2].ID != 2 {
+		t.Error("the oldest change should be dropped & the newest returned first. Got: ", all)
+	}
+
+	if filtered := store.Changes(Filter{SplitName: "split2", Limit: 1}); len(filtered) != 1 || filtered[0].ChangeNumber != 4 {
+		t.Error("wrong filtered changes: ", filtered)
+	}
+
+	if ranged := store.Changes(Filter{Since: 2500, Until: 3500}); len(ranged) != 1 || ranged[0].ChangeNumber != 3 {
+		t.Error("wrong changes in range: ", ranged)
+	}
+
+	if change := store.Change(3); change == nil || change.ChangeNumber != 3 {
+		t.Error("change 3 should be found. Got: ", change)
+	}
+
+	if change := store.Change(1); change != nil {
+		t.Error("evicted changes should not be found")
+	}
+
+	if change := store.At("split2", 3999); change == nil || change.ChangeNumber != 2 {
+		t.Error("the change in effect at 3999 should be the one with cn=2. Got: ", change)
+	}
+}
+
+type updaterMock struct {
+	storage *RecordingSplitStorage
+}
+
+func (u *updaterMock) SynchronizeSplits(till *int64) (*split.UpdateResult, error) {
+	u.storage.Update([]dtos.SplitDTO{{Name: "split1", ChangeNumber: 2, DefaultTreatment: "on"}}, nil, 2)
+	return &split.UpdateResult{}, nil
+}
+
+func (u *updaterMock) LocalKill(splitName string, defaultTreatment string, changeNumber int64) {
+	u.storage.KillLocally(splitName, defaultTreatment, changeNumber)
+}
+
+func TestRecordingStorage(t *testing.T) {
+	store := NewInMemoryStore(10)
+	splits := mutexmap.NewMMSplitStorage()
+	recording := NewRecordingSplitStorage(splits, store)
+
+	recording.Update([]dtos.SplitDTO{{Name: "split1", ChangeNumber: 1, DefaultTreatment: "off"}, {Name: "split2", ChangeNumber: 1}}, nil, 1)
+	recording.Update([]dtos.SplitDTO{{Name: "split2", ChangeNumber: 1}}, nil, 1)
+
+	updater := NewSourceTrackingUpdater(&updaterMock{storage: recording}, recording)
+	till := int64(2)
+	updater.SynchronizeSplits(&till)
+	updater.LocalKill("split1", "off", 3)
+	recording.Update(nil, []dtos.SplitDTO{{Name: "split2", ChangeNumber: 4}}, 4)
+
+	changes := store.Changes(Filter{})
+	if len(changes) != 5 {
+		t.Fatal("unchanged splits should not be recorded. Got: ", changes)
+	}
+
+	expected := []struct {
+		name       string
+		changeType string
+		source     string
+		cn         int64
+		previousCn int64
+	}{
+		{"split2", TypeArchived, SourceStreaming, 4, 1},
+		{"split1", TypeKilled, SourceLocalKill, 3, 2},
+		{"split1", TypeUpdated, SourceStreaming, 2, 1},
+	}
+	for idx, exp := range expected {
+		change := changes[idx]
+		if change.SplitName != exp.name || change.Type != exp.changeType || change.Source != exp.source ||
+			change.ChangeNumber != exp.cn || change.PreviousChangeNumber != exp.previousCn {
+			t.Errorf("wrong change at %d: %+v", idx, change)
+		}
+	}
+
+	if changes[4].Type != TypeCreated || changes[4].Source != SourcePolling || changes[4].Previous != nil {
+		t.Error("the first update should create the split: ", changes[4])
+	}
+
+	diff, err := Diff(changes[2].Previous, changes[2].Current)
+	if err != nil || len(diff) != 2 || diff[0].Path != "changeNumber" || diff[1].Path != "defaultTreatment" || diff[1].After != "on" {
+		t.Error("wrong diff: ", diff, err)
+	}
+}
+
+func TestDiffNested(t *testing.T) {
+	before := &dtos.SplitDTO{Conditions: []dtos.ConditionDTO{{Label: "a"}}}
+	after := &dtos.SplitDTO{Conditions: []dtos.ConditionDTO{{Label: "b"}, {Label: "c"}}}
+	diff, _ := Diff(before, after)
+	if len(diff) != 2 || diff[0].Path != "conditions[0].label" || diff[1].Path != "conditions[1]" || diff[1].Before != nil {
+		t.Error("wrong nested diff: ", diff)
+	}
+}
@@ -0,0 +1,144 @@
+package history
+
+import (
+	"sync"
+	"time"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/storage"
+	"github.com/splitio/go-split-commons/v4/synchronizer/worker/split"
+)
+
+// RecordingSplitStorage wraps a split storage and records every change applied through it
+type RecordingSplitStorage struct {
+	storage.SplitStorage
+	store  Store
+	source string
+	mutex  sync.Mutex
+}
+
+// NewRecordingSplitStorage constructs a new split storage wrapper that records changes into the supplied store
+func NewRecordingSplitStorage(wrapped storage.SplitStorage, store Store) *RecordingSplitStorage {
+	return &RecordingSplitStorage{SplitStorage: wrapped, store: store, source: SourcePolling}
+}
+
+// Update applies the changes to the underlying storage & records those that modify a split
+func (s *RecordingSplitStorage) Update(toAdd []dtos.SplitDTO, toRemove []dtos.SplitDTO, changeNumber int64) {
+	names := make([]string, 0, len(toAdd)+len(toRemove))
+	for _, group := range [][]dtos.SplitDTO{toAdd, toRemove} {
+		for idx := range group {
+			names = append(names, group[idx].Name)
+		}
+	}
+
+	if len(names) == 0 {
+		s.SplitStorage.Update(toAdd, toRemove, changeNumber)
+		return
+	}
+
+	previous := s.FetchMany(names)
+	s.SplitStorage.Update(toAdd, toRemove, changeNumber)
+
+	s.mutex.Lock()
+	source := s.source
+	s.mutex.Unlock()
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	changes := make([]Change, 0, len(names))
+	for idx := range toAdd {
+		current := toAdd[idx]
+		before := previous[current.Name]
+		if before != nil && before.ChangeNumber == current.ChangeNumber {
+			continue // nothing changed
+		}
+
+		changeType := TypeUpdated
+		if before == nil {
+			changeType = TypeCreated
+		}
+		changes = append(changes, newChange(current.Name, changeType, source, now, before, &current))
+	}
+
+	for idx := range toRemove {
+		before := previous[toRemove[idx].Name]
+		if before == nil {
+			continue // archiving a split we never had
+		}
+		removed := toRemove[idx]
+		changes = append(changes, newChange(removed.Name, TypeArchived, source, now, before, &removed))
+	}
+
+	if len(changes) > 0 {
+		s.store.Record(changes...)
+	}
+}
+
+// KillLocally kills the split in the underlying storage & records the change
+func (s *RecordingSplitStorage) KillLocally(splitName string, defaultTreatment string, changeNumber int64) {
+	before := s.Split(splitName)
+	s.SplitStorage.KillLocally(splitName, defaultTreatment, changeNumber)
+	after := s.Split(splitName)
+	if before == nil || after == nil || (before.Killed && before.ChangeNumber == after.ChangeNumber) {
+		return
+	}
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	s.store.Record(newChange(splitName, TypeKilled, SourceLocalKill, now, before, after))
+}
+
+func (s *RecordingSplitStorage) setSource(source string) {
+	s.mutex.Lock()
+	s.source = source
+	s.mutex.Unlock()
+}
+
+func newChange(name string, changeType string, source string, now int64, before *dtos.SplitDTO, after *dtos.SplitDTO) Change {
+	change := Change{
+		SplitName:            name,
+		Type:                 changeType,
+		Source:               source,
+		ChangeNumber:         after.ChangeNumber,
+		PreviousChangeNumber: -1,
+		ReceivedAt:           now,
+		Previous:             before,
+		Current:              after,
+	}
+	if before != nil {
+		change.PreviousChangeNumber = before.ChangeNumber
+	}
+	return change
+}
+
+// SourceTrackingUpdater wraps a split updater and tells the recording storage whether changes come from
+// periodic fetches or streaming notifications
+type SourceTrackingUpdater struct {
+	wrapped split.Updater
+	storage *RecordingSplitStorage
+	mutex   sync.Mutex
+}
+
+// NewSourceTrackingUpdater constructs a new source-tracking split updater
+func NewSourceTrackingUpdater(wrapped split.Updater, storage *RecordingSplitStorage) *SourceTrackingUpdater {
+	return &SourceTrackingUpdater{wrapped: wrapped, storage: storage}
+}
+
+// SynchronizeSplits forwards the call to the wrapped updater. Streaming notifications carry a target change number,
+// while periodic fetches don't. Syncs are serialized so that the source is not mixed up between concurrent ones
+func (u *SourceTrackingUpdater) SynchronizeSplits(till *int64) (*split.UpdateResult, error) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	if till != nil {
+		u.storage.setSource(SourceStreaming)
+	} else {
+		u.storage.setSource(SourcePolling)
+	}
+	return u.wrapped.SynchronizeSplits(till)
+}
+
+// LocalKill forwards the call to the wrapped updater
+func (u *SourceTrackingUpdater) LocalKill(splitName string, defaultTreatment string, changeNumber int64) {
+	u.wrapped.LocalKill(splitName, defaultTreatment, changeNumber)
+}
+
+var _ storage.SplitStorage = (*RecordingSplitStorage)(nil)
+var _ split.Updater = (*SourceTrackingUpdater)(nil)
@@ -0,0 +1,155 @@
+// Package history keeps track of the changes applied to splits, so that past definitions can be inspected
+package history
+
+import (
+	"sync"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+)
+
+// Sources of a change
+const (
+	SourcePolling   = "polling"
+	SourceStreaming = "streaming"
+	SourceLocalKill = "localKill"
+)
+
+// Types of change
+const (
+	TypeCreated  = "created"
+	TypeUpdated  = "updated"
+	TypeKilled   = "killed"
+	TypeArchived = "archived"
+)
+
+// Change represents an update applied to a split
+type Change struct {
+	ID                   int64          `json:"id"`
+	SplitName            string         `json:"splitName"`
+	Type                 string         `json:"type"`
+	Source               string         `json:"source"`
+	ChangeNumber         int64          `json:"changeNumber"`
+	PreviousChangeNumber int64          `json:"previousChangeNumber"`
+	ReceivedAt           int64          `json:"receivedAt"`
+	Previous             *dtos.SplitDTO `json:"previous,omitempty"`
+	Current              *dtos.SplitDTO `json:"current,omitempty"`
+}
+
+// Filter is used to narrow down the changes returned by the store
+type Filter struct {
+	SplitName string
+	Since     int64
+	Until     int64
+	Limit     int
+}
+
+func (f *Filter) matches(change *Change) bool {
+	return (f.SplitName == "" || change.SplitName == f.SplitName) &&
+		(f.Since == 0 || change.ReceivedAt >= f.Since) &&
+		(f.Until == 0 || change.ReceivedAt <= f.Until)
+}
+
+// Store defines the interface of a split change history storage
+type Store interface {
+	Record(changes ...Change)
+	Changes(filter Filter) []Change
+	Change(id int64) *Change
+	At(splitName string, timestamp int64) *Change
+}
+
+// InMemoryStore is a bounded store that drops the oldest changes when full
+type InMemoryStore struct {
+	changes []Change
+	next    int
+	full    bool
+	lastID  int64
+	mutex   sync.RWMutex
+}
+
+// NewInMemoryStore constructs a new history store which keeps at most `maxChanges` items
+func NewInMemoryStore(maxChanges int) *InMemoryStore {
+	if maxChanges <= 0 {
+		maxChanges = 1
+	}
+	return &InMemoryStore{changes: make([]Change, maxChanges)}
+}
+
+// Record adds changes to the history, assigning them an incremental id
+func (s *InMemoryStore) Record(changes ...Change) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, change := range changes {
+		s.lastID++
+		change.ID = s.lastID
+		s.changes[s.next] = change
+		s.next = (s.next + 1) % len(s.changes)
+		if s.next == 0 {
+			s.full = true
+		}
+	}
+}
+
+// Changes returns the changes matching the filter, newest first
+func (s *InMemoryStore) Changes(filter Filter) []Change {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	toReturn := make([]Change, 0)
+	s.reverseIterate(func(change *Change) bool {
+		if filter.matches(change) {
+			toReturn = append(toReturn, *change)
+		}
+		return filter.Limit <= 0 || len(toReturn) < filter.Limit
+	})
+	return toReturn
+}
+
+// Change returns a specific change, or nil if it's not (or no longer) in the store
+func (s *InMemoryStore) Change(id int64) *Change {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	var found *Change
+	s.reverseIterate(func(change *Change) bool {
+		if change.ID == id {
+			found = copyChange(change)
+		}
+		return found == nil && change.ID > id
+	})
+	return found
+}
+
+// At returns the latest change applied to a split before the supplied timestamp (in millis),
+// which contains the definition in use at that time
+func (s *InMemoryStore) At(splitName string, timestamp int64) *Change {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	var found *Change
+	s.reverseIterate(func(change *Change) bool {
+		if change.SplitName == splitName && change.ReceivedAt <= timestamp {
+			found = copyChange(change)
+		}
+		return found == nil
+	})
+	return found
+}
+
+// reverseIterate calls `f` with each change, newest first, until it returns false
+func (s *InMemoryStore) reverseIterate(f func(change *Change) bool) {
+	count := s.next
+	if s.full {
+		count = len(s.changes)
+	}
+
+	for idx := 0; idx < count; idx++ {
+		position := (s.next - 1 - idx + len(s.changes)) % len(s.changes)
+		if !f(&s.changes[position]) {
+			return
+		}
+	}
+}
+
+func copyChange(change *Change) *Change {
+	c := *change
+	return &c
+}
+
+var _ Store = (*InMemoryStore)(nil)
@@ -0,0 +1,201 @@
+package impressiontap
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+)
+
+// Limits applied to every subscription, so that taps are safe to use in production
+const (
+	DefaultMaxItems = 100
+	MaxItems        = 1000
+	DefaultTTL      = 5 * time.Minute
+	MaxTTL          = 30 * time.Minute
+)
+
+// Reasons why a subscription ends
+const (
+	ReasonCapReached   = "cap reached"
+	ReasonExpired      = "expired"
+	ReasonUnsubscribed = "unsubscribed"
+)
+
+// ErrNoFilter is returned when attempting to subscribe without filtering by key, flag or machine name
+var ErrNoFilter = errors.New("at least one of key, flag or machine name is required")
+
+// ErrTooManySubscriptions is returned when the max number of concurrent subscriptions is reached
+var ErrTooManySubscriptions = errors.New("too many active impression taps")
+
+// Filter selects the impressions forwarded to a subscription. Empty fields match everything
+type Filter struct {
+	Key         string `json:"key,omitempty"`
+	Flag        string `json:"flag,omitempty"`
+	MachineName string `json:"machineName,omitempty"`
+}
+
+func (f *Filter) isEmpty() bool {
+	return f.Key == "" && f.Flag == "" && f.MachineName == ""
+}
+
+func (f *Filter) matches(metadata *dtos.Metadata, flag string, impression *dtos.ImpressionDTO) bool {
+	return (f.Key == "" || f.Key == impression.KeyName) &&
+		(f.Flag == "" || f.Flag == flag) &&
+		(f.MachineName == "" || f.MachineName == metadata.MachineName)
+}
+
+// Impression is a tapped impression along with the metadata of the sdk that generated it
+type Impression struct {
+	Flag         string `json:"flag"`
+	KeyName      string `json:"keyName"`
+	BucketingKey string `json:"bucketingKey,omitempty"`
+	Treatment    string `json:"treatment"`
+	Label        string `json:"label"`
+	ChangeNumber int64  `json:"changeNumber"`
+	Time         int64  `json:"time"`
+	SDKVersion   string `json:"sdkVersion"`
+	MachineName  string `json:"machineName"`
+	MachineIP    string `json:"machineIP"`
+	ReceivedAt   int64  `json:"receivedAt"`
+}
+
+// Subscription receives matching impressions until the cap is reached, it expires or it's unsubscribed
+type Subscription struct {
+	filter    Filter
+	remaining int
+	items     chan Impression
+	timer     *time.Timer
+	reason    string
+}
+
+// Items returns the channel where matching impressions are received. It's closed when the subscription ends
+func (s *Subscription) Items() <-chan Impression {
+	return s.items
+}
+
+// Reason returns why the subscription ended. Only valid after the items channel is closed
+func (s *Subscription) Reason() string {
+	return s.reason
+}
+
+// Tap forwards impressions matching active subscriptions
+type Tap struct {
+	subscriptions    map[*Subscription]struct{}
+	maxSubscriptions int
+	active           int32
+	mutex            sync.Mutex
+}
+
+// New constructs a new impression tap allowing up to maxSubscriptions concurrent subscriptions
+func New(maxSubscriptions int) *Tap {
+	return &Tap{subscriptions: make(map[*Subscription]struct{}), maxSubscriptions: maxSubscriptions}
+}
+
+// Active returns true if there are subscriptions. Used by callers to avoid parsing impressions when nobody's listening
+func (t *Tap) Active() bool {
+	return atomic.LoadInt32(&t.active) > 0
+}
+
+// Subscribe registers a new subscription. maxItems & ttl are capped, and defaults are used when non-positive
+func (t *Tap) Subscribe(filter Filter, maxItems int, ttl time.Duration) (*Subscription, error) {
+	if filter.isEmpty() {
+		return nil, ErrNoFilter
+	}
+
+	if maxItems <= 0 {
+		maxItems = DefaultMaxItems
+	} else if maxItems > MaxItems {
+		maxItems = MaxItems
+	}
+
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	} else if ttl > MaxTTL {
+		ttl = MaxTTL
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if len(t.subscriptions) >= t.maxSubscriptions {
+		return nil, ErrTooManySubscriptions
+	}
+
+	// the channel can hold every item the subscription will get, so that publishers never block
+	subscription := &Subscription{filter: filter, remaining: maxItems, items: make(chan Impression, maxItems)}
+	subscription.timer = time.AfterFunc(ttl, func() { t.end(subscription, ReasonExpired) })
+	t.subscriptions[subscription] = struct{}{}
+	atomic.StoreInt32(&t.active, int32(len(t.subscriptions)))
+	return subscription, nil
+}
+
+// Unsubscribe ends the subscription
+func (t *Tap) Unsubscribe(subscription *Subscription) {
+	t.end(subscription, ReasonUnsubscribed)
+}
+
+// Offer forwards an impression to the subscriptions it matches
+func (t *Tap) Offer(metadata *dtos.Metadata, flag string, impression *dtos.ImpressionDTO) {
+	if !t.Active() {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	var tapped *Impression
+	for subscription := range t.subscriptions {
+		if !subscription.filter.matches(metadata, flag, impression) {
+			continue
+		}
+
+		if tapped == nil {
+			tapped = &Impression{
+				Flag:         flag,
+				KeyName:      impression.KeyName,
+				BucketingKey: impression.BucketingKey,
+				Treatment:    impression.Treatment,
+				Label:        impression.Label,
+				ChangeNumber: impression.ChangeNumber,
+				Time:         impression.Time,
+				SDKVersion:   metadata.SDKVersion,
+				MachineName:  metadata.MachineName,
+				MachineIP:    metadata.MachineIP,
+				ReceivedAt:   time.Now().UnixNano() / int64(time.Millisecond),
+			}
+		}
+
+		subscription.items <- *tapped
+		if subscription.remaining--; subscription.remaining <= 0 {
+			t.remove(subscription, ReasonCapReached)
+		}
+	}
+}
+
+// OfferBulk forwards the impressions in a bulk posted by an sdk
+func (t *Tap) OfferBulk(metadata *dtos.Metadata, impressions []dtos.ImpressionsDTO) {
+	for _, group := range impressions {
+		for idx := range group.KeyImpressions {
+			t.Offer(metadata, group.TestName, &group.KeyImpressions[idx])
+		}
+	}
+}
+
+func (t *Tap) end(subscription *Subscription, reason string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.remove(subscription, reason)
+}
+
+func (t *Tap) remove(subscription *Subscription, reason string) {
+	if _, ok := t.subscriptions[subscription]; !ok {
+		return
+	}
+
+	subscription.timer.Stop()
+	subscription.reason = reason
+	delete(t.subscriptions, subscription)
+	close(subscription.items)
+	atomic.StoreInt32(&t.active, int32(len(t.subscriptions)))
+}
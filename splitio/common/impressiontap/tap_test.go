@@ -0,0 +1,60 @@
+package impressiontap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+)
+
+func TestTap(t *testing.T) {
+	tap := New(2)
+	if _, err := tap.Subscribe(Filter{}, 10, time.Minute); err != ErrNoFilter {
+		t.Error("subscriptions without filters should be rejected. Got: ", err)
+	}
+
+	byKey, _ := tap.Subscribe(Filter{Key: "key1"}, 2, time.Minute)
+	byFlagAndMachine, _ := tap.Subscribe(Filter{Flag: "flag2", MachineName: "machine1"}, 10, time.Minute)
+	if _, err := tap.Subscribe(Filter{Key: "key2"}, 10, time.Minute); err != ErrTooManySubscriptions {
+		t.Error("the max number of subscriptions should be enforced. Got: ", err)
+	}
+	if !tap.Active() {
+		t.Error("the tap should be active")
+	}
+
+	tap.OfferBulk(&dtos.Metadata{SDKVersion: "go-6.1.0", MachineName: "machine1"}, []dtos.ImpressionsDTO{
+		{TestName: "flag1", KeyImpressions: []dtos.ImpressionDTO{{KeyName: "key1", Treatment: "on"}, {KeyName: "key2", Treatment: "on"}}},
+		{TestName: "flag2", KeyImpressions: []dtos.ImpressionDTO{{KeyName: "key1", Treatment: "off"}, {KeyName: "key3", Treatment: "off"}}},
+	})
+	tap.Offer(&dtos.Metadata{MachineName: "machine2"}, "flag2", &dtos.ImpressionDTO{KeyName: "key1"})
+
+	var received []Impression
+	for item := range byKey.Items() {
+		received = append(received, item)
+	}
+	if len(received) != 2 || received[0].Flag != "flag1" || received[1].Flag != "flag2" || received[0].SDKVersion != "go-6.1.0" {
+		t.Error("wrong impressions received by key: ", received)
+	}
+	if byKey.Reason() != ReasonCapReached {
+		t.Error("the subscription should end once the cap is reached. Got: ", byKey.Reason())
+	}
+
+	if len(byFlagAndMachine.Items()) != 2 {
+		t.Error("flag & machine name should both match. Got: ", len(byFlagAndMachine.Items()))
+	}
+	tap.Unsubscribe(byFlagAndMachine)
+	tap.Unsubscribe(byFlagAndMachine)
+	if byFlagAndMachine.Reason() != ReasonUnsubscribed || tap.Active() {
+		t.Error("the tap should be inactive after unsubscribing")
+	}
+
+	expiring, _ := tap.Subscribe(Filter{Key: "key1"}, 10, 10*time.Millisecond)
+	select {
+	case _, ok := <-expiring.Items():
+		if ok || expiring.Reason() != ReasonExpired {
+			t.Error("the subscription should have expired")
+		}
+	case <-time.After(time.Second):
+		t.Error("the subscription should have expired")
+	}
+}
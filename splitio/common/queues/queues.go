@@ -0,0 +1,192 @@
+package queues
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Limits applied to admin actions, so that they're safe to use in production
+const (
+	DefaultMaxDrop  = 100000
+	DefaultPeekSize = 10
+	DefaultMaxPeek  = 100
+)
+
+// Placeholder used when redacting user keys from peeked items
+const redactedValue = "<redacted>"
+
+// ErrQueueNotFound is returned when an action targets an unknown queue
+var ErrQueueNotFound = errors.New("queue not found")
+
+// ErrNotFlushable is returned when requesting an immediate flush of a queue that can only be inspected or dropped
+var ErrNotFlushable = errors.New("queue cannot be flushed")
+
+// ErrDropTooLarge is returned when attempting to drop more items than allowed in a single action
+var ErrDropTooLarge = errors.New("too many items to drop")
+
+// Queue is a buffer of items waiting to be posted to split servers
+type Queue interface {
+	Name() string
+	Size() int64
+	Peek(n int) ([]interface{}, error)
+	Drop(n int64) (int64, error)
+}
+
+// Flusher is implemented by queues able to post their items right away
+type Flusher interface {
+	Flush() error
+}
+
+// Info describes the current state of a queue
+type Info struct {
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`
+	Flushable bool   `json:"flushable"`
+}
+
+// Manager exposes admin actions (flush, drop & peek) over a set of queues
+type Manager struct {
+	queues  []Queue
+	maxDrop int64
+	maxPeek int
+	mutex   sync.Mutex
+}
+
+// NewManager constructs a queue manager. Non-positive limits are replaced by the defaults
+func NewManager(maxDrop int64, maxPeek int, queues ...Queue) *Manager {
+	if maxDrop <= 0 {
+		maxDrop = DefaultMaxDrop
+	}
+	if maxPeek <= 0 {
+		maxPeek = DefaultMaxPeek
+	}
+	return &Manager{queues: queues, maxDrop: maxDrop, maxPeek: maxPeek}
+}
+
+// MaxDrop returns the max number of items that can be dropped in a single action
+func (m *Manager) MaxDrop() int64 {
+	return m.maxDrop
+}
+
+// Queues returns the current state of every managed queue
+func (m *Manager) Queues() []Info {
+	toRet := make([]Info, 0, len(m.queues))
+	for _, queue := range m.queues {
+		_, flushable := queue.(Flusher)
+		toRet = append(toRet, Info{Name: queue.Name(), Size: queue.Size(), Flushable: flushable})
+	}
+	return toRet
+}
+
+// Flush requests an immediate flush of the queue
+func (m *Manager) Flush(name string) error {
+	queue, err := m.get(name)
+	if err != nil {
+		return err
+	}
+
+	flusher, ok := queue.(Flusher)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFlushable, name)
+	}
+	return flusher.Flush()
+}
+
+// Peek returns up to `count` items from the head of the queue, optionally replacing user keys with a placeholder
+func (m *Manager) Peek(name string, count int, redact bool) ([]interface{}, error) {
+	queue, err := m.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if count <= 0 {
+		count = DefaultPeekSize
+	}
+	if count > m.maxPeek {
+		count = m.maxPeek
+	}
+
+	items, err := queue.Peek(count)
+	if err != nil {
+		return nil, fmt.Errorf("error peeking into %s: %w", name, err)
+	}
+
+	if redact {
+		for idx := range items {
+			items[idx] = redactKeys(items[idx])
+		}
+	}
+	return items, nil
+}
+
+// Drop discards the `count` oldest items of the queue, or all of them if `count` is not positive.
+// Requests exceeding the max drop size are rejected without dropping anything
+func (m *Manager) Drop(name string, count int64) (int64, error) {
+	queue, err := m.get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	// serialize drops so that concurrent requests cannot add up beyond the limit
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if count <= 0 {
+		count = queue.Size()
+	}
+	if count > m.maxDrop {
+		return 0, fmt.Errorf("%w: requested %d, max %d", ErrDropTooLarge, count, m.maxDrop)
+	}
+	return queue.Drop(count)
+}
+
+func (m *Manager) get(name string) (Queue, error) {
+	for _, queue := range m.queues {
+		if queue.Name() == name {
+			return queue, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrQueueNotFound, name)
+}
+
+// names of the fields holding user keys in impressions, events & unique keys, either as stored in redis or
+// as posted by sdks to the proxy
+var keyFields = map[string]struct{}{
+	"k":            {},
+	"b":            {},
+	"ks":           {},
+	"key":          {},
+	"keyName":      {},
+	"bucketingKey": {},
+}
+
+// redactKeys walks a decoded json document replacing the values of user key fields
+func redactKeys(item interface{}) interface{} {
+	switch value := item.(type) {
+	case map[string]interface{}:
+		for field, nested := range value {
+			if _, isKey := keyFields[field]; isKey {
+				value[field] = redactValue(nested)
+				continue
+			}
+			value[field] = redactKeys(nested)
+		}
+	case []interface{}:
+		for idx := range value {
+			value[idx] = redactKeys(value[idx])
+		}
+	}
+	return item
+}
+
+func redactValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case string:
+		return redactedValue
+	case []interface{}:
+		for idx := range typed {
+			typed[idx] = redactValue(typed[idx])
+		}
+	}
+	return value
+}
@@ -0,0 +1,143 @@
+package queues
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+type listMock struct {
+	items []string
+}
+
+func (l *listMock) Count() int64 { return int64(len(l.items)) }
+
+func (l *listMock) Peek(n int64) ([]string, error) {
+	if n > int64(len(l.items)) {
+		n = int64(len(l.items))
+	}
+	return append([]string(nil), l.items[:n]...), nil
+}
+
+func (l *listMock) Drop(n int64) error {
+	l.items = l.items[n:]
+	return nil
+}
+
+func newListMock(size int) *listMock {
+	list := &listMock{}
+	for idx := 0; idx < size; idx++ {
+		list.items = append(list.items, `{"m":{"s":"go-1.2.3"},"i":{"k":"key`+strconv.Itoa(idx)+`","b":"bk","f":"feature"}}`)
+	}
+	return list
+}
+
+func TestManagerQueues(t *testing.T) {
+	flushes := 0
+	manager := NewManager(0, 0,
+		NewRedisQueue("impressions", newListMock(3), func() error { flushes++; return nil }),
+		NewRedisQueue("impressions.failed", newListMock(1), nil),
+	)
+
+	infos := manager.Queues()
+	if len(infos) != 2 || infos[0] != (Info{Name: "impressions", Size: 3, Flushable: true}) || infos[1] != (Info{Name: "impressions.failed", Size: 1}) {
+		t.Error("wrong queue infos: ", infos)
+	}
+
+	if err := manager.Flush("impressions"); err != nil || flushes != 1 {
+		t.Error("the flush should have been forwarded. Got: ", err, flushes)
+	}
+
+	if err := manager.Flush("impressions.failed"); !errors.Is(err, ErrNotFlushable) {
+		t.Error("queues without a flusher cannot be flushed. Got: ", err)
+	}
+
+	if err := manager.Flush("nonexistent"); !errors.Is(err, ErrQueueNotFound) {
+		t.Error("unknown queues should be reported. Got: ", err)
+	}
+
+	if manager.MaxDrop() != DefaultMaxDrop {
+		t.Error("the default max drop should be used. Got: ", manager.MaxDrop())
+	}
+}
+
+func TestManagerPeek(t *testing.T) {
+	list := newListMock(10)
+	list.items = append(list.items, "not json")
+	manager := NewManager(0, 5, NewRedisQueue("impressions", list, nil))
+
+	items, err := manager.Peek("impressions", 20, false)
+	if err != nil || len(items) != 5 {
+		t.Fatal("the number of items should be capped by the max peek. Got: ", err, len(items))
+	}
+
+	impression := items[0].(map[string]interface{})["i"].(map[string]interface{})
+	if impression["k"] != "key0" || impression["f"] != "feature" {
+		t.Error("items should be decoded as json. Got: ", impression)
+	}
+
+	items, _ = manager.Peek("impressions", 1, true)
+	decoded := items[0].(map[string]interface{})
+	impression = decoded["i"].(map[string]interface{})
+	if impression["k"] != redactedValue || impression["b"] != redactedValue || impression["f"] != "feature" {
+		t.Error("keys should be redacted. Got: ", impression)
+	}
+	if decoded["m"].(map[string]interface{})["s"] != "go-1.2.3" {
+		t.Error("non-key fields should be untouched. Got: ", decoded["m"])
+	}
+	if list.items[0] != `{"m":{"s":"go-1.2.3"},"i":{"k":"key0","b":"bk","f":"feature"}}` {
+		t.Error("peeking should not modify the queue")
+	}
+
+	manager = NewManager(0, 20, NewRedisQueue("impressions", list, nil))
+	items, _ = manager.Peek("impressions", 20, true)
+	if len(items) != 11 || items[10] != "not json" {
+		t.Error("invalid json items should be returned as strings. Got: ", items[len(items)-1])
+	}
+}
+
+func TestManagerDrop(t *testing.T) {
+	list := newListMock(10)
+	manager := NewManager(5, 0, NewRedisQueue("events", list, nil))
+
+	if _, err := manager.Drop("events", 0); !errors.Is(err, ErrDropTooLarge) || list.Count() != 10 {
+		t.Error("dropping the whole queue above the limit should be rejected. Got: ", err, list.Count())
+	}
+
+	if _, err := manager.Drop("events", 6); !errors.Is(err, ErrDropTooLarge) || list.Count() != 10 {
+		t.Error("dropping above the limit should be rejected. Got: ", err, list.Count())
+	}
+
+	dropped, err := manager.Drop("events", 4)
+	if err != nil || dropped != 4 || list.Count() != 6 || list.items[0] != newListMock(5).items[4] {
+		t.Error("the 4 oldest items should have been dropped. Got: ", err, dropped, list.Count())
+	}
+
+	list.items = list.items[:3]
+	if dropped, err := manager.Drop("events", 0); err != nil || dropped != 3 || list.Count() != 0 {
+		t.Error("the whole queue should have been dropped. Got: ", err, dropped, list.Count())
+	}
+
+	if _, err := manager.Drop("nonexistent", 1); !errors.Is(err, ErrQueueNotFound) {
+		t.Error("unknown queues should be reported. Got: ", err)
+	}
+}
+
+func TestRedactKeys(t *testing.T) {
+	item := map[string]interface{}{
+		"keys": []interface{}{
+			map[string]interface{}{"f": "feature", "ks": []interface{}{"key1", "key2"}},
+		},
+		"key":        "user",
+		"properties": map[string]interface{}{"plan": "gold"},
+	}
+
+	redactKeys(item)
+	unique := item["keys"].([]interface{})[0].(map[string]interface{})
+	if keys := unique["ks"].([]interface{}); keys[0] != redactedValue || keys[1] != redactedValue || unique["f"] != "feature" {
+		t.Error("unique keys should be redacted. Got: ", unique)
+	}
+	if item["key"] != redactedValue || item["properties"].(map[string]interface{})["plan"] != "gold" {
+		t.Error("event keys should be redacted. Got: ", item)
+	}
+}
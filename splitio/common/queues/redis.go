@@ -0,0 +1,79 @@
+package queues
+
+import (
+	"encoding/json"
+)
+
+// RawList is a redis list holding serialized items
+type RawList interface {
+	Count() int64
+	Peek(n int64) ([]string, error)
+	Drop(n int64) error
+}
+
+// RedisQueue adapts a redis list, decoding peeked items as json
+type RedisQueue struct {
+	name  string
+	list  RawList
+	flush func() error
+}
+
+// NewRedisQueue constructs a queue backed by a redis list. `flush` can be nil if items cannot be posted on demand
+func NewRedisQueue(name string, list RawList, flush func() error) Queue {
+	queue := &RedisQueue{name: name, list: list, flush: flush}
+	if flush == nil {
+		return queue
+	}
+	return &flushableRedisQueue{RedisQueue: queue}
+}
+
+// Name returns the name of the queue
+func (q *RedisQueue) Name() string {
+	return q.name
+}
+
+// Size returns the number of items in the list
+func (q *RedisQueue) Size() int64 {
+	return q.list.Count()
+}
+
+// Peek returns up to `n` items from the head of the list. Items that are not valid json are returned as strings
+func (q *RedisQueue) Peek(n int) ([]interface{}, error) {
+	raw, err := q.list.Peek(int64(n))
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]interface{}, 0, len(raw))
+	for _, current := range raw {
+		var item interface{}
+		if err := json.Unmarshal([]byte(current), &item); err != nil {
+			item = current
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Drop removes up to `n` of the oldest items in the list
+func (q *RedisQueue) Drop(n int64) (int64, error) {
+	if size := q.list.Count(); n > size {
+		n = size
+	}
+
+	if err := q.list.Drop(n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+type flushableRedisQueue struct {
+	*RedisQueue
+}
+
+func (q *flushableRedisQueue) Flush() error {
+	return q.flush()
+}
+
+var _ Queue = (*RedisQueue)(nil)
+var _ Flusher = (*flushableRedisQueue)(nil)
@@ -2,6 +2,7 @@ package common
 
 import (
 	"errors"
+	"io"
 	"os"
 	"os/signal"
 	"syscall"
@@ -41,6 +42,7 @@ type RuntimeImpl struct {
 	osSignals          chan os.Signal
 	appMonitor         application.MonitorIterface
 	servicesMonitor    services.MonitorIterface
+	closers            []io.Closer
 }
 
 // NewRuntime constructs a RuntimeImpl object
@@ -53,6 +55,7 @@ func NewRuntime(
 	slackWriter *log.SlackWriter,
 	appMonitor application.MonitorIterface,
 	servicesMonitor services.MonitorIterface,
+	closers ...io.Closer, // closed once the sync manager is stopped
 ) *RuntimeImpl {
 	return &RuntimeImpl{
 		proxy:              proxy,
@@ -67,6 +70,7 @@ func NewRuntime(
 		osSignals:          make(chan os.Signal, 1),
 		appMonitor:         appMonitor,
 		servicesMonitor:    servicesMonitor,
+		closers:            closers,
 	}
 }
 
@@ -102,6 +106,11 @@ func (r *RuntimeImpl) Shutdown() {
 		r.slackWriter.PostNow(message, attachments)
 	}
 	r.syncManager.Stop()
+	for _, c := range r.closers {
+		if err := c.Close(); err != nil {
+			r.logger.Error("error closing component on shutdown: ", err)
+		}
+	}
 	if r.impListener != nil {
 		r.impListener.Stop(true)
 	}
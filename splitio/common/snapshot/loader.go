@@ -0,0 +1,21 @@
+package snapshot
+
+import (
+	"errors"
+)
+
+// ErrOlderSnapshot is returned when trying to load a snapshot whose change number is older than the data currently served
+var ErrOlderSnapshot = errors.New("snapshot is older than the currently loaded data")
+
+// LoadResult summarizes the outcome of loading a snapshot into a running instance
+type LoadResult struct {
+	PreviousChangeNumber int64 `json:"previousChangeNumber"`
+	ChangeNumber         int64 `json:"changeNumber"`
+	Splits               int   `json:"splits"`
+	Segments             int   `json:"segments"`
+}
+
+// Loader is implemented by components capable of replacing the data currently being served with the one in a snapshot
+type Loader interface {
+	Load(snap *Snapshot, force bool) (*LoadResult, error)
+}
@@ -70,6 +70,9 @@ func (s *Snapshot) Meta() Metadata {
 // Data returns the unzipped Snapshot data
 func (s *Snapshot) Data() ([]byte, error) {
 	gz, err := gzip.NewReader(bytes.NewBuffer(s.data))
+	if err != nil {
+		return nil, fmt.Errorf("error building gzip reader: %w", err)
+	}
 	defer gz.Close()
 	data, err := ioutil.ReadAll(gz)
 	if err != nil {
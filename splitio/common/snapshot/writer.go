@@ -0,0 +1,274 @@
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/asynctask"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/storage"
+)
+
+// Name prefixes used for snapshots written by each app
+const (
+	ProxyPrefix = "split.proxy"
+	SyncPrefix  = "split.sync"
+)
+
+const (
+	defaultRetention = 5
+	snapshotSuffix   = ".snapshot"
+	tmpSuffix        = ".tmp"
+)
+
+// ErrNoSnapshotFound is returned when a directory is scanned for snapshots and none is valid
+var ErrNoSnapshotFound = errors.New("no valid snapshot found in directory")
+
+var snapshotNameRegex = regexp.MustCompile(`^(.+)\.(-?\d+)\.(\d+)\.snapshot$`)
+
+// FileInfo describes a snapshot file written by the periodic writer
+type FileInfo struct {
+	Path         string
+	ChangeNumber int64
+	Timestamp    time.Time
+	ModTime      time.Time
+}
+
+// WriterConfig bundles the options used to build a periodic snapshot writer
+type WriterConfig struct {
+	Logger       logging.LoggerInterface
+	Source       storage.Snapshotter
+	ChangeNumber func() int64
+	Directory    string
+	Prefix       string
+	Retention    int
+}
+
+func (c *WriterConfig) normalize() {
+	if c.Retention <= 0 {
+		c.Retention = defaultRetention
+	}
+
+	if c.Prefix == "" {
+		c.Prefix = ProxyPrefix
+	}
+}
+
+// Writer dumps the contents of a snapshotter into a directory and rotates older snapshots
+type Writer struct {
+	logger       logging.LoggerInterface
+	source       storage.Snapshotter
+	changeNumber func() int64
+	directory    string
+	prefix       string
+	retention    int
+}
+
+// NewWriter constructs a new snapshot writer
+func NewWriter(cfg *WriterConfig) (*Writer, error) {
+	cfg.normalize()
+	if cfg.Source == nil {
+		return nil, errors.New("a snapshot source is required")
+	}
+
+	if err := os.MkdirAll(cfg.Directory, 0755); err != nil {
+		return nil, fmt.Errorf("error creating snapshot directory '%s': %w", cfg.Directory, err)
+	}
+
+	return &Writer{
+		logger:       cfg.Logger,
+		source:       cfg.Source,
+		changeNumber: cfg.ChangeNumber,
+		directory:    cfg.Directory,
+		prefix:       cfg.Prefix,
+		retention:    cfg.Retention,
+	}, nil
+}
+
+// Write dumps a new snapshot into the configured directory and removes the ones exceeding the retention count.
+// The file is written under a temporary name and then renamed, so that a partially written snapshot is never picked up.
+func (w *Writer) Write() (*FileInfo, error) {
+	raw, err := w.source.GetRawSnapshot()
+	if err != nil {
+		return nil, fmt.Errorf("error reading data for snapshot: %w", err)
+	}
+
+	snap, err := New(Metadata{Version: 1, Storage: StorageBoltDB}, raw)
+	if err != nil {
+		return nil, fmt.Errorf("error building snapshot: %w", err)
+	}
+
+	encoded, err := snap.Encode()
+	if err != nil {
+		return nil, fmt.Errorf("error encoding snapshot: %w", err)
+	}
+
+	var cn int64 = -1
+	if w.changeNumber != nil {
+		cn = w.changeNumber()
+	}
+
+	now := time.Now()
+	path := filepath.Join(w.directory, fmt.Sprintf("%s.%d.%d%s", w.prefix, cn, now.UnixNano(), snapshotSuffix))
+	if err := writeAtomically(path, encoded); err != nil {
+		return nil, err
+	}
+
+	w.rotate()
+	return &FileInfo{Path: path, ChangeNumber: cn, Timestamp: now}, nil
+}
+
+// List returns the snapshots currently present in the directory, newest first
+func (w *Writer) List() ([]FileInfo, error) {
+	return listSnapshots(w.directory, w.prefix)
+}
+
+func (w *Writer) rotate() {
+	all, err := w.List()
+	if err != nil {
+		w.logger.Error("error listing snapshots for rotation: ", err)
+		return
+	}
+
+	for idx := w.retention; idx < len(all); idx++ {
+		w.logger.Debug("removing old snapshot ", all[idx].Path)
+		if err := os.Remove(all[idx].Path); err != nil {
+			w.logger.Error(fmt.Sprintf("error removing old snapshot '%s': %s", all[idx].Path, err))
+		}
+	}
+}
+
+// NewPeriodicWriterTask builds an async task that writes a snapshot every `period` seconds
+func NewPeriodicWriterTask(writer *Writer, logger logging.LoggerInterface, period int) *asynctask.AsyncTask {
+	doWork := func(l logging.LoggerInterface) error {
+		info, err := writer.Write()
+		if err != nil {
+			return err
+		}
+		l.Info(fmt.Sprintf("snapshot with changeNumber %d written to %s", info.ChangeNumber, info.Path))
+		return nil
+	}
+	return asynctask.NewAsyncTask("periodic-snapshot", doWork, period, nil, nil, logger)
+}
+
+// IsDirectory returns true if the supplied path exists and is a directory
+func IsDirectory(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return info.IsDir()
+}
+
+// ListInDir returns the snapshots found in a directory, newest first, without decoding them
+func ListInDir(dir string) ([]FileInfo, error) {
+	return listSnapshots(dir, "")
+}
+
+// LatestInDir scans a directory and returns the path of the newest snapshot that can be properly decoded.
+// Files that are corrupt or have an unknown storage type are skipped.
+func LatestInDir(dir string, logger logging.LoggerInterface) (string, error) {
+	all, err := listSnapshots(dir, "")
+	if err != nil {
+		return "", err
+	}
+
+	for _, candidate := range all {
+		if err := validateFile(candidate.Path); err != nil {
+			logger.Warning(fmt.Sprintf("skipping invalid snapshot '%s': %s", candidate.Path, err))
+			continue
+		}
+		return candidate.Path, nil
+	}
+	return "", ErrNoSnapshotFound
+}
+
+func validateFile(path string) error {
+	snap, err := DecodeFromFile(path)
+	if err != nil {
+		return err
+	}
+	return Validate(snap)
+}
+
+// Validate checks that the snapshot metadata is supported and that the data can be decompressed
+func Validate(snap *Snapshot) error {
+	if snap.Meta().Storage != StorageBoltDB {
+		return fmt.Errorf("unknown storage type %d", snap.Meta().Storage)
+	}
+
+	if _, err := snap.Data(); err != nil {
+		return fmt.Errorf("error reading snapshot data: %w", err)
+	}
+	return nil
+}
+
+func listSnapshots(dir string, prefix string) ([]FileInfo, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading snapshot directory '%s': %w", dir, err)
+	}
+
+	toReturn := make([]FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		matches := snapshotNameRegex.FindStringSubmatch(entry.Name())
+		if matches == nil || (prefix != "" && matches[1] != prefix) {
+			continue
+		}
+
+		cn, _ := strconv.ParseInt(matches[2], 10, 64)
+		ts, _ := strconv.ParseInt(matches[3], 10, 64)
+		toReturn = append(toReturn, FileInfo{
+			Path:         filepath.Join(dir, entry.Name()),
+			ChangeNumber: cn,
+			Timestamp:    time.Unix(0, ts),
+			ModTime:      entry.ModTime(),
+		})
+	}
+
+	sort.Slice(toReturn, func(i, j int) bool { return toReturn[i].Timestamp.After(toReturn[j].Timestamp) })
+	return toReturn, nil
+}
+
+func writeAtomically(path string, data []byte) error {
+	tmpPath := path + tmpSuffix
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("error creating temporary snapshot file: %w", err)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("error writing temporary snapshot file: %w", err)
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("error flushing temporary snapshot file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error closing temporary snapshot file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error moving snapshot into place: %w", err)
+	}
+	return nil
+}
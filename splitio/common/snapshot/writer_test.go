@@ -0,0 +1,104 @@
+package snapshot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+type snapshotterMock struct {
+	data []byte
+}
+
+func (s *snapshotterMock) GetRawSnapshot() ([]byte, error) {
+	return s.data, nil
+}
+
+func TestWriterRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshots")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	var cn int64
+	writer, err := NewWriter(&WriterConfig{
+		Logger:       logging.NewLogger(nil),
+		Source:       &snapshotterMock{data: []byte("some data")},
+		ChangeNumber: func() int64 { cn++; return cn },
+		Directory:    dir,
+		Retention:    3,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := writer.Write(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	all, err := writer.List()
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(all) != 3 {
+		t.Error("there should be 3 snapshots. Got: ", len(all))
+	}
+
+	if all[0].ChangeNumber != 5 || all[2].ChangeNumber != 3 {
+		t.Error("the newest snapshots should have been kept. Got: ", all)
+	}
+
+	entries, _ := ioutil.ReadDir(dir)
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) == tmpSuffix {
+			t.Error("no temporary files should be left behind: ", entry.Name())
+		}
+	}
+}
+
+func TestLatestInDirSkipsCorrupt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshots")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := LatestInDir(dir, logging.NewLogger(nil)); err != ErrNoSnapshotFound {
+		t.Error("an empty dir should return ErrNoSnapshotFound. Got: ", err)
+	}
+
+	writer, _ := NewWriter(&WriterConfig{
+		Logger:       logging.NewLogger(nil),
+		Source:       &snapshotterMock{data: []byte("some data")},
+		ChangeNumber: func() int64 { return 123 },
+		Directory:    dir,
+	})
+	valid, err := writer.Write()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// a newer file with garbage in it
+	corrupt := filepath.Join(dir, "split.proxy.456.99999999999999999.snapshot")
+	ioutil.WriteFile(corrupt, []byte("garbage"), 0644)
+
+	latest, err := LatestInDir(dir, logging.NewLogger(nil))
+	if err != nil {
+		t.Error(err)
+	}
+
+	if latest != valid.Path {
+		t.Error("the corrupt snapshot should have been skipped. Got: ", latest)
+	}
+}
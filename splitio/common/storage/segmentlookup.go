@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/splitio/go-split-commons/v4/storage"
+	"github.com/splitio/go-toolkit/v5/datastructures/cache"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+const (
+	defaultLookupConcurrency = 10
+	defaultLookupCacheSize   = 1000
+	defaultLookupCacheTTL    = 30 * time.Second
+)
+
+// SegmentLookup answers which segments contain a certain key
+type SegmentLookup interface {
+	SegmentsForKey(key string) ([]string, error)
+}
+
+// segmentIndex is implemented by storages that keep an inverted key -> segments index (ie: the proxy's)
+type segmentIndex interface {
+	SegmentsFor(key string) ([]string, error)
+}
+
+// SegmentLookupImpl checks every segment referenced by splits in parallel and caches the results for a short period,
+// unless the segment storage provides an inverted index, in which case it's used directly
+type SegmentLookupImpl struct {
+	splits      storage.SplitStorageConsumer
+	segments    storage.SegmentStorageConsumer
+	concurrency int
+	cache       cache.LocalCache
+	logger      logging.LoggerInterface
+}
+
+// NewSegmentLookup constructs a new segment lookup component
+func NewSegmentLookup(
+	splits storage.SplitStorageConsumer,
+	segments storage.SegmentStorageConsumer,
+	logger logging.LoggerInterface,
+) *SegmentLookupImpl {
+	// can only fail with a non-positive size
+	c, _ := cache.NewLocalCache(defaultLookupCacheSize, defaultLookupCacheTTL)
+	return &SegmentLookupImpl{
+		splits:      splits,
+		segments:    segments,
+		concurrency: defaultLookupConcurrency,
+		cache:       c,
+		logger:      logger,
+	}
+}
+
+// SegmentsForKey returns the (sorted) names of the segments the key belongs to
+func (l *SegmentLookupImpl) SegmentsForKey(key string) ([]string, error) {
+	if index, ok := l.segments.(segmentIndex); ok {
+		found, err := index.SegmentsFor(key)
+		if err != nil {
+			return nil, fmt.Errorf("error querying segment index: %w", err)
+		}
+		sort.Strings(found)
+		return found, nil
+	}
+
+	if cached, err := l.cache.Get(key); err == nil {
+		if asSlice, ok := cached.([]string); ok {
+			return asSlice, nil
+		}
+	}
+
+	found, err := l.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+
+	l.cache.Set(key, found)
+	return found, nil
+}
+
+func (l *SegmentLookupImpl) lookup(key string) ([]string, error) {
+	names := l.splits.SegmentNames()
+	if names == nil {
+		return []string{}, nil
+	}
+
+	pending := make(chan string, names.Size())
+	for _, name := range names.List() {
+		if asStr, ok := name.(string); ok {
+			pending <- asStr
+		}
+	}
+	close(pending)
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	var errs []error
+	found := make([]string, 0)
+	for idx := 0; idx < l.concurrency; idx++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for segment := range pending {
+				contained, err := l.segments.SegmentContainsKey(segment, key)
+				mutex.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("segment '%s': %w", segment, err))
+				} else if contained {
+					found = append(found, segment)
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		// partial results are not cached nor returned, since they could be misleading
+		return nil, fmt.Errorf("error checking %d segments. first error: %w", len(errs), errs[0])
+	}
+
+	sort.Strings(found)
+	return found, nil
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/storage/inmemory/mutexmap"
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+type countingSegmentStorage struct {
+	*mutexmap.MMSegmentStorage
+	calls int64
+	fail  bool
+}
+
+func (s *countingSegmentStorage) SegmentContainsKey(segmentName string, key string) (bool, error) {
+	atomic.AddInt64(&s.calls, 1)
+	if s.fail {
+		return false, errors.New("some error")
+	}
+	return s.MMSegmentStorage.SegmentContainsKey(segmentName, key)
+}
+
+type indexedSegmentStorage struct {
+	*mutexmap.MMSegmentStorage
+}
+
+func (s *indexedSegmentStorage) SegmentsFor(key string) ([]string, error) {
+	return []string{"s2", "s1"}, nil
+}
+
+func inSegment(name string) dtos.ConditionDTO {
+	return dtos.ConditionDTO{MatcherGroup: dtos.MatcherGroupDTO{Matchers: []dtos.MatcherDTO{{
+		MatcherType:        "IN_SEGMENT",
+		UserDefinedSegment: &dtos.UserDefinedSegmentMatcherDataDTO{SegmentName: name},
+	}}}}
+}
+
+func TestSegmentLookup(t *testing.T) {
+	splits := mutexmap.NewMMSplitStorage()
+	splits.Update([]dtos.SplitDTO{
+		{Name: "split1", Conditions: []dtos.ConditionDTO{inSegment("s1"), inSegment("s2")}},
+		{Name: "split2", Conditions: []dtos.ConditionDTO{inSegment("s3")}},
+	}, nil, 1)
+
+	segments := &countingSegmentStorage{MMSegmentStorage: mutexmap.NewMMSegmentStorage()}
+	segments.Update("s1", set.NewSet("key1", "key2"), set.NewSet(), 1)
+	segments.Update("s2", set.NewSet("key2"), set.NewSet(), 1)
+	segments.Update("s3", set.NewSet("key1"), set.NewSet(), 1)
+
+	lookup := NewSegmentLookup(splits, segments, logging.NewLogger(nil))
+	found, err := lookup.SegmentsForKey("key1")
+	if err != nil || len(found) != 2 || found[0] != "s1" || found[1] != "s3" {
+		t.Error("wrong segments for key1: ", found, err)
+	}
+
+	if calls := atomic.LoadInt64(&segments.calls); calls != 3 {
+		t.Error("every segment should be checked once. Got: ", calls)
+	}
+
+	lookup.SegmentsForKey("key1")
+	if calls := atomic.LoadInt64(&segments.calls); calls != 3 {
+		t.Error("results should be cached. Got: ", calls)
+	}
+
+	segments.fail = true
+	if _, err := lookup.SegmentsForKey("key2"); err == nil {
+		t.Error("errors should be propagated")
+	}
+
+	indexed := NewSegmentLookup(splits, &indexedSegmentStorage{mutexmap.NewMMSegmentStorage()}, logging.NewLogger(nil))
+	if found, _ := indexed.SegmentsForKey("key1"); len(found) != 2 || found[0] != "s1" {
+		t.Error("the inverted index should be used when available. Got: ", found)
+	}
+}
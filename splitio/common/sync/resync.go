@@ -0,0 +1,287 @@
+package sync
+
+import (
+	"fmt"
+	gosync "sync"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/service"
+	"github.com/splitio/go-split-commons/v4/storage"
+	"github.com/splitio/go-split-commons/v4/synchronizer/worker/segment"
+	"github.com/splitio/go-split-commons/v4/synchronizer/worker/split"
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+// SplitsResyncResult contains the outcome of a forced split sync
+type SplitsResyncResult struct {
+	PreviousChangeNumber int64    `json:"previousChangeNumber"`
+	ChangeNumber         int64    `json:"changeNumber"`
+	UpdatedSplits        []string `json:"updatedSplits"`
+	RemovedSplits        []string `json:"removedSplits,omitempty"`
+}
+
+// SegmentResyncResult contains the outcome of a forced segment sync
+type SegmentResyncResult struct {
+	Name                 string `json:"name"`
+	PreviousChangeNumber int64  `json:"previousChangeNumber"`
+	ChangeNumber         int64  `json:"changeNumber"`
+	UpdatedKeys          int    `json:"updatedKeys"`
+	RemovedKeys          int    `json:"removedKeys,omitempty"`
+	Error                string `json:"error,omitempty"`
+}
+
+// RebuildResult contains the outcome of a full rebuild
+type RebuildResult struct {
+	Splits   *SplitsResyncResult   `json:"splits"`
+	Segments []SegmentResyncResult `json:"segments"`
+}
+
+// Resyncer defines the interface for triggering on-demand synchronizations
+type Resyncer interface {
+	SyncSplits() (*SplitsResyncResult, error)
+	SyncSegment(name string) (*SegmentResyncResult, error)
+	Rebuild() (*RebuildResult, error)
+}
+
+// segment storages that keep track of removed keys (ie: the proxy's) expose the active ones separately
+type activeKeysProvider interface {
+	ActiveKeys(segmentName string) *set.ThreadUnsafeSet
+}
+
+// split storages serving sdks (ie: the proxy's) swap their whole content at once when rebuilt,
+// under a change number that sdks on any previously served one pick up
+type splitRebuilder interface {
+	Rebuild(splits []dtos.SplitDTO, changeNumber int64)
+}
+
+// ResyncerImpl forces synchronizations through the same updaters used by the periodic tasks,
+// so that any wrapping logic (ie: cache eviction in the proxy) is honored. Rebuilds fetch from scratch
+// without touching the storages, and apply the result at once when the fetch is complete
+type ResyncerImpl struct {
+	splitUpdater   split.Updater
+	segmentUpdater segment.Updater
+	splitFetcher   service.SplitFetcher
+	segmentFetcher service.SegmentFetcher
+	splitStorage   storage.SplitStorage
+	segmentStorage storage.SegmentStorage
+	onRebuilt      func()
+	logger         logging.LoggerInterface
+	mutex          gosync.Mutex
+}
+
+// NewResyncer constructs a new resyncer. `onRebuilt` (if any) is invoked after a rebuild replaces data,
+// since rebuilds bypass the updaters (ie: to evict http caches)
+func NewResyncer(
+	splitUpdater split.Updater,
+	segmentUpdater segment.Updater,
+	splitFetcher service.SplitFetcher,
+	segmentFetcher service.SegmentFetcher,
+	splitStorage storage.SplitStorage,
+	segmentStorage storage.SegmentStorage,
+	onRebuilt func(),
+	logger logging.LoggerInterface,
+) *ResyncerImpl {
+	return &ResyncerImpl{
+		splitUpdater:   splitUpdater,
+		segmentUpdater: segmentUpdater,
+		splitFetcher:   splitFetcher,
+		segmentFetcher: segmentFetcher,
+		splitStorage:   splitStorage,
+		segmentStorage: segmentStorage,
+		onRebuilt:      onRebuilt,
+		logger:         logger,
+	}
+}
+
+// SyncSplits fetches split changes since the current change number
+func (r *ResyncerImpl) SyncSplits() (*SplitsResyncResult, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	previous, _ := r.splitStorage.ChangeNumber()
+	result, err := r.splitUpdater.SynchronizeSplits(nil)
+	return splitsResult(previous, result), err
+}
+
+// SyncSegment fetches changes for a segment since its current change number
+func (r *ResyncerImpl) SyncSegment(name string) (*SegmentResyncResult, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	previous, _ := r.segmentStorage.ChangeNumber(name)
+	result, err := r.segmentUpdater.SynchronizeSegment(name, nil)
+	return segmentResult(name, previous, result), err
+}
+
+// Rebuild fetches all splits & segments from scratch (since=-1). Splits & keys that are not present
+// in the fresh payloads are removed, since the -1 fetch only returns active items
+func (r *ResyncerImpl) Rebuild() (*RebuildResult, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.onRebuilt != nil {
+		defer r.onRebuilt()
+	}
+
+	splits, err := r.rebuildSplits()
+	if err != nil {
+		return &RebuildResult{Splits: splits}, fmt.Errorf("error rebuilding splits: %w", err)
+	}
+
+	segmentNames := r.splitStorage.SegmentNames().List()
+	segments := make([]SegmentResyncResult, 0, len(segmentNames))
+	failed := 0
+	for _, raw := range segmentNames {
+		name, ok := raw.(string)
+		if !ok {
+			continue
+		}
+
+		result, err := r.rebuildSegment(name)
+		if err != nil {
+			r.logger.Error(fmt.Sprintf("error rebuilding segment '%s': %s", name, err))
+			result.Error = err.Error()
+			failed++
+		}
+		segments = append(segments, *result)
+	}
+
+	if failed > 0 {
+		return &RebuildResult{Splits: splits, Segments: segments}, fmt.Errorf("%d segments failed to be rebuilt", failed)
+	}
+	return &RebuildResult{Splits: splits, Segments: segments}, nil
+}
+
+func (r *ResyncerImpl) rebuildSplits() (*SplitsResyncResult, error) {
+	previous, _ := r.splitStorage.ChangeNumber()
+	toReturn := &SplitsResyncResult{PreviousChangeNumber: previous, ChangeNumber: previous}
+	fetched, till, err := r.fetchSplits()
+	if err != nil {
+		return toReturn, err
+	}
+
+	active := make([]dtos.SplitDTO, 0, len(fetched))
+	incoming := make(map[string]struct{}, len(fetched))
+	for _, split := range fetched {
+		if split.Status == "ACTIVE" {
+			active = append(active, split)
+			incoming[split.Name] = struct{}{}
+		}
+	}
+
+	toRemove := make([]dtos.SplitDTO, 0)
+	for _, name := range r.splitStorage.SplitNames() {
+		if _, ok := incoming[name]; !ok {
+			toRemove = append(toRemove, dtos.SplitDTO{Name: name, Status: "ARCHIVED", ChangeNumber: till})
+			toReturn.RemovedSplits = append(toReturn.RemovedSplits, name)
+		}
+	}
+
+	if rebuilder, ok := r.splitStorage.(splitRebuilder); ok {
+		rebuilder.Rebuild(active, till)
+	} else {
+		r.splitStorage.Update(active, toRemove, till)
+	}
+
+	toReturn.ChangeNumber = till
+	for _, split := range active {
+		toReturn.UpdatedSplits = append(toReturn.UpdatedSplits, split.Name)
+	}
+	return toReturn, nil
+}
+
+// fetchSplits fetches all splits from scratch, following the change numbers until the payload is up to date
+func (r *ResyncerImpl) fetchSplits() (map[string]dtos.SplitDTO, int64, error) {
+	splits := make(map[string]dtos.SplitDTO)
+	since := int64(-1)
+	for {
+		changes, err := r.splitFetcher.Fetch(since, &service.FetchOptions{CacheControlHeaders: true})
+		if err != nil {
+			return nil, since, err
+		}
+
+		for _, split := range changes.Splits {
+			splits[split.Name] = split
+		}
+
+		if changes.Till == since {
+			return splits, since, nil
+		}
+		since = changes.Till
+	}
+}
+
+func (r *ResyncerImpl) rebuildSegment(name string) (*SegmentResyncResult, error) {
+	previous, _ := r.segmentStorage.ChangeNumber(name)
+	toReturn := &SegmentResyncResult{Name: name, PreviousChangeNumber: previous, ChangeNumber: previous}
+	toAdd, till, err := r.fetchSegment(name)
+	if err != nil {
+		return toReturn, err
+	}
+
+	toRemove := r.activeKeys(name)
+	if toRemove == nil {
+		toRemove = set.NewSet()
+	}
+	for _, key := range toAdd.List() {
+		toRemove.Remove(key)
+	}
+
+	if err := r.segmentStorage.Update(name, toAdd, toRemove, till); err != nil {
+		return toReturn, fmt.Errorf("error replacing keys: %w", err)
+	}
+
+	toReturn.ChangeNumber = till
+	toReturn.UpdatedKeys = toAdd.Size()
+	toReturn.RemovedKeys = toRemove.Size()
+	return toReturn, nil
+}
+
+// fetchSegment fetches the active keys of a segment from scratch, following the change numbers until the payload is up to date
+func (r *ResyncerImpl) fetchSegment(name string) (*set.ThreadUnsafeSet, int64, error) {
+	keys := set.NewSet()
+	since := int64(-1)
+	for {
+		changes, err := r.segmentFetcher.Fetch(name, since, &service.FetchOptions{CacheControlHeaders: true})
+		if err != nil {
+			return nil, since, err
+		}
+
+		for _, key := range changes.Added {
+			keys.Add(key)
+		}
+		for _, key := range changes.Removed {
+			keys.Remove(key)
+		}
+
+		if changes.Till == since {
+			return keys, since, nil
+		}
+		since = changes.Till
+	}
+}
+
+func (r *ResyncerImpl) activeKeys(name string) *set.ThreadUnsafeSet {
+	if provider, ok := r.segmentStorage.(activeKeysProvider); ok {
+		return provider.ActiveKeys(name)
+	}
+	return r.segmentStorage.Keys(name)
+}
+
+func splitsResult(previous int64, result *split.UpdateResult) *SplitsResyncResult {
+	toReturn := &SplitsResyncResult{PreviousChangeNumber: previous, ChangeNumber: previous}
+	if result != nil {
+		toReturn.ChangeNumber = result.NewChangeNumber
+		toReturn.UpdatedSplits = result.UpdatedSplits
+	}
+	return toReturn
+}
+
+func segmentResult(name string, previous int64, result *segment.UpdateResult) *SegmentResyncResult {
+	toReturn := &SegmentResyncResult{Name: name, PreviousChangeNumber: previous, ChangeNumber: previous}
+	if result != nil {
+		toReturn.ChangeNumber = result.NewChangeNumber
+		toReturn.UpdatedKeys = len(result.UpdatedKeys)
+	}
+	return toReturn
+}
+
+var _ Resyncer = (*ResyncerImpl)(nil)
@@ -0,0 +1,145 @@
+package sync
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/service"
+	"github.com/splitio/go-split-commons/v4/storage"
+	"github.com/splitio/go-split-commons/v4/storage/inmemory/mutexmap"
+	"github.com/splitio/go-split-commons/v4/synchronizer/worker/segment"
+	"github.com/splitio/go-split-commons/v4/synchronizer/worker/split"
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+type splitUpdaterMock struct {
+	storage storage.SplitStorage
+	since   []int64
+	fail    bool
+}
+
+func (m *splitUpdaterMock) SynchronizeSplits(till *int64) (*split.UpdateResult, error) {
+	since, _ := m.storage.ChangeNumber()
+	m.since = append(m.since, since)
+	if m.fail {
+		return &split.UpdateResult{NewChangeNumber: since}, errors.New("some error")
+	}
+	m.storage.Update([]dtos.SplitDTO{{Name: "split1", Status: "ACTIVE", ChangeNumber: 10}}, nil, 10)
+	return &split.UpdateResult{UpdatedSplits: []string{"split1"}, NewChangeNumber: 10}, nil
+}
+
+func (m *splitUpdaterMock) LocalKill(splitName string, defaultTreatment string, changeNumber int64) {}
+
+type segmentUpdaterMock struct {
+	storage storage.SegmentStorage
+}
+
+func (m *segmentUpdaterMock) SynchronizeSegment(name string, till *int64) (*segment.UpdateResult, error) {
+	m.storage.Update(name, set.NewSet("key1"), set.NewSet(), 20)
+	return &segment.UpdateResult{UpdatedKeys: []string{"key1"}, NewChangeNumber: 20}, nil
+}
+
+func (m *segmentUpdaterMock) SynchronizeSegments() (map[string]segment.UpdateResult, error) {
+	return nil, nil
+}
+func (m *segmentUpdaterMock) SegmentNames() []interface{}             { return nil }
+func (m *segmentUpdaterMock) IsSegmentCached(segmentName string) bool { return true }
+
+type splitFetcherMock struct {
+	storage storage.SplitStorage
+	since   []int64
+	seenCN  []int64
+	fail    bool
+}
+
+func (m *splitFetcherMock) Fetch(changeNumber int64, fetchOptions *service.FetchOptions) (*dtos.SplitChangesDTO, error) {
+	current, _ := m.storage.ChangeNumber()
+	m.since = append(m.since, changeNumber)
+	m.seenCN = append(m.seenCN, current)
+	if m.fail {
+		return nil, errors.New("some error")
+	}
+	if changeNumber == -1 {
+		return &dtos.SplitChangesDTO{Since: -1, Till: 10, Splits: []dtos.SplitDTO{{Name: "split1", Status: "ACTIVE", ChangeNumber: 10}}}, nil
+	}
+	return &dtos.SplitChangesDTO{Since: changeNumber, Till: changeNumber}, nil
+}
+
+type segmentFetcherMock struct{}
+
+func (m *segmentFetcherMock) Fetch(name string, changeNumber int64, fetchOptions *service.FetchOptions) (*dtos.SegmentChangesDTO, error) {
+	switch changeNumber {
+	case -1:
+		return &dtos.SegmentChangesDTO{Name: name, Since: -1, Till: 18, Added: []string{"key1", "key2"}}, nil
+	case 18:
+		return &dtos.SegmentChangesDTO{Name: name, Since: 18, Till: 20, Removed: []string{"key2"}}, nil
+	}
+	return &dtos.SegmentChangesDTO{Name: name, Since: changeNumber, Till: changeNumber}, nil
+}
+
+func TestResyncerRebuild(t *testing.T) {
+	splits := mutexmap.NewMMSplitStorage()
+	segments := mutexmap.NewMMSegmentStorage()
+	splits.Update([]dtos.SplitDTO{
+		{Name: "split1", Status: "ACTIVE", ChangeNumber: 5, Conditions: []dtos.ConditionDTO{{
+			MatcherGroup: dtos.MatcherGroupDTO{Matchers: []dtos.MatcherDTO{{
+				MatcherType: "IN_SEGMENT", UserDefinedSegment: &dtos.UserDefinedSegmentMatcherDataDTO{SegmentName: "segment1"},
+			}}},
+		}}},
+		{Name: "stale", Status: "ACTIVE", ChangeNumber: 5},
+	}, nil, 5)
+	segments.Update("segment1", set.NewSet("key1", "stale_key"), set.NewSet(), 15)
+
+	splitFetcher := &splitFetcherMock{storage: splits}
+	rebuilt := 0
+	resyncer := NewResyncer(&splitUpdaterMock{storage: splits}, &segmentUpdaterMock{storage: segments}, splitFetcher, &segmentFetcherMock{},
+		splits, segments, func() { rebuilt++ }, logging.NewLogger(nil))
+
+	result, err := resyncer.Rebuild()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(splitFetcher.since) != 2 || splitFetcher.since[0] != -1 || splitFetcher.since[1] != 10 {
+		t.Error("splits should have been fetched from -1 until up to date. Got: ", splitFetcher.since)
+	}
+
+	if splitFetcher.seenCN[0] != 5 || splitFetcher.seenCN[1] != 5 {
+		t.Error("the storage should not be touched while fetching. Got: ", splitFetcher.seenCN)
+	}
+
+	if result.Splits.PreviousChangeNumber != 5 || result.Splits.ChangeNumber != 10 || rebuilt != 1 {
+		t.Error("wrong change numbers: ", result.Splits)
+	}
+
+	if len(result.Splits.RemovedSplits) != 1 || result.Splits.RemovedSplits[0] != "stale" || splits.Split("stale") != nil {
+		t.Error("splits not returned by the -1 fetch should be removed. Got: ", result.Splits.RemovedSplits)
+	}
+
+	splitFetcher.fail = true
+	if _, err := resyncer.Rebuild(); err == nil {
+		t.Error("the error should be propagated")
+	}
+
+	if cn, _ := splits.ChangeNumber(); cn != 10 || splits.Split("split1") == nil {
+		t.Error("the storage should be left untouched after a failure. Got: ", cn)
+	}
+
+	// the mocked fetch replaces split1 with a version that references no segments, so we rebuild it directly
+	segmentResult, err := resyncer.rebuildSegment("segment1")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if segmentResult.PreviousChangeNumber != 15 || segmentResult.ChangeNumber != 20 || segmentResult.RemovedKeys != 1 {
+		t.Error("wrong segment result: ", segmentResult)
+	}
+
+	if keys := segments.Keys("segment1"); keys.Size() != 1 || !keys.Has("key1") {
+		t.Error("stale keys should have been removed. Got: ", keys.List())
+	}
+}
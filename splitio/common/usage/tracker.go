@@ -0,0 +1,204 @@
+package usage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+)
+
+// number of buckets the rolling window is split into
+const bucketCount = 60
+
+// Key identifies an impression counter
+type Key struct {
+	SDK       string
+	Flag      string
+	Treatment string
+}
+
+// Batch accumulates impression counts so that they can be recorded at once
+type Batch map[Key]int64
+
+// Add increments the counter for an sdk/flag/treatment combination
+func (b Batch) Add(sdk string, flag string, treatment string, count int64) {
+	b[Key{SDK: sdk, Flag: flag, Treatment: treatment}] += count
+}
+
+// AddImpressions counts the impressions in a bulk posted by an sdk
+func (b Batch) AddImpressions(metadata *dtos.Metadata, impressions []dtos.ImpressionsDTO) {
+	for _, group := range impressions {
+		for _, impression := range group.KeyImpressions {
+			b.Add(metadata.SDKVersion, group.TestName, impression.Treatment, 1)
+		}
+	}
+}
+
+// FlagUsage contains the impressions counted for a flag within the rolling window
+type FlagUsage struct {
+	Flag       string           `json:"flag"`
+	Total      int64            `json:"total"`
+	Treatments map[string]int64 `json:"treatments"`
+	SDKs       map[string]int64 `json:"sdks"`
+	LastSeen   int64            `json:"lastSeen"`
+}
+
+// StaleFlag is a flag with no impressions within the stale window
+type StaleFlag struct {
+	Name     string `json:"name"`
+	LastSeen *int64 `json:"lastSeen"`
+}
+
+// StaleReport lists stale flags. Complete is false if the tracker has been running for less time than the window,
+// in which case some flags might be reported as stale just because they were not used since startup
+type StaleReport struct {
+	WindowMs      int64       `json:"windowMs"`
+	TrackingSince int64       `json:"trackingSince"`
+	Complete      bool        `json:"complete"`
+	Flags         []StaleFlag `json:"flags"`
+}
+
+type bucket struct {
+	start  int64
+	counts map[Key]int64
+}
+
+// Tracker keeps rolling impression counters per flag, treatment & sdk, along with the last time each flag was seen
+type Tracker struct {
+	window      time.Duration
+	staleWindow time.Duration
+	bucketSize  int64
+	buckets     [bucketCount]bucket
+	lastSeen    map[string]int64
+	startedAt   int64
+	now         func() time.Time
+	mutex       sync.Mutex
+}
+
+// NewTracker constructs a new usage tracker. Counters cover the supplied window, while flags are considered stale
+// if no impressions are seen for them during `staleWindow`
+func NewTracker(window time.Duration, staleWindow time.Duration) *Tracker {
+	return newTracker(window, staleWindow, time.Now)
+}
+
+func newTracker(window time.Duration, staleWindow time.Duration, now func() time.Time) *Tracker {
+	bucketSize := int64(window/time.Millisecond) / bucketCount
+	if bucketSize < 1 {
+		bucketSize = 1
+	}
+	return &Tracker{
+		window:      window,
+		staleWindow: staleWindow,
+		bucketSize:  bucketSize,
+		lastSeen:    make(map[string]int64),
+		startedAt:   toMillis(now()),
+		now:         now,
+	}
+}
+
+// Record adds the counts in the batch to the current bucket
+func (t *Tracker) Record(batch Batch) {
+	if len(batch) == 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := toMillis(t.now())
+	start := now - now%t.bucketSize
+	current := &t.buckets[(start/t.bucketSize)%bucketCount]
+	if current.start != start || current.counts == nil {
+		current.start = start
+		current.counts = make(map[Key]int64, len(batch))
+	}
+
+	for key, count := range batch {
+		current.counts[key] += count
+		t.lastSeen[key.Flag] = now
+	}
+}
+
+// Seen marks flags as evaluated without adding to their counters. It's used for impression counts
+// (ie: from sdks in `none` mode), which don't carry treatments but still show that a flag is in use
+func (t *Tracker) Seen(flags ...string) {
+	if len(flags) == 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	now := toMillis(t.now())
+	for _, flag := range flags {
+		t.lastSeen[flag] = now
+	}
+}
+
+// Usage returns the counters for every flag with impressions in the rolling window, sorted by volume
+func (t *Tracker) Usage() []FlagUsage {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	oldest := toMillis(t.now()) - int64(t.window/time.Millisecond)
+	byFlag := make(map[string]*FlagUsage)
+	for idx := range t.buckets {
+		b := &t.buckets[idx]
+		if b.counts == nil || b.start+t.bucketSize <= oldest {
+			continue
+		}
+
+		for key, count := range b.counts {
+			usage, ok := byFlag[key.Flag]
+			if !ok {
+				usage = &FlagUsage{Flag: key.Flag, Treatments: make(map[string]int64), SDKs: make(map[string]int64), LastSeen: t.lastSeen[key.Flag]}
+				byFlag[key.Flag] = usage
+			}
+			usage.Total += count
+			usage.Treatments[key.Treatment] += count
+			usage.SDKs[key.SDK] += count
+		}
+	}
+
+	result := make([]FlagUsage, 0, len(byFlag))
+	for _, usage := range byFlag {
+		result = append(result, *usage)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Total != result[j].Total {
+			return result[i].Total > result[j].Total
+		}
+		return result[i].Flag < result[j].Flag
+	})
+	return result
+}
+
+// Stale returns the flags (among the supplied ones) without impressions during the stale window
+func (t *Tracker) Stale(flags []string) StaleReport {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := toMillis(t.now())
+	windowMs := int64(t.staleWindow / time.Millisecond)
+	report := StaleReport{
+		WindowMs:      windowMs,
+		TrackingSince: t.startedAt,
+		Complete:      now-t.startedAt >= windowMs,
+		Flags:         make([]StaleFlag, 0),
+	}
+
+	for _, flag := range flags {
+		lastSeen, ok := t.lastSeen[flag]
+		if !ok {
+			report.Flags = append(report.Flags, StaleFlag{Name: flag})
+		} else if now-lastSeen >= windowMs {
+			report.Flags = append(report.Flags, StaleFlag{Name: flag, LastSeen: &lastSeen})
+		}
+	}
+	sort.Slice(report.Flags, func(i, j int) bool { return report.Flags[i].Name < report.Flags[j].Name })
+	return report
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,70 @@
+package usage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+)
+
+func TestTracker(t *testing.T) {
+	now := time.Unix(1650000000, 0)
+	tracker := newTracker(time.Hour, 24*time.Hour, func() time.Time { return now })
+
+	batch := make(Batch)
+	batch.AddImpressions(&dtos.Metadata{SDKVersion: "go-6.1.0"}, []dtos.ImpressionsDTO{
+		{TestName: "flag1", KeyImpressions: []dtos.ImpressionDTO{{Treatment: "on"}, {Treatment: "on"}, {Treatment: "off"}}},
+		{TestName: "flag2", KeyImpressions: []dtos.ImpressionDTO{{Treatment: "on"}}},
+	})
+	tracker.Record(batch)
+
+	now = now.Add(30 * time.Minute)
+	batch = make(Batch)
+	batch.Add("java-4.0.0", "flag2", "off", 5)
+	tracker.Record(batch)
+
+	usage := tracker.Usage()
+	if len(usage) != 2 || usage[0].Flag != "flag2" || usage[0].Total != 6 || usage[1].Flag != "flag1" || usage[1].Total != 3 {
+		t.Error("wrong usage: ", usage)
+	}
+	if usage[1].Treatments["on"] != 2 || usage[1].Treatments["off"] != 1 || usage[1].SDKs["go-6.1.0"] != 3 {
+		t.Error("wrong treatment/sdk breakdown: ", usage[1])
+	}
+	if usage[0].SDKs["java-4.0.0"] != 5 || usage[0].LastSeen != toMillis(now) {
+		t.Error("wrong sdk breakdown/last seen: ", usage[0])
+	}
+
+	// the first batch falls out of the window
+	now = now.Add(45 * time.Minute)
+	usage = tracker.Usage()
+	if len(usage) != 1 || usage[0].Flag != "flag2" || usage[0].Total != 5 {
+		t.Error("old buckets should be discarded. Got: ", usage)
+	}
+
+	report := tracker.Stale([]string{"flag1", "flag2", "flag3"})
+	if report.Complete || len(report.Flags) != 1 || report.Flags[0].Name != "flag3" || report.Flags[0].LastSeen != nil {
+		t.Error("only the never-seen flag should be stale. Got: ", report)
+	}
+
+	now = now.Add(23 * time.Hour)
+	report = tracker.Stale([]string{"flag1", "flag2", "flag3"})
+	if !report.Complete || len(report.Flags) != 2 || report.Flags[0].Name != "flag1" || report.Flags[0].LastSeen == nil || report.Flags[1].Name != "flag3" {
+		t.Error("flags not seen during the stale window should be reported. Got: ", report)
+	}
+}
+
+func TestTrackerSeen(t *testing.T) {
+	now := time.Unix(1650000000, 0)
+	tracker := newTracker(time.Hour, time.Hour, func() time.Time { return now })
+	tracker.Seen("flag1")
+
+	now = now.Add(30 * time.Minute)
+	report := tracker.Stale([]string{"flag1", "flag2"})
+	if len(report.Flags) != 1 || report.Flags[0].Name != "flag2" {
+		t.Error("flags seen in impression counts should not be stale. Got: ", report)
+	}
+
+	if usage := tracker.Usage(); len(usage) != 0 {
+		t.Error("seen flags should not be counted. Got: ", usage)
+	}
+}
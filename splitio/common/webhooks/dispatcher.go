@@ -0,0 +1,219 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/splitio/go-toolkit/v5/struct/traits/lifecycle"
+)
+
+// Event types
+const (
+	EventSplitsUpdated  = "SPLITS_UPDATED"
+	EventSplitKilled    = "SPLIT_KILLED"
+	EventSegmentUpdated = "SEGMENT_UPDATED"
+)
+
+// Headers sent along with every webhook
+const (
+	HeaderSignature = "X-Split-Signature"
+	HeaderEventType = "X-Split-Event"
+)
+
+const (
+	defaultTimeout     = 10 * time.Second
+	defaultBackoffBase = time.Second
+)
+
+// ErrInvalidQueueSize is returned when attempting to construct a dispatcher with an invalid queue size
+var ErrInvalidQueueSize = errors.New("queue size must be at least 1")
+
+// ErrQueueFull is returned when attempting to push an event in a full queue
+var ErrQueueFull = errors.New("queue is full, cannot add webhook event")
+
+// ErrAlreadyRunning is returned when attempting to start an already running dispatcher
+var ErrAlreadyRunning = errors.New("dispatcher is already running")
+
+// ErrNotRunning is returned when attempting to stop a non-running dispatcher
+var ErrNotRunning = errors.New("dispatcher is not running")
+
+// Event is the payload posted to the webhook endpoint
+type Event struct {
+	Type         string   `json:"type"`
+	Names        []string `json:"names"`
+	ChangeNumber int64    `json:"changeNumber"`
+	Timestamp    int64    `json:"timestamp"`
+}
+
+// Notifier is implemented by components that deliver change events
+type Notifier interface {
+	Notify(event Event) error
+}
+
+// Dispatcher defines the interface of the webhook dispatcher
+type Dispatcher interface {
+	Notifier
+	Start() error
+	Stop(blocking bool) error
+}
+
+// DispatcherImpl queues change events & posts them in the background, retrying failed posts with an exponential backoff.
+// When a secret is set, every payload is signed with HMAC-SHA256 & the hex-encoded signature sent in the X-Split-Signature header
+type DispatcherImpl struct {
+	lifecycle   lifecycle.Manager
+	endpoint    string
+	secret      []byte
+	maxRetries  int
+	backoffBase time.Duration
+	httpClient  *http.Client
+	queue       chan Event
+	logger      logging.LoggerInterface
+}
+
+// NewDispatcher constructs a new webhook dispatcher
+func NewDispatcher(endpoint string, secret string, maxRetries int, queueSize int, httpClient *http.Client, logger logging.LoggerInterface) (*DispatcherImpl, error) {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+
+	if queueSize < 1 {
+		return nil, ErrInvalidQueueSize
+	}
+
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	dispatcher := &DispatcherImpl{
+		endpoint:    endpoint,
+		secret:      []byte(secret),
+		maxRetries:  maxRetries,
+		backoffBase: defaultBackoffBase,
+		httpClient:  httpClient,
+		queue:       make(chan Event, queueSize),
+		logger:      logger,
+	}
+	dispatcher.lifecycle.Setup()
+	return dispatcher, nil
+}
+
+// Notify attempts to push an event into the queue. Will fail if the queue is full
+func (d *DispatcherImpl) Notify(event Event) error {
+	if event.Timestamp == 0 {
+		event.Timestamp = time.Now().UnixNano() / int64(time.Millisecond)
+	}
+
+	select {
+	case d.queue <- event:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Start the bg task that will take events from the queue and post them
+func (d *DispatcherImpl) Start() error {
+	if !d.lifecycle.BeginInitialization() {
+		return ErrAlreadyRunning
+	}
+
+	go func() {
+		defer d.lifecycle.ShutdownComplete()
+		if !d.lifecycle.InitializationComplete() {
+			return
+		}
+
+		for {
+			select {
+			case <-d.lifecycle.ShutdownRequested():
+				return
+			case event := <-d.queue:
+				if err := d.deliver(event); err != nil {
+					d.logger.Error(fmt.Sprintf("error delivering webhook event %s %v: %s", event.Type, event.Names, err))
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop the bg task
+func (d *DispatcherImpl) Stop(blocking bool) error {
+	if !d.lifecycle.BeginShutdown() {
+		return ErrNotRunning
+	}
+
+	if blocking {
+		d.lifecycle.AwaitShutdownComplete()
+	}
+
+	return nil
+}
+
+// Close stops the bg task & waits for it to finish
+func (d *DispatcherImpl) Close() error {
+	return d.Stop(true)
+}
+
+func (d *DispatcherImpl) deliver(event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error serializing event: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err = d.post(event.Type, data)
+		if err == nil || attempt >= d.maxRetries {
+			return err
+		}
+
+		d.logger.Debug(fmt.Sprintf("webhook post failed (attempt %d): %s. Retrying", attempt+1, err))
+		select {
+		case <-d.lifecycle.ShutdownRequested():
+			return fmt.Errorf("shutdown requested while retrying: %w", err)
+		case <-time.After(d.backoffBase * time.Duration(1<<uint(attempt))):
+		}
+	}
+}
+
+func (d *DispatcherImpl) post(eventType string, data []byte) error {
+	request, err := http.NewRequest(http.MethodPost, d.endpoint, bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("error building request: %w", err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderEventType, eventType)
+	if len(d.secret) > 0 {
+		request.Header.Set(HeaderSignature, Sign(d.secret, data))
+	}
+
+	response, err := d.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", response.StatusCode)
+	}
+	return nil
+}
+
+// Sign computes the hex-encoded HMAC-SHA256 of a payload, prefixed with the algorithm name
+func Sign(secret []byte, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+var _ Dispatcher = (*DispatcherImpl)(nil)
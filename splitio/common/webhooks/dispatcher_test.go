@@ -0,0 +1,126 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/splitio/go-split-commons/v4/storage/inmemory/mutexmap"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/conf"
+)
+
+func TestDispatcherSignsAndRetries(t *testing.T) {
+	var calls int32
+	received := make(chan Event, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		if signature := r.Header.Get(HeaderSignature); signature != Sign([]byte("someSecret"), body) {
+			t.Error("invalid signature: ", signature)
+		}
+		if r.Header.Get(HeaderEventType) != EventSplitKilled {
+			t.Error("invalid event type header: ", r.Header.Get(HeaderEventType))
+		}
+
+		var event Event
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Error("error parsing body: ", err)
+		}
+		received <- event
+	}))
+	defer ts.Close()
+
+	dispatcher, err := NewDispatcher(ts.URL, "someSecret", 3, 10, nil, logging.NewLogger(nil))
+	if err != nil {
+		t.Error("error should be nil. Got: ", err)
+	}
+	dispatcher.backoffBase = time.Millisecond
+	dispatcher.Start()
+	defer dispatcher.Stop(true)
+
+	if err := dispatcher.Notify(Event{Type: EventSplitKilled, Names: []string{"split1"}, ChangeNumber: 123}); err != nil {
+		t.Error("error should be nil. Got: ", err)
+	}
+
+	select {
+	case event := <-received:
+		if len(event.Names) != 1 || event.Names[0] != "split1" || event.ChangeNumber != 123 || event.Timestamp == 0 {
+			t.Error("wrong event received: ", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("event should have been delivered")
+	}
+
+	if c := atomic.LoadInt32(&calls); c != 3 {
+		t.Error("the event should have been delivered on the third attempt. Calls: ", c)
+	}
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(HeaderSignature) != "" {
+			t.Error("payloads should not be signed without a secret")
+		}
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	dispatcher, _ := NewDispatcher(ts.URL, "", 2, 10, nil, logging.NewLogger(nil))
+	dispatcher.backoffBase = time.Millisecond
+	dispatcher.Start()
+	dispatcher.Notify(Event{Type: EventSplitsUpdated, Names: []string{"split1"}})
+	time.Sleep(200 * time.Millisecond)
+	dispatcher.Stop(true)
+
+	if c := atomic.LoadInt32(&calls); c != 3 {
+		t.Error("there should be 1 attempt + 2 retries. Got: ", c)
+	}
+}
+
+func TestDispatcherQueue(t *testing.T) {
+	if _, err := NewDispatcher("http://localhost", "", 0, 0, nil, logging.NewLogger(nil)); err != ErrInvalidQueueSize {
+		t.Error("a zero-sized queue should be rejected. Got: ", err)
+	}
+
+	dispatcher, _ := NewDispatcher("http://localhost", "", 0, 1, nil, logging.NewLogger(nil))
+	if err := dispatcher.Notify(Event{Type: EventSplitsUpdated}); err != nil {
+		t.Error("error should be nil. Got: ", err)
+	}
+	if err := dispatcher.Notify(Event{Type: EventSplitsUpdated}); err != ErrQueueFull {
+		t.Error("the queue should be full. Got: ", err)
+	}
+}
+
+func TestSetupLifecycle(t *testing.T) {
+	splits := mutexmap.NewMMSplitStorage()
+	segments := mutexmap.NewMMSegmentStorage()
+	wrappedSplits, _, closer, err := Setup(&conf.Webhook{}, splits, segments, logging.NewLogger(nil))
+	if err != nil || closer != nil || wrappedSplits != splits {
+		t.Error("storages should be returned as-is when no endpoint is configured. Got: ", err, closer)
+	}
+
+	_, _, closer, err = Setup(&conf.Webhook{Endpoint: "http://localhost", QueueSize: 10}, splits, segments, logging.NewLogger(nil))
+	if err != nil || closer == nil {
+		t.Fatal("a dispatcher should be set up. Got: ", err)
+	}
+
+	if err := closer.Close(); err != nil {
+		t.Error("the dispatcher should be running until closed. Got: ", err)
+	}
+
+	if err := closer.Close(); err != ErrNotRunning {
+		t.Error("the dispatcher should be stopped. Got: ", err)
+	}
+}
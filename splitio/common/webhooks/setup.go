@@ -0,0 +1,40 @@
+package webhooks
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/splitio/go-split-commons/v4/storage"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/conf"
+)
+
+// Setup builds & starts a dispatcher for the supplied config, and wraps the storages used by the fetchers so that
+// the changes applied through them are notified. The returned closer stops the dispatcher & should be called once
+// the fetchers are stopped. When no endpoint is configured, the storages are returned as-is along with a nil closer
+func Setup(
+	cfg *conf.Webhook,
+	splits storage.SplitStorage,
+	segments storage.SegmentStorage,
+	logger logging.LoggerInterface,
+) (storage.SplitStorage, storage.SegmentStorage, io.Closer, error) {
+	if cfg.Endpoint == "" {
+		return splits, segments, nil, nil
+	}
+
+	dispatcher, err := NewDispatcher(cfg.Endpoint, cfg.Secret, int(cfg.MaxRetries), int(cfg.QueueSize), nil, logger)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error instantiating webhook dispatcher: %w", err)
+	}
+
+	if err := dispatcher.Start(); err != nil {
+		return nil, nil, nil, fmt.Errorf("error starting webhook dispatcher: %w", err)
+	}
+
+	filter := NewFilter(cfg.Flags, cfg.TrafficTypes)
+	return NewNotifyingSplitStorage(splits, dispatcher, filter, logger),
+		NewNotifyingSegmentStorage(segments, splits, dispatcher, filter, logger),
+		dispatcher,
+		nil
+}
@@ -0,0 +1,157 @@
+package webhooks
+
+import (
+	"fmt"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/storage"
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+// Filter restricts the changes that trigger webhooks to certain flags and/or traffic types.
+// An empty filter lets everything through
+type Filter struct {
+	flags        map[string]struct{}
+	trafficTypes map[string]struct{}
+}
+
+// NewFilter constructs a filter. Empty names are ignored
+func NewFilter(flags []string, trafficTypes []string) *Filter {
+	return &Filter{flags: toLookup(flags), trafficTypes: toLookup(trafficTypes)}
+}
+
+// IsEmpty returns true if the filter lets every change through
+func (f *Filter) IsEmpty() bool {
+	return f == nil || (len(f.flags) == 0 && len(f.trafficTypes) == 0)
+}
+
+// Accepts returns true if changes to the split should be notified. When both flags & traffic types are set,
+// matching any of them is enough
+func (f *Filter) Accepts(split *dtos.SplitDTO) bool {
+	if f.IsEmpty() {
+		return true
+	}
+	_, flagMatches := f.flags[split.Name]
+	_, trafficTypeMatches := f.trafficTypes[split.TrafficTypeName]
+	return flagMatches || trafficTypeMatches
+}
+
+func toLookup(items []string) map[string]struct{} {
+	lookup := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		if item != "" {
+			lookup[item] = struct{}{}
+		}
+	}
+	return lookup
+}
+
+// NotifyingSplitStorage wraps a split storage and notifies every update & local kill applied through it
+type NotifyingSplitStorage struct {
+	storage.SplitStorage
+	notifier Notifier
+	filter   *Filter
+	logger   logging.LoggerInterface
+}
+
+// NewNotifyingSplitStorage constructs a new split storage wrapper that notifies changes to the supplied notifier
+func NewNotifyingSplitStorage(wrapped storage.SplitStorage, notifier Notifier, filter *Filter, logger logging.LoggerInterface) *NotifyingSplitStorage {
+	return &NotifyingSplitStorage{SplitStorage: wrapped, notifier: notifier, filter: filter, logger: logger}
+}
+
+// Update applies the changes to the underlying storage & notifies the names of the (accepted) splits that changed
+func (s *NotifyingSplitStorage) Update(toAdd []dtos.SplitDTO, toRemove []dtos.SplitDTO, changeNumber int64) {
+	s.SplitStorage.Update(toAdd, toRemove, changeNumber)
+
+	names := make([]string, 0, len(toAdd)+len(toRemove))
+	for _, group := range [][]dtos.SplitDTO{toAdd, toRemove} {
+		for idx := range group {
+			if s.filter.Accepts(&group[idx]) {
+				names = append(names, group[idx].Name)
+			}
+		}
+	}
+
+	if len(names) > 0 {
+		s.notify(Event{Type: EventSplitsUpdated, Names: names, ChangeNumber: changeNumber})
+	}
+}
+
+// KillLocally kills the split in the underlying storage & notifies it
+func (s *NotifyingSplitStorage) KillLocally(splitName string, defaultTreatment string, changeNumber int64) {
+	s.SplitStorage.KillLocally(splitName, defaultTreatment, changeNumber)
+	if split := s.Split(splitName); split != nil && s.filter.Accepts(split) {
+		s.notify(Event{Type: EventSplitKilled, Names: []string{splitName}, ChangeNumber: changeNumber})
+	}
+}
+
+func (s *NotifyingSplitStorage) notify(event Event) {
+	if err := s.notifier.Notify(event); err != nil {
+		s.logger.Warning(fmt.Sprintf("error queuing webhook event for %v: %s", event.Names, err))
+	}
+}
+
+// NotifyingSegmentStorage wraps a segment storage and notifies every segment update applied through it.
+// When a filter is set, only segments referenced by accepted splits are notified
+type NotifyingSegmentStorage struct {
+	storage.SegmentStorage
+	splits   storage.SplitStorageConsumer
+	notifier Notifier
+	filter   *Filter
+	logger   logging.LoggerInterface
+}
+
+// NewNotifyingSegmentStorage constructs a new segment storage wrapper that notifies changes to the supplied notifier
+func NewNotifyingSegmentStorage(
+	wrapped storage.SegmentStorage,
+	splits storage.SplitStorageConsumer,
+	notifier Notifier,
+	filter *Filter,
+	logger logging.LoggerInterface,
+) *NotifyingSegmentStorage {
+	return &NotifyingSegmentStorage{SegmentStorage: wrapped, splits: splits, notifier: notifier, filter: filter, logger: logger}
+}
+
+// Update applies the changes to the underlying storage & notifies the segment if keys were added or removed
+func (s *NotifyingSegmentStorage) Update(name string, toAdd *set.ThreadUnsafeSet, toRemove *set.ThreadUnsafeSet, changeNumber int64) error {
+	if err := s.SegmentStorage.Update(name, toAdd, toRemove, changeNumber); err != nil {
+		return err
+	}
+
+	if (isEmpty(toAdd) && isEmpty(toRemove)) || !s.accepts(name) {
+		return nil
+	}
+
+	if err := s.notifier.Notify(Event{Type: EventSegmentUpdated, Names: []string{name}, ChangeNumber: changeNumber}); err != nil {
+		s.logger.Warning(fmt.Sprintf("error queuing webhook event for segment %s: %s", name, err))
+	}
+	return nil
+}
+
+func (s *NotifyingSegmentStorage) accepts(segment string) bool {
+	if s.filter.IsEmpty() {
+		return true
+	}
+
+	for _, split := range s.splits.All() {
+		if !s.filter.Accepts(&split) {
+			continue
+		}
+		for _, condition := range split.Conditions {
+			for _, matcher := range condition.MatcherGroup.Matchers {
+				if matcher.UserDefinedSegment != nil && matcher.UserDefinedSegment.SegmentName == segment {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func isEmpty(s *set.ThreadUnsafeSet) bool {
+	return s == nil || s.IsEmpty()
+}
+
+var _ storage.SplitStorage = (*NotifyingSplitStorage)(nil)
+var _ storage.SegmentStorage = (*NotifyingSegmentStorage)(nil)
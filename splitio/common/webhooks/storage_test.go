@@ -0,0 +1,71 @@
+package webhooks
+
+import (
+	"testing"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/storage/inmemory/mutexmap"
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+type notifierMock struct {
+	events []Event
+}
+
+func (n *notifierMock) Notify(event Event) error {
+	n.events = append(n.events, event)
+	return nil
+}
+
+func TestNotifyingStorages(t *testing.T) {
+	logger := logging.NewLogger(nil)
+	notifier := &notifierMock{}
+	filter := NewFilter([]string{"split1", ""}, []string{"account"})
+
+	splits := NewNotifyingSplitStorage(mutexmap.NewMMSplitStorage(), notifier, filter, logger)
+	splits.Update([]dtos.SplitDTO{
+		{Name: "split1", TrafficTypeName: "user", ChangeNumber: 1},
+		{Name: "split2", TrafficTypeName: "user", ChangeNumber: 1, Conditions: []dtos.ConditionDTO{{
+			MatcherGroup: dtos.MatcherGroupDTO{Matchers: []dtos.MatcherDTO{{
+				UserDefinedSegment: &dtos.UserDefinedSegmentMatcherDataDTO{SegmentName: "segment2"},
+			}}},
+		}}},
+		{Name: "split3", TrafficTypeName: "account", ChangeNumber: 1, Conditions: []dtos.ConditionDTO{{
+			MatcherGroup: dtos.MatcherGroupDTO{Matchers: []dtos.MatcherDTO{{
+				UserDefinedSegment: &dtos.UserDefinedSegmentMatcherDataDTO{SegmentName: "segment3"},
+			}}},
+		}}},
+	}, nil, 1)
+	if len(notifier.events) != 1 || notifier.events[0].Type != EventSplitsUpdated || notifier.events[0].ChangeNumber != 1 {
+		t.Error("a single split update should be notified. Got: ", notifier.events)
+	}
+	if names := notifier.events[0].Names; len(names) != 2 || names[0] != "split1" || names[1] != "split3" {
+		t.Error("only splits matching the filter should be notified. Got: ", names)
+	}
+
+	splits.Update(nil, []dtos.SplitDTO{{Name: "split2", TrafficTypeName: "user", ChangeNumber: 2}}, 2)
+	splits.Update(nil, nil, 2)
+	if len(notifier.events) != 1 {
+		t.Error("no events should be notified for filtered-out splits or empty updates. Got: ", notifier.events)
+	}
+
+	splits.KillLocally("split1", "off", 3)
+	if len(notifier.events) != 2 || notifier.events[1].Type != EventSplitKilled || notifier.events[1].Names[0] != "split1" || notifier.events[1].ChangeNumber != 3 {
+		t.Error("the local kill should be notified. Got: ", notifier.events)
+	}
+
+	segments := NewNotifyingSegmentStorage(mutexmap.NewMMSegmentStorage(), splits, notifier, filter, logger)
+	segments.Update("segment3", set.NewSet("key1"), set.NewSet(), 10)
+	segments.Update("segment2", set.NewSet("key1"), set.NewSet(), 10)
+	segments.Update("segment3", set.NewSet(), set.NewSet(), 10)
+	if len(notifier.events) != 3 || notifier.events[2].Type != EventSegmentUpdated || notifier.events[2].Names[0] != "segment3" || notifier.events[2].ChangeNumber != 10 {
+		t.Error("only updates to segments referenced by accepted splits should be notified. Got: ", notifier.events)
+	}
+
+	unfiltered := NewNotifyingSegmentStorage(mutexmap.NewMMSegmentStorage(), splits, notifier, NewFilter(nil, nil), logger)
+	unfiltered.Update("segment2", set.NewSet("key1"), set.NewSet(), 11)
+	if len(notifier.events) != 4 {
+		t.Error("every segment update should be notified without filters. Got: ", notifier.events)
+	}
+}
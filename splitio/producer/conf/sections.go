@@ -46,8 +46,13 @@ type Storage struct {
 type Sync struct {
 	SplitRefreshRateMs   int64        `json:"splitRefreshRateMs" s-cli:"split-refresh-rate-ms" s-def:"60000" s-desc:"How often to refresh splits"`
 	SegmentRefreshRateMs int64        `json:"segmentRefreshRateMs" s-cli:"segment-refresh-rate-ms" s-def:"60000" s-desc:"How often to refresh segments"`
-	ImpressionsMode      string       `json:"impressionsMode" s-cli:"impressions-mode" s-def:"optimized" s-desc:"whether to send all impressions for debugging"`
+	ImpressionsMode      string       `json:"impressionsMode" s-cli:"impressions-mode" s-def:"optimized" s-desc:"optimized (dedupe), debug (send all impressions) or none (only counts & unique keys)"`
 	Advanced             AdvancedSync `json:"advanced" s-nested:"true"`
+	AutoTuning           AutoTuning   `json:"autoTuning" s-nested:"true"`
+	Compression          Compression  `json:"compression" s-nested:"true"`
+	QueueCaps            QueueCaps    `json:"queueCaps" s-nested:"true"`
+	Fairness             Fairness     `json:"fairness" s-nested:"true"`
+	Dedupe               Dedupe       `json:"dedupe" s-nested:"true"`
 }
 
 // AdvancedSync configuration options
@@ -76,6 +81,49 @@ type AdvancedSync struct {
 	ImpressionsCountWorkerReadRateMs int64 `json:"impressionsCountWorkerReadRateMs" s-cli:"impressions-count-worker-read-rate-ms" s-def:"60000" s-desc:"how often read in redis impression count comming from sdks"`
 }
 
+// QueueCaps configuration options
+type QueueCaps struct {
+	PeriodMs             int64  `json:"periodMs" s-cli:"queue-caps-period-ms" s-def:"10000" s-desc:"How often to check impressions & events queue (and failed items lists) lengths"`
+	ImpressionsMaxLength int64  `json:"impressionsMaxLength" s-cli:"queue-caps-impressions-max-length" s-def:"0" s-desc:"Max #items in the impressions queue (0 = unlimited)"`
+	ImpressionsPolicy    string `json:"impressionsPolicy" s-cli:"queue-caps-impressions-policy" s-def:"trim_oldest" s-desc:"What to do with impressions above the cap: trim_oldest, sample or spill"`
+	EventsMaxLength      int64  `json:"eventsMaxLength" s-cli:"queue-caps-events-max-length" s-def:"0" s-desc:"Max #items in the events queue (0 = unlimited)"`
+	EventsPolicy         string `json:"eventsPolicy" s-cli:"queue-caps-events-policy" s-def:"trim_oldest" s-desc:"What to do with events above the cap: trim_oldest, sample or spill"`
+	FailedMaxLength      int64  `json:"failedMaxLength" s-cli:"queue-caps-failed-max-length" s-def:"100000" s-desc:"Max #items kept in each list of items that could not be posted; the oldest ones are trimmed (0 = unlimited)"`
+	SamplePercent        int    `json:"samplePercent" s-cli:"queue-caps-sample-percent" s-def:"10" s-desc:"Percentage of the overflowing items kept by the sample policy"`
+	SpillDirectory       string `json:"spillDirectory" s-cli:"queue-caps-spill-directory" s-def:"" s-desc:"Directory where overflowing items are written by the spill policy"`
+}
+
+// Fairness configuration options
+type Fairness struct {
+	Policy           string `json:"policy" s-cli:"fairness-policy" s-def:"none" s-desc:"What to do with items from sdks exceeding their share while the backlog is above the threshold: none, quota (items over the quota are re-queued) or sample (sampled out items are lost; impressions are still reflected in impression counts)"`
+	BacklogThreshold int64  `json:"backlogThreshold" s-cli:"fairness-backlog-threshold" s-def:"100000" s-desc:"Impressions/events queue length above which the fairness policy is applied"`
+	QuotaPerWindow   int64  `json:"quotaPerWindow" s-cli:"fairness-quota-per-window" s-def:"50000" s-desc:"Max #items accepted per sdk instance & window by the quota policy"`
+	WindowMs         int64  `json:"windowMs" s-cli:"fairness-window-ms" s-def:"60000" s-desc:"Time window used to measure the volume generated by each sdk instance"`
+	MaxSources       int64  `json:"maxSources" s-cli:"fairness-max-sources" s-def:"5000" s-desc:"Max #sdk instances tracked per queue"`
+	MaxDeferrals     int64  `json:"maxDeferrals" s-cli:"fairness-max-deferrals" s-def:"3" s-desc:"Times an item can be re-queued by the quota policy before it's accepted regardless of the quota"`
+}
+
+// Compression configuration options
+type Compression struct {
+	Enabled bool `json:"enabled" s-cli:"post-compression-enabled" s-def:"false" s-desc:"Gzip impressions, events & unique keys posts"`
+	Level   int  `json:"level" s-cli:"post-compression-level" s-def:"6" s-desc:"Gzip compression level, from 1 (fastest) to 9 (smallest)"`
+}
+
+// AutoTuning configuration options
+type AutoTuning struct {
+	Enabled               bool  `json:"enabled" s-cli:"auto-tuning-enabled" s-def:"false" s-desc:"Adjust impressions & events fetch sizes and concurrency within the configured bounds"`
+	PeriodMs              int64 `json:"periodMs" s-cli:"auto-tuning-period-ms" s-def:"30000" s-desc:"How often to re-evaluate impressions & events settings"`
+	MinFetchSize          int64 `json:"minFetchSize" s-cli:"auto-tuning-min-fetch-size" s-def:"1000" s-desc:"Lower bound for the fetch size"`
+	MaxFetchSize          int64 `json:"maxFetchSize" s-cli:"auto-tuning-max-fetch-size" s-def:"100000" s-desc:"Upper bound for the fetch size"`
+	MinProcessConcurrency int   `json:"minProcessConcurrency" s-cli:"auto-tuning-min-process-concurrency" s-def:"1" s-desc:"Lower bound for #processing threads"`
+	MaxProcessConcurrency int   `json:"maxProcessConcurrency" s-cli:"auto-tuning-max-process-concurrency" s-def:"0" s-desc:"Upper bound for #processing threads (0 = #cpus)"`
+	MinProcessBatchSize   int   `json:"minProcessBatchSize" s-cli:"auto-tuning-min-process-batch-size" s-def:"1000" s-desc:"Lower bound for the processing batch size"`
+	MaxProcessBatchSize   int   `json:"maxProcessBatchSize" s-cli:"auto-tuning-max-process-batch-size" s-def:"100000" s-desc:"Upper bound for the processing batch size"`
+	MinPostConcurrency    int   `json:"minPostConcurrency" s-cli:"auto-tuning-min-post-concurrency" s-def:"10" s-desc:"Lower bound for #concurrent post threads"`
+	MaxPostConcurrency    int   `json:"maxPostConcurrency" s-cli:"auto-tuning-max-post-concurrency" s-def:"2000" s-desc:"Upper bound for #concurrent post threads"`
+	MaxPostLatencyMs      int64 `json:"maxPostLatencyMs" s-cli:"auto-tuning-max-post-latency-ms" s-def:"0" s-desc:"Avg post latency above which post concurrency is reduced (0 = half the http timeout)"`
+}
+
 // Redis configuration options
 type Redis struct {
 	Host                  string   `json:"host" s-cli:"redis-host" s-def:"localhost" s-desc:"Redis server hostname"`
@@ -113,3 +161,13 @@ type Healthcheck struct {
 type HealthcheckApp struct {
 	StorageCheckRateMs int64 `json:"storageCheckRateMs" s-cli:"storage-check-rate-ms" s-def:"3600000" s-desc:"How often to check storage health"`
 }
+
+// Dedupe configuration options
+type Dedupe struct {
+	FilterExpectedElements  int64  `json:"filterExpectedElements" s-cli:"dedupe-filter-expected-elements" s-def:"10000000" s-desc:"#Unique keys the unique keys filter is sized for"`
+	FilterFalsePositiveRate string `json:"filterFalsePositiveRate" s-cli:"dedupe-filter-false-positive-rate" s-def:"0.01" s-desc:"False positive probability of the unique keys filter, between 0 and 1"`
+	FilterCleaningPeriodMs  int64  `json:"filterCleaningPeriodMs" s-cli:"dedupe-filter-cleaning-period-ms" s-def:"86400000" s-desc:"How often to clear the unique keys filter"`
+	Persistence             string `json:"persistence" s-cli:"dedupe-persistence" s-def:"none" s-desc:"Where to save the unique keys filter & pending impression counts across restarts: none, redis or file"`
+	PersistenceFile         string `json:"persistenceFile" s-cli:"dedupe-persistence-file" s-def:"split-dedupe-state.json" s-desc:"File used by the file persistence"`
+	CheckpointRateMs        int64  `json:"checkpointRateMs" s-cli:"dedupe-checkpoint-rate-ms" s-def:"300000" s-desc:"How often to save the unique keys filter when persistence is enabled"`
+}
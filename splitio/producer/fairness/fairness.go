@@ -0,0 +1,367 @@
+package fairness
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+)
+
+// Policies applied to the items of sdk instances exceeding their share while the backlog is above the threshold
+const (
+	PolicyNone   = "none"
+	PolicyQuota  = "quota"
+	PolicySample = "sample"
+)
+
+const (
+	defaultWindow       = time.Minute
+	defaultMaxSources   = 5000
+	defaultMaxDeferrals = 3
+	defaultMaxDeferred  = 100000
+	deferralWindows     = 10 // windows after which a deferred item that wasn't popped again is forgotten
+)
+
+// Verdict is the outcome of admitting an item
+type Verdict int
+
+// Possible verdicts
+const (
+	Accept Verdict = iota // the item should be processed
+	Defer                 // the sdk instance is over its quota: the item should be re-queued to be processed in a later pass
+	Drop                  // the item was sampled out
+)
+
+// ErrUnknownPolicy is returned when constructing a tracker with an unsupported policy
+var ErrUnknownPolicy = errors.New("unknown fairness policy")
+
+// Config bundles the options of a fairness tracker
+type Config struct {
+	Name             string
+	Policy           string
+	BacklogThreshold int64 // queue length above which the policy is applied. Non-positive values disable it
+	Quota            int64 // max items per sdk instance & window accepted by the `quota` policy
+	Window           time.Duration
+	MaxSources       int
+	MaxDeferrals     int // times an item can be deferred before it's accepted regardless of the quota
+	MaxDeferred      int // max items tracked as deferred at once. Once reached, items over the quota are accepted
+}
+
+// Producer describes the volume generated by a single sdk instance
+type Producer struct {
+	SDKVersion          string  `json:"sdkVersion"`
+	MachineName         string  `json:"machineName"`
+	MachineIP           string  `json:"machineIP"`
+	WindowItems         int64   `json:"windowItems"`
+	PreviousWindowItems int64   `json:"previousWindowItems"`
+	Share               float64 `json:"share"`
+	Total               int64   `json:"total"`
+	Deferred            int64   `json:"deferred"`
+	Dropped             int64   `json:"dropped"`
+	LastSeen            int64   `json:"lastSeen"`
+}
+
+// Status describes the current state of a tracker along with its top producers
+type Status struct {
+	Name             string     `json:"name"`
+	Policy           string     `json:"policy"`
+	BacklogThreshold int64      `json:"backlogThreshold"`
+	Backlog          int64      `json:"backlog"`
+	Throttling       bool       `json:"throttling"`
+	WindowMs         int64      `json:"windowMs"`
+	Sources          int        `json:"sources"`
+	Deferred         int64      `json:"deferred"`
+	Dropped          int64      `json:"dropped"`
+	Producers        []Producer `json:"producers"`
+}
+
+type source struct {
+	window   int64
+	previous int64
+	admitted int64 // items accepted in the current window, which is what the quota applies to
+	total    int64
+	deferred int64
+	dropped  int64
+	lastSeen int64
+}
+
+type deferral struct {
+	attempts int
+	at       time.Time
+}
+
+func (s *source) recent() int64 {
+	return s.window + s.previous
+}
+
+// Tracker measures the volume of items produced by each sdk instance sharing a queue, and while the queue backlog
+// is above the threshold, keeps noisy instances from delaying everyone else's data by applying a per-instance quota
+// or by sampling instances in proportion to how much they exceed their fair share
+type Tracker struct {
+	backlog       int64 // accessed atomically
+	cfg           Config
+	mutex         sync.Mutex
+	sources       map[dtos.Metadata]*source
+	active        int // sources with items in the current or previous window
+	windowStart   time.Time
+	windowTotal   int64
+	previousTotal int64
+	deferred      int64
+	dropped       int64
+	deferrals     map[uint64]deferral // items deferred & not popped again yet, by hash
+	rand          *rand.Rand
+}
+
+// NewTracker validates the config & constructs a tracker
+func NewTracker(cfg Config) (*Tracker, error) {
+	switch cfg.Policy {
+	case "":
+		cfg.Policy = PolicyNone
+	case PolicyNone:
+	case PolicyQuota:
+		if cfg.Quota <= 0 {
+			return nil, fmt.Errorf("%s fairness: the quota policy requires a positive quota", cfg.Name)
+		}
+	case PolicySample:
+	default:
+		return nil, fmt.Errorf("%s fairness: %w '%s'", cfg.Name, ErrUnknownPolicy, cfg.Policy)
+	}
+
+	if cfg.Window <= 0 {
+		cfg.Window = defaultWindow
+	}
+	if cfg.MaxSources <= 0 {
+		cfg.MaxSources = defaultMaxSources
+	}
+	if cfg.MaxDeferrals <= 0 {
+		cfg.MaxDeferrals = defaultMaxDeferrals
+	}
+	if cfg.MaxDeferred <= 0 {
+		cfg.MaxDeferred = defaultMaxDeferred
+	}
+
+	return &Tracker{
+		cfg:         cfg,
+		sources:     make(map[dtos.Metadata]*source),
+		deferrals:   make(map[uint64]deferral),
+		windowStart: time.Now(),
+		rand:        rand.New(rand.NewSource(time.Now().UnixNano())),
+	}, nil
+}
+
+// Name returns the name of the queue being tracked
+func (t *Tracker) Name() string {
+	return t.cfg.Name
+}
+
+// SetBacklog updates the length of the queue, as reported after popping items from it
+func (t *Tracker) SetBacklog(size int64) {
+	atomic.StoreInt64(&t.backlog, size)
+}
+
+// Admit records an item produced by an sdk instance & returns what should be done with it.
+// Deferred items are recognized when they're popped again, so that they're recorded only once & count towards the quota
+// only when accepted. Each item is deferred at most `MaxDeferrals` times, and accepted after that
+func (t *Tracker) Admit(metadata *dtos.Metadata, item []byte) Verdict {
+	now := time.Now()
+	key := itemKey(item)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.rotate(now)
+
+	src := t.source(metadata)
+	previous, retry := t.deferrals[key]
+	if retry {
+		delete(t.deferrals, key)
+	} else {
+		if src.recent() == 0 {
+			t.active++
+		}
+		src.window++
+		src.total++
+		t.windowTotal++
+	}
+	src.lastSeen = now.UnixNano() / int64(time.Millisecond)
+
+	verdict := t.verdict(src, previous.attempts)
+	switch verdict {
+	case Accept:
+		src.admitted++
+	case Defer:
+		t.deferrals[key] = deferral{attempts: previous.attempts + 1, at: now}
+		src.deferred++
+		t.deferred++
+	case Drop:
+		src.dropped++
+		t.dropped++
+	}
+	return verdict
+}
+
+// Forget discards the deferral of an item that couldn't be re-queued
+func (t *Tracker) Forget(item []byte) {
+	key := itemKey(item)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.deferrals, key)
+}
+
+// Status returns the state of the tracker along with the `limit` sdk instances with the highest recent volume
+func (t *Tracker) Status(limit int) Status {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.rotate(time.Now())
+
+	producers := make([]Producer, 0, len(t.sources))
+	recentTotal := t.windowTotal + t.previousTotal
+	for metadata, src := range t.sources {
+		producer := Producer{
+			SDKVersion:          metadata.SDKVersion,
+			MachineName:         metadata.MachineName,
+			MachineIP:           metadata.MachineIP,
+			WindowItems:         src.window,
+			PreviousWindowItems: src.previous,
+			Total:               src.total,
+			Deferred:            src.deferred,
+			Dropped:             src.dropped,
+			LastSeen:            src.lastSeen,
+		}
+		if recentTotal > 0 {
+			producer.Share = float64(src.recent()) / float64(recentTotal)
+		}
+		producers = append(producers, producer)
+	}
+
+	sort.Slice(producers, func(i, j int) bool {
+		ri := producers[i].WindowItems + producers[i].PreviousWindowItems
+		rj := producers[j].WindowItems + producers[j].PreviousWindowItems
+		if ri != rj {
+			return ri > rj
+		}
+		return producers[i].Total > producers[j].Total
+	})
+	if limit > 0 && len(producers) > limit {
+		producers = producers[:limit]
+	}
+
+	return Status{
+		Name:             t.cfg.Name,
+		Policy:           t.cfg.Policy,
+		BacklogThreshold: t.cfg.BacklogThreshold,
+		Backlog:          atomic.LoadInt64(&t.backlog),
+		Throttling:       t.throttling(),
+		WindowMs:         t.cfg.Window.Milliseconds(),
+		Sources:          len(t.sources),
+		Deferred:         t.deferred,
+		Dropped:          t.dropped,
+		Producers:        producers,
+	}
+}
+
+func (t *Tracker) verdict(src *source, attempts int) Verdict {
+	if !t.throttling() {
+		return Accept
+	}
+
+	switch t.cfg.Policy {
+	case PolicyQuota:
+		if src.admitted >= t.cfg.Quota && attempts < t.cfg.MaxDeferrals && len(t.deferrals) < t.cfg.MaxDeferred {
+			return Defer
+		}
+	case PolicySample:
+		if t.rand.Float64() >= t.keepRate(src) {
+			return Drop
+		}
+	}
+	return Accept
+}
+
+func (t *Tracker) throttling() bool {
+	return t.cfg.Policy != PolicyNone && t.cfg.BacklogThreshold > 0 && atomic.LoadInt64(&t.backlog) > t.cfg.BacklogThreshold
+}
+
+// keepRate returns the fraction of items to keep for a source, so that sources at or below their fair share
+// (an even split among active sources) keep everything & heavier ones are sampled down to it
+func (t *Tracker) keepRate(src *source) float64 {
+	recentTotal := t.windowTotal + t.previousTotal
+	if recentTotal == 0 || t.active == 0 {
+		return 1
+	}
+
+	share := float64(src.recent()) / float64(recentTotal)
+	fairShare := 1 / float64(t.active)
+	if share <= fairShare {
+		return 1
+	}
+	return fairShare / share
+}
+
+// source returns the counters for an sdk instance, evicting an arbitrary one if the max number of sources is reached
+func (t *Tracker) source(metadata *dtos.Metadata) *source {
+	if src, ok := t.sources[*metadata]; ok {
+		return src
+	}
+
+	if len(t.sources) >= t.cfg.MaxSources {
+		for key, evicted := range t.sources {
+			if evicted.recent() > 0 {
+				t.active--
+			}
+			delete(t.sources, key)
+			break
+		}
+	}
+
+	src := &source{}
+	t.sources[*metadata] = src
+	return src
+}
+
+// rotate starts a new window if the current one is over, dropping sources idle for two windows
+func (t *Tracker) rotate(now time.Time) {
+	elapsed := now.Sub(t.windowStart)
+	if elapsed < t.cfg.Window {
+		return
+	}
+
+	skipped := elapsed >= 2*t.cfg.Window // no items were received during a whole window
+	t.active = 0
+	for key, src := range t.sources {
+		src.previous = src.window
+		if skipped {
+			src.previous = 0
+		}
+		src.window = 0
+		src.admitted = 0
+		if src.previous == 0 {
+			delete(t.sources, key)
+			continue
+		}
+		t.active++
+	}
+
+	t.previousTotal = t.windowTotal
+	if skipped {
+		t.previousTotal = 0
+	}
+	t.windowTotal = 0
+	t.windowStart = now
+
+	for key, item := range t.deferrals {
+		if now.Sub(item.at) >= deferralWindows*t.cfg.Window { // likely flushed or trimmed from the queue
+			delete(t.deferrals, key)
+		}
+	}
+}
+
+func itemKey(item []byte) uint64 {
+	hasher := fnv.New64a()
+	hasher.Write(item)
+	return hasher.Sum64()
+}
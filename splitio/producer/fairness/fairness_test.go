@@ -0,0 +1,146 @@
+package fairness
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+)
+
+var (
+	noisy = dtos.Metadata{SDKVersion: "go-6.1.0", MachineName: "noisy", MachineIP: "10.0.0.1"}
+	quiet = dtos.Metadata{SDKVersion: "java-4.4.0", MachineName: "quiet", MachineIP: "10.0.0.2"}
+)
+
+func admitN(tracker *Tracker, metadata dtos.Metadata, n int) int {
+	kept := 0
+	for idx := 0; idx < n; idx++ {
+		if tracker.Admit(&metadata, []byte(fmt.Sprintf("%s-%d", metadata.MachineName, idx))) == Accept {
+			kept++
+		}
+	}
+	return kept
+}
+
+func TestTrackerConfigValidation(t *testing.T) {
+	if _, err := NewTracker(Config{Name: "impressions", Policy: "nope"}); !errors.Is(err, ErrUnknownPolicy) {
+		t.Error("unknown policies should be rejected. Got: ", err)
+	}
+
+	if _, err := NewTracker(Config{Name: "impressions", Policy: PolicyQuota}); err == nil {
+		t.Error("quotas without a limit should be rejected")
+	}
+
+	tracker, err := NewTracker(Config{Name: "impressions"})
+	if err != nil || tracker.Status(0).Policy != PolicyNone {
+		t.Error("the policy should default to none. Got: ", err)
+	}
+}
+
+func TestTrackerTopProducers(t *testing.T) {
+	tracker, _ := NewTracker(Config{Name: "impressions", Policy: PolicySample, BacklogThreshold: 100})
+	tracker.SetBacklog(50)
+	admitN(tracker, quiet, 10)
+	if kept := admitN(tracker, noisy, 90); kept != 90 {
+		t.Error("nothing should be dropped while the backlog is under the threshold. Kept: ", kept)
+	}
+
+	status := tracker.Status(1)
+	if status.Throttling || status.Sources != 2 || status.Backlog != 50 || len(status.Producers) != 1 {
+		t.Fatal("wrong status: ", status)
+	}
+
+	top := status.Producers[0]
+	if top.MachineName != "noisy" || top.WindowItems != 90 || top.Total != 90 || top.Share != 0.9 || top.LastSeen == 0 {
+		t.Error("the noisy instance should be the top producer. Got: ", top)
+	}
+}
+
+func TestTrackerQuota(t *testing.T) {
+	tracker, _ := NewTracker(Config{Name: "events", Policy: PolicyQuota, BacklogThreshold: 100, Quota: 20})
+	tracker.SetBacklog(1000)
+
+	if kept := admitN(tracker, noisy, 50); kept != 20 {
+		t.Error("only the quota should be accepted. Kept: ", kept)
+	}
+
+	if kept := admitN(tracker, quiet, 10); kept != 10 {
+		t.Error("instances within their quota should not be affected. Kept: ", kept)
+	}
+
+	status := tracker.Status(0)
+	if !status.Throttling || status.Deferred != 30 || status.Dropped != 0 || status.Producers[0].Deferred != 30 || status.Producers[1].Deferred != 0 {
+		t.Error("wrong status: ", status)
+	}
+}
+
+func TestTrackerDeferrals(t *testing.T) {
+	tracker, _ := NewTracker(Config{Name: "events", Policy: PolicyQuota, BacklogThreshold: 100, Quota: 2, MaxDeferrals: 2})
+	tracker.SetBacklog(1000)
+	admitN(tracker, noisy, 2)
+
+	item := []byte("over-the-quota")
+	for attempt := 0; attempt < 2; attempt++ {
+		if verdict := tracker.Admit(&noisy, item); verdict != Defer {
+			t.Error("items over the quota should be deferred. Got: ", verdict)
+		}
+	}
+
+	if verdict := tracker.Admit(&noisy, item); verdict != Accept {
+		t.Error("items deferred the max number of times should be accepted. Got: ", verdict)
+	}
+
+	status := tracker.Status(0)
+	if status.Producers[0].Total != 3 || status.Producers[0].WindowItems != 3 || status.Deferred != 2 {
+		t.Error("deferred items should be recorded once. Got: ", status)
+	}
+
+	tracker.Admit(&noisy, item)
+	tracker.Forget(item)
+	if verdict := tracker.Admit(&noisy, item); verdict != Defer || tracker.Status(0).Producers[0].Total != 5 {
+		t.Error("forgotten items should be recorded as new ones")
+	}
+}
+
+func TestTrackerWeightedSampling(t *testing.T) {
+	tracker, _ := NewTracker(Config{Name: "impressions", Policy: PolicySample, BacklogThreshold: 100})
+
+	// build up the volumes before the backlog crosses the threshold
+	admitN(tracker, quiet, 1000)
+	admitN(tracker, noisy, 9000)
+	tracker.SetBacklog(1000)
+
+	if kept := admitN(tracker, quiet, 1000); kept != 1000 {
+		t.Error("instances under their fair share should keep everything. Kept: ", kept)
+	}
+
+	// the noisy instance has ~82% of the volume & a fair share of 50%, so ~60% of its items should be kept
+	kept := admitN(tracker, noisy, 10000)
+	if kept < 5000 || kept > 7000 {
+		t.Error("the noisy instance should be sampled down towards its fair share. Kept: ", kept)
+	}
+}
+
+func TestTrackerWindows(t *testing.T) {
+	tracker, _ := NewTracker(Config{Name: "impressions", Window: 50 * time.Millisecond, MaxSources: 1})
+	admitN(tracker, noisy, 10)
+	admitN(tracker, quiet, 5)
+
+	status := tracker.Status(0)
+	if status.Sources != 1 || status.Producers[0].MachineName != "quiet" {
+		t.Error("the max number of sources should be enforced. Got: ", status)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	status = tracker.Status(0)
+	if status.Sources != 1 || status.Producers[0].WindowItems != 0 || status.Producers[0].PreviousWindowItems != 5 {
+		t.Error("volumes should move to the previous window. Got: ", status.Producers)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if status := tracker.Status(0); status.Sources != 0 {
+		t.Error("idle instances should be forgotten. Got: ", status)
+	}
+}
@@ -3,17 +3,16 @@ package producer
 import (
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	cconf "github.com/splitio/go-split-commons/v4/conf"
 	"github.com/splitio/go-split-commons/v4/dtos"
 	"github.com/splitio/go-split-commons/v4/provisional/strategy"
 	"github.com/splitio/go-split-commons/v4/service/api"
-	"github.com/splitio/go-split-commons/v4/storage/filter"
 	"github.com/splitio/go-split-commons/v4/storage/inmemory"
 	"github.com/splitio/go-split-commons/v4/storage/redis"
 	"github.com/splitio/go-split-commons/v4/synchronizer"
-	"github.com/splitio/go-split-commons/v4/synchronizer/worker/impressionscount"
 	"github.com/splitio/go-split-commons/v4/synchronizer/worker/segment"
 	"github.com/splitio/go-split-commons/v4/synchronizer/worker/split"
 	"github.com/splitio/go-split-commons/v4/tasks"
@@ -23,10 +22,17 @@ import (
 	"github.com/splitio/split-synchronizer/v5/splitio/admin"
 	adminCommon "github.com/splitio/split-synchronizer/v5/splitio/admin/common"
 	"github.com/splitio/split-synchronizer/v5/splitio/common"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/changefeed"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/export"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/history"
 	"github.com/splitio/split-synchronizer/v5/splitio/common/impressionlistener"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/impressiontap"
 	ssync "github.com/splitio/split-synchronizer/v5/splitio/common/sync"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/usage"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/webhooks"
 	"github.com/splitio/split-synchronizer/v5/splitio/producer/conf"
 	"github.com/splitio/split-synchronizer/v5/splitio/producer/evcalc"
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/fairness"
 	"github.com/splitio/split-synchronizer/v5/splitio/producer/storage"
 	"github.com/splitio/split-synchronizer/v5/splitio/producer/task"
 	"github.com/splitio/split-synchronizer/v5/splitio/producer/worker"
@@ -37,9 +43,11 @@ import (
 )
 
 const (
-	bfExpectedElemenets        = 10000000
-	bfFalsePositiveProbability = 0.01
-	bfCleaningPeriod           = 86400 // 6 hours
+	// how often to check for health transitions to be published in the change feed, in seconds
+	changeFeedHealthCheckPeriod = 5
+
+	// max number of concurrent admin impression taps
+	maxImpressionTaps = 5
 )
 
 // Start initialize the producer mode
@@ -117,11 +125,30 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 	// Creating Workers and Tasks
 	eventEvictionMonitor := evcalc.New(1)
 
+	// publish changes, sync errors & health transitions to the admin change feed
+	changeFeed := changefeed.New(int(cfg.Admin.EventsBuffer))
+	changeFeedTask := changefeed.NewHealthWatcherTask(changeFeed, appMonitor, servicesMonitor, logger, changeFeedHealthCheckPeriod)
+	feedSplitStorage := webhooks.NewNotifyingSplitStorage(storages.SplitStorage, changeFeed, nil, logger)
+	feedSegmentStorage := webhooks.NewNotifyingSegmentStorage(storages.SegmentStorage, storages.SplitStorage, changeFeed, nil, logger)
+
+	// notify changes to the configured webhook (if any)
+	fetcherSplitStorage, fetcherSegmentStorage, webhookDispatcher, err := webhooks.Setup(&cfg.Integrations.Webhook, feedSplitStorage, feedSegmentStorage, logger)
+	if err != nil {
+		return common.NewInitError(err, common.ExitTaskInitialization)
+	}
+
+	// keep track of the changes applied to splits
+	splitHistory := history.NewInMemoryStore(int(cfg.Admin.FlagHistorySize))
+	recordingSplitStorage := history.NewRecordingSplitStorage(fetcherSplitStorage, splitHistory)
+
 	workers := synchronizer.Workers{
-		SplitFetcher: split.NewSplitFetcher(storages.SplitStorage, splitAPI.SplitFetcher, logger, syncTelemetryStorage, appMonitor),
-		SegmentFetcher: segment.NewSegmentFetcher(storages.SplitStorage, storages.SegmentStorage, splitAPI.SegmentFetcher,
-			logger, syncTelemetryStorage, appMonitor),
-		ImpressionsCountRecorder: impressionscount.NewRecorderSingle(impressionsCounter, splitAPI.ImpressionRecorder,
+		SplitFetcher: changefeed.NewSplitUpdater(history.NewSourceTrackingUpdater(
+			split.NewSplitFetcher(recordingSplitStorage, splitAPI.SplitFetcher, logger, syncTelemetryStorage, appMonitor),
+			recordingSplitStorage,
+		), changeFeed),
+		SegmentFetcher: changefeed.NewSegmentUpdater(segment.NewSegmentFetcher(storages.SplitStorage, fetcherSegmentStorage,
+			splitAPI.SegmentFetcher, logger, syncTelemetryStorage, appMonitor), changeFeed),
+		ImpressionsCountRecorder: worker.NewImpressionsCountRecorder(impressionsCounter, splitAPI.ImpressionRecorder,
 			metadata, logger, syncTelemetryStorage),
 		// local telemetry
 		TelemetryRecorder: telemetry.NewTelemetrySynchronizer(syncTelemetryStorage, splitAPI.TelemetryRecorder,
@@ -151,7 +178,37 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 		impListener.Start()
 	}
 
-	impManager := buildImpressionManager(cfg.Sync.ImpressionsMode, impListener, syncTelemetryStorage, impressionObserver, impressionsCounter)
+	usageTracker := usage.NewTracker(
+		time.Duration(cfg.Admin.FlagUsageWindowMinutes)*time.Minute,
+		time.Duration(cfg.Admin.StaleFlagWindowHours)*time.Hour,
+	)
+
+	impressionTap := impressiontap.New(maxImpressionTaps)
+
+	// unique keys are both popped from redis & tracked locally when running in `none` impressions mode
+	dedupeFilter, err := buildDedupeFilter(&cfg.Sync.Dedupe)
+	if err != nil {
+		return common.NewInitError(fmt.Errorf("error instantiating unique keys filter: %w", err), common.ExitInvalidConfiguration)
+	}
+	uniqueKeysTracker := strategy.NewUniqueKeysTracker(dedupeFilter)
+
+	impManagers := buildImpressionManagers(impListener, syncTelemetryStorage, impressionObserver, impressionsCounter, uniqueKeysTracker)
+	impressionsModes := storage.NewSDKImpressionsModes(normalizeImpressionsMode(cfg.Sync.ImpressionsMode))
+
+	compressor, err := buildCompressor(&cfg.Sync.Compression)
+	if err != nil {
+		return common.NewInitError(fmt.Errorf("error instantiating post compressor: %w", err), common.ExitInvalidConfiguration)
+	}
+
+	impFairness, evFairness, err := buildFairnessTrackers(&cfg.Sync.Fairness)
+	if err != nil {
+		return common.NewInitError(fmt.Errorf("error instantiating fairness trackers: %w", err), common.ExitInvalidConfiguration)
+	}
+
+	exportSink, err := export.Setup(&cfg.Integrations.FileExport, logger)
+	if err != nil {
+		return common.NewInitError(fmt.Errorf("error instantiating file export: %w", err), common.ExitTaskInitialization)
+	}
 
 	// Impression & events pipelined tasks @{
 	impWorker, err := task.NewImpressionWorker(&task.ImpressionWorkerConfig{
@@ -161,8 +218,16 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 		URL:                 advanced.EventsURL,
 		Apikey:              cfg.Apikey,
 		ImpressionsListener: impListener,
+		UsageTracker:        usageTracker,
+		ImpressionTap:       impressionTap,
 		FetchSize:           int(cfg.Sync.Advanced.ImpressionsFetchSize),
-		ImpressionManager:   impManager,
+		ImpressionManager:   impManagers[impressionsModes.Fallback()],
+		ImpressionManagers:  impManagers,
+		ImpressionsModes:    impressionsModes,
+		Fairness:            impFairness,
+		Requeue:             storage.NewRedisRawQueue(redisClient, redis.KeyImpressionsQueue, logger),
+		ImpressionsCounter:  impressionsCounter,
+		Exporter:            exportSink,
 	})
 	if err != nil {
 		return common.NewInitError(fmt.Errorf("error instantiating impressions worker: %w", err), common.ExitTaskInitialization)
@@ -177,6 +242,9 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 		PostConcurrency:    cfg.Sync.Advanced.ImpressionsPostConcurrency,
 		MaxAccumWait:       time.Duration(cfg.Sync.Advanced.ImpressionsAccumWaitMs) * time.Millisecond,
 		HTTPTimeout:        time.Millisecond * time.Duration(cfg.Sync.Advanced.HTTPTimeoutMs),
+		FailedStorage:      storage.NewRedisFailedItemsStorage(redisClient, redis.KeyImpressionsQueue, logger),
+		AutoTuning:         buildAutoTuningConfig(&cfg.Sync.AutoTuning, impressionEvictionMonitor),
+		Compressor:         compressor,
 	})
 	if err != nil {
 		return common.NewInitError(fmt.Errorf("error instantiating impressions pipelined task: %w", err), common.ExitTaskInitialization)
@@ -189,6 +257,9 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 		EvictionMonitor: eventEvictionMonitor,
 		Apikey:          cfg.Apikey,
 		FetchSize:       int(cfg.Sync.Advanced.EventsFetchSize),
+		Fairness:        evFairness,
+		Requeue:         storage.NewRedisRawQueue(redisClient, redis.KeyEvents, logger),
+		Exporter:        exportSink,
 	})
 	if err != nil {
 		return common.NewInitError(fmt.Errorf("error instantiating events worker: %w", err), common.ExitTaskInitialization)
@@ -203,13 +274,14 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 		PostConcurrency:    cfg.Sync.Advanced.ImpressionsPostConcurrency,
 		MaxAccumWait:       time.Duration(cfg.Sync.Advanced.EventsAccumWaitMs) * time.Millisecond,
 		HTTPTimeout:        time.Millisecond * time.Duration(cfg.Sync.Advanced.HTTPTimeoutMs),
+		FailedStorage:      storage.NewRedisFailedItemsStorage(redisClient, redis.KeyEvents, logger),
+		AutoTuning:         buildAutoTuningConfig(&cfg.Sync.AutoTuning, eventEvictionMonitor),
+		Compressor:         compressor,
 	})
 	if err != nil {
 		return common.NewInitError(fmt.Errorf("error instantiating events pipelined task: %w", err), common.ExitTaskInitialization)
 	}
 
-	filter := filter.NewBloomFilter(bfExpectedElemenets, bfFalsePositiveProbability)
-	uniqueKeysTracker := strategy.NewUniqueKeysTracker(filter)
 	uniquesWorker := task.NewUniqueKeysWorker(&task.UniqueWorkerConfig{
 		Logger:            logger,
 		Storage:           storages.UniqueKeysStorage,
@@ -229,6 +301,8 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 		PostConcurrency:    cfg.Sync.Advanced.UniqueKeysPostConcurrency,
 		MaxAccumWait:       time.Duration(cfg.Sync.Advanced.UniqueKeysAccumWaitMs) * time.Millisecond,
 		HTTPTimeout:        time.Millisecond * time.Duration(cfg.Sync.Advanced.HTTPTimeoutMs),
+		FailedStorage:      storage.NewRedisFailedItemsStorage(redisClient, redis.KeyUniquekeys, logger),
+		Compressor:         compressor,
 	})
 	if err != nil {
 		return common.NewInitError(fmt.Errorf("error instantiating uniques pipelined task: %w", err), common.ExitTaskInitialization)
@@ -237,16 +311,52 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 	splitTasks.ImpressionSyncTask = impTask
 	splitTasks.EventSyncTask = evTask
 	splitTasks.UniqueKeysTask = uniquesTask
-	splitTasks.CleanFilterTask = tasks.NewCleanFilterTask(filter, logger, bfCleaningPeriod)
+	splitTasks.CleanFilterTask = task.NewCleanFilterTask(dedupeFilter, logger, periodSecs(cfg.Sync.Dedupe.FilterCleaningPeriodMs))
 
 	impcountStorageConsumer := redis.NewImpressionsCountStorage(redisClient, logger)
-	impcountsWorker := worker.NewImpressionsCounstWorker(*impressionsCounter, impcountStorageConsumer, logger)
+	impcountsWorker := worker.NewImpressionsCounstWorker(impressionsCounter, impcountStorageConsumer, usageTracker, logger)
 	splitTasks.ImpsCountConsumerTask = task.NewImpressionCountSyncTask(impcountsWorker, logger, int(cfg.Sync.Advanced.ImpressionsCountWorkerReadRateMs/1000))
 	// @}
 
-	sdkTelemetryWorker := worker.NewTelemetryMultiWorker(logger, sdkTelemetryStorage, splitAPI.TelemetryRecorder)
+	sdkTelemetryWorker := worker.NewTelemetryMultiWorker(logger, sdkTelemetryStorage, splitAPI.TelemetryRecorder, impressionsModes)
 	sdkTelemetryTask := task.NewTelemetrySyncTask(sdkTelemetryWorker, logger, int(cfg.Sync.Advanced.TelemetryPushRateMs/1000))
-	syncImpl := ssync.NewSynchronizer(*advanced, splitTasks, workers, logger, nil, []tasks.Task{sdkTelemetryTask}, appMonitor)
+	// unique keys tracked locally in `none` mode are posted periodically & flushed on shutdown, since the pipelined
+	// task only pops the tracker when unique keys are fetched from redis
+	localUniqueKeysTask := tasks.NewRecordUniqueKeysTask(workers.TelemetryRecorder, uniqueKeysTracker, uniqueKeysPeriodTaskInMemory, logger)
+	extraTasks := []tasks.Task{sdkTelemetryTask, localUniqueKeysTask, changeFeedTask}
+
+	queueCapWorker, err := buildQueueCapWorker(&cfg.Sync.QueueCaps, storages, redisClient, logger)
+	if err != nil {
+		return common.NewInitError(fmt.Errorf("error instantiating queue caps: %w", err), common.ExitInvalidConfiguration)
+	}
+	if queueCapWorker.Enabled() {
+		extraTasks = append(extraTasks, task.NewQueueCapTask(queueCapWorker, logger, periodSecs(cfg.Sync.QueueCaps.PeriodMs)))
+	}
+
+	if exportSink != nil {
+		extraTasks = append(extraTasks, export.NewRotationTask(exportSink, logger, export.DefaultRotationPeriod))
+	}
+
+	// restore the unique keys filter & pending counts saved on the last shutdown. The dedupe state task goes last,
+	// so that it flushes & saves the counts once every other task has been stopped
+	dedupeStore, err := buildDedupeStateStore(&cfg.Sync.Dedupe, redisClient)
+	if err != nil {
+		return common.NewInitError(fmt.Errorf("error instantiating dedupe persistence: %w", err), common.ExitInvalidConfiguration)
+	}
+	dedupeWorker := worker.NewDedupeStateWorker(logger, worker.DedupeStateConfig{
+		Filter:         dedupeFilter,
+		FilterTTL:      time.Duration(cfg.Sync.Dedupe.FilterCleaningPeriodMs) * time.Millisecond,
+		Counter:        impressionsCounter,
+		CountsConsumer: impcountsWorker,
+		CountsRecorder: workers.ImpressionsCountRecorder,
+		Store:          dedupeStore,
+	})
+	if err := dedupeWorker.Restore(); err != nil {
+		logger.Error("error restoring dedupe state, starting from scratch: ", err)
+	}
+	extraTasks = append(extraTasks, task.NewDedupeStateTask(dedupeWorker, logger, periodSecs(cfg.Sync.Dedupe.CheckpointRateMs)))
+
+	syncImpl := ssync.NewSynchronizer(*advanced, splitTasks, workers, logger, nil, extraTasks, appMonitor)
 	managerStatus := make(chan int, 1)
 	syncManager, err := synchronizer.NewSynchronizerManager(
 		syncImpl,
@@ -265,9 +375,20 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 		return common.NewInitError(fmt.Errorf("error instantiating sync manager: %w", err), common.ExitTaskInitialization)
 	}
 
-	rtm := common.NewRuntime(false, syncManager, logger, "Split Synchronizer", nil, nil, appMonitor, servicesMonitor)
+	var closers []io.Closer
+	if webhookDispatcher != nil {
+		closers = append(closers, webhookDispatcher)
+	}
+	rtm := common.NewRuntime(false, syncManager, logger, "Split Synchronizer", nil, nil, appMonitor, servicesMonitor, closers...)
 
 	// --------------------------- ADMIN DASHBOARD ------------------------------
+	queueManager := buildQueueManager(cfg.Admin.QueueMaxDrop, cfg.Admin.QueuePeekLimit, redisClient, logger,
+		queuePipeline{name: "impressions", key: redis.KeyImpressionsQueue, pipeline: impTask},
+		queuePipeline{name: "events", key: redis.KeyEvents, pipeline: evTask},
+		queuePipeline{name: "uniquekeys", key: redis.KeyUniquekeys, pipeline: uniquesTask},
+	)
+	resyncer := ssync.NewResyncer(workers.SplitFetcher, workers.SegmentFetcher, splitAPI.SplitFetcher, splitAPI.SegmentFetcher,
+		storages.SplitStorage, storages.SegmentStorage, nil, logger)
 	cfgForAdmin := *cfg
 	cfgForAdmin.Apikey = logging.ObfuscateAPIKey(cfgForAdmin.Apikey)
 	adminServer, err := admin.NewServer(&admin.Options{
@@ -284,6 +405,15 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 		Runtime:           rtm,
 		HcAppMonitor:      appMonitor,
 		HcServicesMonitor: servicesMonitor,
+		History:           splitHistory,
+		ChangeFeed:        changeFeed,
+		Usage:             usageTracker,
+		ImpressionTap:     impressionTap,
+		Pipelines:         []*task.PipelinedSyncTask{impTask, evTask, uniquesTask},
+		QueueCaps:         queueCapWorker,
+		Queues:            queueManager,
+		Fairness:          []*fairness.Tracker{impFairness, evFairness},
+		Resyncer:          resyncer,
 		FullConfig:        cfgForAdmin,
 	})
 	if err != nil {
@@ -14,6 +14,10 @@ import (
 	"github.com/splitio/go-split-commons/v4/service/mocks"
 	predis "github.com/splitio/go-split-commons/v4/storage/redis"
 	"github.com/splitio/go-toolkit/v5/logging"
+	toolkitRedis "github.com/splitio/go-toolkit/v5/redis"
+	redisMocks "github.com/splitio/go-toolkit/v5/redis/mocks"
+
+	adminCommon "github.com/splitio/split-synchronizer/v5/splitio/admin/common"
 	cconf "github.com/splitio/split-synchronizer/v5/splitio/common/conf"
 	"github.com/splitio/split-synchronizer/v5/splitio/producer/conf"
 	"github.com/splitio/split-synchronizer/v5/splitio/util"
@@ -203,3 +207,38 @@ func getDefaultConf() *conf.Main {
 	cconf.PopulateDefaults(&c)
 	return &c
 }
+
+func TestQueueCapsTrimFailedLists(t *testing.T) {
+	trimmed := make(map[string]int64)
+	client, _ := toolkitRedis.NewPrefixedRedisClient(&redisMocks.MockClient{
+		LLenCall: func(key string) toolkitRedis.Result {
+			return &redisMocks.MockResultOutput{ResultCall: func() (int64, error) {
+				if key == predis.KeyEvents+".failed" {
+					return 150 - trimmed[key], nil
+				}
+				return 10, nil
+			}}
+		},
+		LTrimCall: func(key string, start, stop int64) toolkitRedis.Result {
+			trimmed[key] += start
+			return &redisMocks.MockResultOutput{ErrCall: func() error { return nil }}
+		},
+	}, "")
+
+	capWorker, err := buildQueueCapWorker(&conf.QueueCaps{FailedMaxLength: 100}, adminCommon.Storages{}, client, logging.NewLogger(nil))
+	if err != nil || !capWorker.Enabled() {
+		t.Fatal("the failed items lists should be capped. Got: ", err)
+	}
+
+	if err := capWorker.Enforce(); err != nil {
+		t.Error("no error should be returned. Got: ", err)
+	}
+
+	if len(trimmed) != 1 || trimmed[predis.KeyEvents+".failed"] != 50 {
+		t.Error("only the oldest items above the cap should be trimmed. Got: ", trimmed)
+	}
+
+	if status := capWorker.Status(); len(status) != 3 || status[1].Name != "events.failed" || status[1].Trimmed != 50 || status[1].Length != 100 {
+		t.Error("wrong status: ", status)
+	}
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/bits-and-blooms/bloom/v3"
+	"github.com/splitio/go-split-commons/v4/storage"
+)
+
+// ErrFilterMismatch is returned when restoring a filter built with different parameters
+var ErrFilterMismatch = errors.New("serialized filter parameters don't match the current ones")
+
+// BloomFilter is a thread-safe bloom filter that can be serialized & restored, so that the unique keys
+// tracked by the synchronizer survive restarts
+type BloomFilter struct {
+	mutex  sync.RWMutex
+	filter *bloom.BloomFilter
+}
+
+// NewBloomFilter constructs a filter sized for the expected number of elements & false positive probability
+func NewBloomFilter(expectedElements uint, falsePositiveProbability float64) *BloomFilter {
+	return &BloomFilter{filter: bloom.NewWithEstimates(expectedElements, falsePositiveProbability)}
+}
+
+// Add adds an element to the filter
+func (b *BloomFilter) Add(data string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.filter.Add([]byte(data))
+}
+
+// Contains returns whether an element may have been added to the filter
+func (b *BloomFilter) Contains(data string) bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.filter.Test([]byte(data))
+}
+
+// Clear removes every element from the filter
+func (b *BloomFilter) Clear() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.filter.ClearAll()
+}
+
+// MarshalBinary serializes the filter
+func (b *BloomFilter) MarshalBinary() ([]byte, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	var buffer bytes.Buffer
+	if _, err := b.filter.WriteTo(&buffer); err != nil {
+		return nil, fmt.Errorf("error serializing bloom filter: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalBinary replaces the contents of the filter with a serialized one,
+// as long as both were built with the same size & number of hash functions
+func (b *BloomFilter) UnmarshalBinary(data []byte) error {
+	restored := &bloom.BloomFilter{}
+	if _, err := restored.ReadFrom(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("error deserializing bloom filter: %w", err)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if restored.Cap() != b.filter.Cap() || restored.K() != b.filter.K() {
+		return ErrFilterMismatch
+	}
+	b.filter = restored
+	return nil
+}
+
+var _ storage.Filter = (*BloomFilter)(nil)
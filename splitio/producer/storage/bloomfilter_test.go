@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestBloomFilterSerialization(t *testing.T) {
+	filter := NewBloomFilter(1000, 0.01)
+	filter.Add("feature::key1")
+	filter.Add("feature::key2")
+
+	serialized, err := filter.MarshalBinary()
+	if err != nil {
+		t.Fatal("the filter should be serialized. Got: ", err)
+	}
+
+	restored := NewBloomFilter(1000, 0.01)
+	if err := restored.UnmarshalBinary(serialized); err != nil {
+		t.Fatal("the filter should be restored. Got: ", err)
+	}
+	if !restored.Contains("feature::key1") || !restored.Contains("feature::key2") || restored.Contains("feature::key3") {
+		t.Error("the restored filter should contain the original keys only")
+	}
+
+	restored.Clear()
+	if restored.Contains("feature::key1") {
+		t.Error("the filter should be empty after clearing it")
+	}
+
+	if err := NewBloomFilter(5000, 0.01).UnmarshalBinary(serialized); !errors.Is(err, ErrFilterMismatch) {
+		t.Error("filters with different sizes should be rejected. Got: ", err)
+	}
+
+	if err := restored.UnmarshalBinary([]byte("garbage")); err == nil {
+		t.Error("invalid filters should be rejected")
+	}
+}
+
+func TestFileDedupeStateStore(t *testing.T) {
+	store := NewFileDedupeStateStore(filepath.Join(t.TempDir(), "state.json"))
+	if state, err := store.Load(); err != nil || state != nil {
+		t.Error("nothing should be loaded before saving. Got: ", err, state)
+	}
+
+	if err := store.Save([]byte(`{"savedAt":1}`)); err != nil {
+		t.Fatal("the state should be saved. Got: ", err)
+	}
+	if err := store.Save([]byte(`{"savedAt":2}`)); err != nil {
+		t.Fatal("the state should be overwritten. Got: ", err)
+	}
+	if state, err := store.Load(); err != nil || string(state) != `{"savedAt":2}` {
+		t.Error("the last state should be loaded. Got: ", err, string(state))
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Error("the state should be removed. Got: ", err)
+	}
+	if state, _ := store.Load(); state != nil || store.Clear() != nil {
+		t.Error("clearing should be idempotent. Got: ", string(state))
+	}
+}
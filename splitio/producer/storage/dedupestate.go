@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/splitio/go-toolkit/v5/redis"
+)
+
+// KeyDedupeState is the redis key where the dedupe state is saved when using redis persistence
+const KeyDedupeState = "SPLITIO.synchronizer.dedupeState"
+
+// DedupeStateStore keeps the serialized unique keys filter & pending impression counts between restarts
+type DedupeStateStore interface {
+	Load() ([]byte, error) // returns nil if nothing has been saved
+	Save(state []byte) error
+	Clear() error
+}
+
+// RedisDedupeStateStore saves the dedupe state in a redis key
+type RedisDedupeStateStore struct {
+	client *redis.PrefixedRedisClient
+	key    string
+}
+
+// NewRedisDedupeStateStore constructs a dedupe state store backed by the redis key `key`
+func NewRedisDedupeStateStore(client *redis.PrefixedRedisClient, key string) *RedisDedupeStateStore {
+	return &RedisDedupeStateStore{client: client, key: key}
+}
+
+// Load returns the saved state, if any
+func (r *RedisDedupeStateStore) Load() ([]byte, error) {
+	state, err := r.client.Get(r.key)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading dedupe state from %s: %w", r.key, err)
+	}
+	return []byte(state), nil
+}
+
+// Save overwrites the saved state
+func (r *RedisDedupeStateStore) Save(state []byte) error {
+	if err := r.client.Set(r.key, state, 0); err != nil {
+		return fmt.Errorf("error writing dedupe state to %s: %w", r.key, err)
+	}
+	return nil
+}
+
+// Clear removes the saved state
+func (r *RedisDedupeStateStore) Clear() error {
+	if _, err := r.client.Del(r.key); err != nil {
+		return fmt.Errorf("error removing dedupe state from %s: %w", r.key, err)
+	}
+	return nil
+}
+
+// FileDedupeStateStore saves the dedupe state in a local file
+type FileDedupeStateStore struct {
+	path string
+}
+
+// NewFileDedupeStateStore constructs a dedupe state store backed by the file at `path`
+func NewFileDedupeStateStore(path string) *FileDedupeStateStore {
+	return &FileDedupeStateStore{path: path}
+}
+
+// Load returns the saved state, if any
+func (f *FileDedupeStateStore) Load() ([]byte, error) {
+	state, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading dedupe state from %s: %w", f.path, err)
+	}
+	return state, nil
+}
+
+// Save overwrites the saved state. The state is written to a temporary file first, so that a crash
+// while saving doesn't leave a truncated file behind
+func (f *FileDedupeStateStore) Save(state []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating temporary dedupe state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(state); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing dedupe state to %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error closing dedupe state file %s: %w", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("error moving dedupe state to %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// Clear removes the saved state
+func (f *FileDedupeStateStore) Clear() error {
+	if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing dedupe state file %s: %w", f.path, err)
+	}
+	return nil
+}
+
+var _ DedupeStateStore = (*RedisDedupeStateStore)(nil)
+var _ DedupeStateStore = (*FileDedupeStateStore)(nil)
@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/splitio/go-toolkit/v5/redis"
+)
+
+// FailedSuffix is appended to a queue key to build the name of the list holding the items that could not be posted
+const FailedSuffix = ".failed"
+
+// FailedItemsStorage defines the interface of a storage that keeps raw queue items which could not be posted
+type FailedItemsStorage interface {
+	Push(items []string) error
+	Count() int64
+}
+
+// RedisFailedItemsStorage keeps raw items that could not be posted in a dedicated redis list, using the same
+// format as the queue they were popped from, so that they can be inspected or moved back once the issue is solved
+type RedisFailedItemsStorage struct {
+	*RedisRawQueue
+}
+
+// NewRedisFailedItemsStorage constructs a failed-items storage for the queue stored in `queueKey`
+func NewRedisFailedItemsStorage(client *redis.PrefixedRedisClient, queueKey string, logger logging.LoggerInterface) *RedisFailedItemsStorage {
+	return &RedisFailedItemsStorage{RedisRawQueue: NewRedisRawQueue(client, queueKey+FailedSuffix, logger)}
+}
+
+var _ FailedItemsStorage = (*RedisFailedItemsStorage)(nil)
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"sync"
+
+	"github.com/splitio/go-split-commons/v4/conf"
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/telemetry"
+)
+
+// upper bound on the amount of sdk instances tracked, to avoid unbounded growth when instances are short-lived
+const maxTrackedInstances = 50000
+
+// SDKImpressionsModes keeps track of the impressions mode used by each sdk instance, as reported in the
+// telemetry config they store in redis. Instances whose config hasn't been seen yet are assumed to be using the fallback mode
+type SDKImpressionsModes struct {
+	fallback string
+	modes    map[dtos.Metadata]string
+	mutex    sync.RWMutex
+}
+
+// NewSDKImpressionsModes constructs an empty registry
+func NewSDKImpressionsModes(fallback string) *SDKImpressionsModes {
+	return &SDKImpressionsModes{
+		fallback: fallback,
+		modes:    make(map[dtos.Metadata]string),
+	}
+}
+
+// Update stores the impressions mode (as encoded in the telemetry config) used by a particular sdk instance
+func (s *SDKImpressionsModes) Update(metadata dtos.Metadata, telemetryMode int) {
+	mode := s.fallback
+	switch telemetryMode {
+	case telemetry.ImpressionsModeOptimized:
+		mode = conf.ImpressionsModeOptimized
+	case telemetry.ImpressionsModeDebug:
+		mode = conf.ImpressionsModeDebug
+	case telemetry.ImpressionsModeNone:
+		mode = conf.ImpressionsModeNone
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.modes[metadata]; !ok && len(s.modes) >= maxTrackedInstances {
+		for evicted := range s.modes { // evict an arbitrary instance. it will fall back to the default mode until seen again
+			delete(s.modes, evicted)
+			break
+		}
+	}
+	s.modes[metadata] = mode
+}
+
+// Fallback returns the mode assumed for instances whose config hasn't been seen yet
+func (s *SDKImpressionsModes) Fallback() string {
+	return s.fallback
+}
+
+// Get returns the impressions mode used by an sdk instance
+func (s *SDKImpressionsModes) Get(metadata dtos.Metadata) string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if mode, ok := s.modes[metadata]; ok {
+		return mode
+	}
+	return s.fallback
+}
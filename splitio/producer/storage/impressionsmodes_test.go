@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/splitio/go-split-commons/v4/conf"
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/telemetry"
+)
+
+func TestSDKImpressionsModes(t *testing.T) {
+	modes := NewSDKImpressionsModes(conf.ImpressionsModeOptimized)
+	m1 := dtos.Metadata{SDKVersion: "go-1.1.1", MachineName: "m1", MachineIP: "1.1.1.1"}
+	m2 := dtos.Metadata{SDKVersion: "php-1.1.1", MachineName: "m2", MachineIP: "2.2.2.2"}
+	m3 := dtos.Metadata{SDKVersion: "ruby-1.1.1", MachineName: "m3", MachineIP: "3.3.3.3"}
+
+	if mode := modes.Get(m1); mode != conf.ImpressionsModeOptimized {
+		t.Error("unknown instances should use the fallback mode. Got: ", mode)
+	}
+
+	modes.Update(m1, telemetry.ImpressionsModeDebug)
+	modes.Update(m2, telemetry.ImpressionsModeNone)
+	modes.Update(m3, 42)
+	if mode := modes.Get(m1); mode != conf.ImpressionsModeDebug {
+		t.Error("m1 should be in debug mode. Got: ", mode)
+	}
+	if mode := modes.Get(m2); mode != conf.ImpressionsModeNone {
+		t.Error("m2 should be in none mode. Got: ", mode)
+	}
+	if mode := modes.Get(m3); mode != conf.ImpressionsModeOptimized {
+		t.Error("unrecognized modes should map to the fallback one. Got: ", mode)
+	}
+
+	modes.Update(m1, telemetry.ImpressionsModeOptimized)
+	if mode := modes.Get(m1); mode != conf.ImpressionsModeOptimized {
+		t.Error("m1 should have been updated to optimized mode. Got: ", mode)
+	}
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/splitio/go-toolkit/v5/redis"
+)
+
+// RedisRawQueue appends & pops raw (already serialized) items to/from a redis list
+type RedisRawQueue struct {
+	client *redis.PrefixedRedisClient
+	key    string
+	logger logging.LoggerInterface
+}
+
+// NewRedisRawQueue constructs a raw queue backed by the list stored in `key`
+func NewRedisRawQueue(client *redis.PrefixedRedisClient, key string, logger logging.LoggerInterface) *RedisRawQueue {
+	return &RedisRawQueue{client: client, key: key, logger: logger}
+}
+
+// Push appends the raw items to the list
+func (r *RedisRawQueue) Push(items []string) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	values := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		values = append(values, item)
+	}
+
+	if _, err := r.client.RPush(r.key, values...); err != nil {
+		return fmt.Errorf("error pushing %d items into %s: %w", len(items), r.key, err)
+	}
+	return nil
+}
+
+// Count returns the number of items in the list
+func (r *RedisRawQueue) Count() int64 {
+	count, err := r.client.LLen(r.key)
+	if err != nil {
+		r.logger.Error(fmt.Sprintf("error fetching the size of %s: %s", r.key, err))
+		return 0
+	}
+	return count
+}
+
+// Peek returns up to `n` items from the head of the list without removing them
+func (r *RedisRawQueue) Peek(n int64) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	items, err := r.client.LRange(r.key, 0, n-1)
+	if err != nil {
+		return nil, fmt.Errorf("error reading items from %s: %w", r.key, err)
+	}
+	return items, nil
+}
+
+// Drop removes the `n` oldest items of the list without transferring them
+func (r *RedisRawQueue) Drop(n int64) error {
+	if n <= 0 {
+		return nil
+	}
+
+	if err := r.client.LTrim(r.key, n, -1); err != nil {
+		return fmt.Errorf("error dropping %d items from %s: %w", n, r.key, err)
+	}
+	return nil
+}
+
+// PopNRaw removes & returns up to `n` items from the head of the list, along with the number of items left
+func (r *RedisRawQueue) PopNRaw(n int64) ([]string, int64, error) {
+	items, err := r.Peek(n)
+	if err != nil || len(items) == 0 {
+		return nil, 0, err
+	}
+
+	if err := r.Drop(int64(len(items))); err != nil {
+		return nil, 0, err
+	}
+	return items, r.Count(), nil
+}
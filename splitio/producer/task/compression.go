@@ -0,0 +1,75 @@
+package task
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Compressor gzips the body of outbound requests, reusing writers & buffers across posts to avoid GC churn.
+// A single instance can (and should) be shared among all pipelined tasks
+type Compressor struct {
+	level   int
+	writers sync.Pool
+	buffers sync.Pool
+}
+
+// NewCompressor constructs a gzip compressor with the supplied level (see compress/gzip for valid values)
+func NewCompressor(level int) (*Compressor, error) {
+	if _, err := gzip.NewWriterLevel(nil, level); err != nil {
+		return nil, fmt.Errorf("invalid compression level: %w", err)
+	}
+
+	c := &Compressor{level: level}
+	c.writers.New = func() interface{} {
+		writer, _ := gzip.NewWriterLevel(nil, level) // level already validated
+		return writer
+	}
+	c.buffers.New = func() interface{} { return new(bytes.Buffer) }
+	return c, nil
+}
+
+// compress replaces the body of the request with its gzipped version, returning the sizes before & after compression
+func (c *Compressor) compress(req *http.Request) (int64, int64, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return 0, 0, nil
+	}
+	defer req.Body.Close()
+
+	buffer := c.buffers.Get().(*bytes.Buffer)
+	buffer.Reset()
+	writer := c.writers.Get().(*gzip.Writer)
+	writer.Reset(buffer)
+	defer c.writers.Put(writer)
+
+	raw, err := io.Copy(writer, req.Body)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		c.buffers.Put(buffer)
+		return 0, 0, fmt.Errorf("error compressing request body: %w", err)
+	}
+
+	compressed := int64(buffer.Len())
+	req.Body = &pooledBody{Reader: bytes.NewReader(buffer.Bytes()), release: func() { c.buffers.Put(buffer) }}
+	req.GetBody = nil // the buffer goes back to the pool once the transport is done with it, so the body cannot be replayed
+	req.ContentLength = compressed
+	req.Header.Set("Content-Encoding", "gzip")
+	return raw, compressed, nil
+}
+
+// pooledBody is a request body backed by a pooled buffer, which is released when the http transport closes it
+type pooledBody struct {
+	*bytes.Reader
+	release func()
+	once    sync.Once
+}
+
+func (b *pooledBody) Close() error {
+	b.once.Do(b.release)
+	return nil
+}
@@ -0,0 +1,122 @@
+package task
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+func TestCompressor(t *testing.T) {
+	if _, err := NewCompressor(42); err == nil {
+		t.Error("an invalid level should be rejected")
+	}
+
+	compressor, err := NewCompressor(gzip.BestSpeed)
+	if err != nil {
+		t.Error("unexpected error: ", err)
+	}
+
+	payload := strings.Repeat(`{"keyName":"someKey","treatment":"on"}`, 1000)
+	for idx := 0; idx < 3; idx++ { // make sure pooled writers & buffers are properly reset
+		req, _ := http.NewRequest("POST", "http://localhost", bytes.NewReader([]byte(payload)))
+		raw, compressed, err := compressor.compress(req)
+		if err != nil {
+			t.Error("unexpected error: ", err)
+		}
+
+		if raw != int64(len(payload)) || compressed >= raw || req.ContentLength != compressed {
+			t.Error("unexpected sizes: ", raw, compressed, req.ContentLength)
+		}
+
+		if req.Header.Get("Content-Encoding") != "gzip" {
+			t.Error("content encoding should be set")
+		}
+
+		reader, err := gzip.NewReader(req.Body)
+		if err != nil {
+			t.Error("body should be gzipped: ", err)
+		}
+		decompressed, _ := ioutil.ReadAll(reader)
+		if string(decompressed) != payload {
+			t.Error("decompressed payload doesn't match the original one")
+		}
+		req.Body.Close()
+		req.Body.Close() // releasing twice must not put the buffer back in the pool twice
+	}
+
+	empty, _ := http.NewRequest("GET", "http://localhost", nil)
+	if raw, compressed, err := compressor.compress(empty); raw != 0 || compressed != 0 || err != nil {
+		t.Error("requests without body should be left untouched")
+	}
+	if empty.Header.Get("Content-Encoding") != "" {
+		t.Error("requests without body should not have a content encoding")
+	}
+}
+
+func TestPipelineTaskCompressesPosts(t *testing.T) {
+	payload := strings.Repeat("some impression ", 500)
+	var httpCalls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&httpCalls, 1)
+		if r.Header.Get("Content-Encoding") != "gzip" {
+			t.Error("posts should be gzipped")
+		}
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Error("body should be gzipped: ", err)
+			return
+		}
+		if body, _ := ioutil.ReadAll(reader); string(body) != payload {
+			t.Error("unexpected body: ", string(body))
+		}
+	}))
+	defer server.Close()
+
+	var fetchCalls int64
+	w := &mockWorker{
+		fetchCall: func() ([]string, error) {
+			if atomic.AddInt64(&fetchCalls, 1) > 1 {
+				return nil, nil
+			}
+			return []string{"a"}, nil
+		},
+		processCall: func(rawData [][]byte, sink chan<- interface{}) error {
+			sink <- payload
+			return nil
+		},
+		buildRequestCall: func(data interface{}) (*http.Request, error) {
+			return http.NewRequest("POST", server.URL, strings.NewReader(data.(string)))
+		},
+	}
+
+	compressor, _ := NewCompressor(gzip.DefaultCompression)
+	task, err := NewPipelinedTask(&Config{
+		Worker:       w,
+		Logger:       logging.NewLogger(nil),
+		MaxAccumWait: 100 * time.Millisecond,
+		Compressor:   compressor,
+	})
+	if err != nil {
+		t.Error("task init: ", err)
+	}
+	task.Start()
+	time.Sleep(1 * time.Second)
+	task.Stop(true)
+
+	if c := atomic.LoadInt64(&httpCalls); c != 1 {
+		t.Error("there should be 1 post. Got: ", c)
+	}
+
+	metrics := task.Metrics()
+	if metrics.RawBytes != int64(len(payload)) || metrics.SentBytes == 0 || metrics.SentBytes >= metrics.RawBytes {
+		t.Error("unexpected byte counts: ", metrics.RawBytes, metrics.SentBytes)
+	}
+}
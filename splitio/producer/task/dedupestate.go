@@ -0,0 +1,32 @@
+package task
+
+import (
+	"github.com/splitio/go-toolkit/v5/asynctask"
+	"github.com/splitio/go-toolkit/v5/logging"
+	producerStorage "github.com/splitio/split-synchronizer/v5/splitio/producer/storage"
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/worker"
+)
+
+// NewDedupeStateTask constructs a task used to periodically save the unique keys filter, and to flush & save the
+// pending impression counts when the synchronizer shuts down. It should be stopped after the impression count tasks
+func NewDedupeStateTask(wrk *worker.DedupeStateWorker, logger logging.LoggerInterface, period int) *asynctask.AsyncTask {
+	doWork := func(l logging.LoggerInterface) error {
+		return wrk.Checkpoint()
+	}
+	onStop := func(l logging.LoggerInterface) {
+		if err := wrk.Shutdown(); err != nil {
+			l.Error("error saving dedupe state on shutdown: ", err)
+		}
+	}
+	return asynctask.NewAsyncTask("dedupe-state", doWork, period, nil, onStop, logger)
+}
+
+// NewCleanFilterTask constructs a task used to periodically clear the unique keys filter. Unlike the one in commons,
+// it doesn't clear the filter when stopped, since it's saved by the dedupe state task afterwards
+func NewCleanFilterTask(filter *producerStorage.BloomFilter, logger logging.LoggerInterface, period int) *asynctask.AsyncTask {
+	doWork := func(l logging.LoggerInterface) error {
+		filter.Clear()
+		return nil
+	}
+	return asynctask.NewAsyncTask("clean-filter", doWork, period, nil, nil, logger)
+}
@@ -0,0 +1,21 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	producerStorage "github.com/splitio/split-synchronizer/v5/splitio/producer/storage"
+)
+
+func TestCleanFilterTaskKeepsFilterOnStop(t *testing.T) {
+	filter := producerStorage.NewBloomFilter(1000, 0.01)
+	cleanTask := NewCleanFilterTask(filter, logging.NewLogger(nil), 3600)
+	cleanTask.Start()
+	filter.Add("feature1key1")
+	cleanTask.Stop(true)
+
+	if !filter.Contains("feature1key1") {
+		t.Error("the filter should not be cleared on stop, so that it can be saved")
+	}
+}
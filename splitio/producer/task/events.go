@@ -3,15 +3,19 @@ package task
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/splitio/go-split-commons/v4/dtos"
 	"github.com/splitio/go-split-commons/v4/storage"
 	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/export"
 	"github.com/splitio/split-synchronizer/v5/splitio/producer/evcalc"
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/fairness"
 )
 
 const (
@@ -27,6 +31,9 @@ type EventWorkerConfig struct {
 	URL             string
 	Apikey          string
 	FetchSize       int
+	Fairness        *fairness.Tracker
+	Requeue         RawQueue // where events deferred by the fairness policy are pushed back
+	Exporter        *export.Sink
 }
 
 func (c *EventWorkerConfig) normalize() {
@@ -40,6 +47,9 @@ type EventsPipelineWorker struct {
 	logger          logging.LoggerInterface
 	storage         storage.EventMultiSdkConsumer
 	evictionMonitor evcalc.Monitor
+	fairness        *fairness.Tracker
+	requeue         RawQueue
+	exporter        *export.Sink
 
 	url       string
 	apikey    string
@@ -57,6 +67,9 @@ func NewEventsWorker(cfg *EventWorkerConfig) (*EventsPipelineWorker, error) {
 		url:             cfg.URL + "/events/bulk",
 		apikey:          cfg.Apikey,
 		fetchSize:       int64(cfg.FetchSize),
+		fairness:        cfg.Fairness,
+		requeue:         cfg.Requeue,
+		exporter:        cfg.Exporter,
 		pool:            newEventWorkerMemoryPool(cfg.FetchSize, defaultMetasPerBulk, defaultEventsPerBulk),
 	}, nil
 }
@@ -67,14 +80,27 @@ func NewEventsWorker(cfg *EventWorkerConfig) (*EventsPipelineWorker, error) {
 // We should eventually revisit the redis client interface and see how feasible it is
 // to return bytes directly.
 func (i *EventsPipelineWorker) Fetch() ([]string, error) {
-	raw, sizeAfterPop, err := i.storage.PopNRaw(i.fetchSize)
+	raw, sizeAfterPop, err := i.storage.PopNRaw(atomic.LoadInt64(&i.fetchSize))
 	if err != nil {
 		return nil, fmt.Errorf("error fetching raw events: %w", err)
 	}
 	i.evictionMonitor.StoreDataFlushed(time.Now(), len(raw), sizeAfterPop)
+	if i.fairness != nil {
+		i.fairness.SetBacklog(sizeAfterPop)
+	}
 	return raw, nil
 }
 
+// FetchSize returns the number of items popped from storage on each fetch
+func (i *EventsPipelineWorker) FetchSize() int64 {
+	return atomic.LoadInt64(&i.fetchSize)
+}
+
+// SetFetchSize updates the number of items popped from storage on each fetch
+func (i *EventsPipelineWorker) SetFetchSize(size int64) {
+	atomic.StoreInt64(&i.fetchSize, size)
+}
+
 // Process parses the raw data and packages the events
 func (i *EventsPipelineWorker) Process(raws [][]byte, sink chan<- interface{}) error {
 	batches := newEventBatches(i.pool)
@@ -82,6 +108,7 @@ func (i *EventsPipelineWorker) Process(raws [][]byte, sink chan<- interface{}) e
 	// which will be released after imrpessions have been successfully posted
 	defer batches.recycleContainer()
 
+	var deferred []string
 	for _, raw := range raws {
 		var queueObj dtos.QueueStoredEventDTO
 		err := json.Unmarshal(raw, &queueObj)
@@ -89,9 +116,42 @@ func (i *EventsPipelineWorker) Process(raws [][]byte, sink chan<- interface{}) e
 			i.logger.Error("error deserializing fetched events: ", err.Error())
 			continue
 		}
+
+		if i.fairness != nil {
+			switch i.fairness.Admit(&queueObj.Metadata, raw) {
+			case fairness.Defer:
+				deferred = append(deferred, string(raw))
+				continue
+			case fairness.Drop:
+				continue
+			}
+		}
 		batches.add(&queueObj)
 	}
 
+	if len(deferred) > 0 {
+		var err error
+		if i.requeue == nil {
+			err = errors.New("no queue to push them back to")
+		} else {
+			err = i.requeue.Push(deferred)
+		}
+		if err != nil {
+			i.logger.Error(fmt.Sprintf("%d events deferred by the fairness policy will be dropped: %s", len(deferred), err))
+			for _, raw := range deferred {
+				i.fairness.Forget([]byte(raw))
+			}
+		}
+	}
+
+	if i.exporter != nil {
+		for _, group := range batches.groups {
+			if err := i.exporter.WriteEvents(&group.metadata, group.events); err != nil {
+				i.logger.Error("error exporting events: ", err.Error())
+			}
+		}
+	}
+
 	for retIndex := range batches.groups {
 		sink <- batches.groups[retIndex]
 	}
@@ -120,6 +180,32 @@ func (i *EventsPipelineWorker) BuildRequest(data interface{}) (*http.Request, er
 	return req, nil
 }
 
+// Requeue serializes a bulk of events back into the queue format used by the SDKs
+func (i *EventsPipelineWorker) Requeue(data interface{}) ([]string, error) {
+	ewm, ok := data.(eventsWithMetadata)
+	if !ok {
+		return nil, fmt.Errorf("expected `eventsWithMeta`. Got: %T", data)
+	}
+
+	raw := make([]string, 0, len(ewm.events))
+	for _, event := range ewm.events {
+		serialized, err := json.Marshal(dtos.QueueStoredEventDTO{Metadata: ewm.metadata, Event: event})
+		if err != nil {
+			return nil, fmt.Errorf("error serializing event: %w", err)
+		}
+		raw = append(raw, string(serialized))
+	}
+	return raw, nil
+}
+
+// ItemCount returns the number of events in a bulk
+func (i *EventsPipelineWorker) ItemCount(data interface{}) int {
+	if ewm, ok := data.(eventsWithMetadata); ok {
+		return ewm.count
+	}
+	return 0
+}
+
 type eventBatches struct {
 	groups eventsWithMetaSlice
 	index  metadataMap
@@ -243,3 +329,6 @@ func (p *eventsMemoryPoolImpl) releaseEventsWithMeta(t eventsWithMetaSlice) {
 
 var _ eventsMemoryPool = (*eventsMemoryPoolImpl)(nil)
 var _ Worker = (*EventsPipelineWorker)(nil)
+var _ Requeuer = (*EventsPipelineWorker)(nil)
+var _ FetchSizeAdjuster = (*EventsPipelineWorker)(nil)
+var _ ItemCounter = (*EventsPipelineWorker)(nil)
@@ -5,7 +5,9 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -14,6 +16,7 @@ import (
 	"github.com/splitio/go-split-commons/v4/dtos"
 	"github.com/splitio/go-split-commons/v4/storage/mocks"
 	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/export"
 	"github.com/splitio/split-synchronizer/v5/splitio/producer/evcalc"
 )
 
@@ -205,3 +208,70 @@ func TestEventsIntegration(t *testing.T) {
 		t.Error("machine2 should have 500 events. Has ", r)
 	}
 }
+
+func TestEventsRequeue(t *testing.T) {
+	w, _ := NewEventsWorker(&EventWorkerConfig{
+		EvictionMonitor: evcalc.New(1),
+		Logger:          logging.NewLogger(nil),
+		Storage:         mocks.MockEventStorage{},
+		URL:             "http://test",
+		Apikey:          "someApikey",
+		FetchSize:       100,
+	})
+
+	raws := makeSerializedEvents(2, 5)
+	sinker := make(chan interface{}, 100)
+	w.Process(raws, sinker)
+	close(sinker)
+
+	var requeued []string
+	for bulk := range sinker {
+		items, err := w.Requeue(bulk)
+		if err != nil {
+			t.Error("there should be no error. Got: ", err)
+		}
+		requeued = append(requeued, items...)
+	}
+
+	if len(requeued) != len(raws) {
+		t.Fatal("every event should be requeued. Got: ", len(requeued))
+	}
+	for idx := range raws {
+		if requeued[idx] != string(raws[idx]) {
+			t.Errorf("requeued event should match the original one. Expected %s, got %s", raws[idx], requeued[idx])
+		}
+	}
+
+	if _, err := w.Requeue("something else"); err == nil {
+		t.Error("requeuing an unexpected type should fail")
+	}
+}
+
+func TestEventsExport(t *testing.T) {
+	dir := t.TempDir()
+	sink, _ := export.NewSink(&export.Config{Logger: logging.NewLogger(nil), Directory: dir})
+	w, _ := NewEventsWorker(&EventWorkerConfig{
+		EvictionMonitor: evcalc.New(1),
+		Logger:          logging.NewLogger(nil),
+		Storage:         mocks.MockEventStorage{},
+		URL:             "http://test",
+		Apikey:          "someApikey",
+		FetchSize:       100,
+		Exporter:        sink,
+	})
+
+	sinkChan := make(chan interface{}, 10)
+	if err := w.Process(makeSerializedEvents(2, 3), sinkChan); err != nil {
+		t.Error("no error should be returned. Got: ", err)
+	}
+	close(sinkChan)
+	for bulk := range sinkChan {
+		bulk.(eventsWithMetadata).recycle()
+	}
+
+	sink.Close()
+	manifest, _ := ioutil.ReadFile(filepath.Join(dir, export.ManifestName))
+	if lines := strings.Split(strings.TrimSpace(string(manifest)), "\n"); len(lines) != 1 || !strings.Contains(lines[0], `"records":6`) {
+		t.Error("every processed event should be exported. Got: ", string(manifest))
+	}
+}
@@ -7,7 +7,7 @@ import (
 )
 
 func NewImpressionCountSyncTask(
-	wrk worker.ImpressionsCounstWorkerImp,
+	wrk *worker.ImpressionsCounstWorkerImp,
 	logger logging.LoggerInterface,
 	period int,
 ) *asynctask.AsyncTask {
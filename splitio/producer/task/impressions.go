@@ -3,17 +3,26 @@ package task
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/splitio/go-split-commons/v4/conf"
 	"github.com/splitio/go-split-commons/v4/dtos"
 	"github.com/splitio/go-split-commons/v4/provisional"
+	"github.com/splitio/go-split-commons/v4/provisional/strategy"
 	"github.com/splitio/go-split-commons/v4/storage"
 	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/export"
 	"github.com/splitio/split-synchronizer/v5/splitio/common/impressionlistener"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/impressiontap"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/usage"
 	"github.com/splitio/split-synchronizer/v5/splitio/producer/evcalc"
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/fairness"
+	producerStorage "github.com/splitio/split-synchronizer/v5/splitio/producer/storage"
 )
 
 const (
@@ -24,6 +33,11 @@ const (
 	defaultBulkSize       = defaultFeatureCount * defaultImpsPerFeature
 )
 
+// RawQueue receives the raw items deferred by the fairness policy, so that they're processed in a later window
+type RawQueue interface {
+	Push(items []string) error
+}
+
 // ImpressionWorkerConfig bundles options
 type ImpressionWorkerConfig struct {
 	Logger              logging.LoggerInterface
@@ -33,13 +47,25 @@ type ImpressionWorkerConfig struct {
 	URL                 string
 	Apikey              string
 	FetchSize           int
-	ImpressionManager   provisional.ImpressionManager
+	ImpressionManager   provisional.ImpressionManager            // applied to sdks whose impressions mode has no dedicated manager
+	ImpressionManagers  map[string]provisional.ImpressionManager // dedupe strategy for each sdk impressions mode
+	UsageTracker        *usage.Tracker
+	ImpressionTap       *impressiontap.Tap
+	ImpressionsModes    *producerStorage.SDKImpressionsModes
+	Fairness            *fairness.Tracker
+	Requeue             RawQueue                     // where impressions deferred by the fairness policy are pushed back
+	ImpressionsCounter  *strategy.ImpressionsCounter // counts the impressions dropped by the fairness policy
+	Exporter            *export.Sink
 }
 
 func (c *ImpressionWorkerConfig) normalize() {
 	if c.FetchSize == 0 {
 		c.FetchSize = defaultImpFetchSize
 	}
+
+	if c.ImpressionsModes == nil {
+		c.ImpressionsModes = producerStorage.NewSDKImpressionsModes(conf.ImpressionsModeOptimized)
+	}
 }
 
 // ImpressionsPipelineWorker implements all the required  methods to work with a pipelined task
@@ -47,8 +73,16 @@ type ImpressionsPipelineWorker struct {
 	logger          logging.LoggerInterface
 	storage         storage.ImpressionMultiSdkConsumer
 	impManager      provisional.ImpressionManager
+	impManagers     map[string]provisional.ImpressionManager
 	impListener     impressionlistener.ImpressionBulkListener
 	evictionMonitor evcalc.Monitor
+	usageTracker    *usage.Tracker
+	tap             *impressiontap.Tap
+	modes           *producerStorage.SDKImpressionsModes
+	fairness        *fairness.Tracker
+	requeue         RawQueue
+	counter         *strategy.ImpressionsCounter
+	exporter        *export.Sink
 
 	url       string
 	apikey    string
@@ -65,10 +99,18 @@ func NewImpressionWorker(cfg *ImpressionWorkerConfig) (*ImpressionsPipelineWorke
 		storage:         cfg.Storage,
 		impListener:     cfg.ImpressionsListener,
 		impManager:      cfg.ImpressionManager,
+		impManagers:     cfg.ImpressionManagers,
 		url:             cfg.URL + "/testImpressions/bulk",
 		apikey:          cfg.Apikey,
 		fetchSize:       int64(cfg.FetchSize),
 		evictionMonitor: cfg.EvictionMonitor,
+		usageTracker:    cfg.UsageTracker,
+		tap:             cfg.ImpressionTap,
+		modes:           cfg.ImpressionsModes,
+		fairness:        cfg.Fairness,
+		requeue:         cfg.Requeue,
+		counter:         cfg.ImpressionsCounter,
+		exporter:        cfg.Exporter,
 		pool:            newImpWorkerMemoryPool(cfg.FetchSize, defaultMetasPerBulk, defaultFeatureCount, defaultImpsPerFeature),
 	}, nil
 }
@@ -79,14 +121,27 @@ func NewImpressionWorker(cfg *ImpressionWorkerConfig) (*ImpressionsPipelineWorke
 // We should eventually revisit the redis client interface and see how feasible it is
 // to return bytes directly.
 func (i *ImpressionsPipelineWorker) Fetch() ([]string, error) {
-	raw, sizeAfterPop, err := i.storage.PopNRaw(i.fetchSize)
+	raw, sizeAfterPop, err := i.storage.PopNRaw(atomic.LoadInt64(&i.fetchSize))
 	if err != nil {
 		return nil, fmt.Errorf("error fetching raw impressions: %w", err)
 	}
 	i.evictionMonitor.StoreDataFlushed(time.Now(), len(raw), sizeAfterPop)
+	if i.fairness != nil {
+		i.fairness.SetBacklog(sizeAfterPop)
+	}
 	return raw, nil
 }
 
+// FetchSize returns the number of items popped from storage on each fetch
+func (i *ImpressionsPipelineWorker) FetchSize() int64 {
+	return atomic.LoadInt64(&i.fetchSize)
+}
+
+// SetFetchSize updates the number of items popped from storage on each fetch
+func (i *ImpressionsPipelineWorker) SetFetchSize(size int64) {
+	atomic.StoreInt64(&i.fetchSize, size)
+}
+
 // Process parses the raw data and packages the impressions
 func (i *ImpressionsPipelineWorker) Process(raws [][]byte, sink chan<- interface{}) error {
 	batches := newImpBatches(i.pool)
@@ -95,6 +150,12 @@ func (i *ImpressionsPipelineWorker) Process(raws [][]byte, sink chan<- interface
 	defer batches.recycleContainer()
 
 	deduped := 0
+	throttled := 0
+	var deferred []string
+	var deferredImps []dtos.Impression
+	usageBatch := make(usage.Batch)
+	tapActive := i.tap != nil && i.tap.Active()
+	applied := make(map[dtos.Metadata]appliedMode)
 	for _, raw := range raws {
 		var queueObj dtos.ImpressionQueueObject
 		err := json.Unmarshal(raw, &queueObj)
@@ -103,21 +164,75 @@ func (i *ImpressionsPipelineWorker) Process(raws [][]byte, sink chan<- interface
 			continue
 		}
 
-		toLog := i.impManager.ProcessSingle(&queueObj.Impression)
+		if i.fairness != nil {
+			switch i.fairness.Admit(&queueObj.Metadata, raw) {
+			case fairness.Defer:
+				deferred = append(deferred, string(raw))
+				deferredImps = append(deferredImps, queueObj.Impression)
+				continue
+			case fairness.Drop:
+				throttled++
+				i.countDropped(&queueObj.Impression)
+				continue
+			}
+		}
+
+		// usage is tracked once admitted & prior to deduping, so that every evaluation counts exactly once
+		usageBatch.Add(queueObj.Metadata.SDKVersion, queueObj.Impression.FeatureName, queueObj.Impression.Treatment, 1)
+		if tapActive {
+			i.tap.Offer(&queueObj.Metadata, queueObj.Impression.FeatureName, &dtos.ImpressionDTO{
+				KeyName:      queueObj.Impression.KeyName,
+				BucketingKey: queueObj.Impression.BucketingKey,
+				Treatment:    queueObj.Impression.Treatment,
+				Label:        queueObj.Impression.Label,
+				ChangeNumber: queueObj.Impression.ChangeNumber,
+				Time:         queueObj.Impression.Time,
+			})
+		}
+
+		mode, ok := applied[queueObj.Metadata]
+		if !ok {
+			mode = i.managerFor(&queueObj.Metadata)
+			applied[queueObj.Metadata] = mode
+		}
+
+		toLog := mode.manager.ProcessSingle(&queueObj.Impression)
 		if !toLog {
 			deduped++
 			continue
 		}
 
-		batches.add(&queueObj)
+		batches.add(&queueObj, mode.name)
+	}
+
+	if i.usageTracker != nil {
+		i.usageTracker.Record(usageBatch)
 	}
 
-	i.logger.Debug(fmt.Sprintf("[pipelined imp worker] total impressions Processed: %d, deduped %d", len(raws), deduped))
+	i.logger.Debug(fmt.Sprintf("[pipelined imp worker] total impressions Processed: %d, deduped %d, throttled %d, deferred %d", len(raws), deduped, throttled, len(deferred)))
+
+	if len(deferred) > 0 {
+		if err := i.pushDeferred(deferred); err != nil {
+			i.logger.Error(fmt.Sprintf("%d impressions deferred by the fairness policy will be dropped: %s", len(deferred), err))
+			for idx := range deferredImps {
+				i.fairness.Forget([]byte(deferred[idx]))
+				i.countDropped(&deferredImps[idx])
+			}
+		}
+	}
 
 	if i.impListener != nil {
 		i.sendImpressionsToListener(batches)
 	}
 
+	if i.exporter != nil {
+		for _, group := range batches.groups {
+			if err := i.exporter.WriteImpressions(&group.metadata, group.imps); err != nil {
+				i.logger.Error("error exporting impressions: ", err.Error())
+			}
+		}
+	}
+
 	for retIndex := range batches.groups {
 		sink <- batches.groups[retIndex]
 	}
@@ -144,10 +259,81 @@ func (i *ImpressionsPipelineWorker) BuildRequest(data interface{}) (*http.Reques
 	req.Header.Add("SplitSDKVersion", iwm.metadata.SDKVersion)
 	req.Header.Add("SplitSDKMachineIp", iwm.metadata.MachineIP)
 	req.Header.Add("SplitSDKMachineName", iwm.metadata.MachineName)
-	req.Header.Add("SplitSDKImpressionsMode", "optimized") // TODO(mredolatti): populate this correctly
+	req.Header.Add("SplitSDKImpressionsMode", iwm.mode)
 	return req, nil
 }
 
+// Requeue serializes a bulk of impressions back into the queue format used by the SDKs
+func (i *ImpressionsPipelineWorker) Requeue(data interface{}) ([]string, error) {
+	iwm, ok := data.(impsWithMetadata)
+	if !ok {
+		return nil, fmt.Errorf("expected `impsWithMeta`. Got: %T", data)
+	}
+
+	raw := make([]string, 0, iwm.count)
+	for _, ti := range iwm.imps {
+		for _, ki := range ti.KeyImpressions {
+			serialized, err := json.Marshal(dtos.ImpressionQueueObject{
+				Metadata: iwm.metadata,
+				Impression: dtos.Impression{
+					KeyName:      ki.KeyName,
+					BucketingKey: ki.BucketingKey,
+					FeatureName:  ti.TestName,
+					Treatment:    ki.Treatment,
+					Label:        ki.Label,
+					ChangeNumber: ki.ChangeNumber,
+					Time:         ki.Time,
+					Pt:           ki.Pt,
+				},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error serializing impression: %w", err)
+			}
+			raw = append(raw, string(serialized))
+		}
+	}
+	return raw, nil
+}
+
+// ItemCount returns the number of impressions in a bulk
+func (i *ImpressionsPipelineWorker) ItemCount(data interface{}) int {
+	if iwm, ok := data.(impsWithMetadata); ok {
+		return iwm.count
+	}
+	return 0
+}
+
+// appliedMode is the impressions mode (and the matching dedupe strategy) applied to the impressions of an sdk instance
+type appliedMode struct {
+	name    string
+	manager provisional.ImpressionManager
+}
+
+// managerFor picks the dedupe strategy matching the impressions mode of an sdk instance, so that the mode reported
+// when posting its impressions is the one that was actually applied
+func (i *ImpressionsPipelineWorker) managerFor(metadata *dtos.Metadata) appliedMode {
+	mode := i.modes.Get(*metadata)
+	if manager, ok := i.impManagers[mode]; ok {
+		return appliedMode{name: mode, manager: manager}
+	}
+	return appliedMode{name: i.modes.Fallback(), manager: i.impManager}
+}
+
+// pushDeferred appends the impressions deferred by the fairness policy to the tail of the queue
+func (i *ImpressionsPipelineWorker) pushDeferred(raws []string) error {
+	if i.requeue == nil {
+		return errors.New("no queue to push them back to")
+	}
+	return i.requeue.Push(raws)
+}
+
+// countDropped keeps the impressions dropped by the fairness policy reflected in the impression counts
+func (i *ImpressionsPipelineWorker) countDropped(impression *dtos.Impression) {
+	if i.counter != nil {
+		i.counter.Inc(impression.FeatureName, time.Now().UTC().UnixNano(), 1)
+	}
+}
+
 func (i *ImpressionsPipelineWorker) sendImpressionsToListener(b *impBatches) {
 	for _, group := range b.groups {
 		payload := make([]impressionlistener.ImpressionsForListener, 0, len(group.imps))
@@ -205,10 +391,10 @@ func (i *impBatches) recycleContainer() {
 // add an impression to a bulk
 // after identifying the correct bulk (or creating one if necessary), the call is forwarded
 // to such structure. (see impsWithMetadata.add)
-func (i *impBatches) add(queueObj *dtos.ImpressionQueueObject) {
+func (i *impBatches) add(queueObj *dtos.ImpressionQueueObject, mode string) {
 	idx, ok := i.index[queueObj.Metadata]
 	if !ok || i.groups[idx].count > defaultBulkSize {
-		i.groups = append(i.groups, newImpsWithMetadata(i.pool, &queueObj.Metadata))
+		i.groups = append(i.groups, newImpsWithMetadata(i.pool, &queueObj.Metadata, mode))
 		idx = len(i.groups) - 1
 		i.index[queueObj.Metadata] = idx
 	}
@@ -218,15 +404,17 @@ func (i *impBatches) add(queueObj *dtos.ImpressionQueueObject) {
 type impsWithMetadata struct {
 	pool     impressionsMemoryPool
 	metadata dtos.Metadata
+	mode     string // impressions mode applied when deduping
 	imps     testImpressionsSlice
 	count    int
 	nindex   featureNameMap
 }
 
-func newImpsWithMetadata(pool impressionsMemoryPool, metadata *dtos.Metadata) impsWithMetadata {
+func newImpsWithMetadata(pool impressionsMemoryPool, metadata *dtos.Metadata, mode string) impsWithMetadata {
 	toRet := impsWithMetadata{
 		pool:     pool,
 		metadata: *metadata,
+		mode:     mode,
 		imps:     pool.acquireTestImpressions(),
 		nindex:   pool.acquireFeatureNameMap(),
 	}
@@ -366,3 +554,6 @@ func (p *impressionsMemoryPoolImpl) releaseImpsWithMeta(t impsWithMetaSlice) {
 
 var _ impressionsMemoryPool = (*impressionsMemoryPoolImpl)(nil)
 var _ Worker = (*ImpressionsPipelineWorker)(nil)
+var _ Requeuer = (*ImpressionsPipelineWorker)(nil)
+var _ FetchSizeAdjuster = (*ImpressionsPipelineWorker)(nil)
+var _ ItemCounter = (*ImpressionsPipelineWorker)(nil)
@@ -2,6 +2,7 @@ package task
 
 import (
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -11,13 +12,19 @@ import (
 	"testing"
 	"time"
 
+	"github.com/splitio/go-split-commons/v4/conf"
 	"github.com/splitio/go-split-commons/v4/dtos"
 	"github.com/splitio/go-split-commons/v4/provisional"
 	"github.com/splitio/go-split-commons/v4/provisional/strategy"
+	"github.com/splitio/go-split-commons/v4/storage/filter"
 	"github.com/splitio/go-split-commons/v4/storage/inmemory"
 	"github.com/splitio/go-split-commons/v4/storage/mocks"
+	"github.com/splitio/go-split-commons/v4/telemetry"
 	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/usage"
 	"github.com/splitio/split-synchronizer/v5/splitio/producer/evcalc"
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/fairness"
+	producerStorage "github.com/splitio/split-synchronizer/v5/splitio/producer/storage"
 )
 
 type trackingAllocator struct {
@@ -165,6 +172,34 @@ func TestMemoryIsProperlyReturned(t *testing.T) {
 	poolWrapper.validate(t)
 }
 
+func TestImpressionsUsageTracking(t *testing.T) {
+	impressionsCounter := strategy.NewImpressionsCounter()
+	impressionObserver, _ := strategy.NewImpressionObserver(500)
+	strategy := strategy.NewOptimizedImpl(impressionObserver, impressionsCounter, &inmemory.TelemetryStorage{}, false)
+
+	tracker := usage.NewTracker(time.Hour, time.Hour)
+	w, _ := NewImpressionWorker(&ImpressionWorkerConfig{
+		EvictionMonitor:   evcalc.New(1),
+		Logger:            logging.NewLogger(nil),
+		Storage:           mocks.MockImpressionStorage{},
+		URL:               "http://test",
+		Apikey:            "someApikey",
+		FetchSize:         100,
+		ImpressionManager: provisional.NewImpressionManager(strategy),
+		UsageTracker:      tracker,
+	})
+
+	sinker := make(chan interface{}, 100)
+	raws := makeSerializedImpressions(1, 2, 5)
+	w.Process(append(raws, raws...), sinker)
+
+	// deduped impressions are counted as well
+	tracked := tracker.Usage()
+	if len(tracked) != 2 || tracked[0].Total != 10 || tracked[1].Total != 10 || tracked[0].SDKs["go-1.1.1"] != 10 {
+		t.Error("wrong usage tracked: ", tracked)
+	}
+}
+
 func TestImpressionsIntegration(t *testing.T) {
 
 	var mtx sync.Mutex
@@ -258,3 +293,308 @@ func TestImpressionsIntegration(t *testing.T) {
 	}
 }
 
+
+func TestImpressionsRequeue(t *testing.T) {
+	impressionsCounter := strategy.NewImpressionsCounter()
+	impressionObserver, _ := strategy.NewImpressionObserver(500)
+	strategy := strategy.NewOptimizedImpl(impressionObserver, impressionsCounter, &inmemory.TelemetryStorage{}, false)
+	w, _ := NewImpressionWorker(&ImpressionWorkerConfig{
+		EvictionMonitor:   evcalc.New(1),
+		Logger:            logging.NewLogger(nil),
+		Storage:           mocks.MockImpressionStorage{},
+		URL:               "http://test",
+		Apikey:            "someApikey",
+		FetchSize:         100,
+		ImpressionManager: provisional.NewImpressionManager(strategy),
+	})
+
+	sinker := make(chan interface{}, 100)
+	w.Process(makeSerializedImpressions(2, 3, 4), sinker)
+	close(sinker)
+
+	perMachine := make(map[string]int)
+	for bulk := range sinker {
+		items, err := w.Requeue(bulk)
+		if err != nil {
+			t.Error("there should be no error. Got: ", err)
+		}
+		for _, item := range items {
+			var queueObj dtos.ImpressionQueueObject
+			if err := json.Unmarshal([]byte(item), &queueObj); err != nil || queueObj.Impression.FeatureName == "" {
+				t.Error("requeued items should be valid queue objects. Got: ", item)
+			}
+			perMachine[queueObj.Metadata.MachineName]++
+		}
+	}
+
+	if len(perMachine) != 2 || perMachine["machine_0"] != 12 || perMachine["machine_1"] != 12 {
+		t.Error("every impression should be requeued with its metadata. Got: ", perMachine)
+	}
+}
+
+func TestImpressionsModeHeader(t *testing.T) {
+	impressionObserver, _ := strategy.NewImpressionObserver(500)
+	strategy := strategy.NewDebugImpl(impressionObserver, false)
+
+	modes := producerStorage.NewSDKImpressionsModes(conf.ImpressionsModeOptimized)
+	modes.Update(dtos.Metadata{SDKVersion: "go-1.1.1", MachineName: "machine_1"}, telemetry.ImpressionsModeDebug)
+	w, _ := NewImpressionWorker(&ImpressionWorkerConfig{
+		EvictionMonitor:   evcalc.New(1),
+		Logger:            logging.NewLogger(nil),
+		Storage:           mocks.MockImpressionStorage{},
+		URL:               "http://test",
+		Apikey:            "someApikey",
+		FetchSize:         100,
+		ImpressionManager: provisional.NewImpressionManager(strategy),
+		ImpressionManagers: map[string]provisional.ImpressionManager{
+			conf.ImpressionsModeDebug: provisional.NewImpressionManager(strategy),
+		},
+		ImpressionsModes: modes,
+	})
+
+	sinker := make(chan interface{}, 100)
+	w.Process(makeSerializedImpressions(2, 3, 4), sinker)
+	close(sinker)
+
+	modeByMachine := make(map[string]string)
+	for bulk := range sinker {
+		req, err := w.BuildRequest(bulk)
+		if err != nil {
+			t.Error("there should be no error. Got: ", err)
+		}
+		modeByMachine[req.Header.Get("SplitSDKMachineName")] = req.Header.Get("SplitSDKImpressionsMode")
+	}
+
+	if modeByMachine["machine_0"] != conf.ImpressionsModeOptimized {
+		t.Error("machine_0 config hasn't been seen. It should fall back to optimized. Got: ", modeByMachine["machine_0"])
+	}
+	if modeByMachine["machine_1"] != conf.ImpressionsModeDebug {
+		t.Error("machine_1 reported debug mode. Got: ", modeByMachine["machine_1"])
+	}
+}
+
+func TestImpressionsPerSDKMode(t *testing.T) {
+	impressionObserver, _ := strategy.NewImpressionObserver(500)
+	impressionsCounter := strategy.NewImpressionsCounter()
+	runtimeTelemetry, _ := inmemory.NewTelemetryStorage()
+	optimized := provisional.NewImpressionManager(strategy.NewOptimizedImpl(impressionObserver, impressionsCounter, runtimeTelemetry, false))
+
+	debugSDK := dtos.Metadata{SDKVersion: "go-1.1.1", MachineName: "debug"}
+	optimizedSDK := dtos.Metadata{SDKVersion: "go-1.1.1", MachineName: "optimized"}
+	modes := producerStorage.NewSDKImpressionsModes(conf.ImpressionsModeOptimized)
+	modes.Update(debugSDK, telemetry.ImpressionsModeDebug)
+	w, _ := NewImpressionWorker(&ImpressionWorkerConfig{
+		EvictionMonitor:   evcalc.New(1),
+		Logger:            logging.NewLogger(nil),
+		Storage:           mocks.MockImpressionStorage{},
+		URL:               "http://test",
+		Apikey:            "someApikey",
+		FetchSize:         100,
+		ImpressionManager: optimized,
+		ImpressionManagers: map[string]provisional.ImpressionManager{
+			conf.ImpressionsModeOptimized: optimized,
+			conf.ImpressionsModeDebug:     provisional.NewImpressionManager(strategy.NewDebugImpl(impressionObserver, false)),
+		},
+		ImpressionsModes: modes,
+	})
+
+	// the same impressions are received twice from each sdk
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	var raws [][]byte
+	for _, metadata := range []dtos.Metadata{debugSDK, optimizedSDK} {
+		for idx := 0; idx < 3; idx++ {
+			raw, _ := json.Marshal(&dtos.ImpressionQueueObject{
+				Metadata:   metadata,
+				Impression: dtos.Impression{FeatureName: "feat", KeyName: metadata.MachineName + "_key_" + strconv.Itoa(idx), Treatment: "on", Time: now},
+			})
+			raws = append(raws, raw)
+		}
+	}
+
+	sinker := make(chan interface{}, 100)
+	postedByMode := make(map[string]int)
+	for round := 0; round < 2; round++ {
+		w.Process(raws, sinker)
+		for len(sinker) > 0 {
+			bulk := <-sinker
+			req, _ := w.BuildRequest(bulk)
+			postedByMode[req.Header.Get("SplitSDKImpressionsMode")] += w.ItemCount(bulk)
+		}
+	}
+
+	if postedByMode[conf.ImpressionsModeDebug] != 6 {
+		t.Error("repeated impressions from debug sdks should not be deduped. Got: ", postedByMode[conf.ImpressionsModeDebug])
+	}
+	if postedByMode[conf.ImpressionsModeOptimized] != 3 {
+		t.Error("repeated impressions from optimized sdks should be deduped. Got: ", postedByMode[conf.ImpressionsModeOptimized])
+	}
+
+	total := int64(0)
+	for _, count := range impressionsCounter.PopAll() {
+		total += count
+	}
+	if total != 3 {
+		t.Error("only the impressions deduped in optimized mode should be counted. Got: ", total)
+	}
+}
+
+func TestImpressionsNoneMode(t *testing.T) {
+	impressionsCounter := strategy.NewImpressionsCounter()
+	uniqueKeysTracker := strategy.NewUniqueKeysTracker(filter.NewBloomFilter(1000, 0.01))
+	strategy := strategy.NewNoneImpl(impressionsCounter, uniqueKeysTracker, false)
+
+	w, _ := NewImpressionWorker(&ImpressionWorkerConfig{
+		EvictionMonitor:   evcalc.New(1),
+		Logger:            logging.NewLogger(nil),
+		Storage:           mocks.MockImpressionStorage{},
+		URL:               "http://test",
+		Apikey:            "someApikey",
+		FetchSize:         100,
+		ImpressionManager: provisional.NewImpressionManager(strategy),
+	})
+
+	sinker := make(chan interface{}, 100)
+	w.Process(makeSerializedImpressions(2, 3, 4), sinker)
+	if len(sinker) != 0 {
+		t.Error("no impressions should be posted in none mode. Got: ", len(sinker))
+	}
+
+	uniques := uniqueKeysTracker.PopAll()
+	if len(uniques.Keys) != 3 {
+		t.Error("unique keys should be tracked for each of the 3 features. Got: ", len(uniques.Keys))
+	}
+	for _, key := range uniques.Keys {
+		if len(key.Keys) != 4 {
+			t.Error("there should be 4 unique keys per feature. Got: ", key.Keys)
+		}
+	}
+
+	counts := impressionsCounter.PopAll()
+	total := int64(0)
+	for _, count := range counts {
+		total += count
+	}
+	if total != 24 {
+		t.Error("every impression should be counted. Got: ", total)
+	}
+}
+
+type rawQueueMock struct {
+	items []string
+	err   error
+}
+
+func (r *rawQueueMock) Push(items []string) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.items = append(r.items, items...)
+	return nil
+}
+
+func TestImpressionsFairness(t *testing.T) {
+	tracker, _ := fairness.NewTracker(fairness.Config{Name: "impressions", Policy: fairness.PolicyQuota, BacklogThreshold: 100, Quota: 5})
+	impressionObserver, _ := strategy.NewImpressionObserver(500)
+	impressionsCounter := strategy.NewImpressionsCounter()
+	requeue := &rawQueueMock{}
+	w, _ := NewImpressionWorker(&ImpressionWorkerConfig{
+		EvictionMonitor:   evcalc.New(1),
+		Logger:            logging.NewLogger(nil),
+		Storage:           mocks.MockImpressionStorage{},
+		URL:               "http://test",
+		Apikey:            "someApikey",
+		FetchSize:         100,
+		ImpressionManager:  provisional.NewImpressionManager(strategy.NewDebugImpl(impressionObserver, false)),
+		Fairness:           tracker,
+		Requeue:            requeue,
+		ImpressionsCounter: impressionsCounter,
+	})
+
+	sinker := make(chan interface{}, 100)
+	w.Process(makeSerializedImpressions(2, 2, 5), sinker)
+	if posted := countSunkImpressions(w, sinker); posted != 20 {
+		t.Error("nothing should be dropped while the backlog is under the threshold. Got: ", posted)
+	}
+
+	tracker.SetBacklog(1000)
+	w.Process(makeSerializedImpressions(2, 2, 5), sinker)
+	if posted := countSunkImpressions(w, sinker); posted != 0 {
+		t.Error("instances over their quota should be throttled. Got: ", posted)
+	}
+
+	if len(requeue.items) != 20 {
+		t.Error("impressions over the quota should be pushed back to the queue. Got: ", len(requeue.items))
+	}
+
+	status := tracker.Status(0)
+	if status.Deferred != 20 || status.Dropped != 0 || len(status.Producers) != 2 || status.Producers[0].Total != 20 {
+		t.Error("wrong fairness status: ", status)
+	}
+
+	requeue.err = errors.New("someError")
+	w.Process(makeSerializedImpressions(2, 2, 5), sinker)
+	total := int64(0)
+	for _, count := range impressionsCounter.PopAll() {
+		total += count
+	}
+	if total != 20 || len(requeue.items) != 20 {
+		t.Error("impressions that cannot be pushed back should be counted. Got: ", total)
+	}
+}
+
+func TestImpressionsFairnessRetries(t *testing.T) {
+	tracker, _ := fairness.NewTracker(fairness.Config{Name: "impressions", Policy: fairness.PolicyQuota, BacklogThreshold: 100, Quota: 5, MaxDeferrals: 1})
+	tracker.SetBacklog(1000)
+	impressionObserver, _ := strategy.NewImpressionObserver(500)
+	usageTracker := usage.NewTracker(time.Hour, time.Hour)
+	requeue := &rawQueueMock{}
+	w, _ := NewImpressionWorker(&ImpressionWorkerConfig{
+		EvictionMonitor:   evcalc.New(1),
+		Logger:            logging.NewLogger(nil),
+		Storage:           mocks.MockImpressionStorage{},
+		URL:               "http://test",
+		Apikey:            "someApikey",
+		FetchSize:         100,
+		ImpressionManager: provisional.NewImpressionManager(strategy.NewDebugImpl(impressionObserver, false)),
+		UsageTracker:      usageTracker,
+		Fairness:          tracker,
+		Requeue:           requeue,
+	})
+
+	sinker := make(chan interface{}, 100)
+	w.Process(makeSerializedImpressions(2, 2, 5), sinker)
+	if posted := countSunkImpressions(w, sinker); posted != 10 || len(requeue.items) != 10 {
+		t.Error("impressions over the quota should be deferred. Got: ", posted, len(requeue.items))
+	}
+
+	retries := make([][]byte, 0, len(requeue.items))
+	for _, item := range requeue.items {
+		retries = append(retries, []byte(item))
+	}
+	requeue.items = nil
+	w.Process(retries, sinker)
+	if posted := countSunkImpressions(w, sinker); posted != 10 || len(requeue.items) != 0 {
+		t.Error("impressions deferred the max number of times should be accepted. Got: ", posted, len(requeue.items))
+	}
+
+	total := int64(0)
+	for _, flag := range usageTracker.Usage() {
+		total += flag.Total
+	}
+	if total != 20 {
+		t.Error("deferred impressions should be tracked once. Got: ", total)
+	}
+
+	status := tracker.Status(0)
+	if status.Deferred != 10 || status.Producers[0].Total != 10 || status.Producers[1].Total != 10 {
+		t.Error("deferred impressions should be recorded once. Got: ", status)
+	}
+}
+
+func countSunkImpressions(w *ImpressionsPipelineWorker, sinker chan interface{}) int {
+	count := 0
+	for len(sinker) > 0 {
+		count += w.ItemCount(<-sinker)
+	}
+	return count
+}
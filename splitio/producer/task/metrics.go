@@ -0,0 +1,155 @@
+package task
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/splitio/go-split-commons/v4/telemetry"
+)
+
+const (
+	latencyBucketCount = 23
+	statusNetworkError = "error"
+)
+
+// ItemCounter is implemented by workers able to tell how many items a processed bulk contains.
+// Bulks of workers not implementing it are counted as a single item
+type ItemCounter interface {
+	ItemCount(data interface{}) int
+}
+
+// BufferOccupancy describes how full one of the buffers between pipeline stages is
+type BufferOccupancy struct {
+	Used     int `json:"used"`
+	Capacity int `json:"capacity"`
+}
+
+// StageLatencies holds a latency histogram (in milliseconds, using the same buckets as sdk telemetry) for each stage
+type StageLatencies struct {
+	Fetch   []int64 `json:"fetch"`
+	Process []int64 `json:"process"`
+	Post    []int64 `json:"post"`
+}
+
+// PipelineMetrics is a snapshot of the counters accumulated by a pipelined task since it was started
+type PipelineMetrics struct {
+	Name            string           `json:"name"`
+	Fetched         int64            `json:"fetched"`
+	Processed       int64            `json:"processed"`
+	Posted          int64            `json:"posted"`
+	Requeued        int64            `json:"requeued"`
+	Dropped         int64            `json:"dropped"`
+	Retries         int64            `json:"retries"`
+	RawBytes        int64            `json:"rawBytes"`
+	SentBytes       int64            `json:"sentBytes"`
+	InputBuffer     BufferOccupancy  `json:"inputBuffer"`
+	PreSubmitBuffer BufferOccupancy  `json:"preSubmitBuffer"`
+	Latencies       StageLatencies   `json:"latencies"`
+	StatusCodes     map[string]int64 `json:"statusCodes"`
+}
+
+// pipelineMetrics accumulates per-stage counters, latencies & http status codes
+type pipelineMetrics struct {
+	mutex          sync.Mutex
+	fetched        int64
+	processed      int64
+	posted         int64
+	requeued       int64
+	dropped        int64
+	retries        int64
+	rawBytes       int64
+	sentBytes      int64
+	fetchLatency   [latencyBucketCount]int64
+	processLatency [latencyBucketCount]int64
+	postLatency    [latencyBucketCount]int64
+	statusCodes    map[string]int64
+}
+
+func newPipelineMetrics() *pipelineMetrics {
+	return &pipelineMetrics{statusCodes: make(map[string]int64)}
+}
+
+func (m *pipelineMetrics) recordFetch(latency time.Duration, items int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.fetched += int64(items)
+	m.fetchLatency[telemetry.Bucket(latency.Milliseconds())]++
+}
+
+func (m *pipelineMetrics) recordProcess(latency time.Duration, items int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.processed += int64(items)
+	m.processLatency[telemetry.Bucket(latency.Milliseconds())]++
+}
+
+func (m *pipelineMetrics) recordPostAttempt(latency time.Duration, resp *http.Response, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.postLatency[telemetry.Bucket(latency.Milliseconds())]++
+	if err != nil || resp == nil {
+		m.statusCodes[statusNetworkError]++
+		return
+	}
+	m.statusCodes[strconv.Itoa(resp.StatusCode)]++
+}
+
+func (m *pipelineMetrics) recordPostResult(attempts int, items int, posted bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if attempts > 1 {
+		m.retries += int64(attempts - 1)
+	}
+	if posted {
+		m.posted += int64(items)
+	}
+}
+
+// recordBytes tracks the size of request bodies before & after compression
+func (m *pipelineMetrics) recordBytes(raw int64, sent int64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.rawBytes += raw
+	m.sentBytes += sent
+}
+
+func (m *pipelineMetrics) recordRequeued(items int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.requeued += int64(items)
+}
+
+func (m *pipelineMetrics) recordDropped(items int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.dropped += int64(items)
+}
+
+func (m *pipelineMetrics) snapshot(name string) PipelineMetrics {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	statusCodes := make(map[string]int64, len(m.statusCodes))
+	for code, count := range m.statusCodes {
+		statusCodes[code] = count
+	}
+
+	return PipelineMetrics{
+		Name:      name,
+		Fetched:   m.fetched,
+		Processed: m.processed,
+		Posted:    m.posted,
+		Requeued:  m.requeued,
+		Dropped:   m.dropped,
+		Retries:   m.retries,
+		RawBytes:  m.rawBytes,
+		SentBytes: m.sentBytes,
+		Latencies: StageLatencies{
+			Fetch:   append([]int64(nil), m.fetchLatency[:]...),
+			Process: append([]int64(nil), m.processLatency[:]...),
+			Post:    append([]int64(nil), m.postLatency[:]...),
+		},
+		StatusCodes: statusCodes,
+	}
+}
@@ -0,0 +1,102 @@
+package task
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+type countingWorker struct {
+	mockWorker
+}
+
+func (w *countingWorker) ItemCount(data interface{}) int {
+	return len(data.([]string))
+}
+
+func TestPipelineTaskMetrics(t *testing.T) {
+	var httpCalls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&httpCalls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	var fetchCalls int64
+	w := &countingWorker{
+		mockWorker: mockWorker{
+			fetchCall: func() ([]string, error) {
+				if atomic.AddInt64(&fetchCalls, 1) > 1 {
+					return nil, nil
+				}
+				return []string{"a", "b", "c", "d"}, nil
+			},
+			processCall: func(rawData [][]byte, sink chan<- interface{}) error {
+				bulk := make([]string, 0, len(rawData))
+				for _, raw := range rawData {
+					bulk = append(bulk, string(raw))
+				}
+				sink <- bulk
+				return nil
+			},
+			buildRequestCall: func(data interface{}) (*http.Request, error) {
+				return http.NewRequest("POST", server.URL, nil)
+			},
+		},
+	}
+
+	task, err := NewPipelinedTask(&Config{
+		Name:         "test",
+		Worker:       w,
+		Logger:       logging.NewLogger(nil),
+		MaxAccumWait: 100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Error("task init: ", err)
+	}
+
+	metrics := task.Metrics()
+	if metrics.InputBuffer.Capacity == 0 || metrics.PreSubmitBuffer.Capacity == 0 {
+		t.Error("buffer capacities should be reported. Got: ", metrics.InputBuffer, metrics.PreSubmitBuffer)
+	}
+
+	task.Start()
+	time.Sleep(1 * time.Second)
+	task.Stop(true)
+
+	metrics = task.Metrics()
+	if metrics.Name != "test" {
+		t.Error("unexpected name: ", metrics.Name)
+	}
+	if metrics.Fetched != 4 || metrics.Processed != 4 || metrics.Posted != 4 {
+		t.Error("all 4 items should have gone through every stage. Got: ", metrics.Fetched, metrics.Processed, metrics.Posted)
+	}
+	if metrics.Retries != 1 {
+		t.Error("there should be 1 retry. Got: ", metrics.Retries)
+	}
+	if metrics.Requeued != 0 || metrics.Dropped != 0 {
+		t.Error("nothing should have been requeued or dropped. Got: ", metrics.Requeued, metrics.Dropped)
+	}
+	if len(metrics.StatusCodes) != 2 || metrics.StatusCodes["500"] != 1 || metrics.StatusCodes["200"] != 1 {
+		t.Error("unexpected status codes: ", metrics.StatusCodes)
+	}
+
+	if len(metrics.Latencies.Post) != latencyBucketCount {
+		t.Error("post latencies should have one slot per bucket. Got: ", len(metrics.Latencies.Post))
+	}
+	var posts int64
+	for _, count := range metrics.Latencies.Post {
+		posts += count
+	}
+	if posts != 2 {
+		t.Error("there should be 2 post latencies recorded. Got: ", posts)
+	}
+	if metrics.Latencies.Fetch[0] == 0 || metrics.Latencies.Process[0] == 0 {
+		t.Error("fetch & process latencies should have been recorded")
+	}
+}
@@ -6,12 +6,15 @@ import (
 	"net/http"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	tsync "github.com/splitio/go-toolkit/v5/sync"
 
 	"github.com/splitio/go-toolkit/v5/common"
 	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/storage"
 )
 
 const (
@@ -20,6 +23,9 @@ const (
 	defaultMaxConcurrency   = 2000
 	defaultMaxAccumSecs     = 5
 	defaultHTTPTimeoutSecs  = 3
+
+	// time during which processors submit partial batches after a flush is requested
+	flushWindow = 5 * time.Second
 )
 
 // Config contains the set of options/parameters to setup the eviction component
@@ -33,6 +39,9 @@ type Config struct {
 	PostConcurrency    int
 	MaxAccumWait       time.Duration
 	HTTPTimeout        time.Duration
+	FailedStorage      storage.FailedItemsStorage
+	AutoTuning         *AutoTuningConfig
+	Compressor         *Compressor
 }
 
 // Worker defines the methods that should be implemented by pipeline-suited data-flows
@@ -42,6 +51,18 @@ type Worker interface {
 	BuildRequest(data interface{}) (*http.Request, error)
 }
 
+// Requeuer is implemented by workers capable of serializing a processed bulk back into the raw format it was popped in.
+// When a bulk cannot be posted after all the retries, its raw items are pushed into the failed-items storage
+type Requeuer interface {
+	Requeue(data interface{}) ([]string, error)
+}
+
+// FetchSizeAdjuster is implemented by workers whose fetch size can be changed while running
+type FetchSizeAdjuster interface {
+	FetchSize() int64
+	SetFetchSize(size int64)
+}
+
 func (c *Config) normalize() {
 	if c.InputBufferSize == 0 {
 		c.InputBufferSize = defaultInputBufferSize
@@ -53,6 +74,9 @@ func (c *Config) normalize() {
 
 	if c.ProcessConcurrency == 0 {
 		c.ProcessConcurrency = runtime.NumCPU() / 2
+		if c.ProcessConcurrency < 1 {
+			c.ProcessConcurrency = 1
+		}
 	}
 
 	if c.HTTPTimeout == 0 {
@@ -66,6 +90,13 @@ func (c *Config) normalize() {
 	if c.MaxAccumWait == 0 {
 		c.MaxAccumWait = defaultMaxAccumSecs * time.Second
 	}
+
+	if c.AutoTuning != nil {
+		c.AutoTuning.normalize(c.HTTPTimeout)
+		c.ProcessConcurrency = clamp(c.ProcessConcurrency, c.AutoTuning.MinProcessConcurrency, c.AutoTuning.MaxProcessConcurrency)
+		c.ProcessBatchSize = clamp(c.ProcessBatchSize, c.AutoTuning.MinProcessBatchSize, c.AutoTuning.MaxProcessBatchSize)
+		c.PostConcurrency = clamp(c.PostConcurrency, c.AutoTuning.MinPostConcurrency, c.AutoTuning.MaxPostConcurrency)
+	}
 }
 
 // PipelinedSyncTask implements a fetch-process-evict buffered flow
@@ -77,17 +108,25 @@ type PipelinedSyncTask struct {
 	httpClient http.Client
 	worker     Worker
 	pool       taskMemoryPool
+	failed     storage.FailedItemsStorage
+	compressor *Compressor
 
 	// configs
 	name               string
-	postConcurrency    int
-	processConcurrency int
-	processBatchSize   int
+	postConcurrency    int // number of posting goroutines spawned. Only the first `limits.posters` ones are active
+	processConcurrency int // number of processing goroutines spawned. Only the first `limits.processors` ones are active
 	maxAccumWait       time.Duration
+	limits             *limits
+	tuner              *autoTuner
+	postStats          postStats
+	metrics            *pipelineMetrics
+	flusher            *flusher
 
 	// synchronization elements
 	inputBuffer     chan []string
 	preSubmitBuffer chan interface{}
+	fillingDone     chan struct{}
+	processingDone  chan struct{}
 	waiter          sync.WaitGroup
 	running         *tsync.AtomicBool
 	shutdown        chan struct{}
@@ -97,48 +136,71 @@ type PipelinedSyncTask struct {
 func NewPipelinedTask(config *Config) (*PipelinedSyncTask, error) {
 	t := http.DefaultTransport.(*http.Transport).Clone()
 	config.normalize()
-	t.MaxConnsPerHost = config.PostConcurrency
-	t.MaxIdleConns = config.PostConcurrency
-	t.MaxIdleConnsPerHost = config.PostConcurrency
-	return &PipelinedSyncTask{
+
+	// when auto-tuning, enough goroutines are spawned to reach the upper bounds, but only the configured amount is active
+	postConcurrency, processConcurrency := config.PostConcurrency, config.ProcessConcurrency
+	if config.AutoTuning != nil {
+		postConcurrency, processConcurrency = config.AutoTuning.MaxPostConcurrency, config.AutoTuning.MaxProcessConcurrency
+	}
+
+	t.MaxConnsPerHost = postConcurrency
+	t.MaxIdleConns = postConcurrency
+	t.MaxIdleConnsPerHost = postConcurrency
+	task := &PipelinedSyncTask{
 		name:               config.Name,
 		logger:             config.Logger,
 		worker:             config.Worker,
+		failed:             config.FailedStorage,
+		compressor:         config.Compressor,
 		httpClient:         http.Client{Transport: t, Timeout: config.HTTPTimeout},
 		pool:               newTaskMemoryPool(config.ProcessBatchSize),
-		processBatchSize:   config.ProcessBatchSize,
-		postConcurrency:    config.PostConcurrency,
-		processConcurrency: config.ProcessConcurrency,
+		postConcurrency:    postConcurrency,
+		processConcurrency: processConcurrency,
 		maxAccumWait:       config.MaxAccumWait,
+		limits:             newLimits(config.ProcessConcurrency, config.PostConcurrency, config.ProcessBatchSize),
+		metrics:            newPipelineMetrics(),
+		flusher:            newFlusher(),
 		running:            tsync.NewAtomicBool(true),
 		inputBuffer:        make(chan []string, config.InputBufferSize),
-		preSubmitBuffer:    make(chan interface{}, config.PostConcurrency*4),
+		preSubmitBuffer:    make(chan interface{}, postConcurrency*4),
+		fillingDone:        make(chan struct{}),
+		processingDone:     make(chan struct{}),
 		shutdown:           make(chan struct{}, 1),
-	}, nil
+	}
+
+	if config.AutoTuning != nil {
+		task.tuner = newAutoTuner(task, config.AutoTuning)
+	}
+	return task, nil
 }
 
 // Start begins execution
 func (p *PipelinedSyncTask) Start() {
 	p.waiter.Add(p.postConcurrency + p.processConcurrency + 1)
 	for idx := 0; idx < p.postConcurrency; idx++ {
-		go p.sinker()
+		go p.sinker(idx)
 	}
 
 	processWaiter := &sync.WaitGroup{}
 	processWaiter.Add(p.processConcurrency)
 	for idx := 0; idx < p.processConcurrency; idx++ {
-		go func() {
-			p.processor()
+		go func(idx int) {
+			p.processor(idx)
 			processWaiter.Done()
-		}()
+		}(idx)
 	}
 
 	go func() {
 		processWaiter.Wait()
 		close(p.preSubmitBuffer)
+		close(p.processingDone)
 	}()
 
 	go p.filler()
+
+	if p.tuner != nil {
+		p.tuner.start()
+	}
 }
 
 // Stop the task and drain the pipe
@@ -146,6 +208,9 @@ func (p *PipelinedSyncTask) Stop(blocking bool) error {
 	if !p.running.TestAndClear() {
 		return errTaskRunning
 	}
+	if p.tuner != nil {
+		p.tuner.stop()
+	}
 	p.shutdown <- struct{}{}
 	if blocking {
 		p.waiter.Wait()
@@ -158,19 +223,80 @@ func (p *PipelinedSyncTask) IsRunning() bool {
 	return p.running.IsSet()
 }
 
+// Flush makes the task fetch items right away & post them without waiting for batches to fill up
+func (p *PipelinedSyncTask) Flush() error {
+	if !p.running.IsSet() {
+		return errTaskNotRunning
+	}
+	p.logger.Info(fmt.Sprintf("[pipelined/%s] immediate flush requested", p.name))
+	p.flusher.request(flushWindow)
+	return nil
+}
+
+// Status returns the current settings of the task & the latest auto-tuning decision, if enabled
+func (p *PipelinedSyncTask) Status() PipelineStatus {
+	status := PipelineStatus{Name: p.name, Settings: p.settings()}
+	if p.tuner != nil {
+		tuning := p.tuner.status()
+		status.AutoTuning = &tuning
+	}
+	return status
+}
+
+// Metrics returns the per-stage counters & latencies accumulated since the task was created, along with
+// the current occupancy of the buffers between stages
+func (p *PipelinedSyncTask) Metrics() PipelineMetrics {
+	metrics := p.metrics.snapshot(p.name)
+	metrics.InputBuffer = BufferOccupancy{Used: len(p.inputBuffer), Capacity: cap(p.inputBuffer)}
+	metrics.PreSubmitBuffer = BufferOccupancy{Used: len(p.preSubmitBuffer), Capacity: cap(p.preSubmitBuffer)}
+	return metrics
+}
+
+func (p *PipelinedSyncTask) itemCount(bulk interface{}) int {
+	if counter, ok := p.worker.(ItemCounter); ok {
+		return counter.ItemCount(bulk)
+	}
+	return 1
+}
+
+func (p *PipelinedSyncTask) settings() PipelineSettings {
+	current := p.limits.snapshot()
+	settings := PipelineSettings{
+		ProcessConcurrency: current.processors,
+		ProcessBatchSize:   current.processBatchSize,
+		PostConcurrency:    current.posters,
+	}
+	if adjuster, ok := p.worker.(FetchSizeAdjuster); ok {
+		settings.FetchSize = adjuster.FetchSize()
+	}
+	return settings
+}
+
+func (p *PipelinedSyncTask) apply(settings PipelineSettings) {
+	p.limits.set(settings.ProcessConcurrency, settings.PostConcurrency, settings.ProcessBatchSize)
+	if adjuster, ok := p.worker.(FetchSizeAdjuster); ok && settings.FetchSize > 0 {
+		adjuster.SetFetchSize(settings.FetchSize)
+	}
+}
+
 func (p *PipelinedSyncTask) filler() {
 	p.logger.Debug(fmt.Sprintf("[pipelined/%s] - starting filling task", p.name))
 	defer p.waiter.Done()
+	defer close(p.fillingDone)
+	defer close(p.inputBuffer)
 	timer := time.NewTimer(1 * time.Second)
 	for p.running.IsSet() {
 		timer.Reset(1 * time.Second)
+		before := time.Now()
 		raw, err := p.worker.Fetch()
+		p.metrics.recordFetch(time.Since(before), len(raw))
 		if len(raw) == 0 {
 			select {
 			case <-timer.C:
 				continue
+			case <-p.flusher.wakeUp:
+				continue
 			case <-p.shutdown:
-				close(p.inputBuffer)
 				return
 			}
 		}
@@ -179,19 +305,20 @@ func (p *PipelinedSyncTask) filler() {
 			continue
 		}
 
+		// Items have already been removed from redis at this point, so instead of dropping them when the processing
+		// buffer is full, we block until there's room for them. This also prevents popping more items while
+		// the downstream stages are saturated. Processors keep draining the buffer until it's closed, so this cannot
+		// block a shutdown indefinitely
 		howMany := len(raw)
-		select {
-		case p.inputBuffer <- raw:
-			p.logger.Debug(fmt.Sprintf("[pipelined/%s] Pushed %d items into the processing buffer", p.name, howMany))
-		default:
-			p.logger.Warning(fmt.Sprintf(
-				"[pipelined/%s] - dropping bulk of %d fetched items because processing buffer is full", p.name, len(raw),
-			))
+		if len(p.inputBuffer) == cap(p.inputBuffer) {
+			p.logger.Debug(fmt.Sprintf("[pipelined/%s] processing buffer is full. Waiting before fetching more items", p.name))
 		}
+		p.inputBuffer <- raw
+		p.logger.Debug(fmt.Sprintf("[pipelined/%s] Pushed %d items into the processing buffer", p.name, howMany))
 	}
 }
 
-func (p *PipelinedSyncTask) processor() {
+func (p *PipelinedSyncTask) processor(idx int) {
 	p.logger.Debug(fmt.Sprintf("[pipelined/%s] - starting processing task", p.name))
 	defer p.waiter.Done()
 	timer := time.NewTimer(p.maxAccumWait)
@@ -199,6 +326,17 @@ func (p *PipelinedSyncTask) processor() {
 	processing := tsync.NewAtomicBool(true)
 
 	for processing.IsSet() {
+		current := p.limits.snapshot()
+		if idx >= current.processors {
+			// this processor is currently disabled. Wait until limits change or there's nothing left to process
+			select {
+			case <-current.changed:
+				continue
+			case <-p.fillingDone:
+				return
+			}
+		}
+
 		func() {
 			batch := p.pool.getRawBuffer() // acquire a buffer from the pool and schedule a release
 			defer p.pool.releaseRawBuffer(batch)
@@ -206,6 +344,7 @@ func (p *PipelinedSyncTask) processor() {
 			ready := false
 			for !ready {
 				timer.Reset(p.maxAccumWait)
+				flushRequested := p.flusher.requested()
 				select {
 				case raws, ok := <-p.inputBuffer:
 					if !ok { // no more elements to process, this is the last iteration
@@ -217,7 +356,11 @@ func (p *PipelinedSyncTask) processor() {
 					for idx := range raws {
 						batch = append(batch, []byte(raws[idx]))
 					}
-					if len(batch) >= p.processBatchSize {
+					if len(batch) >= current.processBatchSize || p.flusher.active() {
+						ready = true
+					}
+				case <-flushRequested:
+					if len(batch) > 0 {
 						ready = true
 					}
 				case <-timer.C:
@@ -233,7 +376,9 @@ func (p *PipelinedSyncTask) processor() {
 
 			howMany := len(batch)
 			p.logger.Debug(fmt.Sprintf("[pipelined/%s] processing %d raw items.", p.name, howMany))
+			before := time.Now()
 			err := p.worker.Process(batch, p.preSubmitBuffer) // process the raw data and put the results in the buffer
+			p.metrics.recordProcess(time.Since(before), howMany)
 			if err != nil {
 				p.logger.Error(fmt.Sprintf("[pipelined/%s] failed to process %d items: %s", p.name, howMany, err))
 			}
@@ -241,10 +386,19 @@ func (p *PipelinedSyncTask) processor() {
 	}
 }
 
-func (p *PipelinedSyncTask) sinker() {
+func (p *PipelinedSyncTask) sinker(idx int) {
 	p.logger.Debug(fmt.Sprintf("[pipelined/%s] - starting posting task", p.name))
 	defer p.waiter.Done()
 	for {
+		if current := p.limits.snapshot(); idx >= current.posters {
+			// this poster is currently disabled. Wait until limits change or there's nothing left to post
+			select {
+			case <-current.changed:
+				continue
+			case <-p.processingDone:
+				return
+			}
+		}
 
 		bulk, ok := <-p.preSubmitBuffer
 		if !ok { // no more processed data available, end this goroutine
@@ -256,7 +410,9 @@ func (p *PipelinedSyncTask) sinker() {
 				defer asRecyblable.recycle()
 			}
 
-			common.WithAttempts(3, func() error {
+			attempts := 0
+			err := common.WithAttempts(3, func() error {
+				attempts++
 				p.logger.Debug(fmt.Sprintf("[pipelined/%s] - impressions post ready. making request", p.name))
 				req, err := p.worker.BuildRequest(bulk)
 				if err != nil {
@@ -264,7 +420,15 @@ func (p *PipelinedSyncTask) sinker() {
 					return err
 				}
 
+				if err := p.prepareBody(req); err != nil {
+					p.logger.Error(fmt.Sprintf("[pipelined/%s] %s", p.name, err))
+					return err
+				}
+
+				before := time.Now()
 				resp, err := p.httpClient.Do(req)
+				p.postStats.record(time.Since(before), resp, err)
+				p.metrics.recordPostAttempt(time.Since(before), resp, err)
 				if err != nil {
 					p.logger.Error(fmt.Sprintf("[pipelined/%s] error posting: %s", p.name, err))
 					return err
@@ -281,10 +445,168 @@ func (p *PipelinedSyncTask) sinker() {
 				p.logger.Debug(fmt.Sprintf("[pipelined/%s] - impressions posted successfully", p.name))
 				return nil
 			})
+			p.metrics.recordPostResult(attempts, p.itemCount(bulk), err == nil)
+			if err != nil {
+				p.requeue(bulk)
+			}
 		}()
 	}
 }
 
+// prepareBody compresses the request body when a compressor is configured & records the amount of bytes to be sent
+func (p *PipelinedSyncTask) prepareBody(req *http.Request) error {
+	if p.compressor == nil {
+		if req.ContentLength > 0 {
+			p.metrics.recordBytes(req.ContentLength, req.ContentLength)
+		}
+		return nil
+	}
+
+	raw, compressed, err := p.compressor.compress(req)
+	if err != nil {
+		return err
+	}
+	p.metrics.recordBytes(raw, compressed)
+	return nil
+}
+
+// requeue pushes the raw items of a bulk that could not be posted into the failed-items storage
+func (p *PipelinedSyncTask) requeue(bulk interface{}) {
+	requeuer, ok := p.worker.(Requeuer)
+	if !ok || p.failed == nil {
+		p.logger.Error(fmt.Sprintf("[pipelined/%s] no failed-items storage available. Dropping bulk after final post failure", p.name))
+		p.metrics.recordDropped(p.itemCount(bulk))
+		return
+	}
+
+	raw, err := requeuer.Requeue(bulk)
+	if err != nil {
+		p.logger.Error(fmt.Sprintf("[pipelined/%s] error serializing bulk after final post failure. Dropping it: %s", p.name, err))
+		p.metrics.recordDropped(p.itemCount(bulk))
+		return
+	}
+
+	if err := p.failed.Push(raw); err != nil {
+		p.logger.Error(fmt.Sprintf("[pipelined/%s] error storing %d failed items. Dropping them: %s", p.name, len(raw), err))
+		p.metrics.recordDropped(len(raw))
+		return
+	}
+	p.metrics.recordRequeued(len(raw))
+	p.logger.Warning(fmt.Sprintf("[pipelined/%s] stored %d items that could not be posted in the failed-items list", p.name, len(raw)))
+}
+
+// limits holds the parameters that can be adjusted while the task is running.
+// Every change closes & replaces the `changed` channel, waking up idle goroutines so that they re-evaluate their state
+type limits struct {
+	mutex            sync.RWMutex
+	processors       int
+	posters          int
+	processBatchSize int
+	changed          chan struct{}
+}
+
+type limitsSnapshot struct {
+	processors       int
+	posters          int
+	processBatchSize int
+	changed          <-chan struct{}
+}
+
+func newLimits(processors int, posters int, processBatchSize int) *limits {
+	return &limits{processors: processors, posters: posters, processBatchSize: processBatchSize, changed: make(chan struct{})}
+}
+
+func (l *limits) snapshot() limitsSnapshot {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return limitsSnapshot{processors: l.processors, posters: l.posters, processBatchSize: l.processBatchSize, changed: l.changed}
+}
+
+func (l *limits) set(processors int, posters int, processBatchSize int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if processors == l.processors && posters == l.posters && processBatchSize == l.processBatchSize {
+		return
+	}
+	l.processors, l.posters, l.processBatchSize = processors, posters, processBatchSize
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// flusher keeps track of immediate flush requests. The filler is woken up & processors submit partial batches
+// until the flush window expires
+type flusher struct {
+	until     int64 // unix nanoseconds. Accessed atomically
+	wakeUp    chan struct{}
+	mutex     sync.Mutex
+	requestCh chan struct{}
+}
+
+func newFlusher() *flusher {
+	return &flusher{wakeUp: make(chan struct{}, 1), requestCh: make(chan struct{})}
+}
+
+func (f *flusher) request(window time.Duration) {
+	atomic.StoreInt64(&f.until, time.Now().Add(window).UnixNano())
+	select {
+	case f.wakeUp <- struct{}{}:
+	default: // a wake up is already pending
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	close(f.requestCh)
+	f.requestCh = make(chan struct{})
+}
+
+// requested returns a channel that is closed on the next flush request
+func (f *flusher) requested() <-chan struct{} {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.requestCh
+}
+
+func (f *flusher) active() bool {
+	return time.Now().UnixNano() < atomic.LoadInt64(&f.until)
+}
+
+// postStats accumulates the outcome of post attempts between two reads
+type postStats struct {
+	mutex     sync.Mutex
+	posts     int64
+	errors    int64
+	throttled int64
+	latency   time.Duration
+}
+
+type postStatsSnapshot struct {
+	posts     int64
+	errors    int64
+	throttled int64
+	latency   time.Duration
+}
+
+func (s *postStats) record(latency time.Duration, resp *http.Response, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.posts++
+	s.latency += latency
+	if err != nil || resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		s.errors++
+	}
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		s.throttled++
+	}
+}
+
+func (s *postStats) pop() postStatsSnapshot {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	snapshot := postStatsSnapshot{posts: s.posts, errors: s.errors, throttled: s.throttled, latency: s.latency}
+	s.posts, s.errors, s.throttled, s.latency = 0, 0, 0, 0
+	return snapshot
+}
+
 type rawBuffer = [][]byte
 
 type taskMemoryPool interface {
@@ -315,4 +637,5 @@ type recyclable interface {
 }
 
 var errHTTP = errors.New("http")
+var errTaskNotRunning = errors.New("task is not running")
 var errTaskRunning = errors.New("task already running")
@@ -4,6 +4,8 @@ import (
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -167,3 +169,192 @@ func TestPipelineTask(t *testing.T) {
 
 	poolWrapper.validate(t)
 }
+
+type requeuingWorker struct {
+	mockWorker
+	requeueCall func(data interface{}) ([]string, error)
+}
+
+func (m *requeuingWorker) Requeue(data interface{}) ([]string, error) {
+	return m.requeueCall(data)
+}
+
+type failedStorageMock struct {
+	mutex sync.Mutex
+	items []string
+}
+
+func (f *failedStorageMock) Push(items []string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.items = append(f.items, items...)
+	return nil
+}
+
+func (f *failedStorageMock) Count() int64 {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return int64(len(f.items))
+}
+
+func TestPipelineTaskRequeuesFailedPosts(t *testing.T) {
+	var httpCalls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&httpCalls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var fetchCalls int64
+	w := &requeuingWorker{
+		mockWorker: mockWorker{
+			fetchCall: func() ([]string, error) {
+				if atomic.AddInt64(&fetchCalls, 1) > 1 {
+					return nil, nil
+				}
+				return []string{"a", "b", "c"}, nil
+			},
+			processCall: func(rawData [][]byte, sink chan<- interface{}) error {
+				bulk := make([]string, 0, len(rawData))
+				for _, raw := range rawData {
+					bulk = append(bulk, string(raw))
+				}
+				sink <- bulk
+				return nil
+			},
+			buildRequestCall: func(data interface{}) (*http.Request, error) {
+				return http.NewRequest("POST", server.URL, nil)
+			},
+		},
+		requeueCall: func(data interface{}) ([]string, error) {
+			return data.([]string), nil
+		},
+	}
+
+	failed := &failedStorageMock{}
+	task, err := NewPipelinedTask(&Config{
+		Worker:        w,
+		Logger:        logging.NewLogger(nil),
+		MaxAccumWait:  100 * time.Millisecond,
+		FailedStorage: failed,
+	})
+	if err != nil {
+		t.Error("task init: ", err)
+	}
+	task.Start()
+	time.Sleep(500 * time.Millisecond)
+	task.Stop(true)
+
+	if c := atomic.LoadInt64(&httpCalls); c != 3 {
+		t.Error("the post should be attempted 3 times. Got: ", c)
+	}
+
+	if !reflect.DeepEqual(failed.items, []string{"a", "b", "c"}) {
+		t.Error("raw items should be pushed into the failed storage. Got: ", failed.items)
+	}
+}
+
+func TestPipelineTaskBackpressure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	release := make(chan struct{})
+	var fetchCalls int64
+	var processed int64
+	w := &mockWorker{
+		fetchCall: func() ([]string, error) {
+			if atomic.AddInt64(&fetchCalls, 1) > 5 {
+				return nil, nil
+			}
+			return []string{"item"}, nil
+		},
+		processCall: func(rawData [][]byte, sink chan<- interface{}) error {
+			<-release // simulate a saturated processing stage
+			atomic.AddInt64(&processed, int64(len(rawData)))
+			return nil
+		},
+		buildRequestCall: func(data interface{}) (*http.Request, error) {
+			return http.NewRequest("POST", server.URL, nil)
+		},
+	}
+
+	task, err := NewPipelinedTask(&Config{
+		Worker:             w,
+		Logger:             logging.NewLogger(nil),
+		InputBufferSize:    1,
+		ProcessConcurrency: 1,
+		ProcessBatchSize:   1,
+		MaxAccumWait:       50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Error("task init: ", err)
+	}
+	task.Start()
+	time.Sleep(300 * time.Millisecond)
+
+	// one batch blocked in the processor, one in the buffer & one held by the filler
+	if c := atomic.LoadInt64(&fetchCalls); c != 3 {
+		t.Error("fetching should stop while the pipeline is saturated. Fetch calls: ", c)
+	}
+
+	close(release)
+	time.Sleep(300 * time.Millisecond)
+	task.Stop(true)
+
+	if p := atomic.LoadInt64(&processed); p != 5 {
+		t.Error("no fetched item should be dropped. Processed: ", p)
+	}
+}
+
+func TestPipelineTaskFlush(t *testing.T) {
+	var posts int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&posts, 1)
+	}))
+	defer server.Close()
+
+	var available int64
+	w := &mockWorker{
+		fetchCall: func() ([]string, error) {
+			if atomic.CompareAndSwapInt64(&available, 1, 0) {
+				return []string{"a", "b"}, nil
+			}
+			return nil, nil
+		},
+		processCall: func(rawData [][]byte, sink chan<- interface{}) error {
+			sink <- rawData
+			return nil
+		},
+		buildRequestCall: func(data interface{}) (*http.Request, error) {
+			return http.NewRequest("POST", server.URL, nil)
+		},
+	}
+
+	task, err := NewPipelinedTask(&Config{
+		Worker:           w,
+		Logger:           logging.NewLogger(nil),
+		ProcessBatchSize: 1000,
+		MaxAccumWait:     10 * time.Second,
+	})
+	if err != nil {
+		t.Error("task init: ", err)
+	}
+
+	task.Start()
+	time.Sleep(100 * time.Millisecond) // let the filler find the queue empty & start waiting
+
+	atomic.StoreInt64(&available, 1)
+	if err := task.Flush(); err != nil {
+		t.Error("no error should be returned. Got: ", err)
+	}
+	time.Sleep(300 * time.Millisecond)
+
+	if p := atomic.LoadInt64(&posts); p != 1 {
+		t.Error("items should be posted right away without waiting for the batch to fill up. Posts: ", p)
+	}
+	task.Stop(true)
+
+	if err := task.Flush(); err == nil {
+		t.Error("flushing a stopped task should fail")
+	}
+}
@@ -0,0 +1,15 @@
+package task
+
+import (
+	"github.com/splitio/go-toolkit/v5/asynctask"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/worker"
+)
+
+// NewQueueCapTask constructs a task used to periodically keep the impressions & events queues under their caps
+func NewQueueCapTask(wrk *worker.QueueCapWorker, logger logging.LoggerInterface, period int) *asynctask.AsyncTask {
+	doWork := func(l logging.LoggerInterface) error {
+		return wrk.Enforce()
+	}
+	return asynctask.NewAsyncTask("queue-caps", doWork, period, nil, nil, logger)
+}
@@ -0,0 +1,288 @@
+package task
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/asynctask"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/evcalc"
+)
+
+const (
+	defaultTuningPeriod        = 30 * time.Second
+	defaultMinFetchSize        = 1000
+	defaultMaxFetchSize        = 100000
+	defaultMinProcessBatchSize = 1000
+	defaultMaxProcessBatchSize = 100000
+	defaultMinPostConcurrency  = 10
+
+	// upstream error rate above which post concurrency is reduced
+	tuningMaxErrorRate = 0.1
+
+	// number of consecutive periods with the queue under control before scaling down
+	tuningCalmPeriods = 3
+)
+
+// AutoTuningConfig bundles the bounds within which the auto-tuner is allowed to move each parameter
+type AutoTuningConfig struct {
+	Period                time.Duration
+	Monitor               evcalc.Monitor
+	MinFetchSize          int64
+	MaxFetchSize          int64
+	MinProcessConcurrency int
+	MaxProcessConcurrency int
+	MinProcessBatchSize   int
+	MaxProcessBatchSize   int
+	MinPostConcurrency    int
+	MaxPostConcurrency    int
+	MaxPostLatency        time.Duration
+}
+
+func (c *AutoTuningConfig) normalize(httpTimeout time.Duration) {
+	if c.Period <= 0 {
+		c.Period = defaultTuningPeriod
+	}
+
+	if c.MaxPostLatency <= 0 {
+		c.MaxPostLatency = httpTimeout / 2
+	}
+
+	c.MinFetchSize, c.MaxFetchSize = normalizeBounds64(c.MinFetchSize, c.MaxFetchSize, defaultMinFetchSize, defaultMaxFetchSize)
+	c.MinProcessConcurrency, c.MaxProcessConcurrency = normalizeBounds(c.MinProcessConcurrency, c.MaxProcessConcurrency, 1, runtime.NumCPU())
+	c.MinProcessBatchSize, c.MaxProcessBatchSize = normalizeBounds(c.MinProcessBatchSize, c.MaxProcessBatchSize, defaultMinProcessBatchSize, defaultMaxProcessBatchSize)
+	c.MinPostConcurrency, c.MaxPostConcurrency = normalizeBounds(c.MinPostConcurrency, c.MaxPostConcurrency, defaultMinPostConcurrency, defaultMaxConcurrency)
+}
+
+// PipelineSettings are the parameters of a pipelined task that can be adjusted while running
+type PipelineSettings struct {
+	FetchSize          int64 `json:"fetchSize"`
+	ProcessConcurrency int   `json:"processConcurrency"`
+	ProcessBatchSize   int   `json:"processBatchSize"`
+	PostConcurrency    int   `json:"postConcurrency"`
+}
+
+// TuningStatus describes the inputs & outcome of the latest auto-tuning decision
+type TuningStatus struct {
+	Min              PipelineSettings `json:"min"`
+	Max              PipelineSettings `json:"max"`
+	Lambda           float64          `json:"lambda"`
+	Posts            int64            `json:"posts"`
+	ErrorRate        float64          `json:"errorRate"`
+	ThrottledRate    float64          `json:"throttledRate"`
+	AvgPostLatencyMs float64          `json:"avgPostLatencyMs"`
+	LastDecision     string           `json:"lastDecision"`
+	LastEvaluation   int64            `json:"lastEvaluation"`
+	LastAdjustment   int64            `json:"lastAdjustment"`
+}
+
+// PipelineStatus bundles the current settings of a pipelined task
+type PipelineStatus struct {
+	Name       string           `json:"name"`
+	Settings   PipelineSettings `json:"settings"`
+	AutoTuning *TuningStatus    `json:"autoTuning,omitempty"`
+}
+
+// autoTuner periodically adjusts the settings of a pipelined task based on queue growth (lambda),
+// post latency and upstream error & throttling rates
+type autoTuner struct {
+	task        *PipelinedSyncTask
+	cfg         *AutoTuningConfig
+	bgTask      *asynctask.AsyncTask
+	calmPeriods int
+	mutex       sync.RWMutex
+	last        TuningStatus
+}
+
+func newAutoTuner(task *PipelinedSyncTask, cfg *AutoTuningConfig) *autoTuner {
+	tuner := &autoTuner{
+		task: task,
+		cfg:  cfg,
+		last: TuningStatus{
+			Min:          PipelineSettings{cfg.MinFetchSize, cfg.MinProcessConcurrency, cfg.MinProcessBatchSize, cfg.MinPostConcurrency},
+			Max:          PipelineSettings{cfg.MaxFetchSize, cfg.MaxProcessConcurrency, cfg.MaxProcessBatchSize, cfg.MaxPostConcurrency},
+			Lambda:       1,
+			LastDecision: "waiting for the first evaluation",
+		},
+	}
+
+	if adjuster, ok := task.worker.(FetchSizeAdjuster); ok {
+		adjuster.SetFetchSize(clamp64(adjuster.FetchSize(), cfg.MinFetchSize, cfg.MaxFetchSize))
+	}
+
+	period := int(cfg.Period.Seconds())
+	if period < 1 {
+		period = 1
+	}
+	tuner.bgTask = asynctask.NewAsyncTask("auto-tuner-"+task.name, func(logging.LoggerInterface) error {
+		tuner.evaluate()
+		return nil
+	}, period, nil, nil, task.logger)
+	return tuner
+}
+
+func (t *autoTuner) start() {
+	t.bgTask.Start()
+}
+
+func (t *autoTuner) stop() {
+	t.bgTask.Stop(false)
+}
+
+func (t *autoTuner) status() TuningStatus {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.last
+}
+
+func (t *autoTuner) evaluate() {
+	stats := t.task.postStats.pop()
+	lambda := float64(1)
+	if t.cfg.Monitor != nil {
+		lambda = t.cfg.Monitor.Lambda()
+	}
+
+	current := t.task.settings()
+	next, decision := t.decide(current, stats, lambda)
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.last.Lambda = lambda
+	t.last.Posts = stats.posts
+	t.last.ErrorRate, t.last.ThrottledRate, t.last.AvgPostLatencyMs = 0, 0, 0
+	if stats.posts > 0 {
+		t.last.ErrorRate = float64(stats.errors) / float64(stats.posts)
+		t.last.ThrottledRate = float64(stats.throttled) / float64(stats.posts)
+		t.last.AvgPostLatencyMs = float64(stats.latency.Milliseconds()) / float64(stats.posts)
+	}
+	t.last.LastDecision = decision
+	t.last.LastEvaluation = now
+
+	if next != current {
+		t.task.apply(next)
+		t.last.LastAdjustment = now
+		t.task.logger.Info(fmt.Sprintf("[pipelined/%s] auto-tuning: %s. Settings: %+v", t.task.name, decision, next))
+	}
+}
+
+// decide computes the settings for the next period & a human readable explanation of the decision
+func (t *autoTuner) decide(current PipelineSettings, stats postStatsSnapshot, lambda float64) (PipelineSettings, string) {
+	next := current
+	var errorRate float64
+	var avgLatency time.Duration
+	if stats.posts > 0 {
+		errorRate = float64(stats.errors) / float64(stats.posts)
+		avgLatency = stats.latency / time.Duration(stats.posts)
+	}
+
+	switch {
+	case stats.throttled > 0:
+		t.calmPeriods = 0
+		next.PostConcurrency = clamp(current.PostConcurrency/2, t.cfg.MinPostConcurrency, t.cfg.MaxPostConcurrency)
+		return next, fmt.Sprintf("upstream throttled %d posts: reducing post concurrency", stats.throttled)
+	case errorRate > tuningMaxErrorRate:
+		t.calmPeriods = 0
+		next.PostConcurrency = clamp(current.PostConcurrency/2, t.cfg.MinPostConcurrency, t.cfg.MaxPostConcurrency)
+		return next, fmt.Sprintf("upstream error rate at %.0f%%: reducing post concurrency", errorRate*100)
+	case avgLatency > t.cfg.MaxPostLatency:
+		t.calmPeriods = 0
+		next.PostConcurrency = clamp(scaleDown(current.PostConcurrency), t.cfg.MinPostConcurrency, t.cfg.MaxPostConcurrency)
+		return next, fmt.Sprintf("average post latency at %s: reducing post concurrency", avgLatency.Round(time.Millisecond))
+	case lambda < 1:
+		t.calmPeriods = 0
+		next.FetchSize = t.scaleFetchSize(current.FetchSize, scaleUp)
+		next.ProcessConcurrency = clamp(current.ProcessConcurrency+1, t.cfg.MinProcessConcurrency, t.cfg.MaxProcessConcurrency)
+		next.ProcessBatchSize = clamp(scaleUp(current.ProcessBatchSize), t.cfg.MinProcessBatchSize, t.cfg.MaxProcessBatchSize)
+		next.PostConcurrency = clamp(scaleUp(current.PostConcurrency), t.cfg.MinPostConcurrency, t.cfg.MaxPostConcurrency)
+		if next == current {
+			return next, fmt.Sprintf("queue growing (lambda %.2f) but every parameter is at its upper bound", lambda)
+		}
+		return next, fmt.Sprintf("queue growing (lambda %.2f): scaling up", lambda)
+	}
+
+	t.calmPeriods++
+	if t.calmPeriods < tuningCalmPeriods {
+		return next, fmt.Sprintf("queue under control (lambda %.2f): no changes", lambda)
+	}
+
+	t.calmPeriods = 0
+	next.FetchSize = t.scaleFetchSize(current.FetchSize, scaleDown)
+	next.ProcessConcurrency = clamp(current.ProcessConcurrency-1, t.cfg.MinProcessConcurrency, t.cfg.MaxProcessConcurrency)
+	next.ProcessBatchSize = clamp(scaleDown(current.ProcessBatchSize), t.cfg.MinProcessBatchSize, t.cfg.MaxProcessBatchSize)
+	next.PostConcurrency = clamp(scaleDown(current.PostConcurrency), t.cfg.MinPostConcurrency, t.cfg.MaxPostConcurrency)
+	if next == current {
+		return next, fmt.Sprintf("queue under control (lambda %.2f): every parameter is at its lower bound", lambda)
+	}
+	return next, fmt.Sprintf("queue under control (lambda %.2f): scaling down", lambda)
+}
+
+func (t *autoTuner) scaleFetchSize(current int64, scale func(int) int) int64 {
+	if current == 0 { // the worker doesn't support adjusting its fetch size
+		return 0
+	}
+	return clamp64(int64(scale(int(current))), t.cfg.MinFetchSize, t.cfg.MaxFetchSize)
+}
+
+func scaleUp(value int) int {
+	if scaled := value * 3 / 2; scaled > value {
+		return scaled
+	}
+	return value + 1
+}
+
+func scaleDown(value int) int {
+	if scaled := value * 4 / 5; scaled < value {
+		return scaled
+	}
+	return value - 1
+}
+
+func clamp(value int, min int, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+func clamp64(value int64, min int64, max int64) int64 {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+func normalizeBounds(min int, max int, defaultMin int, defaultMax int) (int, int) {
+	if min <= 0 {
+		min = defaultMin
+	}
+	if max <= 0 {
+		max = defaultMax
+	}
+	if max < min {
+		max = min
+	}
+	return min, max
+}
+
+func normalizeBounds64(min int64, max int64, defaultMin int64, defaultMax int64) (int64, int64) {
+	if min <= 0 {
+		min = defaultMin
+	}
+	if max <= 0 {
+		max = defaultMax
+	}
+	if max < min {
+		max = min
+	}
+	return min, max
+}
@@ -0,0 +1,166 @@
+package task
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/evcalc/mocks"
+)
+
+type adjustableWorker struct {
+	mockWorker
+	fetchSize int64
+}
+
+func (w *adjustableWorker) FetchSize() int64        { return w.fetchSize }
+func (w *adjustableWorker) SetFetchSize(size int64) { w.fetchSize = size }
+
+func newTunedTask(t *testing.T, lambda float64) (*PipelinedSyncTask, *adjustableWorker) {
+	t.Helper()
+	worker := &adjustableWorker{fetchSize: 5000}
+	task, err := NewPipelinedTask(&Config{
+		Name:               "test",
+		Logger:             logging.NewLogger(nil),
+		Worker:             worker,
+		ProcessConcurrency: 2,
+		ProcessBatchSize:   5000,
+		PostConcurrency:    20,
+		AutoTuning: &AutoTuningConfig{
+			Monitor:               &mocks.EvCalcMock{LambdaCall: func() float64 { return lambda }},
+			MinFetchSize:          1000,
+			MaxFetchSize:          10000,
+			MinProcessConcurrency: 1,
+			MaxProcessConcurrency: 4,
+			MinProcessBatchSize:   1000,
+			MaxProcessBatchSize:   10000,
+			MinPostConcurrency:    10,
+			MaxPostConcurrency:    40,
+			MaxPostLatency:        time.Second,
+		},
+	})
+	if err != nil {
+		t.Fatal("task init: ", err)
+	}
+	return task, worker
+}
+
+func TestAutoTunerScalesUpWhenQueueGrows(t *testing.T) {
+	task, worker := newTunedTask(t, 0.5)
+	if task.processConcurrency != 4 || task.postConcurrency != 40 {
+		t.Error("goroutines should be spawned up to the upper bounds. Got: ", task.processConcurrency, task.postConcurrency)
+	}
+
+	task.tuner.evaluate()
+	expected := PipelineSettings{FetchSize: 7500, ProcessConcurrency: 3, ProcessBatchSize: 7500, PostConcurrency: 30}
+	if status := task.Status(); status.Settings != expected || status.AutoTuning.LastAdjustment == 0 {
+		t.Error("wrong settings after scaling up: ", status.Settings, status.AutoTuning)
+	}
+	if worker.fetchSize != 7500 {
+		t.Error("the worker fetch size should be updated. Got: ", worker.fetchSize)
+	}
+
+	for idx := 0; idx < 5; idx++ {
+		task.tuner.evaluate()
+	}
+	expected = PipelineSettings{FetchSize: 10000, ProcessConcurrency: 4, ProcessBatchSize: 10000, PostConcurrency: 40}
+	if status := task.Status(); status.Settings != expected {
+		t.Error("settings should not exceed the upper bounds. Got: ", status.Settings)
+	} else if status.AutoTuning.LastDecision != "queue growing (lambda 0.50) but every parameter is at its upper bound" {
+		t.Error("wrong decision: ", status.AutoTuning.LastDecision)
+	}
+}
+
+func TestAutoTunerBacksOffOnUpstreamIssues(t *testing.T) {
+	task, _ := newTunedTask(t, 0.5)
+
+	task.postStats.record(10*time.Millisecond, &http.Response{StatusCode: http.StatusTooManyRequests}, nil)
+	task.postStats.record(10*time.Millisecond, &http.Response{StatusCode: http.StatusOK}, nil)
+	task.tuner.evaluate()
+	status := task.Status()
+	if status.Settings.PostConcurrency != 10 || status.Settings.FetchSize != 5000 {
+		t.Error("post concurrency should be halved & the rest untouched when throttled. Got: ", status.Settings)
+	}
+	if status.AutoTuning.ThrottledRate != 0.5 || status.AutoTuning.Posts != 2 {
+		t.Error("wrong stats: ", status.AutoTuning)
+	}
+
+	task.apply(PipelineSettings{FetchSize: 5000, ProcessConcurrency: 2, ProcessBatchSize: 5000, PostConcurrency: 40})
+	task.postStats.record(2*time.Second, &http.Response{StatusCode: http.StatusOK}, nil)
+	task.tuner.evaluate()
+	if pc := task.Status().Settings.PostConcurrency; pc != 32 {
+		t.Error("post concurrency should be reduced when latency is too high. Got: ", pc)
+	}
+}
+
+func TestAutoTunerScalesDownWhenCalm(t *testing.T) {
+	task, _ := newTunedTask(t, 1)
+	initial := task.Status().Settings
+	for idx := 0; idx < tuningCalmPeriods-1; idx++ {
+		task.tuner.evaluate()
+	}
+	if task.Status().Settings != initial {
+		t.Error("settings should be kept until the queue has been under control for a while")
+	}
+
+	task.tuner.evaluate()
+	expected := PipelineSettings{FetchSize: 4000, ProcessConcurrency: 1, ProcessBatchSize: 4000, PostConcurrency: 16}
+	if s := task.Status().Settings; s != expected {
+		t.Error("wrong settings after scaling down: ", s)
+	}
+}
+
+func TestPipelineTaskHonorsActiveLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var fetchCalls, posted int64
+	task, worker := newTunedTask(t, 1)
+	worker.fetchCall = func() ([]string, error) {
+		if atomic.AddInt64(&fetchCalls, 1) > 3 {
+			return nil, nil
+		}
+		return []string{"a", "b"}, nil
+	}
+	worker.processCall = func(rawData [][]byte, sink chan<- interface{}) error {
+		for range rawData {
+			sink <- "item"
+		}
+		return nil
+	}
+	worker.buildRequestCall = func(data interface{}) (*http.Request, error) {
+		atomic.AddInt64(&posted, 1)
+		return http.NewRequest("POST", server.URL, nil)
+	}
+
+	snapshot := task.limits.snapshot()
+	task.apply(PipelineSettings{FetchSize: 5000, ProcessConcurrency: 1, ProcessBatchSize: 1000, PostConcurrency: 10})
+	select {
+	case <-snapshot.changed:
+	default:
+		t.Error("idle goroutines should be notified when limits change")
+	}
+
+	task.maxAccumWait = 50 * time.Millisecond
+	task.Start()
+	time.Sleep(300 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		task.Stop(true)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("idle goroutines should exit when the task is stopped")
+	}
+
+	if p := atomic.LoadInt64(&posted); p != 6 {
+		t.Error("every item should be posted by the active goroutines. Got: ", p)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/splitio/go-split-commons/v4/dtos"
 	"github.com/splitio/go-split-commons/v4/provisional/strategy"
@@ -48,7 +49,7 @@ func NewUniqueKeysWorker(cfg *UniqueWorkerConfig) Worker {
 }
 
 func (u *UniqueKeysPipelineWorker) Fetch() ([]string, error) {
-	raw, _, err := u.storage.PopNRaw(u.fetchSize)
+	raw, _, err := u.storage.PopNRaw(atomic.LoadInt64(&u.fetchSize))
 	if err != nil {
 		return nil, fmt.Errorf("error fetching raw unique keys: %w", err)
 	}
@@ -56,6 +57,16 @@ func (u *UniqueKeysPipelineWorker) Fetch() ([]string, error) {
 	return raw, nil
 }
 
+// FetchSize returns the number of items popped from storage on each fetch
+func (u *UniqueKeysPipelineWorker) FetchSize() int64 {
+	return atomic.LoadInt64(&u.fetchSize)
+}
+
+// SetFetchSize updates the number of items popped from storage on each fetch
+func (u *UniqueKeysPipelineWorker) SetFetchSize(size int64) {
+	atomic.StoreInt64(&u.fetchSize, size)
+}
+
 func (u *UniqueKeysPipelineWorker) Process(raws [][]byte, sink chan<- interface{}) error {
 	for _, raw := range raws {
 		err, value := parseToObj(raw)
@@ -108,6 +119,38 @@ func (u *UniqueKeysPipelineWorker) BuildRequest(data interface{}) (*http.Request
 	return req, nil
 }
 
+// Requeue serializes the unique keys back into the queue format used by the SDKs, one item per feature
+func (u *UniqueKeysPipelineWorker) Requeue(data interface{}) ([]string, error) {
+	uniques, ok := data.(dtos.Uniques)
+	if !ok {
+		return nil, fmt.Errorf("expected uniqueKeys. Got: %T", data)
+	}
+
+	raw := make([]string, 0, len(uniques.Keys))
+	for _, key := range uniques.Keys {
+		serialized, err := json.Marshal(key)
+		if err != nil {
+			return nil, fmt.Errorf("error serializing unique keys: %w", err)
+		}
+		raw = append(raw, string(serialized))
+	}
+	return raw, nil
+}
+
+// ItemCount returns the number of keys in a bulk
+func (u *UniqueKeysPipelineWorker) ItemCount(data interface{}) int {
+	uniques, ok := data.(dtos.Uniques)
+	if !ok {
+		return 0
+	}
+
+	count := 0
+	for _, key := range uniques.Keys {
+		count += len(key.Keys)
+	}
+	return count
+}
+
 func parseToArray(raw []byte) (error, []dtos.Key) {
 	var queueObj []dtos.Key
 	err := json.Unmarshal(raw, &queueObj)
@@ -127,3 +170,8 @@ func parseToObj(raw []byte) (error, []dtos.Key) {
 
 	return nil, []dtos.Key{queueObj}
 }
+
+var _ Worker = (*UniqueKeysPipelineWorker)(nil)
+var _ Requeuer = (*UniqueKeysPipelineWorker)(nil)
+var _ FetchSizeAdjuster = (*UniqueKeysPipelineWorker)(nil)
+var _ ItemCounter = (*UniqueKeysPipelineWorker)(nil)
@@ -20,8 +20,16 @@ import (
 	storageCommon "github.com/splitio/go-split-commons/v4/storage"
 	"github.com/splitio/go-split-commons/v4/storage/redis"
 	"github.com/splitio/go-toolkit/v5/logging"
+	toolkitRedis "github.com/splitio/go-toolkit/v5/redis"
+	adminCommon "github.com/splitio/split-synchronizer/v5/splitio/admin/common"
 	"github.com/splitio/split-synchronizer/v5/splitio/common/impressionlistener"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/queues"
 	"github.com/splitio/split-synchronizer/v5/splitio/producer/conf"
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/evcalc"
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/fairness"
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/storage"
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/task"
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/worker"
 	hcAppCounter "github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/application/counter"
 	hcServicesCounter "github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/services/counter"
 	"github.com/splitio/split-synchronizer/v5/splitio/util"
@@ -29,6 +37,7 @@ import (
 
 const (
 	impressionsCountPeriodTaskInMemory = 1800 // 30 min
+	uniqueKeysPeriodTaskInMemory       = 900  // 15 min
 	impressionObserverSize             = 500
 )
 
@@ -188,18 +197,39 @@ func getServicesCountersConfig(advanced *cconf.AdvancedConfig) []hcServicesCount
 	return append(cfgs, telemetryConfig, authConfig, apiConfig, eventsConfig, streamingConfig)
 }
 
+// buildImpressionManagers builds a dedupe strategy for each impressions mode, so that the impressions of each sdk are
+// processed according to the mode it reports
+func buildImpressionManagers(
+	impListener impressionlistener.ImpressionBulkListener,
+	runtimeTelemetry storageCommon.TelemetryRuntimeProducer,
+	impressionObserver strategy.ImpressionObserver,
+	impressionsCounter *strategy.ImpressionsCounter,
+	uniqueKeysTracker strategy.UniqueKeysTracker,
+) map[string]provisional.ImpressionManager {
+	managers := make(map[string]provisional.ImpressionManager, 3)
+	for _, mode := range []string{config.ImpressionsModeOptimized, config.ImpressionsModeDebug, config.ImpressionsModeNone} {
+		managers[mode] = buildImpressionManager(mode, impListener, runtimeTelemetry, impressionObserver, impressionsCounter, uniqueKeysTracker)
+	}
+	return managers
+}
+
 func buildImpressionManager(
 	impressionsMode string,
 	impListener impressionlistener.ImpressionBulkListener,
 	runtimeTelemetry storageCommon.TelemetryRuntimeProducer,
 	impressionObserver strategy.ImpressionObserver,
 	impressionsCounter *strategy.ImpressionsCounter,
+	uniqueKeysTracker strategy.UniqueKeysTracker,
 ) provisional.ImpressionManager {
 	listenerEnabled := impListener != nil
 	switch impressionsMode {
 	case config.ImpressionsModeDebug:
 		strategy := strategy.NewDebugImpl(impressionObserver, listenerEnabled)
 
+		return provisional.NewImpressionManager(strategy)
+	case config.ImpressionsModeNone:
+		strategy := strategy.NewNoneImpl(impressionsCounter, uniqueKeysTracker, listenerEnabled)
+
 		return provisional.NewImpressionManager(strategy)
 	default:
 		strategy := strategy.NewOptimizedImpl(impressionObserver, impressionsCounter, runtimeTelemetry, listenerEnabled)
@@ -207,3 +237,183 @@ func buildImpressionManager(
 		return provisional.NewImpressionManager(strategy)
 	}
 }
+
+// normalizeImpressionsMode maps unknown impressions modes to optimized, the same way buildImpressionManager does
+func normalizeImpressionsMode(impressionsMode string) string {
+	switch impressionsMode {
+	case config.ImpressionsModeDebug, config.ImpressionsModeNone:
+		return impressionsMode
+	default:
+		return config.ImpressionsModeOptimized
+	}
+}
+
+func buildQueueCapWorker(
+	cfg *conf.QueueCaps,
+	storages adminCommon.Storages,
+	redisClient *toolkitRedis.PrefixedRedisClient,
+	logger logging.LoggerInterface,
+) (*worker.QueueCapWorker, error) {
+	sampleRate := float64(cfg.SamplePercent) / 100
+	configs := []worker.QueueCapConfig{
+		{
+			Name:       "impressions",
+			Queue:      storages.ImpressionStorage,
+			Appender:   storage.NewRedisRawQueue(redisClient, redis.KeyImpressionsQueue, logger),
+			MaxLength:  cfg.ImpressionsMaxLength,
+			Policy:     cfg.ImpressionsPolicy,
+			SampleRate: sampleRate,
+			SpillDir:   cfg.SpillDirectory,
+		},
+		{
+			Name:       "events",
+			Queue:      storages.EventStorage,
+			Appender:   storage.NewRedisRawQueue(redisClient, redis.KeyEvents, logger),
+			MaxLength:  cfg.EventsMaxLength,
+			Policy:     cfg.EventsPolicy,
+			SampleRate: sampleRate,
+			SpillDir:   cfg.SpillDirectory,
+		},
+	}
+
+	// failed items are only kept for inspection or manual replay, so the oldest ones are simply dropped
+	failed := []queuePipeline{
+		{name: "impressions", key: redis.KeyImpressionsQueue},
+		{name: "events", key: redis.KeyEvents},
+		{name: "uniquekeys", key: redis.KeyUniquekeys},
+	}
+	for _, current := range failed {
+		configs = append(configs, worker.QueueCapConfig{
+			Name:      current.name + storage.FailedSuffix,
+			Queue:     storage.NewRedisFailedItemsStorage(redisClient, current.key, logger),
+			MaxLength: cfg.FailedMaxLength,
+			Policy:    worker.OverflowTrimOldest,
+		})
+	}
+	return worker.NewQueueCapWorker(logger, configs...)
+}
+
+// queuePipeline binds a redis queue to the pipelined task consuming it
+type queuePipeline struct {
+	name     string
+	key      string
+	pipeline *task.PipelinedSyncTask
+}
+
+// buildQueueManager exposes the redis queues & their failed-items lists to the admin api.
+// Only the queues consumed by a pipelined task can be flushed on demand
+func buildQueueManager(
+	maxDrop int64,
+	maxPeek int64,
+	redisClient *toolkitRedis.PrefixedRedisClient,
+	logger logging.LoggerInterface,
+	pipelines ...queuePipeline,
+) *queues.Manager {
+	managed := make([]queues.Queue, 0, 2*len(pipelines))
+	for _, current := range pipelines {
+		managed = append(managed,
+			queues.NewRedisQueue(current.name, storage.NewRedisRawQueue(redisClient, current.key, logger), current.pipeline.Flush),
+			queues.NewRedisQueue(
+				current.name+storage.FailedSuffix,
+				storage.NewRedisRawQueue(redisClient, current.key+storage.FailedSuffix, logger),
+				nil,
+			),
+		)
+	}
+	return queues.NewManager(maxDrop, int(maxPeek), managed...)
+}
+
+func buildFairnessTrackers(cfg *conf.Fairness) (*fairness.Tracker, *fairness.Tracker, error) {
+	build := func(name string) (*fairness.Tracker, error) {
+		return fairness.NewTracker(fairness.Config{
+			Name:             name,
+			Policy:           cfg.Policy,
+			BacklogThreshold: cfg.BacklogThreshold,
+			Quota:            cfg.QuotaPerWindow,
+			Window:           time.Duration(cfg.WindowMs) * time.Millisecond,
+			MaxSources:       int(cfg.MaxSources),
+			MaxDeferrals:     int(cfg.MaxDeferrals),
+		})
+	}
+
+	impressions, err := build("impressions")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events, err := build("events")
+	if err != nil {
+		return nil, nil, err
+	}
+	return impressions, events, nil
+}
+
+// periodSecs converts a period in milliseconds to the whole seconds expected by async tasks (at least 1)
+func periodSecs(periodMs int64) int {
+	if secs := int(periodMs / 1000); secs > 0 {
+		return secs
+	}
+	return 1
+}
+
+// Dedupe persistence backends
+const (
+	dedupePersistenceNone  = "none"
+	dedupePersistenceRedis = "redis"
+	dedupePersistenceFile  = "file"
+)
+
+func buildDedupeFilter(cfg *conf.Dedupe) (*storage.BloomFilter, error) {
+	if cfg.FilterExpectedElements <= 0 {
+		return nil, fmt.Errorf("the unique keys filter expected elements must be positive. Got: %d", cfg.FilterExpectedElements)
+	}
+
+	falsePositiveRate, err := strconv.ParseFloat(cfg.FilterFalsePositiveRate, 64)
+	if err != nil || falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, fmt.Errorf("the unique keys filter false positive rate must be between 0 and 1. Got: '%s'", cfg.FilterFalsePositiveRate)
+	}
+	return storage.NewBloomFilter(uint(cfg.FilterExpectedElements), falsePositiveRate), nil
+}
+
+func buildDedupeStateStore(cfg *conf.Dedupe, redisClient *toolkitRedis.PrefixedRedisClient) (storage.DedupeStateStore, error) {
+	switch cfg.Persistence {
+	case "", dedupePersistenceNone:
+		return nil, nil
+	case dedupePersistenceRedis:
+		return storage.NewRedisDedupeStateStore(redisClient, storage.KeyDedupeState), nil
+	case dedupePersistenceFile:
+		if cfg.PersistenceFile == "" {
+			return nil, errors.New("a dedupe persistence file is required when using file persistence")
+		}
+		return storage.NewFileDedupeStateStore(cfg.PersistenceFile), nil
+	default:
+		return nil, fmt.Errorf("unknown dedupe persistence '%s'. Should be one of: none, redis, file", cfg.Persistence)
+	}
+}
+
+func buildCompressor(cfg *conf.Compression) (*task.Compressor, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	return task.NewCompressor(cfg.Level)
+}
+
+func buildAutoTuningConfig(cfg *conf.AutoTuning, monitor evcalc.Monitor) *task.AutoTuningConfig {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	return &task.AutoTuningConfig{
+		Period:                time.Duration(cfg.PeriodMs) * time.Millisecond,
+		Monitor:               monitor,
+		MinFetchSize:          cfg.MinFetchSize,
+		MaxFetchSize:          cfg.MaxFetchSize,
+		MinProcessConcurrency: cfg.MinProcessConcurrency,
+		MaxProcessConcurrency: cfg.MaxProcessConcurrency,
+		MinProcessBatchSize:   cfg.MinProcessBatchSize,
+		MaxProcessBatchSize:   cfg.MaxProcessBatchSize,
+		MinPostConcurrency:    cfg.MinPostConcurrency,
+		MaxPostConcurrency:    cfg.MaxPostConcurrency,
+		MaxPostLatency:        time.Duration(cfg.MaxPostLatencyMs) * time.Millisecond,
+	}
+}
@@ -0,0 +1,139 @@
+package worker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/provisional/strategy"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/storage"
+)
+
+// DedupeState is the serialized form of the unique keys filter & the impression counts not yet posted to split
+type DedupeState struct {
+	SavedAt int64                            `json:"savedAt"`
+	Filter  []byte                           `json:"filter,omitempty"`
+	Counts  []dtos.ImpressionsInTimeFrameDTO `json:"counts,omitempty"`
+}
+
+// DedupeStateConfig bundles the components involved in saving & restoring the dedupe state
+type DedupeStateConfig struct {
+	Filter         *storage.BloomFilter
+	FilterTTL      time.Duration // saved filters older than this (the cleaning period) are discarded
+	Counter        *strategy.ImpressionsCounter
+	CountsConsumer *ImpressionsCounstWorkerImp // pops the counts sent by the sdks into the counter
+	CountsRecorder interface{ SynchronizeImpressionsCount() error }
+	Store          storage.DedupeStateStore // nil if persistence is disabled
+}
+
+// DedupeStateWorker keeps the unique keys filter & the pending impression counts across restarts: the state is
+// restored on boot, the filter is checkpointed periodically, and on shutdown the pending counts are flushed to
+// split & whatever could not be posted is saved along with the filter
+type DedupeStateWorker struct {
+	cfg    DedupeStateConfig
+	logger logging.LoggerInterface
+}
+
+// NewDedupeStateWorker constructs a dedupe state worker
+func NewDedupeStateWorker(logger logging.LoggerInterface, cfg DedupeStateConfig) *DedupeStateWorker {
+	return &DedupeStateWorker{cfg: cfg, logger: logger}
+}
+
+// Restore loads the saved state (if any) into the filter & counter and removes it from the store,
+// so that the same counts are not restored twice if the synchronizer is not shut down gracefully
+func (w *DedupeStateWorker) Restore() error {
+	if w.cfg.Store == nil {
+		return nil
+	}
+
+	raw, err := w.cfg.Store.Load()
+	if err != nil || raw == nil {
+		return err
+	}
+
+	var state DedupeState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return fmt.Errorf("error parsing saved dedupe state: %w", err)
+	}
+
+	if err := w.cfg.Store.Clear(); err != nil {
+		return err
+	}
+
+	for _, count := range state.Counts {
+		w.cfg.Counter.Inc(count.FeatureName, count.TimeFrame, count.RawCount)
+	}
+
+	age := time.Since(time.Unix(0, state.SavedAt*int64(time.Millisecond)))
+	switch {
+	case len(state.Filter) == 0:
+	case w.cfg.FilterTTL > 0 && age > w.cfg.FilterTTL:
+		w.logger.Info(fmt.Sprintf("discarding saved unique keys filter since it's older than the cleaning period (%s)", age))
+	default:
+		if err := w.cfg.Filter.UnmarshalBinary(state.Filter); err != nil {
+			if errors.Is(err, storage.ErrFilterMismatch) {
+				w.logger.Warning("discarding saved unique keys filter since it was built with a different size/false positive rate")
+			} else {
+				return err
+			}
+		}
+	}
+
+	w.logger.Info(fmt.Sprintf("restored dedupe state saved %s ago with %d pending impression counts", age.Round(time.Second), len(state.Counts)))
+	return nil
+}
+
+// Checkpoint saves the filter. Pending counts are not included since they'll be posted before the next restart
+func (w *DedupeStateWorker) Checkpoint() error {
+	if w.cfg.Store == nil {
+		return nil
+	}
+	return w.save(nil)
+}
+
+// Shutdown consumes the counts left in redis, flushes every pending count to split & saves the filter along
+// with the counts that could not be posted
+func (w *DedupeStateWorker) Shutdown() error {
+	if err := w.cfg.CountsConsumer.Process(); err != nil {
+		w.logger.Error("error fetching impression counts from redis on shutdown: ", err)
+	}
+
+	if err := w.cfg.CountsRecorder.SynchronizeImpressionsCount(); err != nil {
+		w.logger.Error("error posting impression counts on shutdown: ", err)
+	}
+
+	if w.cfg.Store == nil {
+		if pending := w.cfg.Counter.Size(); pending > 0 {
+			w.logger.Warning(fmt.Sprintf("%d impression counts could not be posted & will be lost since persistence is disabled", pending))
+		}
+		return nil
+	}
+
+	pending := w.cfg.Counter.PopAll()
+	counts := make([]dtos.ImpressionsInTimeFrameDTO, 0, len(pending))
+	for key, count := range pending {
+		counts = append(counts, dtos.ImpressionsInTimeFrameDTO{FeatureName: key.FeatureName, TimeFrame: key.TimeFrame, RawCount: count})
+	}
+	return w.save(counts)
+}
+
+func (w *DedupeStateWorker) save(counts []dtos.ImpressionsInTimeFrameDTO) error {
+	filter, err := w.cfg.Filter.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	serialized, err := json.Marshal(DedupeState{
+		SavedAt: time.Now().UnixNano() / int64(time.Millisecond),
+		Filter:  filter,
+		Counts:  counts,
+	})
+	if err != nil {
+		return fmt.Errorf("error serializing dedupe state: %w", err)
+	}
+	return w.cfg.Store.Save(serialized)
+}
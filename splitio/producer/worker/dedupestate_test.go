@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/provisional/strategy"
+	serviceMocks "github.com/splitio/go-split-commons/v4/service/mocks"
+	"github.com/splitio/go-split-commons/v4/storage/inmemory"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/producer/storage"
+)
+
+type stateStoreMock struct {
+	state []byte
+}
+
+func (s *stateStoreMock) Load() ([]byte, error)   { return s.state, nil }
+func (s *stateStoreMock) Save(state []byte) error { s.state = state; return nil }
+func (s *stateStoreMock) Clear() error            { s.state = nil; return nil }
+
+type countsConsumerMock struct {
+	counts []dtos.ImpressionsInTimeFrameDTO
+}
+
+func (c countsConsumerMock) GetImpressionsCount() (*dtos.ImpressionsCountDTO, error) {
+	return &dtos.ImpressionsCountDTO{PerFeature: c.counts}, nil
+}
+
+func setupDedupeWorker(store storage.DedupeStateStore, recordErr error, redisCounts []dtos.ImpressionsInTimeFrameDTO) (*DedupeStateWorker, *storage.BloomFilter, *strategy.ImpressionsCounter, *[]dtos.ImpressionsCountDTO) {
+	logger := logging.NewLogger(nil)
+	telemetry, _ := inmemory.NewTelemetryStorage()
+	filter := storage.NewBloomFilter(1000, 0.01)
+	counter := strategy.NewImpressionsCounter()
+	var posted []dtos.ImpressionsCountDTO
+	recorder := NewImpressionsCountRecorder(counter, serviceMocks.MockImpressionRecorder{
+		RecordImpressionsCountCall: func(pf dtos.ImpressionsCountDTO, metadata dtos.Metadata) error {
+			posted = append(posted, pf)
+			return recordErr
+		},
+	}, dtos.Metadata{}, logger, telemetry)
+	consumer := NewImpressionsCounstWorker(counter, countsConsumerMock{counts: redisCounts}, nil, logger)
+
+	return NewDedupeStateWorker(logger, DedupeStateConfig{
+		Filter:         filter,
+		FilterTTL:      time.Hour,
+		Counter:        counter,
+		CountsConsumer: consumer,
+		CountsRecorder: recorder,
+		Store:          store,
+	}), filter, counter, &posted
+}
+
+func TestDedupeStateShutdownFlushesCounts(t *testing.T) {
+	store := &stateStoreMock{}
+	wrk, filter, counter, posted := setupDedupeWorker(store, nil, []dtos.ImpressionsInTimeFrameDTO{{FeatureName: "f2", TimeFrame: 1, RawCount: 3}})
+	filter.Add("f1::key1")
+	counter.Inc("f1", 1, 2)
+
+	if err := wrk.Shutdown(); err != nil {
+		t.Fatal("shutdown should succeed. Got: ", err)
+	}
+	if len(*posted) != 1 || len((*posted)[0].PerFeature) != 2 {
+		t.Error("the local & redis counts should have been posted. Got: ", *posted)
+	}
+
+	var state DedupeState
+	if err := json.Unmarshal(store.state, &state); err != nil || len(state.Filter) == 0 || len(state.Counts) != 0 {
+		t.Error("only the filter should be saved once the counts are posted. Got: ", err, state.Counts)
+	}
+}
+
+func TestDedupeStateSaveAndRestore(t *testing.T) {
+	store := &stateStoreMock{}
+	wrk, filter, counter, posted := setupDedupeWorker(store, errors.New("split is down"), nil)
+	filter.Add("f1::key1")
+	counter.Inc("f1", 1, 2)
+
+	if err := wrk.Shutdown(); err != nil {
+		t.Fatal("shutdown should succeed. Got: ", err)
+	}
+	if len(*posted) != 1 || counter.Size() != 0 {
+		t.Error("the counts should have been posted & then saved. Got: ", *posted, counter.Size())
+	}
+
+	restarted, restoredFilter, restoredCounter, _ := setupDedupeWorker(store, nil, nil)
+	if err := restarted.Restore(); err != nil {
+		t.Fatal("the state should be restored. Got: ", err)
+	}
+	if !restoredFilter.Contains("f1::key1") {
+		t.Error("the filter should have been restored")
+	}
+	if counts := restoredCounter.PopAll(); len(counts) != 1 || counts[strategy.Key{FeatureName: "f1", TimeFrame: 0}] != 2 {
+		t.Error("the pending counts should have been restored. Got: ", counts)
+	}
+	if store.state != nil {
+		t.Error("the saved state should be removed once restored")
+	}
+}
+
+func TestDedupeStateDiscardsStaleFilters(t *testing.T) {
+	saved, _ := json.Marshal(DedupeState{
+		SavedAt: time.Now().Add(-2*time.Hour).UnixNano() / int64(time.Millisecond),
+		Filter:  []byte("not even a filter"),
+		Counts:  []dtos.ImpressionsInTimeFrameDTO{{FeatureName: "f1", TimeFrame: 0, RawCount: 5}},
+	})
+	store := &stateStoreMock{state: saved}
+	wrk, _, counter, _ := setupDedupeWorker(store, nil, nil)
+	if err := wrk.Restore(); err != nil {
+		t.Error("filters older than the cleaning period should be ignored. Got: ", err)
+	}
+	if counter.Size() != 1 {
+		t.Error("counts should be restored regardless of the filter age")
+	}
+
+	if err := wrk.Checkpoint(); err != nil || store.state == nil {
+		t.Error("the filter should be checkpointed. Got: ", err)
+	}
+}
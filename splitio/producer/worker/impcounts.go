@@ -1,25 +1,35 @@
 package worker
 
 import (
+	"time"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
 	"github.com/splitio/go-split-commons/v4/provisional/strategy"
+	"github.com/splitio/go-split-commons/v4/service"
 	"github.com/splitio/go-split-commons/v4/storage"
+	"github.com/splitio/go-split-commons/v4/telemetry"
 	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/usage"
 )
 
 type ImpressionsCounstWorkerImp struct {
-	impressionsCounter strategy.ImpressionsCounter
+	impressionsCounter *strategy.ImpressionsCounter
 	storage            storage.ImpressionsCountConsumer
+	usageTracker       *usage.Tracker
 	logger             logging.LoggerInterface
 }
 
 func NewImpressionsCounstWorker(
-	impressionsCounter strategy.ImpressionsCounter,
+	impressionsCounter *strategy.ImpressionsCounter,
 	storage storage.ImpressionsCountConsumer,
+	usageTracker *usage.Tracker,
 	logger logging.LoggerInterface,
-) ImpressionsCounstWorkerImp {
-	return ImpressionsCounstWorkerImp{
+) *ImpressionsCounstWorkerImp {
+	return &ImpressionsCounstWorkerImp{
 		impressionsCounter: impressionsCounter,
 		storage:            storage,
+		usageTracker:       usageTracker,
 		logger:             logger,
 	}
 }
@@ -30,9 +40,70 @@ func (i *ImpressionsCounstWorkerImp) Process() error {
 		return err
 	}
 
+	flags := make([]string, 0, len(impcounts.PerFeature))
 	for _, count := range impcounts.PerFeature {
 		i.impressionsCounter.Inc(count.FeatureName, count.TimeFrame, count.RawCount)
+		flags = append(flags, count.FeatureName)
+	}
+
+	// sdks in `none` mode only produce counts, which still show that their flags are in use
+	if i.usageTracker != nil {
+		i.usageTracker.Seen(flags...)
 	}
+	return nil
+}
 
+// ImpressionsCountRecorder posts the accumulated impression counts to split. Unlike the one in commons,
+// counts that cannot be posted are added back to the counter instead of being lost, so that they're retried
+// in the next run or persisted on shutdown
+type ImpressionsCountRecorder struct {
+	impressionsCounter *strategy.ImpressionsCounter
+	recorder           service.ImpressionsRecorder
+	metadata           dtos.Metadata
+	logger             logging.LoggerInterface
+	runtimeTelemetry   storage.TelemetryRuntimeProducer
+}
+
+// NewImpressionsCountRecorder constructs an impression counts recorder
+func NewImpressionsCountRecorder(
+	impressionsCounter *strategy.ImpressionsCounter,
+	recorder service.ImpressionsRecorder,
+	metadata dtos.Metadata,
+	logger logging.LoggerInterface,
+	runtimeTelemetry storage.TelemetryRuntimeProducer,
+) *ImpressionsCountRecorder {
+	return &ImpressionsCountRecorder{
+		impressionsCounter: impressionsCounter,
+		recorder:           recorder,
+		metadata:           metadata,
+		logger:             logger,
+		runtimeTelemetry:   runtimeTelemetry,
+	}
+}
+
+// SynchronizeImpressionsCount posts the accumulated counts
+func (r *ImpressionsCountRecorder) SynchronizeImpressionsCount() error {
+	counts := r.impressionsCounter.PopAll()
+	if len(counts) == 0 {
+		return nil
+	}
+
+	perFeature := make([]dtos.ImpressionsInTimeFrameDTO, 0, len(counts))
+	for key, count := range counts {
+		perFeature = append(perFeature, dtos.ImpressionsInTimeFrameDTO{FeatureName: key.FeatureName, TimeFrame: key.TimeFrame, RawCount: count})
+	}
+
+	before := time.Now()
+	if err := r.recorder.RecordImpressionsCount(dtos.ImpressionsCountDTO{PerFeature: perFeature}, r.metadata); err != nil {
+		if httpError, ok := err.(*dtos.HTTPError); ok {
+			r.runtimeTelemetry.RecordSyncError(telemetry.ImpressionCountSync, httpError.Code)
+		}
+		for key, count := range counts {
+			r.impressionsCounter.Inc(key.FeatureName, key.TimeFrame, count)
+		}
+		return err
+	}
+	r.runtimeTelemetry.RecordSyncLatency(telemetry.ImpressionCountSync, time.Since(before))
+	r.runtimeTelemetry.RecordSuccessfulSync(telemetry.ImpressionCountSync, time.Now().UTC())
 	return nil
 }
@@ -0,0 +1,300 @@
+package worker
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+// Overflow policies applied when a queue grows beyond its cap
+const (
+	OverflowTrimOldest = "trim_oldest"
+	OverflowSample     = "sample"
+	OverflowSpill      = "spill"
+)
+
+// max number of items popped from redis at once when sampling or spilling
+const overflowPopChunk = 10000
+
+// ErrUnknownOverflowPolicy is returned when constructing a queue cap with an unsupported policy
+var ErrUnknownOverflowPolicy = errors.New("unknown overflow policy")
+
+// CappedQueue is the subset of the impressions & events multi-sdk consumers required to enforce a cap
+type CappedQueue interface {
+	Count() int64
+	PopNRaw(int64) ([]string, int64, error)
+}
+
+// QueueAppender appends raw items to the tail of a queue
+type QueueAppender interface {
+	Push(items []string) error
+}
+
+// queueDropper is implemented by redis queues able to trim their oldest items without transferring them
+type queueDropper interface {
+	Drop(size int64) error
+}
+
+// QueueCapConfig bundles the options for capping a single queue
+type QueueCapConfig struct {
+	Name       string
+	Queue      CappedQueue
+	Appender   QueueAppender // used to re-append the sampled items. Required by the `sample` policy
+	MaxLength  int64
+	Policy     string
+	SampleRate float64 // fraction of the overflowing items kept when sampling
+	SpillDir   string  // directory where overflowing items are written to. Required by the `spill` policy
+}
+
+// QueueCapStatus describes the state of a capped queue
+type QueueCapStatus struct {
+	Name       string  `json:"name"`
+	MaxLength  int64   `json:"maxLength"`
+	Policy     string  `json:"policy"`
+	SampleRate float64 `json:"sampleRate,omitempty"`
+	Length     int64   `json:"length"`
+	CapHits    int64   `json:"capHits"`
+	Trimmed    int64   `json:"trimmed"`
+	Sampled    int64   `json:"sampled"`
+	Spilled    int64   `json:"spilled"`
+	LastHit    int64   `json:"lastHit"`
+	LastError  string  `json:"lastError,omitempty"`
+}
+
+type queueCap struct {
+	cfg    QueueCapConfig
+	mutex  sync.Mutex
+	status QueueCapStatus
+}
+
+// QueueCapWorker keeps the impressions & events queues under the configured lengths by applying an overflow policy
+// to the oldest items, so that redis doesn't run out of memory & reject writes from the sdks when the synchronizer
+// falls behind
+type QueueCapWorker struct {
+	logger logging.LoggerInterface
+	caps   []*queueCap
+}
+
+// NewQueueCapWorker validates the supplied configs & constructs a worker. Queues with no max length are ignored
+func NewQueueCapWorker(logger logging.LoggerInterface, configs ...QueueCapConfig) (*QueueCapWorker, error) {
+	w := &QueueCapWorker{logger: logger}
+	for _, cfg := range configs {
+		if cfg.MaxLength <= 0 {
+			continue
+		}
+
+		switch cfg.Policy {
+		case OverflowTrimOldest:
+		case OverflowSample:
+			if cfg.Appender == nil {
+				return nil, fmt.Errorf("queue %s: sampling requires an appender", cfg.Name)
+			}
+			if cfg.SampleRate <= 0 || cfg.SampleRate >= 1 {
+				return nil, fmt.Errorf("queue %s: sample rate must be between 0 and 1 (exclusive). Got: %f", cfg.Name, cfg.SampleRate)
+			}
+		case OverflowSpill:
+			if cfg.SpillDir == "" {
+				return nil, fmt.Errorf("queue %s: spilling requires a directory", cfg.Name)
+			}
+		default:
+			return nil, fmt.Errorf("queue %s: %w '%s'", cfg.Name, ErrUnknownOverflowPolicy, cfg.Policy)
+		}
+
+		status := QueueCapStatus{Name: cfg.Name, MaxLength: cfg.MaxLength, Policy: cfg.Policy}
+		if cfg.Policy == OverflowSample {
+			status.SampleRate = cfg.SampleRate
+		}
+		w.caps = append(w.caps, &queueCap{cfg: cfg, status: status})
+	}
+	return w, nil
+}
+
+// Enabled returns true if at least one queue is capped
+func (w *QueueCapWorker) Enabled() bool {
+	return len(w.caps) > 0
+}
+
+// Enforce checks every capped queue & applies the overflow policy to those exceeding their max length
+func (w *QueueCapWorker) Enforce() error {
+	var errs []error
+	for _, qc := range w.caps {
+		if err := w.enforce(qc); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("errors enforcing queue caps: %v", errs)
+	}
+	return nil
+}
+
+// Status returns the current state of every capped queue
+func (w *QueueCapWorker) Status() []QueueCapStatus {
+	toRet := make([]QueueCapStatus, 0, len(w.caps))
+	for _, qc := range w.caps {
+		qc.mutex.Lock()
+		toRet = append(toRet, qc.status)
+		qc.mutex.Unlock()
+	}
+	return toRet
+}
+
+func (w *QueueCapWorker) enforce(qc *queueCap) error {
+	length := qc.cfg.Queue.Count()
+	excess := length - qc.cfg.MaxLength
+
+	qc.mutex.Lock()
+	qc.status.Length = length
+	qc.mutex.Unlock()
+	if excess <= 0 {
+		return nil
+	}
+
+	w.logger.Warning(fmt.Sprintf("%s queue has %d items, exceeding its cap of %d. Applying policy '%s' to the %d oldest ones",
+		qc.cfg.Name, length, qc.cfg.MaxLength, qc.cfg.Policy, excess))
+
+	var trimmed, sampled, spilled int64
+	var err error
+	switch qc.cfg.Policy {
+	case OverflowTrimOldest:
+		trimmed, err = w.trim(qc, excess)
+	case OverflowSample:
+		trimmed, sampled, err = w.sample(qc, excess, length)
+	case OverflowSpill:
+		spilled, trimmed, err = w.spill(qc, excess)
+	}
+
+	qc.mutex.Lock()
+	defer qc.mutex.Unlock()
+	qc.status.CapHits++
+	qc.status.LastHit = time.Now().UnixNano() / int64(time.Millisecond)
+	qc.status.Trimmed += trimmed
+	qc.status.Sampled += sampled
+	qc.status.Spilled += spilled
+	qc.status.Length = qc.cfg.Queue.Count()
+	qc.status.LastError = ""
+	if err != nil {
+		qc.status.LastError = err.Error()
+		w.logger.Error(fmt.Sprintf("error enforcing %s queue cap: %s", qc.cfg.Name, err))
+	}
+	return err
+}
+
+// trim discards the oldest `excess` items
+func (w *QueueCapWorker) trim(qc *queueCap, excess int64) (int64, error) {
+	if dropper, ok := qc.cfg.Queue.(queueDropper); ok {
+		if err := dropper.Drop(excess); err != nil {
+			return 0, fmt.Errorf("error trimming %s queue: %w", qc.cfg.Name, err)
+		}
+		return excess, nil
+	}
+
+	var trimmed int64
+	err := popChunks(qc.cfg.Queue, excess, func(items []string) error {
+		trimmed += int64(len(items))
+		return nil
+	})
+	return trimmed, err
+}
+
+// sample pops enough of the oldest items so that, after re-appending a random `SampleRate` fraction of them to the
+// tail of the queue, its length is back at the cap. It returns the number of discarded & re-appended items
+func (w *QueueCapWorker) sample(qc *queueCap, excess int64, length int64) (int64, int64, error) {
+	toPop := int64(math.Ceil(float64(excess) / (1 - qc.cfg.SampleRate)))
+	if toPop > length {
+		toPop = length
+	}
+
+	var trimmed, sampled int64
+	err := popChunks(qc.cfg.Queue, toPop, func(items []string) error {
+		kept := items[:0]
+		for _, item := range items {
+			if rand.Float64() < qc.cfg.SampleRate {
+				kept = append(kept, item)
+			}
+		}
+
+		trimmed += int64(len(items) - len(kept))
+		if err := qc.cfg.Appender.Push(kept); err != nil {
+			trimmed += int64(len(kept))
+			return fmt.Errorf("error re-appending sampled items to %s queue: %w", qc.cfg.Name, err)
+		}
+		sampled += int64(len(kept))
+		return nil
+	})
+	return trimmed, sampled, err
+}
+
+// spill moves the oldest `excess` items into a newline-delimited file in the spill directory.
+// It returns the number of items written & the number of items lost if writing failed
+func (w *QueueCapWorker) spill(qc *queueCap, excess int64) (int64, int64, error) {
+	if err := os.MkdirAll(qc.cfg.SpillDir, 0755); err != nil {
+		return 0, 0, fmt.Errorf("error creating spill directory: %w", err)
+	}
+
+	path := filepath.Join(qc.cfg.SpillDir, fmt.Sprintf("%s-%d.ndjson", qc.cfg.Name, time.Now().UnixNano()))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error creating spill file: %w", err)
+	}
+	defer file.Close()
+
+	var spilled, lost int64
+	writer := bufio.NewWriter(file)
+	err = popChunks(qc.cfg.Queue, excess, func(items []string) error {
+		// a chunk only counts as spilled once it's flushed, since a failed write may leave it partially written
+		for _, item := range items {
+			if _, err := writer.WriteString(item + "\n"); err != nil {
+				lost += int64(len(items))
+				return fmt.Errorf("error writing to spill file: %w", err)
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			lost += int64(len(items))
+			return fmt.Errorf("error flushing spill file: %w", err)
+		}
+		spilled += int64(len(items))
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("%d items lost, spill file %s may be partial: %w", lost, path, err)
+	}
+
+	if spilled > 0 {
+		w.logger.Info(fmt.Sprintf("spilled %d %s into %s", spilled, qc.cfg.Name, path))
+	}
+	return spilled, lost, err
+}
+
+// popChunks pops `n` items from the head of the queue in chunks, handing each of them to the callback
+func popChunks(queue CappedQueue, n int64, callback func([]string) error) error {
+	for n > 0 {
+		chunk := n
+		if chunk > overflowPopChunk {
+			chunk = overflowPopChunk
+		}
+
+		items, _, err := queue.PopNRaw(chunk)
+		if err != nil {
+			return fmt.Errorf("error popping items: %w", err)
+		}
+		if len(items) == 0 {
+			return nil
+		}
+
+		if err := callback(items); err != nil {
+			return err
+		}
+		n -= int64(len(items))
+	}
+	return nil
+}
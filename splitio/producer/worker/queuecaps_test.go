@@ -0,0 +1,189 @@
+package worker
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+type queueMock struct {
+	mutex sync.Mutex
+	items []string
+}
+
+func newQueueMock(size int) *queueMock {
+	q := &queueMock{}
+	for idx := 0; idx < size; idx++ {
+		q.items = append(q.items, "item_"+strconv.Itoa(idx))
+	}
+	return q
+}
+
+func (q *queueMock) Count() int64 {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return int64(len(q.items))
+}
+
+func (q *queueMock) PopNRaw(n int64) ([]string, int64, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if n > int64(len(q.items)) {
+		n = int64(len(q.items))
+	}
+	popped := append([]string(nil), q.items[:n]...)
+	q.items = q.items[n:]
+	return popped, int64(len(q.items)), nil
+}
+
+func (q *queueMock) Push(items []string) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.items = append(q.items, items...)
+	return nil
+}
+
+type droppingQueueMock struct {
+	*queueMock
+	dropped int64
+}
+
+func (q *droppingQueueMock) Drop(size int64) error {
+	q.dropped = size
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.items = q.items[size:]
+	return nil
+}
+
+type failingAppender struct{}
+
+func (failingAppender) Push([]string) error { return errors.New("someError") }
+
+func TestQueueCapConfigValidation(t *testing.T) {
+	logger := logging.NewLogger(nil)
+	queue := newQueueMock(0)
+
+	if _, err := NewQueueCapWorker(logger, QueueCapConfig{Name: "q", Queue: queue, MaxLength: 10, Policy: "nope"}); !errors.Is(err, ErrUnknownOverflowPolicy) {
+		t.Error("unknown policies should be rejected. Got: ", err)
+	}
+
+	if _, err := NewQueueCapWorker(logger, QueueCapConfig{Name: "q", Queue: queue, MaxLength: 10, Policy: OverflowSample, Appender: queue}); err == nil {
+		t.Error("sampling without a valid rate should be rejected")
+	}
+
+	if _, err := NewQueueCapWorker(logger, QueueCapConfig{Name: "q", Queue: queue, MaxLength: 10, Policy: OverflowSpill}); err == nil {
+		t.Error("spilling without a directory should be rejected")
+	}
+
+	w, err := NewQueueCapWorker(logger, QueueCapConfig{Name: "q", Queue: queue, Policy: "nope"})
+	if err != nil || w.Enabled() {
+		t.Error("queues without a max length should be ignored. Got: ", err)
+	}
+}
+
+func TestQueueCapTrimOldest(t *testing.T) {
+	queue := newQueueMock(25)
+	w, _ := NewQueueCapWorker(logging.NewLogger(nil), QueueCapConfig{Name: "impressions", Queue: queue, MaxLength: 10, Policy: OverflowTrimOldest})
+	if err := w.Enforce(); err != nil {
+		t.Error("no error should be returned. Got: ", err)
+	}
+
+	if queue.Count() != 10 || queue.items[0] != "item_15" {
+		t.Error("the 15 oldest items should have been removed. Got: ", queue.items)
+	}
+
+	status := w.Status()
+	if len(status) != 1 || status[0].CapHits != 1 || status[0].Trimmed != 15 || status[0].Length != 10 || status[0].LastHit == 0 {
+		t.Error("unexpected status: ", status)
+	}
+
+	// under the cap, nothing should happen
+	w.Enforce()
+	if status := w.Status(); status[0].CapHits != 1 || status[0].Trimmed != 15 {
+		t.Error("no cap hit should be recorded when under the cap. Got: ", status)
+	}
+
+	dropping := &droppingQueueMock{queueMock: newQueueMock(25)}
+	w, _ = NewQueueCapWorker(logging.NewLogger(nil), QueueCapConfig{Name: "events", Queue: dropping, MaxLength: 20, Policy: OverflowTrimOldest})
+	w.Enforce()
+	if dropping.dropped != 5 || dropping.Count() != 20 {
+		t.Error("queues supporting it should be trimmed without popping. Got: ", dropping.dropped, dropping.Count())
+	}
+}
+
+func TestQueueCapSample(t *testing.T) {
+	queue := newQueueMock(1000)
+	w, _ := NewQueueCapWorker(logging.NewLogger(nil), QueueCapConfig{
+		Name:       "impressions",
+		Queue:      queue,
+		Appender:   queue,
+		MaxLength:  500,
+		Policy:     OverflowSample,
+		SampleRate: 0.5,
+	})
+	if err := w.Enforce(); err != nil {
+		t.Error("no error should be returned. Got: ", err)
+	}
+
+	status := w.Status()[0]
+	if status.Trimmed+status.Sampled != 1000 || status.Sampled == 0 || status.Trimmed == 0 {
+		t.Error("every popped item should be either trimmed or sampled. Got: ", status)
+	}
+	if queue.Count() != status.Sampled {
+		t.Error("sampled items should have been re-appended. Got: ", queue.Count(), status.Sampled)
+	}
+
+	queue = newQueueMock(100)
+	w, _ = NewQueueCapWorker(logging.NewLogger(nil), QueueCapConfig{
+		Name:       "events",
+		Queue:      queue,
+		Appender:   failingAppender{},
+		MaxLength:  50,
+		Policy:     OverflowSample,
+		SampleRate: 0.2,
+	})
+	if err := w.Enforce(); err == nil {
+		t.Error("append errors should be propagated")
+	}
+	if status := w.Status()[0]; status.LastError == "" || status.Sampled != 0 {
+		t.Error("the error should be reported in the status. Got: ", status)
+	}
+}
+
+func TestQueueCapSpill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	spillDir := filepath.Join(dir, "nested")
+
+	queue := newQueueMock(30)
+	w, _ := NewQueueCapWorker(logging.NewLogger(nil), QueueCapConfig{Name: "events", Queue: queue, MaxLength: 10, Policy: OverflowSpill, SpillDir: spillDir})
+	if err := w.Enforce(); err != nil {
+		t.Error("no error should be returned. Got: ", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(spillDir, "events-*.ndjson"))
+	if len(files) != 1 {
+		t.Fatal("there should be 1 spill file. Got: ", files)
+	}
+
+	contents, _ := ioutil.ReadFile(files[0])
+	lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+	if len(lines) != 20 || lines[0] != "item_0" || lines[19] != "item_19" {
+		t.Error("the 20 oldest items should have been spilled. Got: ", lines)
+	}
+
+	if status := w.Status()[0]; status.Spilled != 20 || status.Trimmed != 0 || queue.Count() != 10 {
+		t.Error("unexpected status: ", status)
+	}
+}
@@ -39,14 +39,22 @@ type TelemetryMultiWorkerImpl struct {
 	logger  logging.LoggerInterface
 	storage storage.RedisTelemetryConsumerMulti
 	sync    service.TelemetryRecorder
+	modes   *storage.SDKImpressionsModes
 }
 
-// NewTelemetryMultiWorker instantes a new telemetry worker
-func NewTelemetryMultiWorker(logger logging.LoggerInterface, store storage.RedisTelemetryConsumerMulti, sync service.TelemetryRecorder) *TelemetryMultiWorkerImpl {
+// NewTelemetryMultiWorker instantes a new telemetry worker.
+// If a modes registry is supplied, it's updated with the impressions mode reported in each sdk config
+func NewTelemetryMultiWorker(
+	logger logging.LoggerInterface,
+	store storage.RedisTelemetryConsumerMulti,
+	sync service.TelemetryRecorder,
+	modes *storage.SDKImpressionsModes,
+) *TelemetryMultiWorkerImpl {
 	return &TelemetryMultiWorkerImpl{
 		logger:  logger,
 		storage: store,
 		sync:    sync,
+		modes:   modes,
 	}
 }
 
@@ -102,6 +110,9 @@ func (w *TelemetryMultiWorkerImpl) SynchronizeStats() error {
 func (w *TelemetryMultiWorkerImpl) SyncrhonizeConfigs() error {
 	errors := make(map[dtos.Metadata]error)
 	for metadata, config := range w.storage.PopConfigs() {
+		if w.modes != nil {
+			w.modes.Update(metadata, config.ImpressionsMode)
+		}
 		err := w.sync.RecordConfig(config, metadata)
 		if err != nil {
 			errors[metadata] = err
@@ -3,6 +3,7 @@ package worker
 import (
 	"testing"
 
+	"github.com/splitio/go-split-commons/v4/conf"
 	"github.com/splitio/go-split-commons/v4/dtos"
 	serviceMocks "github.com/splitio/go-split-commons/v4/service/mocks"
 	"github.com/splitio/go-split-commons/v4/telemetry"
@@ -39,8 +40,8 @@ func TestTelemetryMultiWorker(t *testing.T) {
 		},
 		PopConfigsCall: func() storage.MultiConfigs {
 			return map[dtos.Metadata]dtos.Config{
-				metadata1: dtos.Config{OperationMode: 1},
-				metadata2: dtos.Config{OperationMode: 2},
+				metadata1: dtos.Config{OperationMode: 1, ImpressionsMode: telemetry.ImpressionsModeDebug},
+				metadata2: dtos.Config{OperationMode: 2, ImpressionsMode: telemetry.ImpressionsModeNone},
 			}
 		},
 	}
@@ -79,7 +80,8 @@ func TestTelemetryMultiWorker(t *testing.T) {
 		},
 	}
 
-	worker := NewTelemetryMultiWorker(logger, &store, &sync)
+	modes := storage.NewSDKImpressionsModes(conf.ImpressionsModeOptimized)
+	worker := NewTelemetryMultiWorker(logger, &store, &sync, modes)
 	err := worker.SynchronizeStats()
 	if err != nil {
 		t.Error("no errors should have been returned.")
@@ -93,4 +95,11 @@ func TestTelemetryMultiWorker(t *testing.T) {
 	if configCalls != 2 || statsCalls != 2 {
 		t.Error("invalid number of calls: ", configCalls, statsCalls)
 	}
+
+	if mode := modes.Get(metadata1); mode != conf.ImpressionsModeDebug {
+		t.Error("metadata1 should be tracked as debug. Got: ", mode)
+	}
+	if mode := modes.Get(metadata2); mode != conf.ImpressionsModeNone {
+		t.Error("metadata2 should be tracked as none. Got: ", mode)
+	}
 }
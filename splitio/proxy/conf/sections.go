@@ -18,6 +18,8 @@ type Main struct {
 	Logging          conf.Logging      `json:"logging" s-nested:"true"`
 	Healthcheck      Healthcheck       `json:"healthcheck" s-nested:"true"`
 	Observability    Observability     `json:"observability" s-nested:"true"`
+	Snapshot         conf.Snapshot     `json:"snapshot" s-nested:"true"`
+	Offline          Offline           `json:"offline" s-nested:"true"`
 }
 
 // BuildAdvancedConfig generates a commons-compatible advancedconfig with default + overriden parameters
@@ -35,7 +37,7 @@ func (m *Main) BuildAdvancedConfig() *cconf.AdvancedConfig {
 // Initialization configuration options
 type Initialization struct {
 	TimeoutMs         int64  `json:"timeoutMS" s-cli:"timeout-ms" s-def:"10000" s-desc:"How long to wait until the synchronizer is ready"`
-	Snapshot          string `json:"snapshot" s-cli:"snapshot" s-def:"" s-desc:"Snapshot file to use as a starting point"`
+	Snapshot          string `json:"snapshot" s-cli:"snapshot" s-def:"" s-desc:"Snapshot file (or directory to pick the newest one from) to use as a starting point"`
 	ForceFreshStartup bool   `json:"forceFreshStartup" s-cli:"force-fresh-startup" s-def:"false" s-desc:"Wipe storage before starting the synchronizer"`
 }
 
@@ -97,3 +99,13 @@ type Observability struct {
 	TimeSliceWidthSecs int64 `json:"timeSliceWidthSecs" s-cli:"observability-time-slice-width-secs" s-def:"300" s-desc:"time slice size in seconds"`
 	MaxTimeSliceCount  int64 `json:"maxTimeSliceCount" s-cli:"observability-time-slice-max-count" s-def:"100" s-desc:"max time slices to keep in memory before rotating"`
 }
+
+// Offline configuration options
+type Offline struct {
+	Enabled           bool   `json:"enabled" s-cli:"offline-enabled" s-def:"false" s-desc:"Run without contacting split servers. Data is only loaded from snapshots"`
+	SnapshotDirectory string `json:"snapshotDirectory" s-cli:"offline-snapshot-directory" s-def:"" s-desc:"Directory to watch for new snapshots"`
+	WatchRateMs       int64  `json:"watchRateMs" s-cli:"offline-watch-rate-ms" s-def:"10000" s-desc:"How often to look for new snapshots"`
+	OutputDirectory   string `json:"outputDirectory" s-cli:"offline-output-directory" s-def:"" s-desc:"Directory where impressions, events & telemetry are written to"`
+	MaxFileSizeBytes  int64  `json:"maxFileSizeBytes" s-cli:"offline-max-file-size-bytes" s-def:"104857600" s-desc:"Size after which an output file is rotated"`
+	MaxFileAgeMs      int64  `json:"maxFileAgeMs" s-cli:"offline-max-file-age-ms" s-def:"3600000" s-desc:"Time after which an output file is rotated"`
+}
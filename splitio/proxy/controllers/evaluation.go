@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v4/conf"
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/evaluator"
+)
+
+// maximum number of key/split pairs that can be evaluated in a single request
+const maxEvaluationsPerRequest = 1000
+
+// FlagLister is used to enumerate the flags available for bulk evaluations
+type FlagLister interface {
+	SplitNames() []string
+	ChangeNumber() (int64, error)
+}
+
+// ImpressionStager stages impressions the same way as those posted by sdks (ie: EventsServerController)
+type ImpressionStager interface {
+	StageImpressions(metadata dtos.Metadata, impressionsMode string, data []byte) error
+}
+
+// EvaluationServerController bundles the endpoints used by clients without an sdk to get treatments evaluated by the proxy
+type EvaluationServerController struct {
+	logger      logging.LoggerInterface
+	evaluator   *evaluator.Evaluator
+	flags       FlagLister
+	impressions ImpressionStager
+}
+
+// NewEvaluationServerController constructs a new evaluation controller
+func NewEvaluationServerController(
+	logger logging.LoggerInterface,
+	evaluator *evaluator.Evaluator,
+	flags FlagLister,
+	impressions ImpressionStager,
+) *EvaluationServerController {
+	return &EvaluationServerController{logger: logger, evaluator: evaluator, flags: flags, impressions: impressions}
+}
+
+// Register mounts the evaluation endpoints onto the supplied router
+func (c *EvaluationServerController) Register(router gin.IRouter) {
+	router.GET("/v1/treatments", c.TreatmentsFromQuery)
+	router.POST("/v1/treatments", c.Treatments)
+}
+
+// RegisterOFREP mounts the OpenFeature remote evaluation protocol endpoints onto the supplied router
+func (c *EvaluationServerController) RegisterOFREP(router gin.IRouter) {
+	router.POST("/v1/evaluate/flags/:key", c.OFREPEvaluateFlag)
+	router.POST("/v1/evaluate/flags", c.OFREPEvaluateFlags)
+}
+
+// EvaluationRequest is the payload accepted by the treatments endpoint
+type EvaluationRequest struct {
+	Key          string                 `json:"key"`
+	BucketingKey string                 `json:"bucketingKey,omitempty"`
+	SplitNames   []string               `json:"splitNames"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// EvaluationResponse contains the treatments computed for a key
+type EvaluationResponse struct {
+	Key          string                       `json:"key"`
+	BucketingKey string                       `json:"bucketingKey,omitempty"`
+	Treatments   map[string]*evaluator.Result `json:"treatments"`
+}
+
+// TreatmentsFromQuery evaluates the splits for a single key, taking the parameters from the querystring:
+// GET /api/v1/treatments?key=k&bucketing-key=b&split-names=s1,s2&attributes={"a":1}
+func (c *EvaluationServerController) TreatmentsFromQuery(ctx *gin.Context) {
+	req := EvaluationRequest{
+		Key:          ctx.Query("key"),
+		BucketingKey: ctx.Query("bucketing-key"),
+	}
+
+	if names := ctx.Query("split-names"); names != "" {
+		req.SplitNames = strings.Split(names, ",")
+	}
+
+	if attributes := ctx.Query("attributes"); attributes != "" {
+		if err := json.Unmarshal([]byte(attributes), &req.Attributes); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "attributes must be a json object"})
+			return
+		}
+	}
+
+	responses, err := c.evaluate(ctx, []EvaluationRequest{req})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, responses[0])
+}
+
+// Treatments evaluates splits for one key (if the body is an object) or many (if the body is an array)
+func (c *EvaluationServerController) Treatments(ctx *gin.Context) {
+	body, err := ioutil.ReadAll(ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "error reading request body"})
+		return
+	}
+
+	batch := bytes.HasPrefix(bytes.TrimSpace(body), []byte("["))
+	var reqs []EvaluationRequest
+	if batch {
+		err = json.Unmarshal(body, &reqs)
+	} else {
+		reqs = make([]EvaluationRequest, 1)
+		err = json.Unmarshal(body, &reqs[0])
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("error parsing request body: %s", err)})
+		return
+	}
+
+	responses, err := c.evaluate(ctx, reqs)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if batch {
+		ctx.JSON(http.StatusOK, responses)
+		return
+	}
+	ctx.JSON(http.StatusOK, responses[0])
+}
+
+func (c *EvaluationServerController) evaluate(ctx *gin.Context, reqs []EvaluationRequest) ([]EvaluationResponse, error) {
+	total := 0
+	for idx := range reqs {
+		if reqs[idx].Key == "" {
+			return nil, fmt.Errorf("a key is required (item %d)", idx)
+		}
+		if len(reqs[idx].SplitNames) == 0 {
+			return nil, fmt.Errorf("at least one split name is required (item %d)", idx)
+		}
+		total += len(reqs[idx].SplitNames)
+	}
+
+	if total > maxEvaluationsPerRequest {
+		return nil, fmt.Errorf("too many evaluations requested (%d). Max allowed is %d", total, maxEvaluationsPerRequest)
+	}
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	impressions := make(map[string][]dtos.ImpressionDTO)
+	responses := make([]EvaluationResponse, 0, len(reqs))
+	for _, req := range reqs {
+		key := evaluator.Key{MatchingKey: req.Key, BucketingKey: req.BucketingKey}
+		results := c.evaluator.EvaluateMany(key, req.SplitNames, req.Attributes)
+		for split, result := range results {
+			if result.Label == evaluator.LabelDefinitionNotFound {
+				continue
+			}
+			impressions[split] = append(impressions[split], dtos.ImpressionDTO{
+				KeyName:      req.Key,
+				BucketingKey: req.BucketingKey,
+				Treatment:    result.Treatment,
+				Label:        result.Label,
+				ChangeNumber: result.ChangeNumber,
+				Time:         now,
+			})
+		}
+		responses = append(responses, EvaluationResponse{Key: req.Key, BucketingKey: req.BucketingKey, Treatments: results})
+	}
+
+	c.recordImpressions(evaluationMetadata(ctx), impressions)
+	return responses, nil
+}
+
+// recordImpressions stages the impressions generated by the evaluations. Failures are logged but don't fail the request,
+// since treatments have already been computed
+func (c *EvaluationServerController) recordImpressions(metadata dtos.Metadata, impressions map[string][]dtos.ImpressionDTO) {
+	if len(impressions) == 0 {
+		return
+	}
+
+	bulk := make([]dtos.ImpressionsDTO, 0, len(impressions))
+	for split, keyImpressions := range impressions {
+		bulk = append(bulk, dtos.ImpressionsDTO{TestName: split, KeyImpressions: keyImpressions})
+	}
+
+	payload, err := json.Marshal(bulk)
+	if err != nil {
+		c.logger.Error("error serializing impressions generated by remote evaluations: ", err)
+		return
+	}
+
+	if err := c.impressions.StageImpressions(metadata, conf.ImpressionsModeDebug, payload); err != nil {
+		c.logger.Error("error staging impressions generated by remote evaluations: ", err)
+	}
+}
+
+func evaluationMetadata(ctx *gin.Context) dtos.Metadata {
+	metadata := metadataFromHeaders(ctx)
+	if metadata.SDKVersion == "" {
+		metadata.SDKVersion = "proxy-" + splitio.Version
+	}
+	if metadata.MachineIP == "" {
+		metadata.MachineIP = "NA"
+	}
+	if metadata.MachineName == "" {
+		metadata.MachineName = "NA"
+	}
+	return metadata
+}
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/storage/inmemory/mutexmap"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/evaluator"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/usage"
+	mw "github.com/splitio/split-synchronizer/v5/splitio/proxy/controllers/middleware"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/internal"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/tasks/mocks"
+)
+
+func TestTreatments(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logging.NewLogger(nil)
+
+	splits := mutexmap.NewMMSplitStorage()
+	splits.Update([]dtos.SplitDTO{{
+		Name:              "split1",
+		Status:            "ACTIVE",
+		ChangeNumber:      123,
+		DefaultTreatment:  "off",
+		TrafficAllocation: 100,
+		Conditions: []dtos.ConditionDTO{{
+			ConditionType: "WHITELIST",
+			Label:         "whitelisted",
+			MatcherGroup: dtos.MatcherGroupDTO{Combiner: "AND", Matchers: []dtos.MatcherDTO{{
+				MatcherType: "WHITELIST",
+				Whitelist:   &dtos.WhitelistMatcherDataDTO{Whitelist: []string{"key1"}},
+			}}},
+			Partitions: []dtos.PartitionDTO{{Treatment: "on", Size: 100}},
+		}},
+	}}, nil, 123)
+
+	var staged []dtos.ImpressionsDTO
+	sink := &mocks.MockDeferredRecordingTask{
+		StageCall: func(rawData interface{}) error {
+			data := rawData.(*internal.RawImpressions)
+			if data.Metadata.SDKVersion != "python-1.0.0" || data.Mode != "debug" {
+				t.Error("wrong metadata or mode: ", data.Metadata, data.Mode)
+			}
+			var parsed []dtos.ImpressionsDTO
+			json.Unmarshal(data.Payload, &parsed)
+			staged = append(staged, parsed...)
+			return nil
+		},
+	}
+
+	router := gin.New()
+	group := router.Group("/api")
+	group.Use(mw.NewAPIKeyValidator([]string{"someApiKey"}).AsMiddleware)
+	NewEvaluationServerController(logger, evaluator.NewEvaluator(splits, mutexmap.NewMMSegmentStorage(), logger), splits, NewEventsServerController(logger, sink, nil, nil, nil, nil, nil, nil)).Register(group)
+
+	do := func(method string, path string, body string, apikey string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+apikey)
+		req.Header.Set("SplitSDKVersion", "python-1.0.0")
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	if resp := do(http.MethodPost, "/api/v1/treatments", `{"key":"key1","splitNames":["split1"]}`, "invalid"); resp.Code != http.StatusUnauthorized {
+		t.Error("invalid apikeys should be rejected. Got: ", resp.Code)
+	}
+
+	resp := do(http.MethodPost, "/api/v1/treatments", `{"key":"key1","splitNames":["split1","nonexistent"]}`, "someApiKey")
+	var single EvaluationResponse
+	json.Unmarshal(resp.Body.Bytes(), &single)
+	if resp.Code != http.StatusOK || single.Treatments["split1"].Treatment != "on" || single.Treatments["nonexistent"].Treatment != "control" {
+		t.Error("wrong single evaluation response: ", resp.Code, resp.Body.String())
+	}
+
+	if len(staged) != 1 || staged[0].TestName != "split1" || staged[0].KeyImpressions[0].Label != "whitelisted" {
+		t.Error("only the impression for the existing split should be recorded. Got: ", staged)
+	}
+
+	resp = do(http.MethodPost, "/api/v1/treatments", `[{"key":"key1","splitNames":["split1"]},{"key":"key2","splitNames":["split1"]}]`, "someApiKey")
+	var batch []EvaluationResponse
+	json.Unmarshal(resp.Body.Bytes(), &batch)
+	if resp.Code != http.StatusOK || len(batch) != 2 || batch[1].Treatments["split1"].Treatment != "off" {
+		t.Error("wrong batch evaluation response: ", resp.Code, resp.Body.String())
+	}
+
+	if len(staged) != 2 || len(staged[1].KeyImpressions) != 2 {
+		t.Error("batch impressions should be recorded in a single bulk. Got: ", staged)
+	}
+
+	query := url.Values{"key": {"key1"}, "split-names": {"split1"}, "attributes": {`{"age":3}`}}
+	resp = do(http.MethodGet, "/api/v1/treatments?"+query.Encode(), "", "someApiKey")
+	json.Unmarshal(resp.Body.Bytes(), &single)
+	if resp.Code != http.StatusOK || single.Treatments["split1"].Treatment != "on" {
+		t.Error("wrong evaluation response for querystring params: ", resp.Code, resp.Body.String())
+	}
+
+	if resp := do(http.MethodPost, "/api/v1/treatments", `{"splitNames":["split1"]}`, "someApiKey"); resp.Code != http.StatusBadRequest {
+		t.Error("requests without key should be rejected. Got: ", resp.Code)
+	}
+}
+
+func TestTreatmentsUsageTracking(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logging.NewLogger(nil)
+
+	splits := mutexmap.NewMMSplitStorage()
+	splits.Update([]dtos.SplitDTO{{Name: "split1", Status: "ACTIVE", ChangeNumber: 123, DefaultTreatment: "off", TrafficAllocation: 100}}, nil, 123)
+
+	tracker := usage.NewTracker(time.Hour, time.Hour)
+	sink := &mocks.MockDeferredRecordingTask{StageCall: func(rawData interface{}) error { return nil }}
+	events := NewEventsServerController(logger, sink, nil, nil, nil, nil, tracker, nil)
+
+	router := gin.New()
+	NewEvaluationServerController(logger, evaluator.NewEvaluator(splits, mutexmap.NewMMSegmentStorage(), logger), splits, events).Register(router.Group("/api"))
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/treatments", bytes.NewBufferString(`{"key":"key1","splitNames":["split1"]}`))
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Error("wrong status code: ", resp.Code)
+	}
+
+	// usage is tracked asynchronously
+	for attempt := 0; attempt < 100 && len(tracker.Usage()) == 0; attempt++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if tracked := tracker.Usage(); len(tracked) != 1 || tracked[0].Flag != "split1" || tracked[0].Total != 1 {
+		t.Error("impressions from remote evaluations should be tracked. Got: ", tracked)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/splitio/go-toolkit/v5/logging"
 
 	"github.com/splitio/split-synchronizer/v5/splitio/common/impressionlistener"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/impressiontap"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/usage"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/internal"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/tasks"
 )
@@ -22,6 +24,8 @@ type EventsServerController struct {
 	eventsSink          tasks.DeferredRecordingTask
 	listener            impressionlistener.ImpressionBulkListener
 	apikeyValidator     func(string) bool
+	usageTracker        *usage.Tracker
+	tap                 *impressiontap.Tap
 }
 
 // NewEventsServerController returns a new events server controller
@@ -32,6 +36,8 @@ func NewEventsServerController(
 	eventsSink tasks.DeferredRecordingTask,
 	listener impressionlistener.ImpressionBulkListener,
 	apikeyValidator func(string) bool,
+	usageTracker *usage.Tracker,
+	tap *impressiontap.Tap,
 ) *EventsServerController {
 	return &EventsServerController{
 		logger:              logger,
@@ -40,6 +46,8 @@ func NewEventsServerController(
 		eventsSink:          eventsSink,
 		listener:            listener,
 		apikeyValidator:     apikeyValidator,
+		usageTracker:        usageTracker,
+		tap:                 tap,
 	}
 }
 
@@ -72,13 +80,7 @@ func (c *EventsServerController) TestImpressionsBulk(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, nil)
 		return
 	}
-	if c.listener != nil {
-		// if we have a listener, schedule a goroutine to convert these impressions and
-		// push them into the channel.
-		go c.submitImpressionsToListener(data, &metadata)
-	}
-
-	err = c.impressionsSink.Stage(internal.NewRawImpressions(metadata, impressionsMode, data))
+	err = c.StageImpressions(metadata, impressionsMode, data)
 	if err != nil {
 		if err == tasks.ErrQueueFull {
 			ctx.AbortWithStatusJSON(500, "Impressions queue is full, please retry later.")
@@ -90,6 +92,20 @@ func (c *EventsServerController) TestImpressionsBulk(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, nil)
 }
 
+// StageImpressions forwards an impressions bulk to the listener, usage tracker & tap (if any),
+// and stages it to be posted. Impressions generated by remote evaluations go through it as well
+func (c *EventsServerController) StageImpressions(metadata dtos.Metadata, impressionsMode string, data []byte) error {
+	if c.listener != nil {
+		// if we have a listener, schedule a goroutine to convert these impressions and
+		// push them into the channel.
+		go c.submitImpressionsToListener(data, &metadata)
+	}
+	if c.usageTracker != nil || (c.tap != nil && c.tap.Active()) {
+		go c.inspectImpressions(data, &metadata)
+	}
+	return c.impressionsSink.Stage(internal.NewRawImpressions(metadata, impressionsMode, data))
+}
+
 // TestImpressionsBeacon accepts beacon style posts with impressions payload
 func (c *EventsServerController) TestImpressionsBeacon(ctx *gin.Context) {
 	if ctx.Request.Body == nil {
@@ -119,7 +135,12 @@ func (c *EventsServerController) TestImpressionsBeacon(ctx *gin.Context) {
 		return
 	}
 
-	err = c.impressionsSink.Stage(internal.NewRawImpressions(dtos.Metadata{SDKVersion: body.Sdk, MachineIP: "NA", MachineName: "NA"}, "", body.Entries))
+	metadata := dtos.Metadata{SDKVersion: body.Sdk, MachineIP: "NA", MachineName: "NA"}
+	if c.usageTracker != nil || (c.tap != nil && c.tap.Active()) {
+		go c.inspectImpressions(body.Entries, &metadata)
+	}
+
+	err = c.impressionsSink.Stage(internal.NewRawImpressions(metadata, "", body.Entries))
 	if err != nil {
 		if err == tasks.ErrQueueFull {
 			ctx.AbortWithStatusJSON(500, "Impressions queue is full, please retry later.")
@@ -142,6 +163,10 @@ func (c *EventsServerController) TestImpressionsCount(ctx *gin.Context) {
 		return
 	}
 
+	if c.usageTracker != nil {
+		go c.inspectImpressionCounts(data)
+	}
+
 	code := http.StatusOK
 	err = c.impressionCountSink.Stage(internal.NewRawImpressionCounts(metadata, data))
 	if err != nil {
@@ -181,6 +206,10 @@ func (c *EventsServerController) TestImpressionsCountBeacon(ctx *gin.Context) {
 		return
 	}
 
+	if c.usageTracker != nil {
+		go c.inspectImpressionCounts(body.Entries)
+	}
+
 	code := http.StatusNoContent
 
 	err = c.impressionCountSink.Stage(internal.NewRawImpressionCounts(dtos.Metadata{SDKVersion: body.Sdk, MachineIP: "NA", MachineName: "NA"}, body.Entries))
@@ -260,6 +289,40 @@ func (c *EventsServerController) EventsBulkBeacon(ctx *gin.Context) {
 // This is meant to be used with legacy telemetry endpoints
 func (c *EventsServerController) DummyAlwaysOk(ctx *gin.Context) {}
 
+// inspectImpressions feeds incoming impressions to the usage tracker & the impression tap
+func (c *EventsServerController) inspectImpressions(raw []byte, metadata *dtos.Metadata) {
+	var parsed []dtos.ImpressionsDTO
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		c.logger.Error("error when parsing impressions prior to inspecting them: ", err)
+		return
+	}
+
+	if c.usageTracker != nil {
+		batch := make(usage.Batch)
+		batch.AddImpressions(metadata, parsed)
+		c.usageTracker.Record(batch)
+	}
+
+	if c.tap != nil {
+		c.tap.OfferBulk(metadata, parsed)
+	}
+}
+
+// inspectImpressionCounts marks the counted flags as in use, since sdks in `none` mode post no impressions
+func (c *EventsServerController) inspectImpressionCounts(raw []byte) {
+	var parsed dtos.ImpressionsCountDTO
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		c.logger.Error("error when parsing impression counts prior to inspecting them: ", err)
+		return
+	}
+
+	flags := make([]string, 0, len(parsed.PerFeature))
+	for _, count := range parsed.PerFeature {
+		flags = append(flags, count.FeatureName)
+	}
+	c.usageTracker.Seen(flags...)
+}
+
 func (c *EventsServerController) submitImpressionsToListener(raw []byte, metadata *dtos.Metadata) {
 	var parsed []dtos.ImpressionsDTO
 	err := json.Unmarshal(raw, &parsed)
@@ -6,12 +6,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/splitio/go-split-commons/v4/dtos"
 	"github.com/splitio/go-toolkit/v5/logging"
 	"github.com/splitio/split-synchronizer/v5/splitio/common/impressionlistener"
 	ilMock "github.com/splitio/split-synchronizer/v5/splitio/common/impressionlistener/mocks"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/usage"
 	mw "github.com/splitio/split-synchronizer/v5/splitio/proxy/controllers/middleware"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/internal"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/tasks/mocks"
@@ -80,6 +82,8 @@ func TestPostImpressionsbulk(t *testing.T) {
 			},
 		},
 		apikeyValidator.IsValid,
+		nil,
+		nil,
 	)
 	controller.Register(group, group)
 
@@ -154,6 +158,8 @@ func TestPostEventsBulk(t *testing.T) {
 		}, // events
 		&ilMock.ImpressionBulkListenerMock{},
 		apikeyValidator.IsValid,
+		nil,
+		nil,
 	)
 	controller.Register(group, group)
 
@@ -213,6 +219,8 @@ func TestPostImpressionsCounts(t *testing.T) {
 		&mocks.MockDeferredRecordingTask{}, // events
 		&ilMock.ImpressionBulkListenerMock{},
 		apikeyValidator.IsValid,
+		nil,
+		nil,
 	)
 	controller.Register(group, group)
 
@@ -255,6 +263,8 @@ func TestPostLegacyMetrics(t *testing.T) {
 		&mocks.MockDeferredRecordingTask{}, // events
 		&ilMock.ImpressionBulkListenerMock{},
 		apikeyValidator.IsValid,
+		nil,
+		nil,
 	)
 	controller.Register(group, group)
 
@@ -368,6 +378,8 @@ func TestPostBeaconImpressionsbulk(t *testing.T) {
 			},
 		},
 		apikeyValidator.IsValid,
+		nil,
+		nil,
 	)
 	controller.Register(group, group)
 
@@ -443,6 +455,8 @@ func TestPostBeaconEventsBulk(t *testing.T) {
 		}, // events
 		&ilMock.ImpressionBulkListenerMock{},
 		apikeyValidator.IsValid,
+		nil,
+		nil,
 	)
 	controller.Register(group, group)
 
@@ -503,6 +517,8 @@ func TestPostBeaconImpressionsCounts(t *testing.T) {
 		&mocks.MockDeferredRecordingTask{}, // events
 		&ilMock.ImpressionBulkListenerMock{},
 		apikeyValidator.IsValid,
+		nil,
+		nil,
 	)
 	controller.Register(group, group)
 
@@ -529,3 +545,84 @@ func TestPostBeaconImpressionsCounts(t *testing.T) {
 		t.Error("Status code should be 200 and is ", resp.Code)
 	}
 }
+
+func TestImpressionsUsageTracking(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	resp := httptest.NewRecorder()
+	ctx, router := gin.CreateTestContext(resp)
+
+	tracker := usage.NewTracker(time.Hour, time.Hour)
+	group := router.Group("/api")
+	controller := NewEventsServerController(
+		logging.NewLogger(nil),
+		&mocks.MockDeferredRecordingTask{StageCall: func(rawData interface{}) error { return nil }},
+		&mocks.MockDeferredRecordingTask{},
+		&mocks.MockDeferredRecordingTask{},
+		nil,
+		mw.NewAPIKeyValidator([]string{"someApiKey"}).IsValid,
+		tracker,
+		nil,
+	)
+	controller.Register(group, group)
+
+	serialized, _ := json.Marshal([]dtos.ImpressionsDTO{
+		{TestName: "test1", KeyImpressions: []dtos.ImpressionDTO{{KeyName: "k1", Treatment: "on"}, {KeyName: "k2", Treatment: "off"}}},
+	})
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/api/testImpressions/bulk", bytes.NewBuffer(serialized))
+	ctx.Request.Header.Set("Authorization", "Bearer someApiKey")
+	ctx.Request.Header.Set("SplitSDKVersion", "go-1.1.1")
+	router.ServeHTTP(resp, ctx.Request)
+	if resp.Code != 200 {
+		t.Error("Status code should be 200 and is ", resp.Code)
+	}
+
+	// usage is tracked asynchronously
+	for attempt := 0; attempt < 100 && len(tracker.Usage()) == 0; attempt++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	tracked := tracker.Usage()
+	if len(tracked) != 1 || tracked[0].Flag != "test1" || tracked[0].Total != 2 || tracked[0].SDKs["go-1.1.1"] != 2 {
+		t.Error("wrong usage tracked: ", tracked)
+	}
+}
+
+func TestImpressionCountsUsageTracking(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	resp := httptest.NewRecorder()
+	ctx, router := gin.CreateTestContext(resp)
+
+	tracker := usage.NewTracker(time.Hour, time.Hour)
+	group := router.Group("/api")
+	controller := NewEventsServerController(
+		logging.NewLogger(nil),
+		&mocks.MockDeferredRecordingTask{},
+		&mocks.MockDeferredRecordingTask{StageCall: func(rawData interface{}) error { return nil }},
+		&mocks.MockDeferredRecordingTask{},
+		nil,
+		mw.NewAPIKeyValidator([]string{"someApiKey"}).IsValid,
+		tracker,
+		nil,
+	)
+	controller.Register(group, group)
+
+	serialized, _ := json.Marshal(dtos.ImpressionsCountDTO{PerFeature: []dtos.ImpressionsInTimeFrameDTO{{FeatureName: "test1", TimeFrame: 1, RawCount: 3}}})
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/api/testImpressions/count", bytes.NewBuffer(serialized))
+	ctx.Request.Header.Set("Authorization", "Bearer someApiKey")
+	ctx.Request.Header.Set("SplitSDKVersion", "go-1.1.1")
+	router.ServeHTTP(resp, ctx.Request)
+	if resp.Code != 200 {
+		t.Error("Status code should be 200 and is ", resp.Code)
+	}
+
+	// counts are inspected asynchronously
+	for attempt := 0; attempt < 100 && len(tracker.Stale([]string{"test1"}).Flags) != 0; attempt++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if stale := tracker.Stale([]string{"test1", "test2"}); len(stale.Flags) != 1 || stale.Flags[0].Name != "test2" {
+		t.Error("counted flags should not be reported as stale. Got: ", stale.Flags)
+	}
+
+	if tracked := tracker.Usage(); len(tracked) != 0 {
+		t.Error("counts should not be added to the usage counters. Got: ", tracked)
+	}
+}
@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v4/dtos"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/evaluator"
+)
+
+// OFREP evaluation reasons
+const (
+	ofrepReasonTargetingMatch = "TARGETING_MATCH"
+	ofrepReasonDefault        = "DEFAULT"
+	ofrepReasonDisabled       = "DISABLED"
+	ofrepReasonError          = "ERROR"
+)
+
+// OFREP error codes
+const (
+	ofrepErrorParse          = "PARSE_ERROR"
+	ofrepErrorTargetingKey   = "TARGETING_KEY_MISSING"
+	ofrepErrorInvalidContext = "INVALID_CONTEXT"
+	ofrepErrorFlagNotFound   = "FLAG_NOT_FOUND"
+	ofrepErrorGeneral        = "GENERAL"
+)
+
+// well known context & metadata properties
+const (
+	ofrepContextTargetingKey  = "targetingKey"
+	ofrepContextBucketingKey  = "bucketingKey"
+	ofrepMetadataLabel        = "label"
+	ofrepMetadataChangeNumber = "changeNumber"
+)
+
+// OFREPRequest is the payload accepted by the OFREP evaluation endpoints
+type OFREPRequest struct {
+	Context map[string]interface{} `json:"context"`
+}
+
+// OFREPEvaluation is the result of evaluating a flag, following the OFREP specification.
+// Successful evaluations populate value/variant/reason, failed ones errorCode/errorDetails
+type OFREPEvaluation struct {
+	Key          string                 `json:"key"`
+	Value        interface{}            `json:"value,omitempty"`
+	Variant      string                 `json:"variant,omitempty"`
+	Reason       string                 `json:"reason,omitempty"`
+	ErrorCode    string                 `json:"errorCode,omitempty"`
+	ErrorDetails string                 `json:"errorDetails,omitempty"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// OFREPBulkResponse contains the evaluation of every flag known by the proxy
+type OFREPBulkResponse struct {
+	Flags []OFREPEvaluation `json:"flags"`
+}
+
+// ofrepContext is the parsed version of the evaluation context
+type ofrepContext struct {
+	key        evaluator.Key
+	attributes map[string]interface{}
+}
+
+// OFREPEvaluateFlag evaluates a single flag:
+// curl -X POST -H 'Authorization: Bearer <apikey>' -d '{"context":{"targetingKey":"user1","plan":"gold"}}' http://localhost:3000/ofrep/v1/evaluate/flags/<flag>
+func (c *EvaluationServerController) OFREPEvaluateFlag(ctx *gin.Context) {
+	flag := ctx.Param("key")
+	evalCtx, errCode, err := parseOFREPContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, OFREPEvaluation{Key: flag, ErrorCode: errCode, ErrorDetails: err.Error()})
+		return
+	}
+
+	result := c.evaluator.Evaluate(evalCtx.key, flag, evalCtx.attributes)
+	evaluation := toOFREPEvaluation(flag, result)
+	switch evaluation.ErrorCode {
+	case ofrepErrorFlagNotFound:
+		ctx.JSON(http.StatusNotFound, evaluation)
+		return
+	case "":
+	default:
+		ctx.JSON(http.StatusBadRequest, evaluation)
+		return
+	}
+
+	c.recordImpressions(evaluationMetadata(ctx), map[string][]dtos.ImpressionDTO{flag: {{
+		KeyName:      evalCtx.key.MatchingKey,
+		BucketingKey: evalCtx.key.BucketingKey,
+		Treatment:    result.Treatment,
+		Label:        result.Label,
+		ChangeNumber: result.ChangeNumber,
+		Time:         time.Now().UnixNano() / int64(time.Millisecond),
+	}}})
+	ctx.JSON(http.StatusOK, evaluation)
+}
+
+// OFREPEvaluateFlags evaluates all the flags for a context. No impressions are recorded, since providers call this
+// endpoint to cache the whole flag set rather than to get treatments that will actually be used.
+// The ETag changes with the flags & the context, so that providers can poll using If-None-Match:
+// curl -X POST -H 'Authorization: Bearer <apikey>' -d '{"context":{"targetingKey":"user1"}}' http://localhost:3000/ofrep/v1/evaluate/flags
+func (c *EvaluationServerController) OFREPEvaluateFlags(ctx *gin.Context) {
+	evalCtx, errCode, err := parseOFREPContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errorCode": errCode, "errorDetails": err.Error()})
+		return
+	}
+
+	etag, err := c.ofrepETag(evalCtx)
+	if err != nil {
+		c.logger.Error("error building etag for bulk OFREP evaluation: ", err)
+	} else {
+		if match := ctx.GetHeader("If-None-Match"); match != "" && match == etag {
+			ctx.Status(http.StatusNotModified)
+			return
+		}
+		ctx.Header("ETag", etag)
+	}
+
+	names := c.flags.SplitNames()
+	sort.Strings(names)
+	results := c.evaluator.EvaluateMany(evalCtx.key, names, evalCtx.attributes)
+	response := OFREPBulkResponse{Flags: make([]OFREPEvaluation, 0, len(names))}
+	for _, name := range names {
+		response.Flags = append(response.Flags, toOFREPEvaluation(name, results[name]))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ofrepETag combines the current change number with a hash of the evaluation context
+func (c *EvaluationServerController) ofrepETag(evalCtx *ofrepContext) (string, error) {
+	cn, err := c.flags.ChangeNumber()
+	if err != nil {
+		return "", fmt.Errorf("error fetching change number: %w", err)
+	}
+
+	// maps are serialized with sorted keys, so equal contexts yield the same hash
+	serialized, err := json.Marshal(map[string]interface{}{"key": evalCtx.key, "attributes": evalCtx.attributes})
+	if err != nil {
+		return "", fmt.Errorf("error serializing context: %w", err)
+	}
+
+	hasher := fnv.New64a()
+	hasher.Write(serialized)
+	return fmt.Sprintf(`"%d-%x"`, cn, hasher.Sum64()), nil
+}
+
+func parseOFREPContext(ctx *gin.Context) (*ofrepContext, string, error) {
+	var req OFREPRequest
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
+		return nil, ofrepErrorParse, fmt.Errorf("error parsing request body: %w", err)
+	}
+
+	if req.Context == nil {
+		return nil, ofrepErrorInvalidContext, fmt.Errorf("an evaluation context is required")
+	}
+
+	rawKey, ok := req.Context[ofrepContextTargetingKey]
+	if !ok {
+		return nil, ofrepErrorTargetingKey, fmt.Errorf("'%s' is required in the evaluation context", ofrepContextTargetingKey)
+	}
+
+	key, ok := rawKey.(string)
+	if !ok || key == "" {
+		return nil, ofrepErrorInvalidContext, fmt.Errorf("'%s' must be a non-empty string", ofrepContextTargetingKey)
+	}
+
+	parsed := &ofrepContext{key: evaluator.Key{MatchingKey: key}, attributes: make(map[string]interface{}, len(req.Context))}
+	for name, value := range req.Context {
+		switch name {
+		case ofrepContextTargetingKey:
+		case ofrepContextBucketingKey:
+			bucketingKey, ok := value.(string)
+			if !ok {
+				return nil, ofrepErrorInvalidContext, fmt.Errorf("'%s' must be a string", ofrepContextBucketingKey)
+			}
+			parsed.key.BucketingKey = bucketingKey
+		default:
+			parsed.attributes[name] = value
+		}
+	}
+	return parsed, "", nil
+}
+
+// toOFREPEvaluation maps treatments to variants & configs to values. When a treatment has no config attached,
+// the treatment itself is used as value
+func toOFREPEvaluation(flag string, result *evaluator.Result) OFREPEvaluation {
+	switch result.Label {
+	case evaluator.LabelDefinitionNotFound:
+		return OFREPEvaluation{Key: flag, Reason: ofrepReasonError, ErrorCode: ofrepErrorFlagNotFound, ErrorDetails: fmt.Sprintf("flag '%s' not found", flag)}
+	case evaluator.LabelException, evaluator.LabelUnsupportedMatcher:
+		return OFREPEvaluation{Key: flag, Reason: ofrepReasonError, ErrorCode: ofrepErrorGeneral, ErrorDetails: result.Label}
+	}
+
+	evaluation := OFREPEvaluation{
+		Key:      flag,
+		Variant:  result.Treatment,
+		Value:    result.Treatment,
+		Reason:   ofrepReasonTargetingMatch,
+		Metadata: map[string]interface{}{ofrepMetadataLabel: result.Label, ofrepMetadataChangeNumber: result.ChangeNumber},
+	}
+
+	switch result.Label {
+	case evaluator.LabelKilled:
+		evaluation.Reason = ofrepReasonDisabled
+	case evaluator.LabelDefaultRule, evaluator.LabelNotInSplit:
+		evaluation.Reason = ofrepReasonDefault
+	}
+
+	if result.Config != nil {
+		var config interface{}
+		if err := json.Unmarshal([]byte(*result.Config), &config); err == nil {
+			evaluation.Value = config
+		} else {
+			evaluation.Value = *result.Config
+		}
+	}
+	return evaluation
+}
@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/storage/inmemory/mutexmap"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/evaluator"
+	mw "github.com/splitio/split-synchronizer/v5/splitio/proxy/controllers/middleware"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/tasks/mocks"
+)
+
+func TestOFREP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logging.NewLogger(nil)
+
+	splits := mutexmap.NewMMSplitStorage()
+	splits.Update([]dtos.SplitDTO{
+		{
+			Name:              "split1",
+			Status:            "ACTIVE",
+			ChangeNumber:      123,
+			DefaultTreatment:  "off",
+			TrafficAllocation: 100,
+			Conditions: []dtos.ConditionDTO{{
+				ConditionType: "WHITELIST",
+				Label:         "gold users",
+				MatcherGroup: dtos.MatcherGroupDTO{Combiner: "AND", Matchers: []dtos.MatcherDTO{{
+					MatcherType: "WHITELIST",
+					KeySelector: &dtos.KeySelectorDTO{Attribute: func() *string { s := "plan"; return &s }()},
+					Whitelist:   &dtos.WhitelistMatcherDataDTO{Whitelist: []string{"gold"}},
+				}}},
+				Partitions: []dtos.PartitionDTO{{Treatment: "on", Size: 100}},
+			}},
+			Configurations: map[string]string{"on": `{"color":"blue"}`},
+		},
+		{Name: "killed", Status: "ACTIVE", ChangeNumber: 124, Killed: true, DefaultTreatment: "off"},
+	}, nil, 124)
+
+	impressions := 0
+	sink := &mocks.MockDeferredRecordingTask{StageCall: func(rawData interface{}) error { impressions++; return nil }}
+
+	router := gin.New()
+	group := router.Group("/ofrep")
+	group.Use(mw.NewAPIKeyValidator([]string{"someApiKey"}).AsMiddleware)
+	NewEvaluationServerController(logger, evaluator.NewEvaluator(splits, mutexmap.NewMMSegmentStorage(), logger), splits, NewEventsServerController(logger, sink, nil, nil, nil, nil, nil, nil)).RegisterOFREP(group)
+
+	do := func(path string, body string, headers map[string]string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer someApiKey")
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	var evaluation OFREPEvaluation
+	resp := do("/ofrep/v1/evaluate/flags/split1", `{"context":{"targetingKey":"key1","plan":"gold"}}`, nil)
+	json.Unmarshal(resp.Body.Bytes(), &evaluation)
+	if resp.Code != http.StatusOK || evaluation.Variant != "on" || evaluation.Reason != "TARGETING_MATCH" {
+		t.Error("wrong evaluation: ", resp.Code, resp.Body.String())
+	}
+	if value, ok := evaluation.Value.(map[string]interface{}); !ok || value["color"] != "blue" {
+		t.Error("config should be used as value. Got: ", evaluation.Value)
+	}
+	if impressions != 1 {
+		t.Error("an impression should be recorded for single evaluations. Got: ", impressions)
+	}
+
+	resp = do("/ofrep/v1/evaluate/flags/split1", `{"context":{"targetingKey":"key1"}}`, nil)
+	json.Unmarshal(resp.Body.Bytes(), &evaluation)
+	if resp.Code != http.StatusOK || evaluation.Value != "off" || evaluation.Reason != "DEFAULT" {
+		t.Error("the treatment should be used as value when there's no config. Got: ", resp.Body.String())
+	}
+
+	resp = do("/ofrep/v1/evaluate/flags/killed", `{"context":{"targetingKey":"key1"}}`, nil)
+	json.Unmarshal(resp.Body.Bytes(), &evaluation)
+	if evaluation.Reason != "DISABLED" {
+		t.Error("killed splits should be reported as disabled. Got: ", resp.Body.String())
+	}
+
+	errorCases := []struct {
+		path string
+		body string
+		code int
+		err  string
+	}{
+		{"/ofrep/v1/evaluate/flags/nonexistent", `{"context":{"targetingKey":"key1"}}`, http.StatusNotFound, "FLAG_NOT_FOUND"},
+		{"/ofrep/v1/evaluate/flags/split1", `{"context":{"plan":"gold"}}`, http.StatusBadRequest, "TARGETING_KEY_MISSING"},
+		{"/ofrep/v1/evaluate/flags/split1", `{"context":{"targetingKey":3}}`, http.StatusBadRequest, "INVALID_CONTEXT"},
+		{"/ofrep/v1/evaluate/flags/split1", `{"context":`, http.StatusBadRequest, "PARSE_ERROR"},
+	}
+	for _, errorCase := range errorCases {
+		resp = do(errorCase.path, errorCase.body, nil)
+		evaluation = OFREPEvaluation{}
+		json.Unmarshal(resp.Body.Bytes(), &evaluation)
+		if resp.Code != errorCase.code || evaluation.ErrorCode != errorCase.err {
+			t.Errorf("expected %d/%s for body '%s'. Got: %d %s", errorCase.code, errorCase.err, errorCase.body, resp.Code, resp.Body.String())
+		}
+	}
+
+	impressions = 0
+	resp = do("/ofrep/v1/evaluate/flags", `{"context":{"targetingKey":"key1","plan":"gold"}}`, nil)
+	var bulk OFREPBulkResponse
+	json.Unmarshal(resp.Body.Bytes(), &bulk)
+	if resp.Code != http.StatusOK || len(bulk.Flags) != 2 || bulk.Flags[0].Key != "killed" || bulk.Flags[1].Variant != "on" {
+		t.Error("wrong bulk evaluation: ", resp.Code, resp.Body.String())
+	}
+	if impressions != 0 {
+		t.Error("bulk evaluations should not record impressions")
+	}
+
+	etag := resp.Header().Get("ETag")
+	if resp = do("/ofrep/v1/evaluate/flags", `{"context":{"plan":"gold","targetingKey":"key1"}}`, map[string]string{"If-None-Match": etag}); resp.Code != http.StatusNotModified {
+		t.Error("matching etags should return a 304. Got: ", resp.Code)
+	}
+
+	if resp = do("/ofrep/v1/evaluate/flags", `{"context":{"targetingKey":"key2"}}`, map[string]string{"If-None-Match": etag}); resp.Code != http.StatusOK {
+		t.Error("a different context should not match the etag. Got: ", resp.Code)
+	}
+}
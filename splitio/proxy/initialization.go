@@ -1,14 +1,18 @@
 package proxy
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/url"
+	"path/filepath"
 	"time"
 
 	"strings"
 
 	"github.com/splitio/go-split-commons/v4/conf"
+	"github.com/splitio/go-split-commons/v4/service"
 	"github.com/splitio/go-split-commons/v4/service/api"
 	"github.com/splitio/go-split-commons/v4/synchronizer"
 	"github.com/splitio/go-split-commons/v4/tasks"
@@ -18,32 +22,76 @@ import (
 	"github.com/splitio/split-synchronizer/v5/splitio/admin"
 	adminCommon "github.com/splitio/split-synchronizer/v5/splitio/admin/common"
 	"github.com/splitio/split-synchronizer/v5/splitio/common"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/changefeed"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/evaluator"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/export"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/history"
 	"github.com/splitio/split-synchronizer/v5/splitio/common/impressionlistener"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/impressiontap"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/queues"
 	"github.com/splitio/split-synchronizer/v5/splitio/common/snapshot"
 	ssync "github.com/splitio/split-synchronizer/v5/splitio/common/sync"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/usage"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/webhooks"
 	hcApplication "github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/application"
 	hcAppCounter "github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/application/counter"
 	hcServices "github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/services"
 	hcServicesCounter "github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/services/counter"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/caching"
 	pconf "github.com/splitio/split-synchronizer/v5/splitio/proxy/conf"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/offline"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
 	pTasks "github.com/splitio/split-synchronizer/v5/splitio/proxy/tasks"
 	"github.com/splitio/split-synchronizer/v5/splitio/util"
 )
 
+// how often to check whether offline output files need to be rotated, in seconds
+const offlineRotationPeriod = 60
+
+// how often to check for expired split overrides, in seconds
+const overridesExpirationPeriod = 10
+
+// how often to check for health transitions to be published in the change feed, in seconds
+const changeFeedHealthCheckPeriod = 5
+
+// max number of concurrent admin impression taps
+const maxImpressionTaps = 5
+
 // Start initialize in proxy mode
 func Start(logger logging.LoggerInterface, cfg *pconf.Main) error {
 
-	clientKey, err := util.GetClientKey(cfg.Apikey)
-	if err != nil {
-		return common.NewInitError(fmt.Errorf("error parsing client key from provided apikey: %w", err), common.ExitInvalidApikey)
+	offlineMode := cfg.Offline.Enabled
+	snapSource := cfg.Initialization.Snapshot
+	var clientKey string
+	if offlineMode {
+		if cfg.Offline.SnapshotDirectory == "" || cfg.Offline.OutputDirectory == "" {
+			return common.NewInitError(errors.New("offline mode requires both a snapshot and an output directory"), common.ExitInvalidConfiguration)
+		}
+		if cfg.Snapshot.Directory != "" && filepath.Clean(cfg.Snapshot.Directory) == filepath.Clean(cfg.Offline.SnapshotDirectory) {
+			return common.NewInitError(errors.New("scheduled snapshots cannot be written to the directory watched in offline mode"), common.ExitInvalidConfiguration)
+		}
+		if cfg.Offline.WatchRateMs < 1000 {
+			return common.NewInitError(fmt.Errorf("offline watch rate must be at least 1000ms. Got: %d", cfg.Offline.WatchRateMs), common.ExitInvalidConfiguration)
+		}
+		snapSource = cfg.Offline.SnapshotDirectory
+		logger.Info("Running in offline mode. No requests will be made to split servers")
+	} else {
+		var err error
+		clientKey, err = util.GetClientKey(cfg.Apikey)
+		if err != nil {
+			return common.NewInitError(fmt.Errorf("error parsing client key from provided apikey: %w", err), common.ExitInvalidApikey)
+		}
 	}
 
 	// Initialization of DB
 	var dbpath = persistent.BoltInMemoryMode
-	if snapFile := cfg.Initialization.Snapshot; snapFile != "" {
+	snapFile, err := resolveSnapshotFile(snapSource, logger)
+	if err != nil {
+		return err
+	}
+
+	if snapFile != "" {
 		snap, err := snapshot.DecodeFromFile(snapFile)
 		if err != nil {
 			return fmt.Errorf("error parsing snapshot file: %w", err)
@@ -70,12 +118,9 @@ func Start(logger logging.LoggerInterface, cfg *pconf.Main) error {
 	advanced := cfg.BuildAdvancedConfig()
 	metadata := util.GetMetadata(cfg.IPAddressEnabled, true)
 
-	// Setup fetchers & recorders
-	splitAPI := api.NewSplitAPI(cfg.Apikey, *advanced, logger, metadata)
-
 	// Proxy storages already implement the observable interface, so no need to wrap them
-	splitStorage := storage.NewProxySplitStorage(dbInstance, logger, cfg.Initialization.Snapshot != "")
-	segmentStorage := storage.NewProxySegmentStorage(dbInstance, logger, cfg.Initialization.Snapshot != "")
+	splitStorage := storage.NewProxySplitStorage(dbInstance, logger, snapFile != "")
+	segmentStorage := storage.NewProxySegmentStorage(dbInstance, logger, snapFile != "")
 
 	// Local telemetry
 	tbufferSize := int(cfg.Sync.Advanced.TelemetryBuffer)
@@ -90,104 +135,244 @@ func Start(logger logging.LoggerInterface, cfg *pconf.Main) error {
 	// Healcheck Monitor
 	splitsConfig, segmentsConfig := getAppCounterConfigs()
 	appMonitor := hcApplication.NewMonitorImp(splitsConfig, segmentsConfig, nil, logger)
-	servicesMonitor := hcServices.NewMonitorImp(getServicesCountersConfig(*advanced), logger)
+	var servicesMonitor *hcServices.MonitorImp
+	if offlineMode {
+		// there are no external dependencies to check
+		servicesMonitor = hcServices.NewMonitorImp(nil, logger)
+	} else {
+		servicesMonitor = hcServices.NewMonitorImp(getServicesCountersConfig(*advanced), logger)
+	}
+
+	// Setup recorders. When offline, everything is written to local files
+	var impressionRecorder, eventsRecorder, telemetrySinkRecorder pTasks.RawRecorder
+	var telemetryRecorder *api.HTTPTelemetryRecorder
+	var outputFiles []*offline.FileRecorder
+	if offlineMode {
+		outputFiles, err = setupOfflineRecorders(&cfg.Offline, logger)
+		if err != nil {
+			return common.NewInitError(fmt.Errorf("error setting up offline output files: %w", err), common.ExitTaskInitialization)
+		}
+		impressionRecorder, eventsRecorder, telemetrySinkRecorder = outputFiles[0], outputFiles[1], outputFiles[2]
+	} else {
+		impressionRecorder = api.NewHTTPImpressionRecorder(cfg.Apikey, *advanced, logger)
+		eventsRecorder = api.NewHTTPEventsRecorder(cfg.Apikey, *advanced, logger)
+		telemetryRecorder = api.NewHTTPTelemetryRecorder(cfg.Apikey, *advanced, logger)
+		telemetrySinkRecorder = telemetryRecorder
+	}
+
+	// keep a copy of the impressions & events recorded in the export directory (if any)
+	exportSink, err := export.Setup(&cfg.Integrations.FileExport, logger)
+	if err != nil {
+		return common.NewInitError(fmt.Errorf("error instantiating file export: %w", err), common.ExitTaskInitialization)
+	}
+	if exportSink != nil {
+		impressionRecorder = pTasks.NewExportingRecorder(impressionRecorder, exportSink, logger)
+		eventsRecorder = pTasks.NewExportingRecorder(eventsRecorder, exportSink, logger)
+	}
 
 	// Creating Workers and Tasks
-	telemetryRecorder := api.NewHTTPTelemetryRecorder(cfg.Apikey, *advanced, logger)
-	telemetryConfigTask := pTasks.NewTelemetryConfigFlushTask(telemetryRecorder, logger, 1, tbufferSize, tworkers)
-	telemetryUsageTask := pTasks.NewTelemetryUsageFlushTask(telemetryRecorder, logger, 1, tbufferSize, tworkers)
-	telemetryKeysClientSideTask := pTasks.NewTelemetryKeysClientSideFlushTask(telemetryRecorder, logger, 1, tbufferSize, tworkers)
-	telemetryKeysServerSideTask := pTasks.NewTelemetryKeysServerSideFlushTask(telemetryRecorder, logger, 1, tbufferSize, tworkers)
+	telemetryConfigTask := pTasks.NewTelemetryConfigFlushTask(telemetrySinkRecorder, logger, 1, tbufferSize, tworkers)
+	telemetryUsageTask := pTasks.NewTelemetryUsageFlushTask(telemetrySinkRecorder, logger, 1, tbufferSize, tworkers)
+	telemetryKeysClientSideTask := pTasks.NewTelemetryKeysClientSideFlushTask(telemetrySinkRecorder, logger, 1, tbufferSize, tworkers)
+	telemetryKeysServerSideTask := pTasks.NewTelemetryKeysServerSideFlushTask(telemetrySinkRecorder, logger, 1, tbufferSize, tworkers)
 
 	// impression bulks & counts - events
 	ibufferSize := int(cfg.Sync.Advanced.ImpressionsBuffer)
 	iworkers := int(cfg.Sync.Advanced.ImpressionsWorkers)
-	impressionRecorder := api.NewHTTPImpressionRecorder(cfg.Apikey, *advanced, logger)
 	impressionTask := pTasks.NewImpressionsFlushTask(impressionRecorder, logger, 1, ibufferSize, iworkers)
 	impressionCountTask := pTasks.NewImpressionCountFlushTask(impressionRecorder, logger, 1, ibufferSize, iworkers)
-	eventsRecorder := api.NewHTTPEventsRecorder(cfg.Apikey, *advanced, logger)
 	eventsTask := pTasks.NewEventsFlushTask(eventsRecorder, logger, 1, int(cfg.Sync.Advanced.EventsBuffer), int(cfg.Sync.Advanced.EventsWorkers))
 
-	// setup split, segments & local telemetry API interactions
-	workers := synchronizer.Workers{
-		SplitFetcher: caching.NewCacheAwareSplitSync(splitStorage, splitAPI.SplitFetcher, logger, localTelemetryStorage, httpCache, appMonitor),
-		SegmentFetcher: caching.NewCacheAwareSegmentSync(splitStorage, segmentStorage, splitAPI.SegmentFetcher, logger, localTelemetryStorage, httpCache,
-			appMonitor),
-		TelemetryRecorder: telemetry.NewTelemetrySynchronizer(localTelemetryStorage, telemetryRecorder, splitStorage, segmentStorage, logger,
-			metadata, localTelemetryStorage),
-	}
+	snapshotLoader := storage.NewSnapshotLoader(splitStorage, segmentStorage, httpCache, logger)
+	overrides := storage.NewOverridesManager(splitStorage, httpCache, logger)
+	overridesExpirationTask := storage.NewOverridesExpirationTask(overrides, logger, overridesExpirationPeriod)
+	splitHistory := history.NewInMemoryStore(int(cfg.Admin.FlagHistorySize))
 
-	// setup periodic tasks in case streaming is disabled or we need to fall back to polling
-	stasks := synchronizer.SplitTasks{
-		SplitSyncTask: tasks.NewFetchSplitsTask(workers.SplitFetcher, int(cfg.Sync.SplitRefreshRateMs/1000), logger),
-		SegmentSyncTask: tasks.NewFetchSegmentsTask(workers.SegmentFetcher, int(cfg.Sync.SegmentRefreshRateMs/1000), advanced.SegmentWorkers,
-			advanced.SegmentQueueSize, logger),
-		TelemetrySyncTask:        tasks.NewRecordTelemetryTask(workers.TelemetryRecorder, int(cfg.Sync.Advanced.InternalMetricsRateMs), logger),
-		ImpressionSyncTask:       impressionTask,
-		ImpressionsCountSyncTask: impressionCountTask,
-		EventSyncTask:            eventsTask,
-	}
+	// publish changes, sync errors & health transitions to the admin change feed
+	changeFeed := changefeed.New(int(cfg.Admin.EventsBuffer))
+	changeFeedTask := changefeed.NewHealthWatcherTask(changeFeed, appMonitor, servicesMonitor, logger, changeFeedHealthCheckPeriod)
 
-	// Creating Synchronizer for tasks
-	sync := ssync.NewSynchronizer(*advanced, stasks, workers, logger, nil, []tasks.Task{telemetryConfigTask, telemetryUsageTask, telemetryKeysClientSideTask, telemetryKeysServerSideTask}, appMonitor)
-
-	mstatus := make(chan int, 1)
-	syncManager, err := synchronizer.NewSynchronizerManager(
-		sync,
-		logger,
-		*advanced,
-		splitAPI.AuthClient,
-		splitStorage,
-		mstatus,
-		localTelemetryStorage,
-		metadata,
-		&clientKey,
-		appMonitor,
-	)
-	if err != nil {
-		return common.NewInitError(fmt.Errorf("error instantiating sync manager: %w", err), common.ExitTaskInitialization)
+	// scheduled snapshots of the local db, taken in both online & offline modes
+	var snapshotTask tasks.Task
+	if dir := cfg.Snapshot.Directory; dir != "" {
+		if cfg.Snapshot.RateMs < 1000 {
+			return common.NewInitError(fmt.Errorf("snapshot rate must be at least 1000ms. Got: %d", cfg.Snapshot.RateMs), common.ExitInvalidConfiguration)
+		}
+		snapshotWriter, err := snapshot.NewWriter(&snapshot.WriterConfig{
+			Logger:       logger,
+			Source:       dbInstance,
+			ChangeNumber: func() int64 { cn, _ := splitStorage.ChangeNumber(); return cn },
+			Directory:    dir,
+			Prefix:       snapshot.ProxyPrefix,
+			Retention:    int(cfg.Snapshot.MaxToKeep),
+		})
+		if err != nil {
+			return common.NewInitError(fmt.Errorf("error instantiating snapshot writer: %w", err), common.ExitTaskInitialization)
+		}
+		snapshotTask = snapshot.NewPeriodicWriterTask(snapshotWriter, logger, int(cfg.Snapshot.RateMs/1000))
 	}
 
-	// Run Sync Manager
-	before := time.Now()
-	go syncManager.Start()
-	status := <-mstatus
-	switch status {
-	case synchronizer.Ready:
-		logger.Info("Synchronizer tasks started")
+	var syncManager synchronizer.Manager
+	var splitFetcher service.SplitFetcher
+	var resyncer ssync.Resyncer
+	var runtimeClosers []io.Closer
+	if offlineMode {
+		offlineTasks := []tasks.Task{
+			impressionTask, impressionCountTask, eventsTask,
+			telemetryConfigTask, telemetryUsageTask, telemetryKeysClientSideTask, telemetryKeysServerSideTask,
+			offline.NewSnapshotWatcherTask(cfg.Offline.SnapshotDirectory, snapFile, snapshotLoader, appMonitor, logger, int(cfg.Offline.WatchRateMs/1000)),
+			offline.NewRotationTask(outputFiles, logger, offlineRotationPeriod),
+			overridesExpirationTask, changeFeedTask,
+		}
+		if snapshotTask != nil {
+			offlineTasks = append(offlineTasks, snapshotTask)
+		}
+		if exportSink != nil {
+			offlineTasks = append(offlineTasks, export.NewRotationTask(exportSink, logger, export.DefaultRotationPeriod))
+		}
+		closers := make([]io.Closer, 0, len(outputFiles))
+		for _, f := range outputFiles {
+			closers = append(closers, f)
+		}
+		syncManager = offline.NewManager(offlineTasks, closers, logger)
+		splitFetcher = offline.NewSplitFetcher(splitStorage)
+		syncManager.Start()
 		appMonitor.Start()
 		servicesMonitor.Start()
-		workers.TelemetryRecorder.SynchronizeConfig(
-			telemetry.InitConfig{
-				AdvancedConfig: *advanced,
-				TaskPeriods: conf.TaskPeriods{
-					SplitSync:     int(cfg.Sync.SplitRefreshRateMs / 1000),
-					SegmentSync:   int(cfg.Sync.SegmentRefreshRateMs / 1000),
-					TelemetrySync: int(cfg.Sync.Advanced.InternalMetricsRateMs / 1000),
-				},
-				ListenerEnabled: cfg.Integrations.ImpressionListener.Endpoint != "",
-			},
-			time.Since(before).Milliseconds(),
-			map[string]int64{cfg.Apikey: 1},
-			nil,
+	} else {
+		splitAPI := api.NewSplitAPI(cfg.Apikey, *advanced, logger, metadata)
+		splitFetcher = splitAPI.SplitFetcher
+
+		// fetches & streaming notifications are driven by the upstream change number, which
+		// the one served to sdks is ahead of while overrides are set
+		upstreamSplitStorage := splitStorage.Upstream()
+
+		// notify changes to the configured webhook (if any)
+		fetcherSplitStorage, fetcherSegmentStorage, webhookDispatcher, err := webhooks.Setup(
+			&cfg.Integrations.Webhook,
+			webhooks.NewNotifyingSplitStorage(upstreamSplitStorage, changeFeed, nil, logger),
+			webhooks.NewNotifyingSegmentStorage(segmentStorage, splitStorage, changeFeed, nil, logger),
+			logger,
 		)
-	case synchronizer.Error:
-		if cfg.Initialization.Snapshot == "" {
-			// If we started from a snapshot, failure to sinchronize should not bring the app down
-			logger.Error("Initial synchronization failed. Either split is unreachable or the APIKey is incorrect. Aborting execution.")
+		if err != nil {
+			return common.NewInitError(err, common.ExitTaskInitialization)
+		}
+		if webhookDispatcher != nil {
+			runtimeClosers = append(runtimeClosers, webhookDispatcher)
+		}
+
+		// setup split, segments & local telemetry API interactions
+		recordingSplitStorage := history.NewRecordingSplitStorage(fetcherSplitStorage, splitHistory)
+		workers := synchronizer.Workers{
+			SplitFetcher: changefeed.NewSplitUpdater(history.NewSourceTrackingUpdater(
+				caching.NewCacheAwareSplitSync(recordingSplitStorage, splitAPI.SplitFetcher, logger, localTelemetryStorage, httpCache, appMonitor),
+				recordingSplitStorage,
+			), changeFeed),
+			SegmentFetcher: changefeed.NewSegmentUpdater(caching.NewCacheAwareSegmentSync(splitStorage, fetcherSegmentStorage, splitAPI.SegmentFetcher,
+				logger, localTelemetryStorage, httpCache, appMonitor), changeFeed),
+			TelemetryRecorder: telemetry.NewTelemetrySynchronizer(localTelemetryStorage, telemetryRecorder, splitStorage, segmentStorage, logger,
+				metadata, localTelemetryStorage),
+		}
+		resyncer = ssync.NewResyncer(workers.SplitFetcher, workers.SegmentFetcher, splitAPI.SplitFetcher, splitAPI.SegmentFetcher,
+			upstreamSplitStorage, segmentStorage, httpCache.EvictAll, logger)
+
+		// setup periodic tasks in case streaming is disabled or we need to fall back to polling
+		stasks := synchronizer.SplitTasks{
+			SplitSyncTask: tasks.NewFetchSplitsTask(workers.SplitFetcher, int(cfg.Sync.SplitRefreshRateMs/1000), logger),
+			SegmentSyncTask: tasks.NewFetchSegmentsTask(workers.SegmentFetcher, int(cfg.Sync.SegmentRefreshRateMs/1000), advanced.SegmentWorkers,
+				advanced.SegmentQueueSize, logger),
+			TelemetrySyncTask:        tasks.NewRecordTelemetryTask(workers.TelemetryRecorder, int(cfg.Sync.Advanced.InternalMetricsRateMs), logger),
+			ImpressionSyncTask:       impressionTask,
+			ImpressionsCountSyncTask: impressionCountTask,
+			EventSyncTask:            eventsTask,
+		}
+
+		extraTasks := []tasks.Task{
+			telemetryConfigTask, telemetryUsageTask, telemetryKeysClientSideTask, telemetryKeysServerSideTask,
+			overridesExpirationTask, changeFeedTask,
+		}
+		if snapshotTask != nil {
+			extraTasks = append(extraTasks, snapshotTask)
+		}
+		if exportSink != nil {
+			extraTasks = append(extraTasks, export.NewRotationTask(exportSink, logger, export.DefaultRotationPeriod))
+		}
+
+		// Creating Synchronizer for tasks
+		sync := ssync.NewSynchronizer(*advanced, stasks, workers, logger, nil, extraTasks, appMonitor)
+
+		mstatus := make(chan int, 1)
+		syncManager, err = synchronizer.NewSynchronizerManager(
+			sync,
+			logger,
+			*advanced,
+			splitAPI.AuthClient,
+			upstreamSplitStorage,
+			mstatus,
+			localTelemetryStorage,
+			metadata,
+			&clientKey,
+			appMonitor,
+		)
+		if err != nil {
 			return common.NewInitError(fmt.Errorf("error instantiating sync manager: %w", err), common.ExitTaskInitialization)
 		}
-		logger.Warning("Failed to perform initial sync with split servers but continuing from snapshot. Will keep retrying in BG")
+
+		// Run Sync Manager
+		before := time.Now()
+		go syncManager.Start()
+		status := <-mstatus
+		switch status {
+		case synchronizer.Ready:
+			logger.Info("Synchronizer tasks started")
+			appMonitor.Start()
+			servicesMonitor.Start()
+			workers.TelemetryRecorder.SynchronizeConfig(
+				telemetry.InitConfig{
+					AdvancedConfig: *advanced,
+					TaskPeriods: conf.TaskPeriods{
+						SplitSync:     int(cfg.Sync.SplitRefreshRateMs / 1000),
+						SegmentSync:   int(cfg.Sync.SegmentRefreshRateMs / 1000),
+						TelemetrySync: int(cfg.Sync.Advanced.InternalMetricsRateMs / 1000),
+					},
+					ListenerEnabled: cfg.Integrations.ImpressionListener.Endpoint != "",
+				},
+				time.Since(before).Milliseconds(),
+				map[string]int64{cfg.Apikey: 1},
+				nil,
+			)
+		case synchronizer.Error:
+			if snapFile == "" {
+				// If we started from a snapshot, failure to sinchronize should not bring the app down
+				logger.Error("Initial synchronization failed. Either split is unreachable or the APIKey is incorrect. Aborting execution.")
+				return common.NewInitError(fmt.Errorf("error instantiating sync manager: %w", err), common.ExitTaskInitialization)
+			}
+			logger.Warning("Failed to perform initial sync with split servers but continuing from snapshot. Will keep retrying in BG")
+		}
 	}
 
-	rtm := common.NewRuntime(false, syncManager, logger, "Split Proxy", nil, nil, appMonitor, servicesMonitor)
+	rtm := common.NewRuntime(false, syncManager, logger, "Split Proxy", nil, nil, appMonitor, servicesMonitor, runtimeClosers...)
 	storages := adminCommon.Storages{
 		SplitStorage:          splitStorage,
 		SegmentStorage:        segmentStorage,
 		LocalTelemetryStorage: localTelemetryStorage,
 	}
 
+	usageTracker := usage.NewTracker(
+		time.Duration(cfg.Admin.FlagUsageWindowMinutes)*time.Minute,
+		time.Duration(cfg.Admin.StaleFlagWindowHours)*time.Hour,
+	)
+
+	impressionTap := impressiontap.New(maxImpressionTaps)
+
 	// --------------------------- ADMIN DASHBOARD ------------------------------
+	queueManager := queues.NewManager(cfg.Admin.QueueMaxDrop, int(cfg.Admin.QueuePeekLimit),
+		pTasks.NewStagingQueue("impressions", impressionTask),
+		pTasks.NewStagingQueue("impressioncounts", impressionCountTask),
+		pTasks.NewStagingQueue("events", eventsTask),
+		pTasks.NewStagingQueue("uniquekeys.clientside", telemetryKeysClientSideTask),
+		pTasks.NewStagingQueue("uniquekeys.serverside", telemetryKeysServerSideTask),
+	)
 	cfgForAdmin := *cfg
 	cfgForAdmin.Apikey = logging.ObfuscateAPIKey(cfgForAdmin.Apikey)
 	adminServer, err := admin.NewServer(&admin.Options{
@@ -201,6 +386,14 @@ func Start(logger logging.LoggerInterface, cfg *pconf.Main) error {
 		Storages:          storages,
 		Runtime:           rtm,
 		Snapshotter:       dbInstance,
+		SnapshotLoader:    snapshotLoader,
+		Overrides:         overrides,
+		History:           splitHistory,
+		ChangeFeed:        changeFeed,
+		Usage:             usageTracker,
+		ImpressionTap:     impressionTap,
+		Queues:            queueManager,
+		Resyncer:          resyncer,
 		HcAppMonitor:      appMonitor,
 		HcServicesMonitor: servicesMonitor,
 		FullConfig:        cfgForAdmin,
@@ -217,7 +410,11 @@ func Start(logger logging.LoggerInterface, cfg *pconf.Main) error {
 		DebugOn:                     strings.ToLower(cfg.Logging.Level) == "debug" || strings.ToLower(cfg.Logging.Level) == "verbose",
 		Logger:                      logger,
 		ProxySplitStorage:           splitStorage,
-		SplitFetcher:                splitAPI.SplitFetcher,
+		SplitFetcher:                splitFetcher,
+		Evaluator:                   evaluator.NewEvaluator(splitStorage, segmentStorage, logger),
+		EvaluationFlags:             splitStorage,
+		UsageTracker:                usageTracker,
+		ImpressionTap:               impressionTap,
 		ProxySegmentStorage:         segmentStorage,
 		Telemetry:                   localTelemetryStorage,
 		ImpressionsSink:             impressionTask,
@@ -247,6 +444,45 @@ func Start(logger logging.LoggerInterface, cfg *pconf.Main) error {
 	return nil
 }
 
+// resolveSnapshotFile returns the snapshot file to load on startup. If the configured path is a directory,
+// the newest valid snapshot within it is picked. An empty string is returned if there's nothing to load.
+func resolveSnapshotFile(path string, logger logging.LoggerInterface) (string, error) {
+	if path == "" || !snapshot.IsDirectory(path) {
+		return path, nil
+	}
+
+	latest, err := snapshot.LatestInDir(path, logger)
+	if err != nil {
+		if errors.Is(err, snapshot.ErrNoSnapshotFound) {
+			logger.Warning(fmt.Sprintf("no valid snapshot found in '%s'. Starting without one", path))
+			return "", nil
+		}
+		return "", common.NewInitError(fmt.Errorf("error looking for snapshots in '%s': %w", path, err), common.ExitErrorDB)
+	}
+
+	logger.Info("Using snapshot ", latest)
+	return latest, nil
+}
+
+func setupOfflineRecorders(cfg *pconf.Offline, logger logging.LoggerInterface) ([]*offline.FileRecorder, error) {
+	prefixes := []string{"impressions", "events", "telemetry"}
+	recorders := make([]*offline.FileRecorder, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		recorder, err := offline.NewFileRecorder(&offline.FileRecorderConfig{
+			Logger:      logger,
+			Directory:   cfg.OutputDirectory,
+			Prefix:      prefix,
+			MaxFileSize: cfg.MaxFileSizeBytes,
+			MaxFileAge:  time.Duration(cfg.MaxFileAgeMs) * time.Millisecond,
+		})
+		if err != nil {
+			return nil, err
+		}
+		recorders = append(recorders, recorder)
+	}
+	return recorders, nil
+}
+
 func getAppCounterConfigs() (hcAppCounter.ThresholdConfig, hcAppCounter.ThresholdConfig) {
 	splitsConfig := hcAppCounter.DefaultThresholdConfig("Splits")
 	segmentsConfig := hcAppCounter.DefaultThresholdConfig("Segments")
@@ -0,0 +1,91 @@
+// Package offline contains the components used to run the proxy without any connectivity to split servers
+package offline
+
+import (
+	"io"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-split-commons/v4/service"
+	"github.com/splitio/go-split-commons/v4/synchronizer"
+	"github.com/splitio/go-split-commons/v4/tasks"
+	"github.com/splitio/go-toolkit/v5/logging"
+	gtSync "github.com/splitio/go-toolkit/v5/sync"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+)
+
+// Manager replaces the synchronizer manager when running offline. It only starts & stops local tasks
+type Manager struct {
+	tasks   []tasks.Task
+	closers []io.Closer
+	running *gtSync.AtomicBool
+	logger  logging.LoggerInterface
+}
+
+// NewManager constructs a new offline manager. Closers are invoked after all tasks have been stopped
+func NewManager(tasks []tasks.Task, closers []io.Closer, logger logging.LoggerInterface) *Manager {
+	return &Manager{
+		tasks:   tasks,
+		closers: closers,
+		running: gtSync.NewAtomicBool(false),
+		logger:  logger,
+	}
+}
+
+// Start starts all the tasks
+func (m *Manager) Start() {
+	if !m.running.TestAndSet() {
+		m.logger.Warning("offline manager already running")
+		return
+	}
+
+	for _, t := range m.tasks {
+		t.Start()
+	}
+}
+
+// Stop stops all the tasks (flushing any pending data) and closes the output files
+func (m *Manager) Stop() {
+	if !m.running.TestAndClear() {
+		return
+	}
+
+	for _, t := range m.tasks {
+		t.Stop(true)
+	}
+
+	for _, c := range m.closers {
+		if err := c.Close(); err != nil {
+			m.logger.Error("error closing offline component: ", err)
+		}
+	}
+}
+
+// IsRunning returns whether the manager is running or not
+func (m *Manager) IsRunning() bool {
+	return m.running.IsSet()
+}
+
+// SplitFetcher serves the whole known dataset to SDKs whose change number has no cached recipe,
+// since there's no upstream to fetch it from
+type SplitFetcher struct {
+	splits storage.ProxySplitStorage
+}
+
+// NewSplitFetcher constructs a new offline split fetcher
+func NewSplitFetcher(splits storage.ProxySplitStorage) *SplitFetcher {
+	return &SplitFetcher{splits: splits}
+}
+
+// Fetch returns all the active splits
+func (f *SplitFetcher) Fetch(changeNumber int64, fetchOptions *service.FetchOptions) (*dtos.SplitChangesDTO, error) {
+	all, err := f.splits.ChangesSince(-1)
+	if err != nil {
+		return nil, err
+	}
+	all.Since = changeNumber
+	return all, nil
+}
+
+var _ synchronizer.Manager = (*Manager)(nil)
+var _ service.SplitFetcher = (*SplitFetcher)(nil)
@@ -0,0 +1,198 @@
+package offline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-toolkit/v5/asynctask"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/tasks"
+)
+
+const (
+	defaultMaxFileSize = 100 * 1024 * 1024
+	defaultMaxFileAge  = time.Hour
+	fileSuffix         = ".ndjson"
+	partialSuffix      = ".part"
+)
+
+// Record is the structure of each line written to an output file
+type Record struct {
+	Timestamp int64             `json:"timestamp"`
+	Path      string            `json:"path"`
+	Metadata  dtos.Metadata     `json:"metadata"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Payload   json.RawMessage   `json:"payload"`
+}
+
+// FileRecorderConfig bundles the options used to build a file recorder
+type FileRecorderConfig struct {
+	Logger      logging.LoggerInterface
+	Directory   string
+	Prefix      string
+	MaxFileSize int64
+	MaxFileAge  time.Duration
+}
+
+func (c *FileRecorderConfig) normalize() {
+	if c.MaxFileSize <= 0 {
+		c.MaxFileSize = defaultMaxFileSize
+	}
+
+	if c.MaxFileAge <= 0 {
+		c.MaxFileAge = defaultMaxFileAge
+	}
+}
+
+// FileRecorder writes incoming payloads as newline-delimited json into local files instead of posting them to split servers.
+// The file being written has a `.part` suffix which is removed upon rotation, so that only complete files are picked up for transfer.
+type FileRecorder struct {
+	logger      logging.LoggerInterface
+	directory   string
+	prefix      string
+	maxFileSize int64
+	maxFileAge  time.Duration
+	current     *os.File
+	currentPath string
+	written     int64
+	openedAt    time.Time
+	mutex       sync.Mutex
+}
+
+// NewFileRecorder constructs a new file recorder
+func NewFileRecorder(cfg *FileRecorderConfig) (*FileRecorder, error) {
+	cfg.normalize()
+	if cfg.Prefix == "" {
+		return nil, errors.New("a file prefix is required")
+	}
+
+	if err := os.MkdirAll(cfg.Directory, 0755); err != nil {
+		return nil, fmt.Errorf("error creating output directory '%s': %w", cfg.Directory, err)
+	}
+
+	return &FileRecorder{
+		logger:      cfg.Logger,
+		directory:   cfg.Directory,
+		prefix:      cfg.Prefix,
+		maxFileSize: cfg.MaxFileSize,
+		maxFileAge:  cfg.MaxFileAge,
+	}, nil
+}
+
+// RecordRaw appends a payload to the current file, rotating it if needed
+func (r *FileRecorder) RecordRaw(url string, data []byte, metadata dtos.Metadata, extraHeaders map[string]string) error {
+	if !json.Valid(data) {
+		return fmt.Errorf("payload for '%s' is not valid json", url)
+	}
+
+	line, err := json.Marshal(Record{
+		Timestamp: time.Now().UnixNano() / int64(time.Millisecond),
+		Path:      url,
+		Metadata:  metadata,
+		Headers:   extraHeaders,
+		Payload:   data,
+	})
+	if err != nil {
+		return fmt.Errorf("error serializing record: %w", err)
+	}
+	line = append(line, '\n')
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.current != nil && (r.written+int64(len(line)) > r.maxFileSize || time.Since(r.openedAt) > r.maxFileAge) {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+	}
+
+	if r.current == nil {
+		if err := r.open(); err != nil {
+			return err
+		}
+	}
+
+	n, err := r.current.Write(line)
+	r.written += int64(n)
+	if err != nil {
+		return fmt.Errorf("error writing to '%s': %w", r.currentPath, err)
+	}
+	return nil
+}
+
+// RotateIfExpired closes the current file if it has been open for longer than the configured max age.
+// It's meant to be called periodically so that files are handed over even if no more data arrives.
+func (r *FileRecorder) RotateIfExpired() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.current == nil || time.Since(r.openedAt) <= r.maxFileAge {
+		return nil
+	}
+	return r.rotate()
+}
+
+// Close flushes and closes the current file
+func (r *FileRecorder) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.current == nil {
+		return nil
+	}
+	return r.rotate()
+}
+
+func (r *FileRecorder) open() error {
+	now := time.Now()
+	path := filepath.Join(r.directory, fmt.Sprintf("%s.%d%s%s", r.prefix, now.UnixNano(), fileSuffix, partialSuffix))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("error creating output file '%s': %w", path, err)
+	}
+
+	r.current = f
+	r.currentPath = path
+	r.written = 0
+	r.openedAt = now
+	return nil
+}
+
+func (r *FileRecorder) rotate() error {
+	f, path := r.current, r.currentPath
+	r.current, r.currentPath = nil, ""
+	if err := f.Sync(); err != nil {
+		r.logger.Error(fmt.Sprintf("error flushing output file '%s': %s", path, err))
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error closing output file '%s': %w", path, err)
+	}
+
+	final := path[:len(path)-len(partialSuffix)]
+	if err := os.Rename(path, final); err != nil {
+		return fmt.Errorf("error renaming output file '%s': %w", path, err)
+	}
+
+	r.logger.Debug("output file ready: ", final)
+	return nil
+}
+
+// NewRotationTask builds a task that periodically hands over output files that have exceeded their max age
+func NewRotationTask(recorders []*FileRecorder, logger logging.LoggerInterface, period int) *asynctask.AsyncTask {
+	doWork := func(l logging.LoggerInterface) error {
+		for _, r := range recorders {
+			if err := r.RotateIfExpired(); err != nil {
+				l.Error("error rotating output file: ", err)
+			}
+		}
+		return nil
+	}
+	return asynctask.NewAsyncTask("offline-file-rotation", doWork, period, nil, nil, logger)
+}
+
+var _ tasks.RawRecorder = (*FileRecorder)(nil)
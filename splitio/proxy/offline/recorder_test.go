@@ -0,0 +1,74 @@
+package offline
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+func TestFileRecorderRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "offline")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	recorder, err := NewFileRecorder(&FileRecorderConfig{
+		Logger:      logging.NewLogger(nil),
+		Directory:   dir,
+		Prefix:      "impressions",
+		MaxFileSize: 200,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	metadata := dtos.Metadata{SDKVersion: "go-1.2.3", MachineName: "m1"}
+	for i := 0; i < 3; i++ {
+		if err := recorder.RecordRaw("/testImpressions/bulk", []byte(`[{"f":"split1","i":[]}]`), metadata, nil); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if err := recorder.RecordRaw("/testImpressions/bulk", []byte("not json"), metadata, nil); err == nil {
+		t.Error("invalid payloads should be rejected")
+	}
+
+	if err := recorder.Close(); err != nil {
+		t.Error(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "impressions.*"))
+	if len(files) != 3 {
+		t.Error("each record should have been written to a different file due to size limit. Got: ", files)
+	}
+
+	for _, path := range files {
+		if strings.HasSuffix(path, partialSuffix) {
+			t.Error("no partial files should be left after closing: ", path)
+			continue
+		}
+
+		f, _ := os.Open(path)
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var record Record
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Error(err)
+			}
+			if record.Path != "/testImpressions/bulk" || record.Metadata.MachineName != "m1" {
+				t.Error("wrong record: ", record)
+			}
+		}
+		f.Close()
+	}
+}
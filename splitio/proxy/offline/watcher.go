@@ -0,0 +1,103 @@
+package offline
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/splitio/go-split-commons/v4/healthcheck/application"
+	"github.com/splitio/go-toolkit/v5/asynctask"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/snapshot"
+)
+
+// NewSnapshotWatcherTask builds a task that periodically looks for newer snapshots in a directory and loads them.
+// `current` is the path of the snapshot used on startup (if any), so that it's not loaded twice.
+// Files are only decoded when the ones newer than the last loaded change (by name or modification time).
+// Since there's no sync with split servers, the healthcheck counters are notified every time the directory is properly scanned.
+func NewSnapshotWatcherTask(
+	directory string,
+	current string,
+	loader snapshot.Loader,
+	appMonitor application.MonitorProducerInterface,
+	logger logging.LoggerInterface,
+	period int,
+) *asynctask.AsyncTask {
+	lastLoaded := current
+	lastChecked := ""
+	doWork := func(l logging.LoggerInterface) error {
+		candidates, err := snapshot.ListInDir(directory)
+		if err != nil {
+			return err
+		}
+
+		if checked := fingerprint(candidates, lastLoaded); checked != lastChecked {
+			// files failing to load are not retried until a newer one shows up or they're modified
+			lastChecked = checked
+			loaded, err := loadNewest(candidates, lastLoaded, loader, l)
+			if err != nil {
+				return err
+			}
+			if loaded != "" {
+				lastLoaded = loaded
+				lastChecked = fingerprint(candidates, lastLoaded)
+			}
+		}
+
+		appMonitor.NotifyEvent(application.Splits)
+		appMonitor.NotifyEvent(application.Segments)
+		return nil
+	}
+	return asynctask.NewAsyncTask("offline-snapshot-watcher", doWork, period, nil, nil, logger)
+}
+
+// fingerprint identifies the snapshots newer than the one loaded last by name & modification time
+func fingerprint(candidates []snapshot.FileInfo, lastLoaded string) string {
+	var builder strings.Builder
+	for _, candidate := range candidates {
+		if candidate.Path == lastLoaded {
+			break
+		}
+		fmt.Fprintf(&builder, "%s:%d;", candidate.Path, candidate.ModTime.UnixNano())
+	}
+	return builder.String()
+}
+
+// loadNewest loads the newest snapshot that can be decoded, unless it's the one loaded last.
+// Returns the path of the loaded snapshot, if any
+func loadNewest(candidates []snapshot.FileInfo, lastLoaded string, loader snapshot.Loader, logger logging.LoggerInterface) (string, error) {
+	for _, candidate := range candidates {
+		if candidate.Path == lastLoaded {
+			return "", nil
+		}
+
+		snap, err := snapshot.DecodeFromFile(candidate.Path)
+		if err != nil {
+			logger.Warning(fmt.Sprintf("skipping invalid snapshot '%s': %s", candidate.Path, err))
+			continue
+		}
+
+		if err := loadSnapshot(candidate.Path, snap, loader, logger); err != nil {
+			return "", err
+		}
+		return candidate.Path, nil
+	}
+	return "", nil
+}
+
+func loadSnapshot(path string, snap *snapshot.Snapshot, loader snapshot.Loader, logger logging.LoggerInterface) error {
+	result, err := loader.Load(snap, false)
+	if err != nil {
+		if errors.Is(err, snapshot.ErrOlderSnapshot) {
+			// don't keep retrying the same file over and over
+			logger.Warning(fmt.Sprintf("ignoring snapshot '%s': %s", path, err))
+			return nil
+		}
+		return fmt.Errorf("error loading snapshot '%s': %w", path, err)
+	}
+
+	logger.Info(fmt.Sprintf("loaded snapshot '%s' (changeNumber: %d, splits: %d, segments: %d)",
+		path, result.ChangeNumber, result.Splits, result.Segments))
+	return nil
+}
@@ -0,0 +1,80 @@
+package offline
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/snapshot"
+)
+
+type loaderMock struct {
+	loaded chan *snapshot.Snapshot
+}
+
+func (l *loaderMock) Load(snap *snapshot.Snapshot, force bool) (*snapshot.LoadResult, error) {
+	l.loaded <- snap
+	return &snapshot.LoadResult{}, nil
+}
+
+type monitorMock struct {
+	scans chan int
+}
+
+func (m *monitorMock) NotifyEvent(monitorType int) {
+	select {
+	case m.scans <- monitorType:
+	default:
+	}
+}
+func (m *monitorMock) Reset(monitorType int, value int) {}
+
+// waitScans blocks until the watcher notifies the health counters of `n` scans (2 events each)
+func (m *monitorMock) waitScans(t *testing.T, n int) {
+	for idx := 0; idx < 2*n; idx++ {
+		select {
+		case <-m.scans:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the directory should have been scanned")
+		}
+	}
+}
+
+func TestSnapshotWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "offline")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	raw, err := ioutil.ReadFile("../../../test/snapshot/proxy.snapshot")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ioutil.WriteFile(filepath.Join(dir, "split.proxy.1.1.snapshot"), raw, 0644)
+
+	loader := &loaderMock{loaded: make(chan *snapshot.Snapshot, 10)}
+	monitor := &monitorMock{scans: make(chan int, 100)}
+	task := NewSnapshotWatcherTask(dir, "", loader, monitor, logging.NewLogger(nil), 1)
+	task.Start()
+	defer task.Stop(true)
+
+	monitor.waitScans(t, 2)
+	if len(loader.loaded) != 1 {
+		t.Error("the snapshot should have been loaded once. Got: ", len(loader.loaded))
+	}
+	<-loader.loaded
+
+	ioutil.WriteFile(filepath.Join(dir, "split.proxy.3.3.snapshot"), []byte("corrupt"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "split.proxy.2.2.snapshot"), raw, 0644)
+	monitor.waitScans(t, 2)
+	if len(loader.loaded) != 1 {
+		t.Error("the newest valid snapshot should have been loaded once. Got: ", len(loader.loaded))
+	}
+}
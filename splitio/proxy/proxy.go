@@ -7,7 +7,10 @@ import (
 	"github.com/splitio/go-split-commons/v4/service"
 	"github.com/splitio/go-toolkit/v5/logging"
 
+	"github.com/splitio/split-synchronizer/v5/splitio/common/evaluator"
 	"github.com/splitio/split-synchronizer/v5/splitio/common/impressionlistener"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/impressiontap"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/usage"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/controllers"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/controllers/middleware"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
@@ -72,6 +75,18 @@ type Options struct {
 	// used to record local metrics
 	Telemetry storage.ProxyEndpointTelemetry
 
+	// used to serve treatments to clients that don't embed an sdk. Evaluation endpoints are disabled if nil
+	Evaluator *evaluator.Evaluator
+
+	// used to enumerate flags in bulk evaluations
+	EvaluationFlags controllers.FlagLister
+
+	// used to count incoming impressions per flag. Usage is not tracked if nil
+	UsageTracker *usage.Tracker
+
+	// used to forward incoming impressions to admin subscribers. Impressions are not tapped if nil
+	ImpressionTap *impressiontap.Tap
+
 	Cache *gincache.Middleware
 }
 
@@ -127,6 +142,21 @@ func New(options *Options) *API {
 	sdkController.Register(cacheableRouter)
 	eventsController.Register(regular, beacon)
 	telemetryController.Register(regular, beacon)
+	if options.Evaluator != nil {
+		evaluationController := controllers.NewEvaluationServerController(
+			options.Logger,
+			options.Evaluator,
+			options.EvaluationFlags,
+			eventsController,
+		)
+		evaluationController.Register(regular)
+
+		// OpenFeature remote evaluation protocol endpoints
+		ofrep := router.Group("/ofrep")
+		ofrep.Use(apikeyValidator.AsMiddleware)
+		ofrep.Use(gzip.Gzip(gzip.DefaultCompression))
+		evaluationController.RegisterOFREP(ofrep)
+	}
 
 	return &API{
 		server:              &http.Server{Addr: fmt.Sprintf("0.0.0.0:%d", options.Port), Handler: router},
@@ -153,6 +183,8 @@ func setupEventsController(options *Options, apikeyValidator *middleware.APIKeyV
 		options.EventsSink,
 		options.ImpressionListener,
 		apikeyValidator.IsValid,
+		options.UsageTracker,
+		options.ImpressionTap,
 	)
 }
 
@@ -179,6 +211,8 @@ func setupCorsMiddleware() func(*gin.Context) {
 		"SplitSDKVersion",
 		"SplitSDKImpressionsMode",
 		"Authorization",
+		"If-None-Match",
 	}
+	corsConfig.ExposeHeaders = []string{"ETag"}
 	return cors.New(corsConfig)
 }
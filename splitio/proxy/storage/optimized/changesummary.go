@@ -111,6 +111,28 @@ func (s *SplitChangesSummaries) AddOlderChange(added []dtos.SplitDTO, removed []
 	s.changes[cn] = summary
 }
 
+// Rebase applies a set of changes regardless of the current change number and makes `cn` the new current one.
+// It's used when the whole dataset is replaced (ie: a snapshot is loaded), so that SDKs on any known change number
+// receive the full replacement. Recipes for change numbers newer than `cn` are dropped.
+func (s *SplitChangesSummaries) Rebase(added []dtos.SplitDTO, removed []dtos.SplitDTO, cn int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	addedViews := toSplitMinimalViews(added)
+	removedViews := toSplitMinimalViews(removed)
+	for key, summary := range s.changes {
+		if key > cn {
+			delete(s.changes, key)
+			continue
+		}
+		summary.applyChange(addedViews, removedViews)
+		s.changes[key] = summary
+	}
+
+	s.currentCN = cn
+	s.changes[cn] = newEmptyChangeSummary()
+}
+
 // FetchSince returns a recipe explaining how to build a /splitChanges payload to serve an sdk which
 // is currently on changeNumber `since`. It will contain the list of splits that need to be updated, and those that need
 // to be deleted
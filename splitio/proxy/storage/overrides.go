@@ -0,0 +1,339 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-toolkit/v5/asynctask"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/gincache"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/caching"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
+)
+
+// Override types
+const (
+	OverrideKill             = "kill"
+	OverrideDefaultTreatment = "defaultTreatment"
+	OverridePin              = "pin"
+)
+
+// ErrInvalidOverride is returned when an override is malformed
+var ErrInvalidOverride = errors.New("invalid override")
+
+// ErrSplitNotFound is returned when trying to override a split that doesn't exist upstream
+var ErrSplitNotFound = errors.New("split not found")
+
+// ErrOverrideNotFound is returned when trying to remove an override that doesn't exist
+var ErrOverrideNotFound = errors.New("override not found")
+
+// SplitOverride is a local modification of a split that takes precedence over the upstream definition
+// until it expires, is removed, or a newer version of the split is received from split servers
+type SplitOverride struct {
+	SplitName        string         `json:"splitName"`
+	Type             string         `json:"type"`
+	DefaultTreatment string         `json:"defaultTreatment,omitempty"`
+	Definition       *dtos.SplitDTO `json:"definition,omitempty"`
+	BaseChangeNumber int64          `json:"baseChangeNumber"`
+	ChangeNumber     int64          `json:"changeNumber"`
+	CreatedAt        int64          `json:"createdAt"`
+	ExpiresAt        int64          `json:"expiresAt,omitempty"`
+}
+
+func (o *SplitOverride) validate() error {
+	if o.SplitName == "" {
+		return fmt.Errorf("%w: a split name is required", ErrInvalidOverride)
+	}
+
+	switch o.Type {
+	case OverrideKill:
+	case OverrideDefaultTreatment:
+		if o.DefaultTreatment == "" {
+			return fmt.Errorf("%w: a default treatment is required", ErrInvalidOverride)
+		}
+	case OverridePin:
+		if o.Definition == nil {
+			return fmt.Errorf("%w: a split definition is required", ErrInvalidOverride)
+		}
+		if o.Definition.Name != "" && o.Definition.Name != o.SplitName {
+			return fmt.Errorf("%w: definition name '%s' doesn't match '%s'", ErrInvalidOverride, o.Definition.Name, o.SplitName)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type '%s'", ErrInvalidOverride, o.Type)
+	}
+	return nil
+}
+
+func (o *SplitOverride) expired(now int64) bool {
+	return o.ExpiresAt > 0 && o.ExpiresAt <= now
+}
+
+func (o *SplitOverride) apply(split dtos.SplitDTO) dtos.SplitDTO {
+	switch o.Type {
+	case OverrideKill:
+		split.Killed = true
+		if o.DefaultTreatment != "" {
+			split.DefaultTreatment = o.DefaultTreatment
+		}
+	case OverrideDefaultTreatment:
+		split.DefaultTreatment = o.DefaultTreatment
+	case OverridePin:
+		split = *o.Definition
+		split.Name = o.SplitName
+		if split.Status == "" {
+			split.Status = "ACTIVE"
+		}
+	}
+	split.ChangeNumber = o.ChangeNumber
+	return split
+}
+
+// SetOverride registers an override for a split and applies it right away, under a synthetic change number
+// one unit above the current one so that every SDK picks it up
+func (p *ProxySplitStorageImpl) SetOverride(override SplitOverride) (*SplitOverride, error) {
+	if err := override.validate(); err != nil {
+		return nil, err
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	original, err := p.db.Fetch(override.SplitName)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching split '%s' from db: %w", override.SplitName, err)
+	}
+
+	var base dtos.SplitDTO
+	override.BaseChangeNumber = -1
+	if original != nil && original.Status == "ACTIVE" {
+		base = *original
+		override.BaseChangeNumber = original.ChangeNumber
+	} else if override.Type != OverridePin {
+		return nil, fmt.Errorf("%w: '%s'", ErrSplitNotFound, override.SplitName)
+	}
+
+	cn, _ := p.snapshot.ChangeNumber()
+	override.ChangeNumber = cn + 1
+	override.CreatedAt = time.Now().UnixNano() / int64(time.Millisecond)
+	if err := p.saveOverride(&override); err != nil {
+		return nil, err
+	}
+
+	applied := override.apply(base)
+	p.snapshot.Update([]dtos.SplitDTO{applied}, nil, override.ChangeNumber)
+	p.recipes.AddChanges([]dtos.SplitDTO{applied}, nil, override.ChangeNumber)
+	return &override, nil
+}
+
+// RemoveOverride drops the override for a split, restoring the last definition received from split servers
+func (p *ProxySplitStorageImpl) RemoveOverride(name string) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if _, ok := p.overrides[name]; !ok {
+		return fmt.Errorf("%w: '%s'", ErrOverrideNotFound, name)
+	}
+	return p.removeOverride(name)
+}
+
+// Overrides returns the currently active overrides sorted by split name
+func (p *ProxySplitStorageImpl) Overrides() []SplitOverride {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	toReturn := make([]SplitOverride, 0, len(p.overrides))
+	for _, o := range p.overrides {
+		toReturn = append(toReturn, *o)
+	}
+	sort.Slice(toReturn, func(i, j int) bool { return toReturn[i].SplitName < toReturn[j].SplitName })
+	return toReturn
+}
+
+// ExpireOverrides removes the overrides whose expiration time is older than `now` (in millis) & returns their names
+func (p *ProxySplitStorageImpl) ExpireOverrides(now int64) []string {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	expired := make([]string, 0)
+	for name, o := range p.overrides {
+		if !o.expired(now) {
+			continue
+		}
+		if err := p.removeOverride(name); err != nil {
+			p.logger.Error(fmt.Sprintf("error removing expired override for split '%s': %s", name, err))
+			continue
+		}
+		expired = append(expired, name)
+	}
+	return expired
+}
+
+// must be called with p.mtx held
+func (p *ProxySplitStorageImpl) removeOverride(name string) error {
+	if err := p.overridesDB.Delete(name); err != nil {
+		return fmt.Errorf("error removing override for split '%s' from db: %w", name, err)
+	}
+	delete(p.overrides, name)
+
+	original, err := p.db.Fetch(name)
+	if err != nil {
+		p.logger.Error(fmt.Sprintf("error fetching split '%s' from db. It will be archived: %s", name, err))
+	}
+
+	cn, _ := p.snapshot.ChangeNumber()
+	cn++
+	if original != nil && original.Status == "ACTIVE" {
+		p.snapshot.Update([]dtos.SplitDTO{*original}, nil, cn)
+		p.recipes.AddChanges([]dtos.SplitDTO{*original}, nil, cn)
+		return nil
+	}
+
+	archived := []dtos.SplitDTO{{Name: name, Status: "ARCHIVED", ChangeNumber: cn}}
+	p.snapshot.Update(nil, archived, cn)
+	p.recipes.AddChanges(nil, archived, cn)
+	return nil
+}
+
+// must be called with p.mtx held
+func (p *ProxySplitStorageImpl) saveOverride(override *SplitOverride) error {
+	asJSON, err := json.Marshal(override)
+	if err != nil {
+		return fmt.Errorf("error serializing override: %w", err)
+	}
+
+	if err := p.overridesDB.Save(persistent.SplitOverrideItem{Name: override.SplitName, JSON: string(asJSON)}); err != nil {
+		return fmt.Errorf("error storing override for split '%s': %w", override.SplitName, err)
+	}
+	p.overrides[override.SplitName] = override
+	return nil
+}
+
+// applyOverrides replaces incoming splits with their overridden versions. Overrides whose split has been updated
+// upstream after they were set are considered stale and dropped. Must be called with p.mtx held
+func (p *ProxySplitStorageImpl) applyOverrides(toAdd []dtos.SplitDTO, toRemove []dtos.SplitDTO) ([]dtos.SplitDTO, []dtos.SplitDTO) {
+	if len(p.overrides) == 0 {
+		return toAdd, toRemove
+	}
+
+	resolve := func(split *dtos.SplitDTO) *SplitOverride {
+		override, ok := p.overrides[split.Name]
+		if !ok {
+			return nil
+		}
+
+		if split.ChangeNumber > override.BaseChangeNumber {
+			p.logger.Info(fmt.Sprintf("split '%s' has been updated upstream. Dropping local override", split.Name))
+			if err := p.overridesDB.Delete(split.Name); err != nil {
+				p.logger.Error(fmt.Sprintf("error removing override for split '%s' from db: %s", split.Name, err))
+			}
+			delete(p.overrides, split.Name)
+			return nil
+		}
+		return override
+	}
+
+	newToAdd := make([]dtos.SplitDTO, 0, len(toAdd))
+	newToRemove := make([]dtos.SplitDTO, 0, len(toRemove))
+	for _, split := range toAdd {
+		if override := resolve(&split); override != nil {
+			split = override.apply(split)
+		}
+		newToAdd = append(newToAdd, split)
+	}
+
+	for _, split := range toRemove {
+		if override := resolve(&split); override != nil && override.Type == OverridePin {
+			newToAdd = append(newToAdd, override.apply(split))
+			continue
+		}
+		newToRemove = append(newToRemove, split)
+	}
+
+	return newToAdd, newToRemove
+}
+
+func overridesFromDisk(src *persistent.SplitOverridesCollection, logger logging.LoggerInterface) map[string]*SplitOverride {
+	overrides := make(map[string]*SplitOverride)
+	items, err := src.FetchAll()
+	if err != nil {
+		logger.Error("error fetching split overrides from db. They will be ignored: ", err)
+		return overrides
+	}
+
+	for _, item := range items {
+		var override SplitOverride
+		if err := json.Unmarshal([]byte(item.JSON), &override); err != nil {
+			logger.Error(fmt.Sprintf("error parsing override for split '%s': %s", item.Name, err))
+			continue
+		}
+		overrides[override.SplitName] = &override
+	}
+	return overrides
+}
+
+// SplitOverrides defines the interface used to manage local split overrides
+type SplitOverrides interface {
+	Set(override SplitOverride) (*SplitOverride, error)
+	Remove(name string) error
+	All() []SplitOverride
+}
+
+// OverridesManager wraps the proxy split storage & evicts cached splitChanges responses when overrides are modified
+type OverridesManager struct {
+	splits       *ProxySplitStorageImpl
+	cacheFlusher gincache.CacheFlusher
+	logger       logging.LoggerInterface
+}
+
+// NewOverridesManager constructs a new overrides manager
+func NewOverridesManager(splits *ProxySplitStorageImpl, cacheFlusher gincache.CacheFlusher, logger logging.LoggerInterface) *OverridesManager {
+	return &OverridesManager{splits: splits, cacheFlusher: cacheFlusher, logger: logger}
+}
+
+// Set registers an override
+func (m *OverridesManager) Set(override SplitOverride) (*SplitOverride, error) {
+	result, err := m.splits.SetOverride(override)
+	if err != nil {
+		return nil, err
+	}
+	m.cacheFlusher.EvictBySurrogate(caching.SplitSurrogate)
+	m.logger.Info(fmt.Sprintf("override of type '%s' set for split '%s'", result.Type, result.SplitName))
+	return result, nil
+}
+
+// Remove drops an override
+func (m *OverridesManager) Remove(name string) error {
+	if err := m.splits.RemoveOverride(name); err != nil {
+		return err
+	}
+	m.cacheFlusher.EvictBySurrogate(caching.SplitSurrogate)
+	m.logger.Info(fmt.Sprintf("override for split '%s' removed", name))
+	return nil
+}
+
+// All returns the active overrides
+func (m *OverridesManager) All() []SplitOverride {
+	return m.splits.Overrides()
+}
+
+func (m *OverridesManager) expire() {
+	expired := m.splits.ExpireOverrides(time.Now().UnixNano() / int64(time.Millisecond))
+	if len(expired) == 0 {
+		return
+	}
+	m.cacheFlusher.EvictBySurrogate(caching.SplitSurrogate)
+	m.logger.Info("expired overrides removed for splits: ", expired)
+}
+
+// NewOverridesExpirationTask builds a task that periodically drops expired overrides
+func NewOverridesExpirationTask(manager *OverridesManager, logger logging.LoggerInterface, period int) *asynctask.AsyncTask {
+	doWork := func(l logging.LoggerInterface) error {
+		manager.expire()
+		return nil
+	}
+	return asynctask.NewAsyncTask("split-overrides-expiration", doWork, period, nil, nil, logger)
+}
+
+var _ SplitOverrides = (*OverridesManager)(nil)
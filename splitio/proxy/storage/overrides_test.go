@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/gincache/mocks"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
+)
+
+func TestSplitOverrides(t *testing.T) {
+	logger := logging.NewLogger(nil)
+	db, err := persistent.NewBoltWrapper(persistent.BoltInMemoryMode, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	splits := NewProxySplitStorage(db, logger, false)
+	splits.Update([]dtos.SplitDTO{
+		{Name: "split1", ChangeNumber: 10, Status: "ACTIVE", DefaultTreatment: "off"},
+		{Name: "split2", ChangeNumber: 20, Status: "ACTIVE", DefaultTreatment: "off"},
+	}, nil, 20)
+
+	evictions := 0
+	manager := NewOverridesManager(splits, &mocks.CacheFlusherMock{EvictBySurrogateCall: func(string) { evictions++ }}, logger)
+
+	if _, err := manager.Set(SplitOverride{SplitName: "nonexistent", Type: OverrideKill}); !errors.Is(err, ErrSplitNotFound) {
+		t.Error("overriding an unknown split should fail. Got: ", err)
+	}
+
+	if _, err := manager.Set(SplitOverride{SplitName: "split1", Type: OverrideDefaultTreatment}); !errors.Is(err, ErrInvalidOverride) {
+		t.Error("a default treatment override without treatment should fail. Got: ", err)
+	}
+
+	override, err := manager.Set(SplitOverride{SplitName: "split1", Type: OverrideKill, DefaultTreatment: "on"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if override.BaseChangeNumber != 10 || override.ChangeNumber != 21 {
+		t.Error("wrong change numbers in override: ", override)
+	}
+
+	changes, _ := splits.ChangesSince(20)
+	if changes.Till != 21 || len(changes.Splits) != 1 || !changes.Splits[0].Killed || changes.Splits[0].DefaultTreatment != "on" {
+		t.Error("the killed split should be returned with a bumped change number. Got: ", changes)
+	}
+
+	pinned := &dtos.SplitDTO{TrafficTypeName: "user", DefaultTreatment: "v1"}
+	if _, err := manager.Set(SplitOverride{SplitName: "local_only", Type: OverridePin, Definition: pinned}); err != nil {
+		t.Error(err)
+	}
+
+	if evictions != 2 {
+		t.Error("cache should be evicted on every change. Got: ", evictions)
+	}
+
+	// overrides are restored along with the snapshot
+	restored := NewProxySplitStorage(db, logger, true)
+	if len(restored.Overrides()) != 2 {
+		t.Error("overrides should be restored from db. Got: ", restored.Overrides())
+	}
+
+	if split := restored.Split("split1"); split == nil || !split.Killed {
+		t.Error("restored split should have the override applied. Got: ", split)
+	}
+
+	if split := restored.Split("local_only"); split == nil || split.DefaultTreatment != "v1" {
+		t.Error("pinned split should be restored. Got: ", split)
+	}
+
+	// a newer version of the split upstream clears the override
+	splits.Update([]dtos.SplitDTO{{Name: "split1", ChangeNumber: 30, Status: "ACTIVE", DefaultTreatment: "off"}}, nil, 30)
+	if split := splits.Split("split1"); split.Killed || split.ChangeNumber != 30 {
+		t.Error("upstream version should prevail. Got: ", split)
+	}
+
+	if len(splits.Overrides()) != 1 {
+		t.Error("only the pinned override should remain. Got: ", splits.Overrides())
+	}
+
+	if err := manager.Remove("split1"); !errors.Is(err, ErrOverrideNotFound) {
+		t.Error("removing an unknown override should fail. Got: ", err)
+	}
+
+	// expiration of a pinned split not present upstream archives it
+	splits.mtx.Lock()
+	splits.overrides["local_only"].ExpiresAt = 1
+	splits.mtx.Unlock()
+	manager.expire()
+	if splits.Split("local_only") != nil {
+		t.Error("expired pin should have been removed")
+	}
+
+	changes, _ = splits.ChangesSince(30)
+	if changes.Till != 31 || len(changes.Splits) != 1 || changes.Splits[0].Status != "ARCHIVED" {
+		t.Error("the expired pin should be archived. Got: ", changes)
+	}
+
+	if len(splits.Overrides()) != 0 || evictions != 3 {
+		t.Error("no overrides should remain & cache should be evicted. Got: ", splits.Overrides(), evictions)
+	}
+}
+
+func TestSplitOverridesUpstreamChangeNumber(t *testing.T) {
+	logger := logging.NewLogger(nil)
+	db, err := persistent.NewBoltWrapper(persistent.BoltInMemoryMode, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	splits := NewProxySplitStorage(db, logger, false)
+	splits.Update([]dtos.SplitDTO{
+		{Name: "split1", ChangeNumber: 10, Status: "ACTIVE", DefaultTreatment: "off"},
+		{Name: "split2", ChangeNumber: 20, Status: "ACTIVE", DefaultTreatment: "off"},
+	}, nil, 20)
+
+	override, _ := splits.SetOverride(SplitOverride{SplitName: "split1", Type: OverrideKill})
+	if served, _ := splits.ChangeNumber(); served != 21 || override.ChangeNumber != 21 {
+		t.Error("the override should be served under a synthetic change number. Got: ", served)
+	}
+
+	if upstream, _ := splits.Upstream().ChangeNumber(); upstream != 20 {
+		t.Error("the synthetic change number should not be used when fetching from split servers. Got: ", upstream)
+	}
+
+	// an upstream change with the same change number as the override
+	splits.Upstream().Update([]dtos.SplitDTO{{Name: "split2", ChangeNumber: 21, Status: "ACTIVE", DefaultTreatment: "on"}}, nil, 21)
+	if upstream, _ := splits.Upstream().ChangeNumber(); upstream != 21 {
+		t.Error("the upstream change number should be updated. Got: ", upstream)
+	}
+
+	changes, _ := splits.ChangesSince(21)
+	if changes.Till != 22 || len(changes.Splits) != 1 || changes.Splits[0].Name != "split2" || changes.Splits[0].DefaultTreatment != "on" {
+		t.Error("sdks that picked up the override should receive the upstream change. Got: ", changes)
+	}
+}
+
+func TestSplitRebuildWithOverrides(t *testing.T) {
+	logger := logging.NewLogger(nil)
+	db, err := persistent.NewBoltWrapper(persistent.BoltInMemoryMode, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	splits := NewProxySplitStorage(db, logger, false)
+	splits.Update([]dtos.SplitDTO{
+		{Name: "split1", ChangeNumber: 10, Status: "ACTIVE", DefaultTreatment: "off"},
+		{Name: "split2", ChangeNumber: 20, Status: "ACTIVE", DefaultTreatment: "off"},
+	}, nil, 20)
+	splits.SetOverride(SplitOverride{SplitName: "split1", Type: OverrideKill})
+
+	splits.Upstream().(*upstreamSplitStorage).Rebuild([]dtos.SplitDTO{
+		{Name: "split1", ChangeNumber: 10, Status: "ACTIVE", DefaultTreatment: "off"},
+		{Name: "split3", ChangeNumber: 20, Status: "ACTIVE", DefaultTreatment: "on"},
+	}, 20)
+
+	if upstream := splits.UpstreamChangeNumber(); upstream != 20 {
+		t.Error("the upstream change number should be the fetched one. Got: ", upstream)
+	}
+
+	changes, _ := splits.ChangesSince(21)
+	if changes.Till != 22 || len(changes.Splits) != 3 {
+		t.Error("sdks that picked up the override should receive the rebuilt data. Got: ", changes)
+	}
+
+	for _, split := range changes.Splits {
+		switch split.Name {
+		case "split1":
+			if !split.Killed {
+				t.Error("overrides should be applied to the rebuilt data")
+			}
+		case "split2":
+			if split.Status != "ARCHIVED" {
+				t.Error("splits not fetched should be archived")
+			}
+		}
+	}
+}
+
+func TestSplitKillAheadOfUpstream(t *testing.T) {
+	logger := logging.NewLogger(nil)
+	db, err := persistent.NewBoltWrapper(persistent.BoltInMemoryMode, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	splits := NewProxySplitStorage(db, logger, false)
+	splits.Update([]dtos.SplitDTO{
+		{Name: "split1", ChangeNumber: 10, Status: "ACTIVE", DefaultTreatment: "off"},
+		{Name: "split2", ChangeNumber: 20, Status: "ACTIVE", DefaultTreatment: "off"},
+	}, nil, 20)
+	splits.SetOverride(SplitOverride{SplitName: "split1", Type: OverrideKill})
+
+	// kills older than the upstream change number are ignored
+	splits.KillLocally("split2", "on", 15)
+	if split := splits.Split("split2"); split.Killed {
+		t.Error("stale kills should be ignored")
+	}
+
+	// a streaming kill with a change number below the synthetic one used by the override
+	splits.KillLocally("split2", "on", 21)
+	changes, _ := splits.ChangesSince(21)
+	if changes.Till != 22 || len(changes.Splits) != 1 || !changes.Splits[0].Killed || changes.Splits[0].DefaultTreatment != "on" {
+		t.Error("sdks that picked up the override should receive the kill. Got: ", changes)
+	}
+
+	if upstream := splits.UpstreamChangeNumber(); upstream != 20 {
+		t.Error("the upstream change number should be kept so that the kill's change is fetched. Got: ", upstream)
+	}
+}
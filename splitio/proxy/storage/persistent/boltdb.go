@@ -60,6 +60,11 @@ func (b *BoltDBWrapper) Unlock() {
 	b.mutex.Unlock()
 }
 
+// Close releases the underlying db file
+func (b *BoltDBWrapper) Close() error {
+	return b.wrapped.Close()
+}
+
 // GetRawSnapshot dumps all the contents of the db into a raw byte buffer
 func (b *BoltDBWrapper) GetRawSnapshot() ([]byte, error) {
 	var buffer bytes.Buffer
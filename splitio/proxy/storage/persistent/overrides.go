@@ -0,0 +1,70 @@
+package persistent
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+const splitOverridesCollectionName = "SPLIT_OVERRIDES_COLLECTION"
+
+// SplitOverrideItem represents a locally defined override for a split, serialized as JSON
+type SplitOverrideItem struct {
+	Name string
+	JSON string
+}
+
+// SplitOverridesCollection stores the overrides set by the user so that they survive restarts
+type SplitOverridesCollection struct {
+	collection CollectionWrapper
+	mutex      sync.RWMutex
+}
+
+// NewSplitOverridesCollection returns an instance of SplitOverridesCollection
+func NewSplitOverridesCollection(db DBWrapper, logger logging.LoggerInterface) *SplitOverridesCollection {
+	return &SplitOverridesCollection{
+		collection: &BoltDBCollectionWrapper{db: db, name: splitOverridesCollectionName, logger: logger},
+	}
+}
+
+// Save stores (or replaces) the override for a split
+func (c *SplitOverridesCollection) Save(item SplitOverrideItem) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.collection.SaveAs([]byte(item.Name), item)
+}
+
+// Delete removes the override for a split
+func (c *SplitOverridesCollection) Delete(name string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.collection.Delete([]byte(name))
+}
+
+// FetchAll returns all the stored overrides
+func (c *SplitOverridesCollection) FetchAll() ([]SplitOverrideItem, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	items, err := c.collection.FetchAll()
+	if err != nil {
+		if errors.Is(err, ErrorBucketNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	toReturn := make([]SplitOverrideItem, 0, len(items))
+	for _, v := range items {
+		var item SplitOverrideItem
+		if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&item); err != nil {
+			c.collection.Logger().Error(fmt.Sprintf("error decoding override fetched from db: %s", err))
+			continue
+		}
+		toReturn = append(toReturn, item)
+	}
+	return toReturn, nil
+}
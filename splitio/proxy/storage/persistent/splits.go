@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/gob"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/splitio/go-split-commons/v4/dtos"
@@ -132,3 +134,27 @@ func (c *SplitChangesCollection) ChangeNumber() int64 {
 	defer c.mutex.RUnlock()
 	return c.changeNumber
 }
+
+// Fetch returns the stored version of a split, or nil if it's not present
+func (c *SplitChangesCollection) Fetch(name string) (*dtos.SplitDTO, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	raw, err := c.collection.FetchBy([]byte(name))
+	if err != nil {
+		if errors.Is(err, ErrorBucketNotFound) || errors.Is(err, ErrorKeyNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var item SplitChangesItem
+	if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&item); err != nil {
+		return nil, fmt.Errorf("error decoding split '%s' fetched from db: %w", name, err)
+	}
+
+	var parsed dtos.SplitDTO
+	if err := json.Unmarshal([]byte(item.JSON), &parsed); err != nil {
+		return nil, fmt.Errorf("error parsing split '%s' fetched from db: %w", name, err)
+	}
+	return &parsed, nil
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/splitio/go-split-commons/v4/dtos"
 	"github.com/splitio/go-split-commons/v4/storage"
@@ -130,8 +131,29 @@ func (s *ProxySegmentStorageImpl) Keys(segmentName string) *set.ThreadUnsafeSet
 	return toReturn
 }
 
-// SegmentContainsKey method
+// ActiveKeys returns the names of the keys that are currently part of a segment, leaving out the removed ones
+func (s *ProxySegmentStorageImpl) ActiveKeys(segmentName string) *set.ThreadUnsafeSet {
+	toReturn := set.NewSet()
+	changes, err := s.db.Fetch(segmentName)
+	if err != nil {
+		return toReturn
+	}
+
+	for name, key := range changes.Keys {
+		if !key.Removed {
+			toReturn.Add(name)
+		}
+	}
+	return toReturn
+}
+
+// SegmentContainsKey checks whether a key belongs to a segment using the mysegments cache
 func (s *ProxySegmentStorageImpl) SegmentContainsKey(segmentName string, key string) (bool, error) {
+	for _, segment := range s.mysegments.SegmentsForUser(key) {
+		if segment == segmentName {
+			return true, nil
+		}
+	}
 	return false, nil
 }
 
@@ -147,6 +169,82 @@ func (s *ProxySegmentStorageImpl) Update(name string, toAdd *set.ThreadUnsafeSet
 	return fmt.Errorf("errors updating cache: %s || errors updating db: %s", errCache.Error(), errDB.Error())
 }
 
+// ReplaceAll swaps the contents of all segments with the supplied ones. Keys (and segments) no longer present are removed.
+// The change number of each segment is set to the highest one found among its keys, regardless of the current one.
+func (s *ProxySegmentStorageImpl) ReplaceAll(segments []persistent.SegmentChangesItem) error {
+	current, err := s.db.FetchAll()
+	if err != nil && !errors.Is(err, persistent.ErrorBucketNotFound) {
+		return fmt.Errorf("error fetching current segments: %w", err)
+	}
+
+	currentByName := make(map[string]*persistent.SegmentChangesItem, len(current))
+	for idx := range current {
+		currentByName[current[idx].Name] = &current[idx]
+	}
+
+	var errs []string
+	for idx := range segments {
+		incoming := &segments[idx]
+		toAdd := set.NewSet()
+		var cn int64 = -1
+		for _, key := range incoming.Keys {
+			if !key.Removed {
+				toAdd.Add(key.Name)
+			}
+			if key.ChangeNumber > cn {
+				cn = key.ChangeNumber
+			}
+		}
+
+		toRemove := set.NewSet()
+		if existing, ok := currentByName[incoming.Name]; ok {
+			for _, key := range existing.Keys {
+				if !key.Removed && !toAdd.Has(key.Name) {
+					toRemove.Add(key.Name)
+				}
+			}
+			delete(currentByName, incoming.Name)
+		}
+
+		if err := s.replace(incoming.Name, toAdd, toRemove, cn); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	// segments not present in the new dataset are emptied
+	for name, existing := range currentByName {
+		toRemove := set.NewSet()
+		for _, key := range existing.Keys {
+			if !key.Removed {
+				toRemove.Add(key.Name)
+			}
+		}
+		if err := s.replace(name, set.NewSet(), toRemove, s.db.ChangeNumber(name)); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("errors replacing segments: %s", strings.Join(errs, " || "))
+	}
+	return nil
+}
+
+func (s *ProxySegmentStorageImpl) replace(name string, toAdd *set.ThreadUnsafeSet, toRemove *set.ThreadUnsafeSet, cn int64) error {
+	previousCount := s.nameCountCache.NamesAndCount()[name]
+	if err := s.mysegments.Update(name, toAdd, toRemove); err != nil {
+		return fmt.Errorf("error updating cache for segment '%s': %w", name, err)
+	}
+
+	if err := s.db.Update(name, toAdd, toRemove, cn); err != nil {
+		return fmt.Errorf("error updating db for segment '%s': %w", name, err)
+	}
+
+	// the tracker works with deltas, so we compute the one that takes us to the new key count
+	s.nameCountCache.Update(name, toAdd.Size(), previousCount)
+	return nil
+}
+
 // CountRemovedKeys method
 func (s *ProxySegmentStorageImpl) CountRemovedKeys(segmentName string) int {
 	segment, err := s.db.Fetch(segmentName)
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/splitio/gincache"
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/snapshot"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
+)
+
+// SnapshotLoader replaces the data served by the proxy with the contents of a snapshot without restarting it
+type SnapshotLoader struct {
+	splits       *ProxySplitStorageImpl
+	segments     *ProxySegmentStorageImpl
+	cacheFlusher gincache.CacheFlusher
+	logger       logging.LoggerInterface
+	mutex        sync.Mutex
+}
+
+// NewSnapshotLoader constructs a new snapshot loader
+func NewSnapshotLoader(
+	splits *ProxySplitStorageImpl,
+	segments *ProxySegmentStorageImpl,
+	cacheFlusher gincache.CacheFlusher,
+	logger logging.LoggerInterface,
+) *SnapshotLoader {
+	return &SnapshotLoader{
+		splits:       splits,
+		segments:     segments,
+		cacheFlusher: cacheFlusher,
+		logger:       logger,
+	}
+}
+
+// Load validates the snapshot and swaps the currently served splits & segments with the ones in it.
+// Snapshots older than the current data are rejected with snapshot.ErrOlderSnapshot unless `force` is set.
+func (l *SnapshotLoader) Load(snap *snapshot.Snapshot, force bool) (*snapshot.LoadResult, error) {
+	if err := snapshot.Validate(snap); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+
+	splits, segments, err := readSnapshot(snap, l.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var cn int64 = -1
+	for idx := range splits {
+		if splits[idx].ChangeNumber > cn {
+			cn = splits[idx].ChangeNumber
+		}
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	previous, _ := l.splits.ChangeNumber()
+	if cn < previous && !force {
+		return nil, fmt.Errorf("%w (snapshot: %d, current: %d)", snapshot.ErrOlderSnapshot, cn, previous)
+	}
+
+	l.splits.ReplaceAll(splits, cn)
+	segmentErr := l.segments.ReplaceAll(segments)
+
+	// Everything served so far is potentially stale
+	l.cacheFlusher.EvictAll()
+
+	if segmentErr != nil {
+		return nil, fmt.Errorf("splits were loaded but some segments failed: %w", segmentErr)
+	}
+
+	l.logger.Info(fmt.Sprintf("snapshot loaded. changeNumber %d -> %d", previous, cn))
+	return &snapshot.LoadResult{
+		PreviousChangeNumber: previous,
+		ChangeNumber:         cn,
+		Splits:               len(splits),
+		Segments:             len(segments),
+	}, nil
+}
+
+func readSnapshot(snap *snapshot.Snapshot, logger logging.LoggerInterface) ([]dtos.SplitDTO, []persistent.SegmentChangesItem, error) {
+	path, err := snap.WriteDataToTmpFile()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error writing snapshot data to a temporary file: %w", err)
+	}
+	defer os.Remove(path)
+
+	db, err := persistent.NewBoltWrapper(path, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening snapshot db: %w", err)
+	}
+	defer db.Close()
+
+	// a missing bucket just means there were no items of that kind when the snapshot was taken
+	splits, err := persistent.NewSplitChangesCollection(db, logger).FetchAll()
+	if err != nil && !errors.Is(err, persistent.ErrorBucketNotFound) {
+		return nil, nil, fmt.Errorf("error reading splits from snapshot: %w", err)
+	}
+
+	segments, err := persistent.NewSegmentChangesCollection(db, logger).FetchAll()
+	if err != nil && !errors.Is(err, persistent.ErrorBucketNotFound) {
+		return nil, nil, fmt.Errorf("error reading segments from snapshot: %w", err)
+	}
+
+	return splits, segments, nil
+}
+
+var _ snapshot.Loader = (*SnapshotLoader)(nil)
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/gincache/mocks"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/snapshot"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
+)
+
+func TestSnapshotLoader(t *testing.T) {
+	snap, err := snapshot.DecodeFromFile("../../../test/snapshot/proxy.snapshot")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	logger := logging.NewLogger(nil)
+	db, err := persistent.NewBoltWrapper(persistent.BoltInMemoryMode, nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	splits := NewProxySplitStorage(db, logger, false)
+	segments := NewProxySegmentStorage(db, logger, false)
+	splits.Update([]dtos.SplitDTO{{Name: "stale_split", ChangeNumber: 1, Status: "ACTIVE", TrafficTypeName: "user"}}, nil, 1)
+	segments.Update("stale_segment", set.NewSet("key1"), set.NewSet(), 1)
+
+	evictions := 0
+	loader := NewSnapshotLoader(splits, segments, &mocks.CacheFlusherMock{EvictAllCall: func() { evictions++ }}, logger)
+
+	result, err := loader.Load(snap, false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if result.PreviousChangeNumber != 1 {
+		t.Error("previous change number should be 1. Got: ", result.PreviousChangeNumber)
+	}
+
+	if cn, _ := splits.ChangeNumber(); cn != result.ChangeNumber {
+		t.Error("storage change number should match the snapshot's. Got: ", cn, result.ChangeNumber)
+	}
+
+	if splits.Split("stale_split") != nil {
+		t.Error("splits not present in the snapshot should have been removed")
+	}
+
+	if len(splits.SplitNames()) != result.Splits {
+		t.Error("all active splits in the snapshot should be loaded")
+	}
+
+	changes, _ := splits.ChangesSince(1)
+	if changes == nil || changes.Till != result.ChangeNumber {
+		t.Error("sdks on the previous change number should be able to catch up. Got: ", changes)
+	}
+
+	if mine, _ := segments.SegmentsFor("key1"); len(mine) != 0 {
+		t.Error("keys from segments not present in the snapshot should have been removed")
+	}
+
+	if evictions != 1 {
+		t.Error("cache should have been flushed once. Got: ", evictions)
+	}
+
+	// a newer change makes the snapshot outdated
+	splits.Update([]dtos.SplitDTO{{Name: "newer", ChangeNumber: result.ChangeNumber + 100, Status: "ACTIVE"}}, nil, result.ChangeNumber+100)
+	if _, err := loader.Load(snap, false); !errors.Is(err, snapshot.ErrOlderSnapshot) {
+		t.Error("an older snapshot should be rejected. Got: ", err)
+	}
+
+	if _, err := loader.Load(snap, true); err != nil {
+		t.Error("an older snapshot should be accepted when forced. Got: ", err)
+	}
+
+	if cn, _ := splits.ChangeNumber(); cn != result.ChangeNumber {
+		t.Error("change number should have been rolled back. Got: ", cn)
+	}
+
+	if splits.Split("newer") != nil {
+		t.Error("split not present in the snapshot should have been removed on forced load")
+	}
+}
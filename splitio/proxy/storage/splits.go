@@ -32,10 +32,14 @@ type ProxySplitStorage interface {
 
 // ProxySplitStorageImpl implements the ProxySplitStorage interface and the SplitProducer interface
 type ProxySplitStorageImpl struct {
-	snapshot mutexmap.MMSplitStorage
-	recipes  optimized.SplitChangesSummaries
-	db       *persistent.SplitChangesCollection
-	mtx      sync.Mutex
+	snapshot    mutexmap.MMSplitStorage
+	recipes     optimized.SplitChangesSummaries
+	db          *persistent.SplitChangesCollection
+	overrides   map[string]*SplitOverride
+	overridesDB *persistent.SplitOverridesCollection
+	upstreamCN  int64 // last change number received from split servers. The snapshot's is ahead of it once overrides are set
+	logger      logging.LoggerInterface
+	mtx         sync.Mutex
 }
 
 // NewProxySplitStorage instantiates a new proxy storage that wraps an in-memory snapshot of the last known,
@@ -43,14 +47,21 @@ type ProxySplitStorageImpl struct {
 // for snapshot purposes
 func NewProxySplitStorage(db persistent.DBWrapper, logger logging.LoggerInterface, restoreBackup bool) *ProxySplitStorageImpl {
 	disk := persistent.NewSplitChangesCollection(db, logger)
+	overridesDisk := persistent.NewSplitOverridesCollection(db, logger)
 	snapshot := mutexmap.NewMMSplitStorage()
+	overrides := overridesFromDisk(overridesDisk, logger)
+	upstreamCN := int64(-1)
 	if restoreBackup {
-		snapshotFromDisk(snapshot, disk, logger)
+		upstreamCN = snapshotFromDisk(snapshot, disk, overrides, logger)
 	}
 	return &ProxySplitStorageImpl{
-		snapshot: *snapshot,
-		recipes:  *optimized.NewSplitChangesSummaries(maxRecipes),
-		db:       disk,
+		snapshot:    *snapshot,
+		recipes:     *optimized.NewSplitChangesSummaries(maxRecipes),
+		db:          disk,
+		overrides:   overrides,
+		overridesDB: overridesDisk,
+		upstreamCN:  upstreamCN,
+		logger:      logger,
 	}
 }
 
@@ -89,23 +100,97 @@ func (p *ProxySplitStorageImpl) ChangesSince(since int64) (*dtos.SplitChangesDTO
 	return &dtos.SplitChangesDTO{Since: since, Till: till, Splits: all}, nil
 }
 
-// KillLocally marks a split as killed in the current storage
+// KillLocally marks a split as killed in the current storage. Kills are checked against the upstream change number
+// (the served one is ahead of it while overrides are set), and served under a change number that sdks pick up
 func (p *ProxySplitStorageImpl) KillLocally(splitName string, defaultTreatment string, changeNumber int64) {
-	p.snapshot.KillLocally(splitName, defaultTreatment, changeNumber)
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if changeNumber <= p.upstreamCN {
+		return
+	}
+
+	split, err := p.db.Fetch(splitName)
+	if err != nil || split == nil || split.Status != "ACTIVE" {
+		return
+	}
+
+	split.Killed = true
+	split.DefaultTreatment = defaultTreatment
+	split.ChangeNumber = changeNumber
+
+	// the upstream change number is kept, so that the change the kill belongs to is still fetched
+	toAdd := []dtos.SplitDTO{*split}
+	p.db.Update(toAdd, nil, p.upstreamCN)
+	toAdd, toRemove := p.applyOverrides(toAdd, nil)
+	served := p.servedChangeNumber(changeNumber)
+	p.snapshot.Update(toAdd, toRemove, served)
+	p.recipes.AddChanges(toAdd, toRemove, served)
 }
 
 // Update the storage atomically
 func (p *ProxySplitStorageImpl) Update(toAdd []dtos.SplitDTO, toRemove []dtos.SplitDTO, changeNumber int64) {
 
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.upstreamCN = changeNumber
 	if len(toAdd) == 0 && len(toRemove) == 0 {
 		return
 	}
 
+	// the db always keeps the upstream version of splits, so that overrides can be reverted
+	p.db.Update(toAdd, toRemove, changeNumber)
+	toAdd, toRemove = p.applyOverrides(toAdd, toRemove)
+	served := p.servedChangeNumber(changeNumber)
+	p.snapshot.Update(toAdd, toRemove, served)
+	p.recipes.AddChanges(toAdd, toRemove, served)
+}
+
+// ReplaceAll swaps the current set of active splits with the supplied one. Splits no longer present are archived.
+// Unlike `Update`, the change number is applied even if it's older than the current one.
+func (p *ProxySplitStorageImpl) ReplaceAll(splits []dtos.SplitDTO, changeNumber int64) {
+	p.replaceAll(splits, changeNumber, false)
+}
+
+// Rebuild swaps the current set of active splits with the ones fetched from scratch from split servers.
+// Unlike `ReplaceAll`, the result is served under a change number ahead of the current one,
+// so that sdks on any previously served change number (including the synthetic ones used by overrides) pick it up
+func (p *ProxySplitStorageImpl) Rebuild(splits []dtos.SplitDTO, changeNumber int64) {
+	p.replaceAll(splits, changeNumber, true)
+}
+
+func (p *ProxySplitStorageImpl) replaceAll(splits []dtos.SplitDTO, changeNumber int64, ahead bool) {
+	toAdd := make([]dtos.SplitDTO, 0, len(splits))
+	incoming := make(map[string]struct{}, len(splits))
+	for _, split := range splits {
+		if split.Status == "ACTIVE" {
+			toAdd = append(toAdd, split)
+			incoming[split.Name] = struct{}{}
+		}
+	}
+
 	p.mtx.Lock()
-	p.snapshot.Update(toAdd, toRemove, changeNumber)
-	p.recipes.AddChanges(toAdd, toRemove, changeNumber)
+	defer p.mtx.Unlock()
+	toRemove := make([]dtos.SplitDTO, 0)
+	for _, current := range p.snapshot.All() {
+		if override, ok := p.overrides[current.Name]; ok && override.Type == OverridePin {
+			continue // pinned definitions are kept regardless of the incoming data
+		}
+		if _, ok := incoming[current.Name]; !ok {
+			current.Status = "ARCHIVED"
+			toRemove = append(toRemove, current)
+		}
+	}
+
+	served := changeNumber
+	if ahead {
+		served = p.servedChangeNumber(changeNumber)
+	}
+
+	p.upstreamCN = changeNumber
 	p.db.Update(toAdd, toRemove, changeNumber)
-	p.mtx.Unlock()
+	toAdd, toRemove = p.applyOverrides(toAdd, toRemove)
+	p.snapshot.Update(toAdd, toRemove, served)
+	p.recipes.Rebase(toAdd, toRemove, served)
 }
 
 // RegisterOlderCn registers payload associated to a fetch request for an old `since` for which we don't
@@ -123,16 +208,42 @@ func (p *ProxySplitStorageImpl) RegisterOlderCn(payload *dtos.SplitChangesDTO) {
 	p.recipes.AddOlderChange(toAdd, toDel, payload.Till)
 }
 
-// ChangeNumber returns the current change number
+// ChangeNumber returns the change number served to sdks
 func (p *ProxySplitStorageImpl) ChangeNumber() (int64, error) {
 	return p.snapshot.ChangeNumber()
 }
 
-// SetChangeNumber updates the change number
+// UpstreamChangeNumber returns the last change number received from split servers
+func (p *ProxySplitStorageImpl) UpstreamChangeNumber() int64 {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.upstreamCN
+}
+
+// SetChangeNumber updates both the served & upstream change numbers
 func (p *ProxySplitStorageImpl) SetChangeNumber(cn int64) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.upstreamCN = cn
 	return p.snapshot.SetChangeNumber(cn)
 }
 
+// Upstream returns a view of the storage reporting the last change number received from split servers,
+// to be used by the components fetching from them
+func (p *ProxySplitStorageImpl) Upstream() storage.SplitStorage {
+	return &upstreamSplitStorage{ProxySplitStorageImpl: p}
+}
+
+// servedChangeNumber returns the change number under which an upstream change is served. Overrides are served under
+// synthetic change numbers ahead of the upstream one, so sdks that picked an override up would miss upstream changes
+// with a change number not greater than it. Must be called with p.mtx held
+func (p *ProxySplitStorageImpl) servedChangeNumber(upstream int64) int64 {
+	if current, _ := p.snapshot.ChangeNumber(); upstream <= current {
+		return current + 1
+	}
+	return upstream
+}
+
 // Remove deletes a split by name
 func (p *ProxySplitStorageImpl) Remove(name string) {
 	p.snapshot.Remove(name)
@@ -165,22 +276,63 @@ func (p *ProxySplitStorageImpl) Count() int {
 	return len(p.SplitNames())
 }
 
-func snapshotFromDisk(dst *mutexmap.MMSplitStorage, src *persistent.SplitChangesCollection, logger logging.LoggerInterface) {
+// snapshotFromDisk loads the stored splits into the snapshot & returns the upstream change number
+func snapshotFromDisk(
+	dst *mutexmap.MMSplitStorage,
+	src *persistent.SplitChangesCollection,
+	overrides map[string]*SplitOverride,
+	logger logging.LoggerInterface,
+) int64 {
 	cn := src.ChangeNumber()
 	all, err := src.FetchAll()
 	if err != nil {
 		logger.Error("error parsing splits from snapshot. No data will be available!: ", err)
-		return
+		return cn
 	}
 
 	var filtered []dtos.SplitDTO
 	for idx := range all {
+		// the collection's change number is not persisted, so we take the newest one found among the stored splits
+		if all[idx].ChangeNumber > cn {
+			cn = all[idx].ChangeNumber
+		}
 		if all[idx].Status == "ACTIVE" {
 			filtered = append(filtered, all[idx])
 		}
 	}
 
+	upstreamCN := cn
+
+	// re-apply the overrides that were active when the proxy was shut down
+	overridden := make(map[string]struct{})
+	for idx := range filtered {
+		if override, ok := overrides[filtered[idx].Name]; ok {
+			filtered[idx] = override.apply(filtered[idx])
+			overridden[filtered[idx].Name] = struct{}{}
+		}
+	}
+
+	for name, override := range overrides {
+		if _, ok := overridden[name]; !ok && override.Type == OverridePin {
+			filtered = append(filtered, override.apply(dtos.SplitDTO{}))
+		}
+		if override.ChangeNumber > cn {
+			cn = override.ChangeNumber
+		}
+	}
+
 	dst.Update(filtered, nil, cn)
+	return upstreamCN
+}
+
+// upstreamSplitStorage is the view of the proxy split storage used when fetching from split servers
+type upstreamSplitStorage struct {
+	*ProxySplitStorageImpl
+}
+
+// ChangeNumber returns the last change number received from split servers
+func (u *upstreamSplitStorage) ChangeNumber() (int64, error) {
+	return u.UpstreamChangeNumber(), nil
 }
 
 var _ ProxySplitStorage = (*ProxySplitStorageImpl)(nil)
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"sync"
 
+	"github.com/splitio/go-split-commons/v4/dtos"
 	"github.com/splitio/go-split-commons/v4/tasks"
 	"github.com/splitio/go-toolkit/v5/asynctask"
 	"github.com/splitio/go-toolkit/v5/logging"
@@ -24,12 +25,20 @@ import (
 // ErrQueueFull is returned when attempting to add data to a full queue
 var ErrQueueFull = errors.New("queue is full, data not pushed")
 
+// ErrFlushInProgress is returned when attempting to inspect or modify the staged items while they're being flushed
+var ErrFlushInProgress = errors.New("flush in progress, try again later")
+
 // DeferredRecordingTask defines the interface for a task that accepts POSTs and submits them asyncrhonously
 type DeferredRecordingTask interface {
 	Stage(rawData interface{}) error
 	tasks.Task
 }
 
+// RawRecorder defines the interface for components capable of forwarding already serialized payloads
+type RawRecorder interface {
+	RecordRaw(url string, data []byte, metadata dtos.Metadata, extraHeaders map[string]string) error
+}
+
 // WorkerFactory defines the signature of a function for instantiating workers
 type WorkerFactory = func() workerpool.Worker
 
@@ -90,6 +99,64 @@ func (t *DeferredRecordingTaskImpl) Stage(data interface{}) error {
 	return nil
 }
 
+// Size returns the number of staged items
+func (t *DeferredRecordingTaskImpl) Size() int64 {
+	return int64(len(t.queue))
+}
+
+// Peek returns up to `n` staged items without removing them
+func (t *DeferredRecordingTaskImpl) Peek(n int) ([]interface{}, error) {
+	staged, err := t.rearrange(func(items []interface{}) []interface{} { return items })
+	if err != nil {
+		return nil, err
+	}
+
+	if n < len(staged) {
+		staged = staged[:n]
+	}
+	return staged, nil
+}
+
+// Drop discards the `n` oldest staged items & returns how many were actually removed
+func (t *DeferredRecordingTaskImpl) Drop(n int64) (int64, error) {
+	var dropped int64
+	_, err := t.rearrange(func(items []interface{}) []interface{} {
+		if n > int64(len(items)) {
+			n = int64(len(items))
+		}
+		dropped = n
+		return items[n:]
+	})
+	return dropped, err
+}
+
+// Flush moves the staged items to the worker pool right away
+func (t *DeferredRecordingTaskImpl) Flush() error {
+	return t.task.WakeUp()
+}
+
+// rearrange drains the staging queue & re-inserts the items returned by the callback, keeping their order.
+// Both staging new items & flushing are blocked in the meantime
+func (t *DeferredRecordingTaskImpl) rearrange(callback func([]interface{}) []interface{}) ([]interface{}, error) {
+	if !t.drainInProgress.TestAndSet() {
+		return nil, ErrFlushInProgress
+	}
+	defer t.drainInProgress.Unset()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	items := make([]interface{}, 0, len(t.queue))
+	for len(t.queue) > 0 {
+		items = append(items, <-t.queue)
+	}
+
+	kept := callback(items)
+	for _, item := range kept {
+		t.queue <- item
+	}
+	return items, nil
+}
+
 // Start starts the flushing task
 func (t *DeferredRecordingTaskImpl) Start() {
 	t.task.Start()
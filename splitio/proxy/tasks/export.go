@@ -0,0 +1,58 @@
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/export"
+)
+
+const (
+	impressionsBulkPath = "/testImpressions/bulk"
+	eventsBulkPath      = "/events/bulk"
+)
+
+// ExportingRecorder wraps a raw recorder & writes a copy of every impressions & events bulk to an export sink before
+// recording it, so that (like in the synchronizer) bulks are exported when processed regardless of the post outcome.
+// Other payloads are forwarded untouched
+type ExportingRecorder struct {
+	RawRecorder
+	sink   *export.Sink
+	logger logging.LoggerInterface
+}
+
+// NewExportingRecorder constructs a new exporting recorder
+func NewExportingRecorder(recorder RawRecorder, sink *export.Sink, logger logging.LoggerInterface) *ExportingRecorder {
+	return &ExportingRecorder{RawRecorder: recorder, sink: sink, logger: logger}
+}
+
+// RecordRaw exports the payload and records it using the wrapped recorder
+func (r *ExportingRecorder) RecordRaw(url string, data []byte, metadata dtos.Metadata, extraHeaders map[string]string) error {
+	if err := r.export(url, data, &metadata); err != nil {
+		r.logger.Error(fmt.Sprintf("error exporting payload recorded to '%s': %s", url, err))
+	}
+	return r.RawRecorder.RecordRaw(url, data, metadata, extraHeaders)
+}
+
+func (r *ExportingRecorder) export(url string, data []byte, metadata *dtos.Metadata) error {
+	switch url {
+	case impressionsBulkPath:
+		var impressions []dtos.ImpressionsDTO
+		if err := json.Unmarshal(data, &impressions); err != nil {
+			return fmt.Errorf("error parsing impressions: %w", err)
+		}
+		return r.sink.WriteImpressions(metadata, impressions)
+	case eventsBulkPath:
+		var events []dtos.EventDTO
+		if err := json.Unmarshal(data, &events); err != nil {
+			return fmt.Errorf("error parsing events: %w", err)
+		}
+		return r.sink.WriteEvents(metadata, events)
+	}
+	return nil
+}
+
+var _ RawRecorder = (*ExportingRecorder)(nil)
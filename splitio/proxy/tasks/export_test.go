@@ -0,0 +1,56 @@
+package tasks
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/export"
+)
+
+type failingRecorder struct{}
+
+func (failingRecorder) RecordRaw(string, []byte, dtos.Metadata, map[string]string) error {
+	return errors.New("split is down")
+}
+
+func TestExportingRecorder(t *testing.T) {
+	dir := t.TempDir()
+	logger := logging.NewLogger(nil)
+	sink, err := export.NewSink(&export.Config{Logger: logger, Directory: dir})
+	if err != nil {
+		t.Fatal("the sink should be created. Got: ", err)
+	}
+
+	metadata := dtos.Metadata{SDKVersion: "go-6.1.0"}
+	recorder := NewExportingRecorder(nopRecorder{}, sink, logger)
+	recorder.RecordRaw(impressionsBulkPath, []byte(`[{"f":"f1","i":[{"k":"key1","t":"on"}]}]`), metadata, nil)
+	recorder.RecordRaw(eventsBulkPath, []byte(`[{"key":"key1","eventTypeId":"click"}]`), metadata, nil)
+	recorder.RecordRaw("/testImpressions/count", []byte(`{"pf":[]}`), metadata, nil)
+
+	if err := recorder.RecordRaw(eventsBulkPath, []byte(`not json`), metadata, nil); err != nil {
+		t.Error("export errors should not fail the recording. Got: ", err)
+	}
+
+	failing := NewExportingRecorder(failingRecorder{}, sink, logger)
+	if err := failing.RecordRaw(eventsBulkPath, []byte(`[{"key":"key2","eventTypeId":"click"}]`), metadata, nil); err == nil {
+		t.Error("recording errors should be propagated")
+	}
+
+	sink.Close()
+	impressions, _ := filepath.Glob(filepath.Join(dir, export.KindImpressions, "*", "sdk=go-6.1.0", "*.ndjson"))
+	events, _ := filepath.Glob(filepath.Join(dir, export.KindEvents, "*", "sdk=go-6.1.0", "*.ndjson"))
+	if len(impressions) != 1 || len(events) != 1 {
+		t.Fatal("only impressions & events should be exported. Got: ", impressions, events)
+	}
+
+	raw, _ := ioutil.ReadFile(events[0])
+	if lines := strings.Count(string(raw), "\n"); lines != 2 {
+		t.Error("events should be exported even if recording them fails. Got: ", lines)
+	}
+}
@@ -3,7 +3,6 @@ package tasks
 import (
 	"fmt"
 
-	"github.com/splitio/go-split-commons/v4/service/api"
 	"github.com/splitio/go-toolkit/v5/common"
 	"github.com/splitio/go-toolkit/v5/logging"
 	"github.com/splitio/go-toolkit/v5/workerpool"
@@ -15,7 +14,7 @@ import (
 type ImpressionWorker struct {
 	name     string
 	logger   logging.LoggerInterface
-	recorder *api.HTTPImpressionRecorder
+	recorder RawRecorder
 }
 
 // Name returns the name of the worker
@@ -39,7 +38,7 @@ func (w *ImpressionWorker) DoWork(message interface{}) error {
 	}
 
 	extraHeaders := map[string]string{"SDKImpressionsMode": asImpressions.Mode}
-	err := w.recorder.RecordRaw("/testImpressions/bulk", asImpressions.Payload, asImpressions.Metadata, extraHeaders)
+	err := w.recorder.RecordRaw(impressionsBulkPath, asImpressions.Payload, asImpressions.Metadata, extraHeaders)
 
 	if err != nil {
 		return fmt.Errorf("error posting impressions to split servers: %w", err)
@@ -49,7 +48,7 @@ func (w *ImpressionWorker) DoWork(message interface{}) error {
 
 func newImpressionWorkerFactory(
 	name string,
-	recorder *api.HTTPImpressionRecorder,
+	recorder RawRecorder,
 	logger logging.LoggerInterface,
 ) WorkerFactory {
 	var i *int = common.IntRef(0)
@@ -61,7 +60,7 @@ func newImpressionWorkerFactory(
 
 // NewImpressionsFlushTask creates a new impressions flushing task
 func NewImpressionsFlushTask(
-	recorder *api.HTTPImpressionRecorder,
+	recorder RawRecorder,
 	logger logging.LoggerInterface,
 	period int,
 	queueSize int,
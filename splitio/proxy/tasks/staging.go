@@ -0,0 +1,76 @@
+package tasks
+
+import (
+	"encoding/json"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/queues"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/internal"
+)
+
+// StagingQueue exposes the items staged by a deferred recording task to the queue manager.
+// Each item is a bulk posted by an sdk, so sizes are expressed in bulks rather than impressions or events
+type StagingQueue struct {
+	name string
+	task *DeferredRecordingTaskImpl
+}
+
+// NewStagingQueue constructs a managed queue wrapping the staging area of a deferred recording task
+func NewStagingQueue(name string, task *DeferredRecordingTaskImpl) *StagingQueue {
+	return &StagingQueue{name: name, task: task}
+}
+
+// Name returns the name of the queue
+func (q *StagingQueue) Name() string {
+	return q.name
+}
+
+// Size returns the number of staged bulks
+func (q *StagingQueue) Size() int64 {
+	return q.task.Size()
+}
+
+// Peek returns up to `n` staged bulks with their payloads decoded
+func (q *StagingQueue) Peek(n int) ([]interface{}, error) {
+	staged, err := q.task.Peek(n)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]interface{}, 0, len(staged))
+	for _, item := range staged {
+		items = append(items, decodeStaged(item))
+	}
+	return items, nil
+}
+
+// Drop discards up to `n` of the oldest staged bulks
+func (q *StagingQueue) Drop(n int64) (int64, error) {
+	return q.task.Drop(n)
+}
+
+// Flush posts the staged bulks right away
+func (q *StagingQueue) Flush() error {
+	return q.task.Flush()
+}
+
+func decodeStaged(item interface{}) interface{} {
+	switch typed := item.(type) {
+	case *internal.RawImpressions:
+		return map[string]interface{}{"metadata": typed.Metadata, "mode": typed.Mode, "payload": decodePayload(typed.Payload)}
+	case *internal.RawData:
+		return map[string]interface{}{"metadata": typed.Metadata, "payload": decodePayload(typed.Payload)}
+	}
+	return item
+}
+
+// decodePayload parses the payload as json, falling back to a string for payloads in other formats
+func decodePayload(payload []byte) interface{} {
+	var decoded interface{}
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		return string(payload)
+	}
+	return decoded
+}
+
+var _ queues.Queue = (*StagingQueue)(nil)
+var _ queues.Flusher = (*StagingQueue)(nil)
@@ -0,0 +1,56 @@
+package tasks
+
+import (
+	"testing"
+
+	"github.com/splitio/go-split-commons/v4/dtos"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/internal"
+)
+
+type nopRecorder struct{}
+
+func (nopRecorder) RecordRaw(string, []byte, dtos.Metadata, map[string]string) error { return nil }
+
+func TestStagingQueue(t *testing.T) {
+	task := NewImpressionsFlushTask(nopRecorder{}, logging.NewLogger(nil), 1, 10, 1)
+	queue := NewStagingQueue("impressions", task)
+	metadata := dtos.Metadata{SDKVersion: "go-1.2.3"}
+	task.Stage(internal.NewRawImpressions(metadata, "optimized", []byte(`[{"f":"f1","i":[{"k":"key1"}]}]`)))
+	task.Stage(internal.NewRawImpressions(metadata, "debug", []byte(`[{"f":"f2","i":[{"k":"key2"}]}]`)))
+	task.Stage(internal.NewRawImpressions(metadata, "debug", []byte(`not json`)))
+
+	if queue.Size() != 3 {
+		t.Error("there should be 3 staged bulks. Got: ", queue.Size())
+	}
+
+	items, err := queue.Peek(2)
+	if err != nil || len(items) != 2 {
+		t.Fatal("2 bulks should be returned. Got: ", err, items)
+	}
+
+	first := items[0].(map[string]interface{})
+	payload := first["payload"].([]interface{})[0].(map[string]interface{})
+	if first["mode"] != "optimized" || first["metadata"] != metadata || payload["f"] != "f1" {
+		t.Error("the first bulk should be decoded. Got: ", first)
+	}
+
+	if queue.Size() != 3 {
+		t.Error("peeking should not remove bulks. Got: ", queue.Size())
+	}
+
+	dropped, err := queue.Drop(1)
+	if err != nil || dropped != 1 || queue.Size() != 2 {
+		t.Error("the oldest bulk should have been dropped. Got: ", err, dropped, queue.Size())
+	}
+
+	items, _ = queue.Peek(10)
+	if len(items) != 2 || items[0].(map[string]interface{})["mode"] != "debug" || items[1].(map[string]interface{})["payload"] != "not json" {
+		t.Error("the remaining bulks should keep their order. Got: ", items)
+	}
+
+	if dropped, _ := queue.Drop(10); dropped != 2 || queue.Size() != 0 {
+		t.Error("every remaining bulk should have been dropped. Got: ", dropped, queue.Size())
+	}
+}
@@ -3,7 +3,6 @@ package tasks
 import (
 	"fmt"
 
-	"github.com/splitio/go-split-commons/v4/service/api"
 	"github.com/splitio/go-toolkit/v5/common"
 	"github.com/splitio/go-toolkit/v5/logging"
 	"github.com/splitio/go-toolkit/v5/workerpool"
@@ -17,7 +16,7 @@ import (
 type TelemetryConfigWorker struct {
 	name     string
 	logger   logging.LoggerInterface
-	recorder *api.HTTPTelemetryRecorder
+	recorder RawRecorder
 }
 
 // Name returns the name of the worker
@@ -44,7 +43,7 @@ func (w *TelemetryConfigWorker) DoWork(message interface{}) error {
 	return nil
 }
 
-func newTelemetryConfigWorkerFactory(name string, recorder *api.HTTPTelemetryRecorder, logger logging.LoggerInterface) WorkerFactory {
+func newTelemetryConfigWorkerFactory(name string, recorder RawRecorder, logger logging.LoggerInterface) WorkerFactory {
 	var i *int = common.IntRef(0)
 	return func() workerpool.Worker {
 		defer func() { *i++ }()
@@ -53,7 +52,7 @@ func newTelemetryConfigWorkerFactory(name string, recorder *api.HTTPTelemetryRec
 }
 
 // NewTelemetryConfigFlushTask creates a new impressions flushing task
-func NewTelemetryConfigFlushTask(recorder *api.HTTPTelemetryRecorder, logger logging.LoggerInterface, period int, queueSize int, threads int) *DeferredRecordingTaskImpl {
+func NewTelemetryConfigFlushTask(recorder RawRecorder, logger logging.LoggerInterface, period int, queueSize int, threads int) *DeferredRecordingTaskImpl {
 	return newDeferredFlushTask(logger, newTelemetryConfigWorkerFactory("telemetry-config-worker", recorder, logger), period, queueSize, threads)
 }
 
@@ -63,7 +62,7 @@ func NewTelemetryConfigFlushTask(recorder *api.HTTPTelemetryRecorder, logger log
 type TelemetryUsageWorker struct {
 	name     string
 	logger   logging.LoggerInterface
-	recorder *api.HTTPTelemetryRecorder
+	recorder RawRecorder
 }
 
 // Name returns the name of the worker
@@ -90,7 +89,7 @@ func (w *TelemetryUsageWorker) DoWork(message interface{}) error {
 	return nil
 }
 
-func newTelemetryUsageWorkerFactory(name string, recorder *api.HTTPTelemetryRecorder, logger logging.LoggerInterface) WorkerFactory {
+func newTelemetryUsageWorkerFactory(name string, recorder RawRecorder, logger logging.LoggerInterface) WorkerFactory {
 	var i *int = common.IntRef(0)
 	return func() workerpool.Worker {
 		defer func() { *i++ }()
@@ -99,7 +98,7 @@ func newTelemetryUsageWorkerFactory(name string, recorder *api.HTTPTelemetryReco
 }
 
 // NewTelemetryUsageFlushTask creates a new impressions flushing task
-func NewTelemetryUsageFlushTask(recorder *api.HTTPTelemetryRecorder, logger logging.LoggerInterface, period int, queueSize int, threads int) *DeferredRecordingTaskImpl {
+func NewTelemetryUsageFlushTask(recorder RawRecorder, logger logging.LoggerInterface, period int, queueSize int, threads int) *DeferredRecordingTaskImpl {
 	return newDeferredFlushTask(logger, newTelemetryUsageWorkerFactory("telemetry-config-worker", recorder, logger), period, queueSize, threads)
 }
 
@@ -109,7 +108,7 @@ func NewTelemetryUsageFlushTask(recorder *api.HTTPTelemetryRecorder, logger logg
 type TelemetryKeysClientSideWorker struct {
 	name     string
 	logger   logging.LoggerInterface
-	recorder *api.HTTPTelemetryRecorder
+	recorder RawRecorder
 }
 
 // Name returns the name of the worker
@@ -136,7 +135,7 @@ func (w *TelemetryKeysClientSideWorker) DoWork(message interface{}) error {
 	return nil
 }
 
-func newTelemetryKeysClientSideWorkerFactory(name string, recorder *api.HTTPTelemetryRecorder, logger logging.LoggerInterface) WorkerFactory {
+func newTelemetryKeysClientSideWorkerFactory(name string, recorder RawRecorder, logger logging.LoggerInterface) WorkerFactory {
 	var i *int = common.IntRef(0)
 	return func() workerpool.Worker {
 		defer func() { *i++ }()
@@ -145,7 +144,7 @@ func newTelemetryKeysClientSideWorkerFactory(name string, recorder *api.HTTPTele
 }
 
 // NewTelemetryKeysClientSideFlushTask creates a new flushing task
-func NewTelemetryKeysClientSideFlushTask(recorder *api.HTTPTelemetryRecorder, logger logging.LoggerInterface, period int, queueSize int, threads int) *DeferredRecordingTaskImpl {
+func NewTelemetryKeysClientSideFlushTask(recorder RawRecorder, logger logging.LoggerInterface, period int, queueSize int, threads int) *DeferredRecordingTaskImpl {
 	return newDeferredFlushTask(logger, newTelemetryKeysClientSideWorkerFactory("telemetry-keys-client-side-worker", recorder, logger), period, queueSize, threads)
 }
 
@@ -155,7 +154,7 @@ func NewTelemetryKeysClientSideFlushTask(recorder *api.HTTPTelemetryRecorder, lo
 type TelemetryKeysServerSideWorker struct {
 	name     string
 	logger   logging.LoggerInterface
-	recorder *api.HTTPTelemetryRecorder
+	recorder RawRecorder
 }
 
 // Name returns the name of the worker
@@ -182,7 +181,7 @@ func (w *TelemetryKeysServerSideWorker) DoWork(message interface{}) error {
 	return nil
 }
 
-func newTelemetryKeysServerSideWorkerWorkerFactory(name string, recorder *api.HTTPTelemetryRecorder, logger logging.LoggerInterface) WorkerFactory {
+func newTelemetryKeysServerSideWorkerWorkerFactory(name string, recorder RawRecorder, logger logging.LoggerInterface) WorkerFactory {
 	var i *int = common.IntRef(0)
 	return func() workerpool.Worker {
 		defer func() { *i++ }()
@@ -191,6 +190,6 @@ func newTelemetryKeysServerSideWorkerWorkerFactory(name string, recorder *api.HT
 }
 
 // NewTelemetryKeysServerSideFlushTask creates a new flushing task
-func NewTelemetryKeysServerSideFlushTask(recorder *api.HTTPTelemetryRecorder, logger logging.LoggerInterface, period int, queueSize int, threads int) *DeferredRecordingTaskImpl {
+func NewTelemetryKeysServerSideFlushTask(recorder RawRecorder, logger logging.LoggerInterface, period int, queueSize int, threads int) *DeferredRecordingTaskImpl {
 	return newDeferredFlushTask(logger, newTelemetryKeysServerSideWorkerWorkerFactory("telemetry-keys-server-side-worker", recorder, logger), period, queueSize, threads)
 }